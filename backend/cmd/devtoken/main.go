@@ -0,0 +1,95 @@
+// devtoken は開発用のアクセストークンを発行するコマンド。
+//
+//	go run ./cmd/devtoken -sub <ユーザーID> [-role admin] [-ttl 24h]
+//
+// HS256 の共有鍵は -secret か環境変数 AUTH_JWT_HS256_SECRET、RS256 の秘密鍵は -key で PEM ファイルを指定する。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Getenv, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "devtoken:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, getenv func(string) string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("devtoken", flag.ContinueOnError)
+	subject := flags.String("sub", "", "ユーザーID（必須）")
+	role := flags.String("role", string(model.RoleMember), "権限")
+	ttl := flags.Duration("ttl", 24*time.Hour, "有効期間")
+	issuer := flags.String("iss", getenv("AUTH_JWT_ISSUER"), "発行者（iss）")
+	audience := flags.String("aud", getenv("AUTH_JWT_AUDIENCE"), "対象（aud）")
+	algorithm := flags.String("alg", auth.AlgorithmHS256, "署名アルゴリズム（HS256 / RS256）")
+	secret := flags.String("secret", getenv("AUTH_JWT_HS256_SECRET"), "HS256 の共有鍵")
+	keyFile := flags.String("key", "", "RS256 の秘密鍵の PEM ファイル")
+	kid := flags.String("kid", "", "鍵のID（kid）")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *subject == "" {
+		return errors.New("-sub is required")
+	}
+	if !model.UserRole(*role).Valid() {
+		return fmt.Errorf("unknown role %q", *role)
+	}
+	if *ttl <= 0 {
+		return errors.New("-ttl must be positive")
+	}
+
+	var key any
+	switch *algorithm {
+	case auth.AlgorithmHS256:
+		if len(*secret) < auth.MinHMACSecretLength {
+			return fmt.Errorf("HS256 secret must be at least %d bytes (-secret or AUTH_JWT_HS256_SECRET)", auth.MinHMACSecretLength)
+		}
+		key = []byte(*secret)
+	case auth.AlgorithmRS256:
+		if *keyFile == "" {
+			return errors.New("-key is required for RS256")
+		}
+		data, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return err
+		}
+		if key, err = jwt.ParseRSAPrivateKeyFromPEM(data); err != nil {
+			return fmt.Errorf("invalid RSA private key: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %q", *algorithm)
+	}
+
+	now := time.Now()
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   *subject,
+			Issuer:    *issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(*ttl)),
+		},
+		Role: model.UserRole(*role),
+	}
+	if *audience != "" {
+		claims.Audience = jwt.ClaimStrings{*audience}
+	}
+
+	token, err := auth.Sign(claims, *algorithm, *kid, key)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, token)
+	return err
+}
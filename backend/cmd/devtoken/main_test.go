@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestRun(t *testing.T) {
+	// 準備
+	env := map[string]string{"AUTH_JWT_HS256_SECRET": testSecret, "AUTH_JWT_ISSUER": "yoyaku"}
+	var out bytes.Buffer
+
+	// 実行
+	err := run([]string{"-sub", "user-1", "-role", "admin"}, func(key string) string { return env[key] }, &out)
+
+	// 検証: 発行したトークンをサーバーと同じ設定で検証できる
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	verifier, err := auth.NewVerifier(auth.VerifierConfig{Keys: []auth.VerificationKey{auth.HS256Key([]byte(testSecret))}, Issuer: "yoyaku"})
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	principal, err := verifier.Verify(strings.TrimSpace(out.String()))
+	if err != nil {
+		t.Fatalf("Expected token to verify, got %v", err)
+	}
+	if principal != (auth.Principal{UserID: "user-1", Role: model.RoleAdmin}) {
+		t.Errorf("Unexpected principal: %+v", principal)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "sub がない", args: []string{"-secret", testSecret}},
+		{name: "未定義の権限", args: []string{"-sub", "user-1", "-role", "owner", "-secret", testSecret}},
+		{name: "共有鍵が短い", args: []string{"-sub", "user-1", "-secret", "short"}},
+		{name: "RS256 で鍵がない", args: []string{"-sub", "user-1", "-alg", "RS256"}},
+		{name: "未対応のアルゴリズム", args: []string{"-sub", "user-1", "-alg", "none"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := run(tt.args, func(string) string { return "" }, &out); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
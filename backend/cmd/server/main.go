@@ -1,30 +1,151 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/config"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/handler"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrateMain(os.Args[2:]))
+	}
+
 	// Initialize Echo instance
 	e := echo.New()
 
 	// Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	// 予約の版（ETag）、一覧の続きのページ、再送への応答かどうかはレスポンスヘッダーで返すため、ブラウザから読めるようにする
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		ExposeHeaders: []string{"ETag", "Link", "X-Next-Cursor", "Idempotent-Replayed"},
+	}))
 
 	// Database connection
+	db, err := connectDB()
+	if err != nil {
+		e.Logger.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Schema migrations
+	autoMigrate, err := strconv.ParseBool(getEnv("DB_AUTO_MIGRATE", "true"))
+	if err != nil {
+		e.Logger.Fatalf("Invalid DB_AUTO_MIGRATE: %v", err)
+	}
+	if autoMigrate {
+		if err := migrateUp(context.Background(), db, os.Stdout); err != nil {
+			e.Logger.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
+
+	// Initialize repository
+	reservationRepo := repository.NewMySQLReservationRepository(db)
+	resourceRepo := repository.NewMySQLResourceRepository(db)
+	seriesRepo := repository.NewMySQLReservationSeriesRepository(db)
+	calendarRepo := repository.NewMySQLCalendarRepository(db)
+	userRepo := repository.NewMySQLUserRepository(db)
+	idempotencyRepo := repository.NewMySQLIdempotencyRepository(db)
+	calendarFeedRepo := repository.NewMySQLCalendarFeedRepository(db)
+	webhookRepo := repository.NewMySQLWebhookRepository(db)
+
+	// Authentication
+	authConfig, err := config.LoadAuth(os.Getenv)
+	if err != nil {
+		e.Logger.Fatalf("Failed to load authentication settings: %v", err)
+	}
+	var reservationMiddleware []echo.MiddlewareFunc
+	if authConfig.JWT != nil {
+		verifier, err := auth.NewVerifier(*authConfig.JWT)
+		if err != nil {
+			e.Logger.Fatalf("Failed to initialize JWT verifier: %v", err)
+		}
+		e.Use(auth.JWT(verifier))
+		reservationMiddleware = append(reservationMiddleware, auth.RequireAuth)
+	} else {
+		// Development only: trust the X-User-ID header and allow anonymous requests
+		fmt.Println("WARNING: JWT authentication is disabled; identifying users by the X-User-ID header")
+		e.Use(auth.UserHeader(userRepo))
+	}
+
+	// Booking policy
+	bookingPolicy, err := config.LoadBookingPolicy(os.Getenv)
+	if err != nil {
+		e.Logger.Fatalf("Failed to load booking policy: %v", err)
+	}
+
+	// Idempotency-Key
+	idempotencyKeyTTL, err := config.LoadIdempotencyKeyTTL(os.Getenv)
+	if err != nil {
+		e.Logger.Fatalf("Failed to load idempotency settings: %v", err)
+	}
+	go purgeExpiredIdempotencyKeys(e, idempotencyRepo)
+
+	// Initialize service
+	reservationService := service.NewReservationService(reservationRepo, resourceRepo, seriesRepo)
+	reservationService.SetPolicy(bookingPolicy)
+	calendarService := service.NewCalendarService(calendarRepo, resourceRepo)
+	reservationService.SetCalendar(calendarService)
+	resourceService := service.NewResourceService(resourceRepo, reservationRepo)
+	availabilityService := service.NewAvailabilityService(reservationRepo, resourceRepo)
+	availabilityService.SetCalendar(calendarService)
+	userService := service.NewUserService(userRepo)
+	calendarFeedService := service.NewCalendarFeedService(calendarFeedRepo, userRepo, resourceRepo)
+	webhookService := service.NewWebhookService(webhookRepo, nil)
+	webhookService.SetLogger(e.Logger.Errorf)
+	reservationService.SetEvents(webhookService)
+	go deliverWebhooks(e, webhookService)
+
+	// Initialize handler
+	reservationHandler := handler.NewReservationHandler(reservationService)
+	reservationHandler.SetIdempotency(idempotencyRepo, idempotencyKeyTTL)
+	resourceHandler := handler.NewResourceHandler(resourceService)
+	availabilityHandler := handler.NewAvailabilityHandler(availabilityService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+	holidayHandler := handler.NewHolidayHandler()
+	userHandler := handler.NewUserHandler(userService)
+	calendarFeedHandler := handler.NewCalendarFeedHandler(calendarFeedService, reservationService)
+	calDAVHandler := handler.NewCalDAVHandler(calendarFeedService, resourceService, reservationService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	// Register routes
+	reservationHandler.RegisterRoutes(e, reservationMiddleware...)
+	resourceHandler.RegisterRoutes(e)
+	availabilityHandler.RegisterRoutes(e, reservationMiddleware...)
+	calendarHandler.RegisterRoutes(e)
+	holidayHandler.RegisterRoutes(e)
+	userHandler.RegisterRoutes(e, reservationMiddleware...)
+	calendarFeedHandler.RegisterRoutes(e, reservationMiddleware...)
+	calDAVHandler.RegisterRoutes(e)
+	webhookHandler.RegisterRoutes(e, reservationMiddleware...)
+
+	// Health check
+	e.GET("/health", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{
+			"status": "ok", 
+			"db":     "connected",
+		})
+	})
+
+	// Start server
+	e.Logger.Fatal(e.Start(":8080"))
+}
+
+// connectDB connects to MySQL, retrying until the database accepts connections
+func connectDB() (*sql.DB, error) {
 	dbHost := getEnv("DB_HOST", "localhost")
 	dbPort := getEnv("DB_PORT", "3306")
 	dbUser := getEnv("DB_USER", "root")
@@ -33,18 +154,18 @@ func main() {
 
 	// Log DB connection info for debugging
 	fmt.Printf("Connecting to MySQL at %s:%s as %s...\n", dbHost, dbPort, dbUser)
-	
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4&collation=utf8mb4_unicode_ci", 
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4&collation=utf8mb4_unicode_ci",
 		dbUser, dbPassword, dbHost, dbPort, dbName)
-	
+
 	// Initialize DB connection with retry logic
 	var db *sql.DB
 	var err error
-	
+
 	// More aggressive retry logic
 	maxRetries := 60
 	fmt.Printf("Attempting database connection (will retry %d times)...\n", maxRetries)
-	
+
 	for i := 0; i < maxRetries; i++ {
 		db, err = sql.Open("mysql", dsn)
 		if err == nil {
@@ -54,48 +175,46 @@ func main() {
 				break
 			}
 		}
-		
+
 		fmt.Printf("Failed to connect to database (attempt %d/%d): %v\n", i+1, maxRetries, err)
 		time.Sleep(5 * time.Second)
 	}
-	
+
 	if err != nil {
-		e.Logger.Fatalf("Failed to connect to database after %d attempts: %v", maxRetries, err)
+		return nil, fmt.Errorf("gave up after %d attempts: %w", maxRetries, err)
 	}
-	
+
 	// Set database connection parameters
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(5 * time.Minute)
-	
-	// Initialize repository
-	var reservationRepo repository.ReservationRepository
-	
-	mysqlRepo, err := repository.NewMySQLReservationRepository(db)
-	if err != nil {
-		e.Logger.Fatalf("Failed to initialize MySQL repository: %v", err)
-	}
-	reservationRepo = mysqlRepo
-
-	// Initialize service
-	reservationService := service.NewReservationService(reservationRepo)
-
-	// Initialize handler
-	reservationHandler := handler.NewReservationHandler(reservationService)
-
-	// Register routes
-	reservationHandler.RegisterRoutes(e)
+	return db, nil
+}
 
-	// Health check
-	e.GET("/health", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]string{
-			"status": "ok", 
-			"db":     "connected",
-		})
-	})
+// purgeExpiredIdempotencyKeys periodically deletes expired Idempotency-Key records
+func purgeExpiredIdempotencyKeys(e *echo.Echo, repo repository.IdempotencyRepository) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if _, err := repo.DeleteExpired(now); err != nil {
+			e.Logger.Errorf("Failed to delete expired idempotency keys: %v", err)
+		}
+	}
+}
 
-	// Start server
-	e.Logger.Fatal(e.Start(":8080"))
+// deliverWebhooks sends due webhook deliveries when new ones are recorded and periodically for retries
+func deliverWebhooks(e *echo.Echo, webhooks *service.WebhookService) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-webhooks.Pending():
+		}
+		if err := webhooks.DeliverDue(context.Background(), time.Now()); err != nil {
+			e.Logger.Errorf("Failed to deliver webhooks: %v", err)
+		}
+	}
 }
 
 // getEnv gets environment variable or returns default value
@@ -10,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/handler"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
@@ -82,8 +83,7 @@ func TestInitializeApp(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
+	// テーブルはマイグレーションで作成するため、初期化ではクエリを実行しない
 
 	// Echoインスタンスを作成
 	e := echo.New()
@@ -116,19 +116,37 @@ func TestInitializeApp(t *testing.T) {
 // initializeApp関数をテスト用に抽出
 func initializeApp(e *echo.Echo, db *sql.DB) (repository.ReservationRepository, *service.ReservationService, *handler.ReservationHandler, error) {
 	// Initialize repository
-	mysqlRepo, err := repository.NewMySQLReservationRepository(db)
-	if err != nil {
-		return nil, nil, nil, err
-	}
+	mysqlRepo := repository.NewMySQLReservationRepository(db)
+	resourceRepo := repository.NewMySQLResourceRepository(db)
+	seriesRepo := repository.NewMySQLReservationSeriesRepository(db)
+	calendarRepo := repository.NewMySQLCalendarRepository(db)
+	userRepo := repository.NewMySQLUserRepository(db)
+	e.Use(auth.UserHeader(userRepo))
 
 	// Initialize service
-	reservationService := service.NewReservationService(mysqlRepo)
+	reservationService := service.NewReservationService(mysqlRepo, resourceRepo, seriesRepo)
+	calendarService := service.NewCalendarService(calendarRepo, resourceRepo)
+	reservationService.SetCalendar(calendarService)
+	resourceService := service.NewResourceService(resourceRepo, mysqlRepo)
+	availabilityService := service.NewAvailabilityService(mysqlRepo, resourceRepo)
+	availabilityService.SetCalendar(calendarService)
+	userService := service.NewUserService(userRepo)
 
 	// Initialize handler
 	reservationHandler := handler.NewReservationHandler(reservationService)
+	resourceHandler := handler.NewResourceHandler(resourceService)
+	availabilityHandler := handler.NewAvailabilityHandler(availabilityService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+	holidayHandler := handler.NewHolidayHandler()
+	userHandler := handler.NewUserHandler(userService)
 
 	// Register routes
 	reservationHandler.RegisterRoutes(e)
+	resourceHandler.RegisterRoutes(e)
+	availabilityHandler.RegisterRoutes(e)
+	calendarHandler.RegisterRoutes(e)
+	holidayHandler.RegisterRoutes(e)
+	userHandler.RegisterRoutes(e)
 
 	return mysqlRepo, reservationService, reservationHandler, nil
 }
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/migration"
+)
+
+// migrateUsage は migrate サブコマンドの使い方
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up        未適用のマイグレーションを全て適用する
+  down [N]  適用済みのマイグレーションを新しい版から N 件取り消す（既定値: 1）
+  status    マイグレーションの適用状況を表示する`
+
+// schemaMigrator はスキーマのマイグレーションを実行する（*migration.Migrator）
+type schemaMigrator interface {
+	Up(ctx context.Context) ([]migration.Migration, error)
+	Down(ctx context.Context, steps int) ([]migration.Migration, error)
+	Status(ctx context.Context) ([]migration.Status, error)
+}
+
+// migrateCommand は migrate サブコマンドの引数
+type migrateCommand struct {
+	name  string
+	steps int
+}
+
+// parseMigrateArgs は migrate サブコマンドの引数を解析する。データベースに接続する前に引数の誤りを報告するために分けている。
+func parseMigrateArgs(args []string) (migrateCommand, error) {
+	if len(args) == 0 {
+		return migrateCommand{}, errors.New("missing command")
+	}
+	command := migrateCommand{name: args[0]}
+	switch {
+	case command.name == "down" && len(args) <= 2:
+		command.steps = 1
+		if len(args) == 2 {
+			steps, err := strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return command, fmt.Errorf("invalid number of migrations %q", args[1])
+			}
+			command.steps = steps
+		}
+	case (command.name == "up" || command.name == "status") && len(args) == 1:
+	default:
+		return command, fmt.Errorf("invalid arguments %q", args)
+	}
+	return command, nil
+}
+
+// run はマイグレーションを実行し、結果を stdout に書き出す
+func (cmd migrateCommand) run(ctx context.Context, migrator schemaMigrator, stdout io.Writer) error {
+	switch cmd.name {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Fprintf(stdout, "applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(stdout, "no pending migrations")
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down(ctx, cmd.steps)
+		for _, m := range reverted {
+			fmt.Fprintf(stdout, "reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Fprintln(stdout, "no applied migrations")
+		}
+		return err
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(stdout, "%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+		return nil
+	}
+}
+
+// migrateMain は migrate サブコマンドを実行して終了コードを返す
+func migrateMain(args []string) int {
+	command, err := parseMigrateArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n\n%s\n", err, migrateUsage)
+		return 2
+	}
+	migrations, err := migration.Embedded()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		return 1
+	}
+	db, err := connectDB()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		return 1
+	}
+	defer db.Close()
+
+	if err := command.run(context.Background(), migration.NewMigrator(db, migrations), os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		return 1
+	}
+	return 0
+}
+
+// migrateUp はサーバーの起動時に未適用のマイグレーションを適用する
+func migrateUp(ctx context.Context, db *sql.DB, stdout io.Writer) error {
+	migrations, err := migration.Embedded()
+	if err != nil {
+		return err
+	}
+	return migrateCommand{name: "up"}.run(ctx, migration.NewMigrator(db, migrations), stdout)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/migration"
+)
+
+// fakeMigrator は呼び出された引数を記録し、決められた結果を返す
+type fakeMigrator struct {
+	migrations []migration.Migration
+	statuses   []migration.Status
+	err        error
+	steps      int
+}
+
+func (m *fakeMigrator) Up(ctx context.Context) ([]migration.Migration, error) {
+	return m.migrations, m.err
+}
+
+func (m *fakeMigrator) Down(ctx context.Context, steps int) ([]migration.Migration, error) {
+	m.steps = steps
+	return m.migrations, m.err
+}
+
+func (m *fakeMigrator) Status(ctx context.Context) ([]migration.Status, error) {
+	return m.statuses, m.err
+}
+
+func TestParseMigrateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    migrateCommand
+		wantErr bool
+	}{
+		{name: "up", args: []string{"up"}, want: migrateCommand{name: "up"}},
+		{name: "down は既定で1件", args: []string{"down"}, want: migrateCommand{name: "down", steps: 1}},
+		{name: "down の件数を指定", args: []string{"down", "3"}, want: migrateCommand{name: "down", steps: 3}},
+		{name: "status", args: []string{"status"}, want: migrateCommand{name: "status"}},
+		{name: "コマンドがない", args: nil, wantErr: true},
+		{name: "未定義のコマンド", args: []string{"redo"}, wantErr: true},
+		{name: "down の件数が0", args: []string{"down", "0"}, wantErr: true},
+		{name: "down の件数が数値でない", args: []string{"down", "all"}, wantErr: true},
+		{name: "up に余分な引数", args: []string{"up", "1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMigrateArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMigrateCommand_Run(t *testing.T) {
+	appliedAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	initial := migration.Migration{Version: 1, Name: "initial_schema"}
+
+	tests := []struct {
+		name     string
+		command  migrateCommand
+		migrator *fakeMigrator
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "適用したマイグレーションを表示する",
+			command:  migrateCommand{name: "up"},
+			migrator: &fakeMigrator{migrations: []migration.Migration{initial}},
+			want:     "applied 0001_initial_schema\n",
+		},
+		{
+			name:     "未適用のマイグレーションがない",
+			command:  migrateCommand{name: "up"},
+			migrator: &fakeMigrator{},
+			want:     "no pending migrations\n",
+		},
+		{
+			name:     "取り消したマイグレーションを表示する",
+			command:  migrateCommand{name: "down", steps: 1},
+			migrator: &fakeMigrator{migrations: []migration.Migration{initial}},
+			want:     "reverted 0001_initial_schema\n",
+		},
+		{
+			name:    "適用状況を表示する",
+			command: migrateCommand{name: "status"},
+			migrator: &fakeMigrator{statuses: []migration.Status{
+				{Version: 1, Name: "initial_schema", AppliedAt: &appliedAt},
+				{Version: 2, Name: "add_index"},
+			}},
+			want: "0001_initial_schema\tapplied at 2030-01-01 09:00:00\n0002_add_index\tpending\n",
+		},
+		{
+			name:     "ロックを取得できない",
+			command:  migrateCommand{name: "up"},
+			migrator: &fakeMigrator{err: migration.ErrLocked},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := tt.command.run(context.Background(), tt.migrator, &out)
+			if tt.wantErr {
+				if !errors.Is(err, migration.ErrLocked) {
+					t.Errorf("Expected ErrLocked, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("Expected output %q, got %q", tt.want, out.String())
+			}
+			if tt.command.name == "down" && tt.migrator.steps != tt.command.steps {
+				t.Errorf("Expected %d steps, got %d", tt.command.steps, tt.migrator.steps)
+			}
+		})
+	}
+}
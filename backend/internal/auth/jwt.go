@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+// 対応している署名アルゴリズム
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// MinHMACSecretLength は HS256 の共有鍵の最小のバイト数（SHA-256 の出力長）
+const MinHMACSecretLength = 32
+
+// Leeway は有効期限などの時刻の検証で許容するサーバー間の時計のずれ
+const Leeway = 30 * time.Second
+
+// Claims はアクセストークンのクレーム。sub をユーザーIDとして扱う。
+type Claims struct {
+	jwt.RegisteredClaims
+	// Role を省略した場合は member として扱う
+	Role model.UserRole `json:"role,omitempty"`
+}
+
+// VerificationKey はトークンの署名を検証する鍵
+type VerificationKey struct {
+	// ID はトークンのヘッダーの kid と照合する。空の場合は kid を問わずに使う。
+	ID string
+	// Algorithm は AlgorithmHS256 か AlgorithmRS256
+	Algorithm string
+	// Key は HS256 では []byte、RS256 では *rsa.PublicKey
+	Key any
+}
+
+// HS256Key は共有鍵で HS256 の署名を検証する鍵を返す
+func HS256Key(secret []byte) VerificationKey {
+	return VerificationKey{Algorithm: AlgorithmHS256, Key: secret}
+}
+
+// RS256Key は公開鍵で RS256 の署名を検証する鍵を返す
+func RS256Key(id string, key *rsa.PublicKey) VerificationKey {
+	return VerificationKey{ID: id, Algorithm: AlgorithmRS256, Key: key}
+}
+
+// ParseRSAPublicKeyPEM は PEM 形式の RSA 公開鍵（PKIX / PKCS #1 / 証明書）を読み込む
+func ParseRSAPublicKeyPEM(data []byte) (*rsa.PublicKey, error) {
+	return jwt.ParseRSAPublicKeyFromPEM(data)
+}
+
+// jwk は JWKS（RFC 7517）の1つの鍵
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA の公開鍵
+	N string `json:"n"`
+	E string `json:"e"`
+	// 共有鍵（kty: oct）
+	K string `json:"k"`
+}
+
+// ParseJWKS は JWKS の JSON から検証に使う鍵を読み込む。
+// kty が RSA の鍵は RS256、oct の鍵は HS256 として扱い、use が sig 以外の鍵と alg が対応していない鍵は無視する。
+func ParseJWKS(data []byte) ([]VerificationKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	var keys []VerificationKey
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch {
+		case k.Kty == "RSA" && (k.Alg == "" || k.Alg == AlgorithmRS256):
+			key, err := rsaPublicKey(k.N, k.E)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JWKS key %d: %w", i, err)
+			}
+			keys = append(keys, RS256Key(k.Kid, key))
+		case k.Kty == "oct" && (k.Alg == "" || k.Alg == AlgorithmHS256):
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JWKS key %d: %w", i, err)
+			}
+			keys = append(keys, VerificationKey{ID: k.Kid, Algorithm: AlgorithmHS256, Key: secret})
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no RS256 or HS256 signing keys")
+	}
+	return keys, nil
+}
+
+func rsaPublicKey(n, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	if len(modulus) == 0 || len(exponent) == 0 || len(exponent) > 4 {
+		return nil, errors.New("invalid RSA public key")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
+
+// VerifierConfig はアクセストークンの検証の設定
+type VerifierConfig struct {
+	Keys []VerificationKey
+	// Issuer と Audience を指定すると iss / aud が一致するトークンだけを受け付ける
+	Issuer   string
+	Audience string
+}
+
+// Verifier はアクセストークンを検証する
+type Verifier struct {
+	keys   []VerificationKey
+	parser *jwt.Parser
+}
+
+// NewVerifier は検証器を作成する。鍵がない場合や HS256 の共有鍵が MinHMACSecretLength より短い場合はエラーを返す。
+func NewVerifier(config VerifierConfig) (*Verifier, error) {
+	if len(config.Keys) == 0 {
+		return nil, errors.New("no verification keys")
+	}
+	for _, k := range config.Keys {
+		switch k.Algorithm {
+		case AlgorithmHS256:
+			secret, ok := k.Key.([]byte)
+			if !ok || len(secret) < MinHMACSecretLength {
+				return nil, fmt.Errorf("HS256 secret must be at least %d bytes", MinHMACSecretLength)
+			}
+		case AlgorithmRS256:
+			if _, ok := k.Key.(*rsa.PublicKey); !ok {
+				return nil, errors.New("RS256 key must be an RSA public key")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+		}
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{AlgorithmHS256, AlgorithmRS256}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(Leeway),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &Verifier{keys: config.Keys, parser: jwt.NewParser(options...)}, nil
+}
+
+// Verify はトークンの署名とクレームを検証し、プリンシパルを返す。
+// sub がないトークンと未定義の role を持つトークンは受け付けない。
+func (v *Verifier) Verify(tokenString string) (Principal, error) {
+	claims := new(Claims)
+	if _, err := v.parser.ParseWithClaims(tokenString, claims, v.keyFunc); err != nil {
+		return Principal{}, err
+	}
+	if claims.Subject == "" {
+		return Principal{}, errors.New("token has no subject")
+	}
+	role := claims.Role
+	if role == "" {
+		role = model.RoleMember
+	}
+	if !role.Valid() {
+		return Principal{}, fmt.Errorf("token has unknown role %q", role)
+	}
+
+	return Principal{UserID: claims.Subject, Role: role}, nil
+}
+
+// keyFunc はトークンのアルゴリズムと kid に合う鍵を返す。複数ある場合は順に検証を試す。
+func (v *Verifier) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	var set jwt.VerificationKeySet
+	for _, k := range v.keys {
+		if k.Algorithm != token.Method.Alg() || (kid != "" && k.ID != "" && k.ID != kid) {
+			continue
+		}
+		set.Keys = append(set.Keys, k.Key)
+	}
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("no %s key for kid %q", token.Method.Alg(), kid)
+	}
+	return set, nil
+}
+
+// Sign は claims に署名したトークンを返す。key は HS256 では []byte、RS256 では *rsa.PrivateKey を渡す。
+// 開発用のトークンの発行とテストに使う。
+func Sign(claims Claims, algorithm, kid string, key any) (string, error) {
+	var method jwt.SigningMethod
+	switch algorithm {
+	case AlgorithmHS256:
+		method = jwt.SigningMethodHS256
+	case AlgorithmRS256:
+		method = jwt.SigningMethodRS256
+	default:
+		return "", fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString(key)
+}
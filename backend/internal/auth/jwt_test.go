@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func testClaims(subject string, role model.UserRole, expiresIn time.Duration) Claims {
+	now := time.Now()
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    "yoyaku",
+			Audience:  jwt.ClaimStrings{"yoyaku-api"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+		},
+		Role: role,
+	}
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	verifier, err := NewVerifier(VerifierConfig{Keys: []VerificationKey{HS256Key(testSecret)}, Issuer: "yoyaku", Audience: "yoyaku-api"})
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	sign := func(claims Claims, secret []byte) string {
+		token, err := Sign(claims, AlgorithmHS256, "", secret)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return token
+	}
+	noExpiry := testClaims("user-1", "", time.Hour)
+	noExpiry.ExpiresAt = nil
+	wrongIssuer := testClaims("user-1", "", time.Hour)
+	wrongIssuer.Issuer = "other"
+
+	tests := []struct {
+		name    string
+		token   string
+		want    Principal
+		wantErr bool
+	}{
+		{name: "role を省略すると member", token: sign(testClaims("user-1", "", time.Hour), testSecret), want: Principal{UserID: "user-1", Role: model.RoleMember}},
+		{name: "管理者", token: sign(testClaims("user-2", model.RoleAdmin, time.Hour), testSecret), want: Principal{UserID: "user-2", Role: model.RoleAdmin}},
+		{name: "期限切れ", token: sign(testClaims("user-1", "", -time.Hour), testSecret), wantErr: true},
+		{name: "有効期限がない", token: sign(noExpiry, testSecret), wantErr: true},
+		{name: "別の鍵で署名", token: sign(testClaims("user-1", "", time.Hour), []byte("fedcba9876543210fedcba9876543210")), wantErr: true},
+		{name: "発行者が異なる", token: sign(wrongIssuer, testSecret), wantErr: true},
+		{name: "sub がない", token: sign(testClaims("", "", time.Hour), testSecret), wantErr: true},
+		{name: "未定義の role", token: sign(testClaims("user-1", "owner", time.Hour), testSecret), wantErr: true},
+		{name: "alg: none", token: func() string {
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodNone, testClaims("user-1", "", time.Hour)).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return token
+		}(), wantErr: true},
+		{name: "トークンの形式が不正", token: "not-a-token", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 実行
+			got, err := verifier.Verify(tt.token)
+
+			// 検証
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVerifier_RS256WithJWKS(t *testing.T) {
+	// 準備: kid の異なる2つの鍵を JWKS にする
+	key1, _ := rsa.GenerateKey(rand.Reader, 2048)
+	key2, _ := rsa.GenerateKey(rand.Reader, 2048)
+	encode := func(kid string, key *rsa.PublicKey) map[string]string {
+		return map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	}
+	jwks, _ := json.Marshal(map[string]any{"keys": []any{
+		encode("key-1", &key1.PublicKey),
+		encode("key-2", &key2.PublicKey),
+		map[string]string{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}})
+	keys, err := ParseJWKS(jwks)
+	if err != nil {
+		t.Fatalf("Failed to parse JWKS: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 signing keys, got %d", len(keys))
+	}
+	verifier, err := NewVerifier(VerifierConfig{Keys: keys})
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	// 実行と検証: kid に対応する鍵で検証する
+	token, _ := Sign(testClaims("user-1", model.RoleAdmin, time.Hour), AlgorithmRS256, "key-2", key2)
+	got, err := verifier.Verify(token)
+	if err != nil || got != (Principal{UserID: "user-1", Role: model.RoleAdmin}) {
+		t.Errorf("Expected admin user-1, got %+v, %v", got, err)
+	}
+
+	// kid と署名の鍵が一致しない場合は拒否する
+	token, _ = Sign(testClaims("user-1", "", time.Hour), AlgorithmRS256, "key-1", key2)
+	if _, err := verifier.Verify(token); err == nil {
+		t.Error("Expected error for mismatched kid, got nil")
+	}
+
+	// HS256 の鍵がない場合は HS256 のトークンを拒否する（アルゴリズムの取り違え対策）
+	token, _ = Sign(testClaims("user-1", "", time.Hour), AlgorithmHS256, "", testSecret)
+	if _, err := verifier.Verify(token); err == nil {
+		t.Error("Expected error for HS256 token, got nil")
+	}
+}
+
+func TestNewVerifier_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config VerifierConfig
+	}{
+		{name: "鍵がない", config: VerifierConfig{}},
+		{name: "共有鍵が短い", config: VerifierConfig{Keys: []VerificationKey{HS256Key([]byte("short"))}}},
+		{name: "未対応のアルゴリズム", config: VerifierConfig{Keys: []VerificationKey{{Algorithm: "ES256", Key: testSecret}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewVerifier(tt.config); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestParseJWKS_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "JSONでない", data: "keys"},
+		{name: "署名用の鍵がない", data: `{"keys": [{"kty": "EC", "crv": "P-256"}]}`},
+		{name: "RSAの値が不正", data: `{"keys": [{"kty": "RSA", "n": "!!", "e": "AQAB"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseJWKS([]byte(tt.data)); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
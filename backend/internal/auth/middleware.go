@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+// UserIDHeader はリクエストを送ったユーザーのIDを伝えるヘッダー。
+// 認証済みのリクエストだけを通すリバースプロキシの内側など、ヘッダーを信頼できる環境で使う。
+const UserIDHeader = "X-User-ID"
+
+// UserFinder はユーザーをIDで検索する。存在しない場合は nil を返す。
+type UserFinder interface {
+	FindByID(id string) (*model.User, error)
+}
+
+// UserHeader は UserIDHeader のユーザーをプリンシパルとしてリクエストのコンテキストに格納するミドルウェアを返す。
+// ヘッダーがないリクエストは匿名として通し、存在しないユーザーのIDには 401 を返す。
+func UserHeader(users UserFinder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(UserIDHeader)
+			if id == "" {
+				return next(c)
+			}
+
+			user, err := users.FindByID(id)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to identify user"})
+			}
+			if user == nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unknown user"})
+			}
+
+			ctx := WithPrincipal(c.Request().Context(), Principal{UserID: user.ID, Role: user.Role})
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// JWT は Authorization ヘッダーの Bearer トークンを検証し、プリンシパルをリクエストのコンテキストに格納するミドルウェアを返す。
+// ヘッダーがないリクエストや Bearer 以外のスキーム（CalDAV の Basic 認証など）は匿名として通し、認証が必要なルートでは RequireAuth で拒否する。
+// トークンが不正な場合や期限切れの場合は 401 を返す。
+func JWT(verifier *Verifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if header == "" {
+				return next(c)
+			}
+
+			token, ok := bearerToken(header)
+			if !ok {
+				return next(c)
+			}
+			if token == "" {
+				return unauthorized(c, "Bearer token is empty")
+			}
+			principal, err := verifier.Verify(token)
+			if err != nil {
+				return unauthorized(c, "Invalid or expired token")
+			}
+
+			ctx := WithPrincipal(c.Request().Context(), principal)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// RequireAuth はプリンシパルのない（匿名の）リクエストに 401 を返すミドルウェア
+func RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, ok := PrincipalFrom(c.Request().Context()); !ok {
+			return unauthorized(c, "Authentication required")
+		}
+		return next(c)
+	}
+}
+
+// bearerToken は "Bearer <token>" 形式のヘッダーからトークンを取り出す。スキーム名の大文字小文字は区別しない。
+// スキームが Bearer でない場合は false を返す。
+func bearerToken(header string) (string, bool) {
+	scheme, token, _ := strings.Cut(strings.TrimSpace(header), " ")
+	if !strings.EqualFold(scheme, "bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func unauthorized(c echo.Context, message string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="yoyaku"`)
+	return c.JSON(http.StatusUnauthorized, map[string]string{"error": message})
+}
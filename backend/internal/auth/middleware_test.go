@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+type mockUserFinder map[string]*model.User
+
+func (m mockUserFinder) FindByID(id string) (*model.User, error) {
+	return m[id], nil
+}
+
+func TestUserHeader(t *testing.T) {
+	admin := model.NewUser("管理者", "admin@example.com", model.RoleAdmin)
+	users := mockUserFinder{admin.ID: admin}
+
+	tests := []struct {
+		name          string
+		header        string
+		wantStatus    int
+		wantPrincipal *Principal
+	}{
+		{name: "ヘッダーなしは匿名", header: "", wantStatus: http.StatusOK},
+		{name: "登録済みのユーザー", header: admin.ID, wantStatus: http.StatusOK, wantPrincipal: &Principal{UserID: admin.ID, Role: model.RoleAdmin}},
+		{name: "存在しないユーザー", header: "missing", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(UserIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var got *Principal
+			next := func(c echo.Context) error {
+				if p, ok := PrincipalFrom(c.Request().Context()); ok {
+					got = &p
+				}
+				return c.NoContent(http.StatusOK)
+			}
+
+			// 実行
+			if err := UserHeader(users)(next)(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if (got == nil) != (tt.wantPrincipal == nil) || (got != nil && *got != *tt.wantPrincipal) {
+				t.Errorf("Expected principal %v, got %v", tt.wantPrincipal, got)
+			}
+		})
+	}
+}
+
+func TestJWT(t *testing.T) {
+	verifier, err := NewVerifier(VerifierConfig{Keys: []VerificationKey{HS256Key(testSecret)}})
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	token, _ := Sign(testClaims("user-1", "", time.Hour), AlgorithmHS256, "", testSecret)
+	expired, _ := Sign(testClaims("user-1", "", -time.Hour), AlgorithmHS256, "", testSecret)
+
+	tests := []struct {
+		name        string
+		header      string
+		requireAuth bool
+		wantStatus  int
+	}{
+		{name: "有効なトークン", header: "Bearer " + token, requireAuth: true, wantStatus: http.StatusOK},
+		{name: "スキーム名は大文字小文字を区別しない", header: "bearer " + token, requireAuth: true, wantStatus: http.StatusOK},
+		{name: "期限切れのトークン", header: "Bearer " + expired, wantStatus: http.StatusUnauthorized},
+		{name: "トークンが空", header: "Bearer ", wantStatus: http.StatusUnauthorized},
+		{name: "Bearer 以外は匿名で通す", header: "Basic dXNlcjpwYXNz", wantStatus: http.StatusOK},
+		{name: "Bearer 以外は認証が必要なルートで拒否する", header: "Basic dXNlcjpwYXNz", requireAuth: true, wantStatus: http.StatusUnauthorized},
+		{name: "認証が不要なルートは匿名で通す", header: "", wantStatus: http.StatusOK},
+		{name: "認証が必要なルートは匿名を拒否する", header: "", requireAuth: true, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			next := func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}
+			if tt.requireAuth {
+				next = RequireAuth(next)
+			}
+
+			// 実行
+			if err := JWT(verifier)(next)(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get(echo.HeaderWWWAuthenticate) == "" {
+				t.Error("Expected WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Package auth はリクエストを送ったユーザー（プリンシパル）の識別を扱う。
+// 識別したプリンシパルは context.Context に格納し、サービス層で権限の確認に使う。
+package auth
+
+import (
+	"context"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+// Principal はリクエストを送ったユーザー
+type Principal struct {
+	UserID string
+	Role   model.UserRole
+}
+
+// IsAdmin は全員の予約を管理できるかを返す
+func (p Principal) IsAdmin() bool {
+	return p.Role == model.RoleAdmin
+}
+
+type principalKey struct{}
+
+// WithPrincipal は p を格納した ctx の子コンテキストを返す
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom は ctx に格納されたプリンシパルを返す。匿名のリクエストでは false を返す。
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+)
+
+// Auth は API の認証の設定
+type Auth struct {
+	// JWT が nil の場合はアクセストークンを検証しない
+	JWT *auth.VerifierConfig
+	// TrustUserHeader が true の場合は auth.UserIDHeader でユーザーを識別する
+	TrustUserHeader bool
+}
+
+// LoadAuth は次の環境変数から認証の設定を読み込む。鍵は複数の方法を組み合わせて指定できる。
+//
+//	AUTH_JWT_HS256_SECRET           HS256 の共有鍵（32バイト以上）
+//	AUTH_JWT_RS256_PUBLIC_KEY_FILE  RS256 の公開鍵の PEM ファイル
+//	AUTH_JWT_JWKS_FILE              検証に使う鍵の JWKS ファイル
+//	AUTH_JWT_ISSUER                 指定するとこの iss のトークンだけを受け付ける
+//	AUTH_JWT_AUDIENCE               指定するとこの aud を含むトークンだけを受け付ける
+//	AUTH_TRUST_USER_HEADER          true の場合、トークンの代わりに X-User-ID ヘッダーでユーザーを識別する（開発用）
+//
+// JWT の鍵と AUTH_TRUST_USER_HEADER のどちらか一方だけを指定する必要がある。
+func LoadAuth(lookup LookupFunc) (Auth, error) {
+	var config Auth
+	var err error
+	if config.TrustUserHeader, err = boolean(lookup, "AUTH_TRUST_USER_HEADER"); err != nil {
+		return config, err
+	}
+
+	var keys []auth.VerificationKey
+	if secret := lookup("AUTH_JWT_HS256_SECRET"); secret != "" {
+		keys = append(keys, auth.HS256Key([]byte(secret)))
+	}
+	if path := lookup("AUTH_JWT_RS256_PUBLIC_KEY_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return config, fmt.Errorf("failed to read AUTH_JWT_RS256_PUBLIC_KEY_FILE: %w", err)
+		}
+		key, err := auth.ParseRSAPublicKeyPEM(data)
+		if err != nil {
+			return config, fmt.Errorf("invalid AUTH_JWT_RS256_PUBLIC_KEY_FILE: %w", err)
+		}
+		keys = append(keys, auth.RS256Key("", key))
+	}
+	if path := lookup("AUTH_JWT_JWKS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return config, fmt.Errorf("failed to read AUTH_JWT_JWKS_FILE: %w", err)
+		}
+		jwks, err := auth.ParseJWKS(data)
+		if err != nil {
+			return config, fmt.Errorf("invalid AUTH_JWT_JWKS_FILE: %w", err)
+		}
+		keys = append(keys, jwks...)
+	}
+
+	switch {
+	case len(keys) > 0 && config.TrustUserHeader:
+		return config, errors.New("AUTH_TRUST_USER_HEADER cannot be combined with JWT keys")
+	case len(keys) == 0 && !config.TrustUserHeader:
+		return config, errors.New("no authentication configured: set AUTH_JWT_HS256_SECRET, AUTH_JWT_RS256_PUBLIC_KEY_FILE or AUTH_JWT_JWKS_FILE (or AUTH_TRUST_USER_HEADER=true for development)")
+	case len(keys) > 0:
+		config.JWT = &auth.VerifierConfig{
+			Keys:     keys,
+			Issuer:   lookup("AUTH_JWT_ISSUER"),
+			Audience: lookup("AUTH_JWT_AUDIENCE"),
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAuth(t *testing.T) {
+	// 準備: RS256 の公開鍵の PEM ファイル
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	pemFile := filepath.Join(t.TempDir(), "public.pem")
+	if err := os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(jwksFile, []byte(`{"keys": [{"kty": "oct", "kid": "dev", "k": "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"}]}`), 0o600); err != nil {
+		t.Fatalf("Failed to write JWKS: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantKeys   int
+		wantHeader bool
+		wantErr    bool
+	}{
+		{
+			name:     "全ての鍵を指定",
+			env:      map[string]string{"AUTH_JWT_HS256_SECRET": "0123456789abcdef0123456789abcdef", "AUTH_JWT_RS256_PUBLIC_KEY_FILE": pemFile, "AUTH_JWT_JWKS_FILE": jwksFile, "AUTH_JWT_ISSUER": "yoyaku"},
+			wantKeys: 3,
+		},
+		{name: "ヘッダーで識別", env: map[string]string{"AUTH_TRUST_USER_HEADER": "true"}, wantHeader: true},
+		{name: "何も指定しない", env: map[string]string{}, wantErr: true},
+		{name: "鍵とヘッダーの両方", env: map[string]string{"AUTH_JWT_HS256_SECRET": "0123456789abcdef0123456789abcdef", "AUTH_TRUST_USER_HEADER": "true"}, wantErr: true},
+		{name: "存在しない鍵ファイル", env: map[string]string{"AUTH_JWT_RS256_PUBLIC_KEY_FILE": filepath.Join(t.TempDir(), "missing.pem")}, wantErr: true},
+		{name: "PEM でない鍵ファイル", env: map[string]string{"AUTH_JWT_RS256_PUBLIC_KEY_FILE": jwksFile}, wantErr: true},
+		{name: "JWKS でないファイル", env: map[string]string{"AUTH_JWT_JWKS_FILE": pemFile}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 実行
+			got, err := LoadAuth(lookupFrom(tt.env))
+
+			// 検証
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.TrustUserHeader != tt.wantHeader {
+				t.Errorf("Expected TrustUserHeader %v, got %v", tt.wantHeader, got.TrustUserHeader)
+			}
+			keys := 0
+			if got.JWT != nil {
+				keys = len(got.JWT.Keys)
+				if got.JWT.Issuer != tt.env["AUTH_JWT_ISSUER"] {
+					t.Errorf("Expected issuer %q, got %q", tt.env["AUTH_JWT_ISSUER"], got.JWT.Issuer)
+				}
+			}
+			if keys != tt.wantKeys {
+				t.Errorf("Expected %d keys, got %d", tt.wantKeys, keys)
+			}
+		})
+	}
+}
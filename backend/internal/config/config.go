@@ -0,0 +1,102 @@
+// Package config は環境変数からアプリケーションの設定を読み込む
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// LookupFunc は環境変数の値を返す。os.Getenv を渡す想定で、テストでは map から返す関数を渡す。
+type LookupFunc func(key string) string
+
+// LoadBookingPolicy は次の環境変数から予約ポリシーを読み込む。未設定の規則は適用しない。
+//
+//	BOOKING_MIN_DURATION      予約の長さの下限（例: 15m）
+//	BOOKING_MAX_DURATION      予約の長さの上限（例: 8h）
+//	BOOKING_MAX_ADVANCE_DAYS  何日先まで予約できるか（例: 90）
+//	BOOKING_MIN_NOTICE        開始までに必要な猶予（例: 1h）
+//	BOOKING_ALLOW_PAST        true の場合、開始時刻が過去の予約を受け付ける（既定値: false）
+//	BOOKING_SLOT_GRANULARITY  開始・終了時刻をそろえる刻み（例: 30m）
+//	BOOKING_REJECT_HOLIDAYS   true の場合、日本の祝日・休日に掛かる予約を受け付けない（既定値: false）
+func LoadBookingPolicy(lookup LookupFunc) (service.BookingPolicy, error) {
+	var policy service.BookingPolicy
+	var err error
+
+	if policy.MinDuration, err = duration(lookup, "BOOKING_MIN_DURATION"); err != nil {
+		return policy, err
+	}
+	if policy.MaxDuration, err = duration(lookup, "BOOKING_MAX_DURATION"); err != nil {
+		return policy, err
+	}
+	if policy.MinNotice, err = duration(lookup, "BOOKING_MIN_NOTICE"); err != nil {
+		return policy, err
+	}
+	if policy.SlotGranularity, err = duration(lookup, "BOOKING_SLOT_GRANULARITY"); err != nil {
+		return policy, err
+	}
+	if value := lookup("BOOKING_MAX_ADVANCE_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return policy, fmt.Errorf("invalid BOOKING_MAX_ADVANCE_DAYS %q", value)
+		}
+		policy.MaxAdvance = time.Duration(days) * 24 * time.Hour
+	}
+	allowPast, err := boolean(lookup, "BOOKING_ALLOW_PAST")
+	if err != nil {
+		return policy, err
+	}
+	policy.RejectPast = !allowPast
+	if policy.RejectHolidays, err = boolean(lookup, "BOOKING_REJECT_HOLIDAYS"); err != nil {
+		return policy, err
+	}
+
+	if err := policy.Validate(); err != nil {
+		return policy, fmt.Errorf("invalid booking policy: %w", err)
+	}
+	return policy, nil
+}
+
+// DefaultIdempotencyKeyTTL は Idempotency-Key の記録を保持する期間の既定値
+const DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+// LoadIdempotencyKeyTTL は次の環境変数から Idempotency-Key の記録を保持する期間を読み込む。
+//
+//	IDEMPOTENCY_KEY_TTL  記録したレスポンスを再送に返す期間（例: 24h、既定値: DefaultIdempotencyKeyTTL）
+func LoadIdempotencyKeyTTL(lookup LookupFunc) (time.Duration, error) {
+	value := lookup("IDEMPOTENCY_KEY_TTL")
+	if value == "" {
+		return DefaultIdempotencyKeyTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL %q", value)
+	}
+	return ttl, nil
+}
+
+func duration(lookup LookupFunc, key string) (time.Duration, error) {
+	value := lookup(key)
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q", key, value)
+	}
+	return d, nil
+}
+
+func boolean(lookup LookupFunc, key string) (bool, error) {
+	value := lookup(key)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q", key, value)
+	}
+	return b, nil
+}
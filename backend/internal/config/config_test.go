@@ -0,0 +1,122 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+func lookupFrom(env map[string]string) LookupFunc {
+	return func(key string) string { return env[key] }
+}
+
+func TestLoadBookingPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    service.BookingPolicy
+		wantErr bool
+	}{
+		{
+			name: "未設定の場合は過去の予約だけを拒否する",
+			env:  map[string]string{},
+			want: service.BookingPolicy{RejectPast: true},
+		},
+		{
+			name: "全ての規則を設定",
+			env: map[string]string{
+				"BOOKING_MIN_DURATION":     "15m",
+				"BOOKING_MAX_DURATION":     "8h",
+				"BOOKING_MAX_ADVANCE_DAYS": "90",
+				"BOOKING_MIN_NOTICE":       "1h",
+				"BOOKING_ALLOW_PAST":       "true",
+				"BOOKING_SLOT_GRANULARITY": "30m",
+				"BOOKING_REJECT_HOLIDAYS":  "true",
+			},
+			want: service.BookingPolicy{
+				MinDuration:     15 * time.Minute,
+				MaxDuration:     8 * time.Hour,
+				MaxAdvance:      90 * 24 * time.Hour,
+				MinNotice:       time.Hour,
+				SlotGranularity: 30 * time.Minute,
+				RejectHolidays:  true,
+			},
+		},
+		{
+			name:    "長さの形式が不正",
+			env:     map[string]string{"BOOKING_MIN_DURATION": "15"},
+			wantErr: true,
+		},
+		{
+			name:    "日数が負",
+			env:     map[string]string{"BOOKING_MAX_ADVANCE_DAYS": "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "真偽値が不正",
+			env:     map[string]string{"BOOKING_ALLOW_PAST": "maybe"},
+			wantErr: true,
+		},
+		{
+			name:    "下限が上限より長い",
+			env:     map[string]string{"BOOKING_MIN_DURATION": "2h", "BOOKING_MAX_DURATION": "1h"},
+			wantErr: true,
+		},
+		{
+			name:    "刻みが1日を割り切れない",
+			env:     map[string]string{"BOOKING_SLOT_GRANULARITY": "7m"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 実行
+			got, err := LoadBookingPolicy(lookupFrom(tt.env))
+
+			// 検証
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got policy %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected policy %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadIdempotencyKeyTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "未設定の場合は既定値", env: map[string]string{}, want: DefaultIdempotencyKeyTTL},
+		{name: "期間を指定", env: map[string]string{"IDEMPOTENCY_KEY_TTL": "1h30m"}, want: 90 * time.Minute},
+		{name: "0は指定できない", env: map[string]string{"IDEMPOTENCY_KEY_TTL": "0s"}, wantErr: true},
+		{name: "形式が不正", env: map[string]string{"IDEMPOTENCY_KEY_TTL": "1day"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadIdempotencyKeyTTL(lookupFrom(tt.env))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Expected %v, got %v, %v", tt.want, got, err)
+			}
+		})
+	}
+}
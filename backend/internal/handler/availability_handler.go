@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// AvailabilityServiceInterface はテスト時にモック可能なインターフェース
+type AvailabilityServiceInterface interface {
+	FindFreeSlots(ctx context.Context, params service.AvailabilityParams) ([]model.TimeRange, error)
+}
+
+type AvailabilityHandler struct {
+	service AvailabilityServiceInterface
+}
+
+func NewAvailabilityHandler(service AvailabilityServiceInterface) *AvailabilityHandler {
+	return &AvailabilityHandler{service: service}
+}
+
+func (h *AvailabilityHandler) RegisterRoutes(e *echo.Echo, middleware ...echo.MiddlewareFunc) {
+	e.GET("/api/availability", h.GetAvailability, middleware...)
+}
+
+// GetAvailability はリソースの空き時間を返す。
+// duration と granularity は Go の時間表記（例: 30m, 1h30m）で指定する。
+func (h *AvailabilityHandler) GetAvailability(c echo.Context) error {
+	params := service.AvailabilityParams{
+		ResourceID: c.QueryParam("resourceId"),
+	}
+	if params.ResourceID == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "resourceId is required"})
+	}
+
+	from, err := time.Parse(time.RFC3339, c.QueryParam("from"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from format"})
+	}
+	params.From = from
+
+	to, err := time.Parse(time.RFC3339, c.QueryParam("to"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to format"})
+	}
+	params.To = to
+
+	duration, err := time.ParseDuration(c.QueryParam("duration"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid duration format"})
+	}
+	params.Duration = duration
+
+	if granularity := c.QueryParam("granularity"); granularity != "" {
+		d, err := time.ParseDuration(granularity)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid granularity format"})
+		}
+		params.Granularity = d
+	}
+
+	slots, err := h.service.FindFreeSlots(c.Request().Context(), params)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, slots)
+	case errors.Is(err, service.ErrInvalidWindow):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "to must be after from"})
+	case errors.Is(err, service.ErrWindowTooLarge):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Time window must not exceed %d days", int(service.MaxListWindow.Hours()/24))})
+	case errors.Is(err, service.ErrInvalidDuration):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "duration must be positive and not longer than the time window"})
+	case errors.Is(err, service.ErrInvalidGranularity):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "granularity must be at least 1 minute and divide a day evenly"})
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "You do not have permission to view the schedule"})
+	case errors.Is(err, service.ErrResourceNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Resource not found"})
+	case errors.Is(err, service.ErrResourceInactive):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Resource is not active"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get availability"})
+	}
+}
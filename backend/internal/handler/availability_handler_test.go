@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+type mockAvailabilityService struct {
+	findFreeSlotsFunc func(params service.AvailabilityParams) ([]model.TimeRange, error)
+}
+
+func (m *mockAvailabilityService) FindFreeSlots(ctx context.Context, params service.AvailabilityParams) ([]model.TimeRange, error) {
+	return m.findFreeSlotsFunc(params)
+}
+
+func TestGetAvailability(t *testing.T) {
+	// 準備
+	e := echo.New()
+	from := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	var received service.AvailabilityParams
+	mockSvc := &mockAvailabilityService{
+		findFreeSlotsFunc: func(params service.AvailabilityParams) ([]model.TimeRange, error) {
+			received = params
+			return []model.TimeRange{{StartTime: from, EndTime: from.Add(time.Hour)}}, nil
+		},
+	}
+	h := NewAvailabilityHandler(mockSvc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/availability?resourceId=room-a&from=2024-04-01T09:00:00Z&to=2024-04-01T18:00:00Z&duration=30m&granularity=15m", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// 実行
+	if err := h.GetAvailability(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if received.ResourceID != "room-a" || !received.From.Equal(from) || received.Duration != 30*time.Minute || received.Granularity != 15*time.Minute {
+		t.Errorf("Unexpected params: %+v", received)
+	}
+	var slots []model.TimeRange
+	if err := json.Unmarshal(rec.Body.Bytes(), &slots); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(slots) != 1 || !slots[0].StartTime.Equal(from) {
+		t.Errorf("Unexpected slots: %v", slots)
+	}
+}
+
+func TestGetAvailability_Errors(t *testing.T) {
+	valid := "resourceId=room-a&from=2024-04-01T09:00:00Z&to=2024-04-01T18:00:00Z&duration=30m"
+
+	tests := []struct {
+		name       string
+		query      string
+		serviceErr error
+		wantStatus int
+	}{
+		{"リソースの指定なし", "from=2024-04-01T09:00:00Z&to=2024-04-01T18:00:00Z&duration=30m", nil, http.StatusBadRequest},
+		{"fromの形式が不正", "resourceId=room-a&from=2024-04-01&to=2024-04-01T18:00:00Z&duration=30m", nil, http.StatusBadRequest},
+		{"durationの形式が不正", "resourceId=room-a&from=2024-04-01T09:00:00Z&to=2024-04-01T18:00:00Z&duration=30", nil, http.StatusBadRequest},
+		{"granularityの形式が不正", valid + "&granularity=abc", nil, http.StatusBadRequest},
+		{"期間が長すぎる", valid, service.ErrWindowTooLarge, http.StatusBadRequest},
+		{"刻みが不正", valid, service.ErrInvalidGranularity, http.StatusBadRequest},
+		{"予約の一覧を参照できない", valid, service.ErrForbidden, http.StatusForbidden},
+		{"存在しないリソース", valid, service.ErrResourceNotFound, http.StatusNotFound},
+		{"サービスのエラー", valid, errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			mockSvc := &mockAvailabilityService{
+				findFreeSlotsFunc: func(params service.AvailabilityParams) ([]model.TimeRange, error) {
+					return nil, tt.serviceErr
+				},
+			}
+			h := NewAvailabilityHandler(mockSvc)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/availability?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if err := h.GetAvailability(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
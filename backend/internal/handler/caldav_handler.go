@@ -0,0 +1,744 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/ical"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// CalDAV の URL。リソースごとに /caldav/calendars/<リソースID>/ のカレンダーがあり、
+// 予約はカレンダーの <UID>.ics（UID はパスとしてエスケープする）のイベントになる。
+const (
+	caldavPrefix        = "/caldav"
+	caldavRootPath      = caldavPrefix + "/"
+	caldavPrincipalPath = caldavPrefix + "/principal/"
+	caldavHomePath      = caldavPrefix + "/calendars/"
+	// caldavEventExt はイベントのリソースの名前の拡張子
+	caldavEventExt = ".ics"
+	// caldavSyncTokenPrefix は sync-token の URI の接頭辞。続く値はカレンダーの ctag。
+	caldavSyncTokenPrefix = "urn:yoyaku:sync:"
+	// caldavFeedResourceKey はフィードのトークンで認証したリクエストで、フィードのリソースIDを格納する echo.Context のキー
+	caldavFeedResourceKey = "caldavFeedResourceID"
+)
+
+// FeedAuthenticator はフィードのトークンでリクエストを認証する
+type FeedAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (context.Context, *model.CalendarFeed, error)
+}
+
+// CalendarResourceLister は CalDAV のカレンダーにするリソースを返す
+type CalendarResourceLister interface {
+	GetAllResources() ([]*model.Resource, error)
+	GetResource(id string) (*model.Resource, error)
+}
+
+// CalDAVReservationService は CalDAV のカレンダーのイベントとして予約を読み書きする
+type CalDAVReservationService interface {
+	StreamReservations(ctx context.Context, params service.ExportReservationsParams, fn func(reservation *model.Reservation, resourceName string) error) error
+	GetEvent(ctx context.Context, resourceID, uid string) (*model.Reservation, error)
+	PutEvent(ctx context.Context, params service.PutEventParams) (*model.Reservation, bool, error)
+	DeleteEvent(ctx context.Context, resourceID, uid string, version int64) error
+}
+
+// CalDAVHandler は予約を CalDAV（RFC 4791）のカレンダーとして提供する。
+// カレンダーアプリでのイベントの作成・変更・削除は、REST API と同じ検証で予約の作成・変更・取り消しになる。
+type CalDAVHandler struct {
+	feeds        FeedAuthenticator
+	resources    CalendarResourceLister
+	reservations CalDAVReservationService
+}
+
+func NewCalDAVHandler(feeds FeedAuthenticator, resources CalendarResourceLister, reservations CalDAVReservationService) *CalDAVHandler {
+	return &CalDAVHandler{
+		feeds:        feeds,
+		resources:    resources,
+		reservations: reservations,
+	}
+}
+
+// RegisterRoutes は CalDAV のルートを登録する。コレクションの URL は末尾のスラッシュを省略しても受け付ける。
+// 認証は authenticate で行うため、auth.RequireAuth などのミドルウェアは受け取らない。
+func (h *CalDAVHandler) RegisterRoutes(e *echo.Echo) {
+	e.Any("/.well-known/caldav", func(c echo.Context) error {
+		return c.Redirect(http.StatusMovedPermanently, caldavRootPath)
+	})
+
+	g := e.Group(caldavPrefix, h.authenticate)
+	collections := []struct {
+		path     string
+		propfind echo.HandlerFunc
+	}{
+		{path: "/", propfind: h.PropfindRoot},
+		{path: "/principal/", propfind: h.PropfindPrincipal},
+		{path: "/calendars/", propfind: h.PropfindHome},
+		{path: "/calendars/:resourceId/", propfind: h.PropfindCalendar},
+	}
+	for _, col := range collections {
+		for _, p := range []string{col.path, strings.TrimSuffix(col.path, "/")} {
+			g.Add(http.MethodOptions, p, h.Options)
+			g.Add(echo.PROPFIND, p, col.propfind)
+		}
+	}
+	g.Add(echo.REPORT, "/calendars/:resourceId/", h.Report)
+	g.Add(echo.REPORT, "/calendars/:resourceId", h.Report)
+
+	event := "/calendars/:resourceId/:name"
+	g.Add(http.MethodOptions, event, h.Options)
+	g.Add(echo.PROPFIND, event, h.PropfindEvent)
+	g.GET(event, h.GetEvent)
+	g.HEAD(event, h.GetEvent)
+	g.PUT(event, h.PutEvent)
+	g.DELETE(event, h.DeleteEvent)
+}
+
+// authenticate は CalDAV のリクエストを認証するミドルウェア。
+// Basic 認証ではパスワードを CalDAV を有効にしたフィードのトークンとして扱い、フィードを発行したユーザーの権限で処理する
+// （ユーザー名は問わない）。リソースを指定したフィードではそのリソースのカレンダーだけを扱える。
+// Basic 認証でない場合は JWT などで認証済みのプリンシパルを使い、ない場合は 401 を返す。
+func (h *CalDAVHandler) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		_, token, ok := c.Request().BasicAuth()
+		if !ok {
+			if _, ok := auth.PrincipalFrom(c.Request().Context()); !ok {
+				return caldavUnauthorized(c)
+			}
+			return next(c)
+		}
+
+		ctx, feed, err := h.feeds.Authenticate(c.Request().Context(), token)
+		if errors.Is(err, service.ErrCalendarFeedNotFound) || (err == nil && !feed.CalDAV) {
+			return caldavUnauthorized(c)
+		}
+		if err != nil {
+			return c.String(http.StatusInternalServerError, "Failed to authenticate")
+		}
+		c.SetRequest(c.Request().WithContext(ctx))
+		c.Set(caldavFeedResourceKey, feed.ResourceID)
+		return next(c)
+	}
+}
+
+func caldavUnauthorized(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="yoyaku"`)
+	return c.String(http.StatusUnauthorized, "Authentication required")
+}
+
+// Options は CalDAV に対応していることと、受け付けるメソッドを返す
+func (h *CalDAVHandler) Options(c echo.Context) error {
+	c.Response().Header().Set("DAV", "1, 3, calendar-access")
+	c.Response().Header().Set(echo.HeaderAllow, "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+	return c.NoContent(http.StatusOK)
+}
+
+// PropfindRoot は CalDAV のルートのプロパティを返す。カレンダーアプリはここからプリンシパルを探す。
+func (h *CalDAVHandler) PropfindRoot(c echo.Context) error {
+	return h.propfind(c, collectionResource(caldavRootPath, "yoyaku"), nil)
+}
+
+// PropfindPrincipal はプリンシパル（カレンダーの利用者）のプロパティを返す
+func (h *CalDAVHandler) PropfindPrincipal(c echo.Context) error {
+	res := collectionResource(caldavPrincipalPath, "yoyaku")
+	res.props[0].value = davValue(`<collection/><principal/>`)
+	res.props = append(res.props,
+		davProp{name: davName(nsDAV, "principal-URL"), value: davValue(hrefXML(caldavPrincipalPath))},
+		davProp{name: davName(nsCalDAV, "calendar-home-set"), value: davValue(hrefXML(caldavHomePath))},
+	)
+	return h.propfind(c, res, nil)
+}
+
+// PropfindHome はカレンダーの一覧のコレクションのプロパティを返す。Depth が 0 でない場合は扱えるカレンダーも返す。
+func (h *CalDAVHandler) PropfindHome(c echo.Context) error {
+	return h.propfind(c, collectionResource(caldavHomePath, icalCalendarName), func() ([]davResource, error) {
+		resources, err := h.calendars(c)
+		if err != nil {
+			return nil, err
+		}
+		children := make([]davResource, 0, len(resources))
+		for _, r := range resources {
+			children = append(children, h.calendarResource(c.Request().Context(), r))
+		}
+		return children, nil
+	})
+}
+
+// PropfindCalendar はリソースのカレンダーのプロパティを返す。Depth が 0 でない場合は、期間を指定しない書き出しと同じ期間の予約のイベントも返す。
+func (h *CalDAVHandler) PropfindCalendar(c echo.Context) error {
+	resource, err := h.calendar(c)
+	if err != nil {
+		return caldavError(c, err)
+	}
+	ctx := c.Request().Context()
+	return h.propfind(c, h.calendarResource(ctx, resource), func() ([]davResource, error) {
+		reservations, err := h.calendarEvents(ctx, resource.ID, service.ExportReservationsParams{})
+		if err != nil {
+			return nil, err
+		}
+		children := make([]davResource, 0, len(reservations))
+		for _, r := range reservations {
+			children = append(children, eventResource(r, resource.Name))
+		}
+		return children, nil
+	})
+}
+
+// PropfindEvent はイベントのプロパティを返す
+func (h *CalDAVHandler) PropfindEvent(c echo.Context) error {
+	resource, uid, err := h.eventTarget(c)
+	if err != nil {
+		return caldavError(c, err)
+	}
+	reservation, err := h.reservations.GetEvent(c.Request().Context(), resource.ID, uid)
+	if err != nil {
+		return caldavError(c, err)
+	}
+	return h.propfind(c, eventResource(reservation, resource.Name), nil)
+}
+
+// propfind は self と、Depth が 0 でない場合は children が返すリソースのプロパティを multistatus で返す。
+// Depth の infinity は 1 として扱う。
+func (h *CalDAVHandler) propfind(c echo.Context, self davResource, children func() ([]davResource, error)) error {
+	var req davPropfind
+	found, err := readDAVRequest(c, &req)
+	if err != nil {
+		return c.String(http.StatusBadRequest, "Invalid PROPFIND request")
+	}
+	props := davPropRequest{all: true}
+	if found && req.AllProp == nil {
+		props = davPropRequest{names: req.PropName != nil, props: req.Prop}
+	}
+
+	resources := []davResource{self}
+	if children != nil && c.Request().Header.Get("Depth") != "0" {
+		more, err := children()
+		if err != nil {
+			return caldavError(c, err)
+		}
+		resources = append(resources, more...)
+	}
+	return writeResources(c, props, resources, "")
+}
+
+// Report はカレンダーの REPORT（calendar-query、calendar-multiget、sync-collection）を処理する
+func (h *CalDAVHandler) Report(c echo.Context) error {
+	resource, err := h.calendar(c)
+	if err != nil {
+		return caldavError(c, err)
+	}
+	var report davReport
+	if found, err := readDAVRequest(c, &report); err != nil || !found {
+		return c.String(http.StatusBadRequest, "Invalid REPORT request")
+	}
+	props := davPropRequest{all: report.AllProp != nil || len(report.Prop) == 0, props: report.Prop}
+	ctx := c.Request().Context()
+
+	switch report.XMLName {
+	case davName(nsCalDAV, "calendar-query"):
+		params, ok, err := queryWindow(report.Filter)
+		if err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		var resources []davResource
+		if ok {
+			reservations, err := h.calendarEvents(ctx, resource.ID, params)
+			if err != nil {
+				return caldavError(c, err)
+			}
+			for _, r := range reservations {
+				resources = append(resources, eventResource(r, resource.Name))
+			}
+		}
+		return writeResources(c, props, resources, "")
+
+	case davName(nsCalDAV, "calendar-multiget"):
+		ms := &davMultistatus{}
+		for _, href := range report.Hrefs {
+			uid, ok := eventHrefUID(href, resource.ID)
+			var reservation *model.Reservation
+			if ok {
+				reservation, err = h.reservations.GetEvent(ctx, resource.ID, uid)
+			}
+			if !ok || errors.Is(err, service.ErrReservationNotFound) {
+				ms.Responses = append(ms.Responses, davResponse{Href: href, Status: davStatus(http.StatusNotFound)})
+				continue
+			}
+			if err != nil {
+				return caldavError(c, err)
+			}
+			resp, err := props.response(eventResource(reservation, resource.Name))
+			if err != nil {
+				return caldavError(c, err)
+			}
+			ms.Responses = append(ms.Responses, resp)
+		}
+		return writeMultistatus(c, ms)
+
+	case davName(nsDAV, "sync-collection"):
+		// 変更の履歴は持たないため、現在の sync-token 以外からの差分は求められない。
+		// その場合は valid-sync-token のエラーを返し、カレンダーアプリに全件を同期し直させる。
+		reservations, err := h.calendarEvents(ctx, resource.ID, service.ExportReservationsParams{})
+		if err != nil {
+			return caldavError(c, err)
+		}
+		token := caldavSyncTokenPrefix + calendarTag(reservations)
+		switch report.SyncToken {
+		case token:
+			return writeMultistatus(c, &davMultistatus{SyncToken: token})
+		case "":
+			resources := make([]davResource, 0, len(reservations))
+			for _, r := range reservations {
+				resources = append(resources, eventResource(r, resource.Name))
+			}
+			return writeResources(c, props, resources, token)
+		default:
+			return writeDAVError(c, http.StatusForbidden, davName(nsDAV, "valid-sync-token"))
+		}
+
+	default:
+		return writeDAVError(c, http.StatusForbidden, davName(nsDAV, "supported-report"))
+	}
+}
+
+// GetEvent はイベントを iCalendar として ETag ヘッダーとともに返す
+func (h *CalDAVHandler) GetEvent(c echo.Context) error {
+	resource, uid, err := h.eventTarget(c)
+	if err != nil {
+		return caldavError(c, err)
+	}
+	reservation, err := h.reservations.GetEvent(c.Request().Context(), resource.ID, uid)
+	if err != nil {
+		return caldavError(c, err)
+	}
+	body, err := eventCalendar(reservation, resource.Name)
+	if err != nil {
+		return caldavError(c, err)
+	}
+
+	c.Response().Header().Set("ETag", reservationETag(reservation))
+	return c.Blob(http.StatusOK, ical.ContentType, []byte(body))
+}
+
+// PutEvent はリクエストの本文のイベントで予約を作成または変更する。リソースの名前はイベントの UID に .ics を付けたものにする。
+// If-Match を指定するとその ETag の予約だけを変更し、If-None-Match: * を指定すると予約がない場合だけ作成する。
+// どちらもない場合は予約がない場合だけ作成し、既存の予約は REST API と同じく 428 を返して変更しない。
+// 保存した予約は送られたイベントと同じ内容にならないため、ETag は返さない（カレンダーアプリは GET で取得し直す）。
+func (h *CalDAVHandler) PutEvent(c echo.Context) error {
+	resource, uid, err := h.eventTarget(c)
+	if err != nil {
+		return caldavError(c, err)
+	}
+	params := service.PutEventParams{
+		ResourceID: resource.ID,
+		CreateOnly: strings.TrimSpace(c.Request().Header.Get("If-None-Match")) == "*",
+	}
+	params.Version, err = ifMatchVersion(c)
+	switch {
+	case errors.Is(err, errMissingIfMatch):
+		params.RequireVersion = !params.CreateOnly
+	case err != nil:
+		return caldavError(c, err)
+	}
+	loc, err := timeZoneParam(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	events, err := ical.Decode(http.MaxBytesReader(c.Response(), c.Request().Body, maxImportBytes), loc)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return c.String(http.StatusRequestEntityTooLarge, fmt.Sprintf("iCalendar must not exceed %d bytes", maxImportBytes))
+	case err != nil:
+		return writeDAVError(c, http.StatusBadRequest, davName(nsCalDAV, "valid-calendar-data"))
+	case len(events) == 0:
+		return writeDAVError(c, http.StatusForbidden, davName(nsCalDAV, "supported-calendar-component"))
+	case len(events) > 1:
+		return writeDAVError(c, http.StatusForbidden, davName(nsCalDAV, "valid-calendar-object-resource"))
+	case events[0].Err != nil:
+		return writeDAVError(c, http.StatusForbidden, davName(nsCalDAV, "valid-calendar-data"))
+	case events[0].UID != uid:
+		return c.String(http.StatusBadRequest, "The resource name must be the event's UID followed by "+caldavEventExt)
+	}
+	params.Event = events[0].Event
+
+	_, created, err := h.reservations.PutEvent(c.Request().Context(), params)
+	if err != nil {
+		return caldavError(c, err)
+	}
+	if created {
+		return c.NoContent(http.StatusCreated)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteEvent はイベントの予約を取り消す。If-Match が必須で、その ETag の予約だけを取り消す（* の場合は版を確認しない）。
+func (h *CalDAVHandler) DeleteEvent(c echo.Context) error {
+	resource, uid, err := h.eventTarget(c)
+	if err != nil {
+		return caldavError(c, err)
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return caldavError(c, err)
+	}
+
+	if err := h.reservations.DeleteEvent(c.Request().Context(), resource.ID, uid, version); err != nil {
+		return caldavError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// caldavError はサービスが返したエラーを CalDAV のレスポンスに変換する
+func caldavError(c echo.Context, err error) error {
+	var policyErr *service.PolicyViolationError
+	var conflictErr *service.ConflictError
+	var detailsErr *service.InvalidDetailsError
+	switch {
+	case errors.As(err, &conflictErr):
+		return c.String(http.StatusConflict, conflictErr.Error())
+	case errors.As(err, &detailsErr):
+		return c.String(http.StatusBadRequest, detailsErr.Error())
+	case errors.As(err, &policyErr):
+		return c.String(http.StatusForbidden, policyErr.Error())
+	case errors.Is(err, service.ErrUIDConflict):
+		return writeDAVError(c, http.StatusConflict, davName(nsCalDAV, "no-uid-conflict"))
+	case errors.Is(err, service.ErrUnsupportedEvent):
+		return c.String(http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		return c.String(http.StatusForbidden, "You do not have permission to perform this action on the reservation")
+	case errors.Is(err, errMissingIfMatch), errors.Is(err, service.ErrVersionRequired):
+		return c.String(http.StatusPreconditionRequired, "If-Match header with the event's ETag is required to change an existing event")
+	case errors.Is(err, service.ErrVersionConflict):
+		return c.String(http.StatusPreconditionFailed, "Reservation has been modified by another request")
+	case errors.Is(err, service.ErrReservationNotFound), errors.Is(err, service.ErrResourceNotFound):
+		return c.String(http.StatusNotFound, "Not found")
+	case errors.Is(err, service.ErrInvalidTimeRange):
+		return c.String(http.StatusBadRequest, "End time must be after start time")
+	case errors.Is(err, service.ErrResourceInactive):
+		return c.String(http.StatusForbidden, "Resource is not active")
+	case errors.Is(err, service.ErrReservationNotEditable):
+		return c.String(http.StatusForbidden, "Cancelled or finished reservations cannot be changed")
+	default:
+		return c.String(http.StatusInternalServerError, "Failed to process CalDAV request")
+	}
+}
+
+// feedResourceID はフィードのトークンで認証したリクエストで、フィードが指定したリソースのIDを返す。指定がない場合は空文字列。
+func feedResourceID(c echo.Context) string {
+	id, _ := c.Get(caldavFeedResourceKey).(string)
+	return id
+}
+
+// calendars はリクエストで扱えるカレンダーのリソースを返す
+func (h *CalDAVHandler) calendars(c echo.Context) ([]*model.Resource, error) {
+	id := feedResourceID(c)
+	if id == "" {
+		return h.resources.GetAllResources()
+	}
+	resource, err := h.resources.GetResource(id)
+	if errors.Is(err, service.ErrResourceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*model.Resource{resource}, nil
+}
+
+// calendar は URL のカレンダーのリソースを返す。リソースが存在しないか扱えない場合は service.ErrResourceNotFound を返す。
+func (h *CalDAVHandler) calendar(c echo.Context) (*model.Resource, error) {
+	id := c.Param("resourceId")
+	if restricted := feedResourceID(c); restricted != "" && restricted != id {
+		return nil, service.ErrResourceNotFound
+	}
+	return h.resources.GetResource(id)
+}
+
+// eventTarget は URL のイベントのカレンダーのリソースと UID を返す。名前が UID.ics の形式でない場合は service.ErrReservationNotFound を返す。
+func (h *CalDAVHandler) eventTarget(c echo.Context) (*model.Resource, string, error) {
+	resource, err := h.calendar(c)
+	if err != nil {
+		return nil, "", err
+	}
+	uid, ok := eventUID(path.Base(c.Request().URL.EscapedPath()))
+	if !ok {
+		return nil, "", service.ErrReservationNotFound
+	}
+	return resource, uid, nil
+}
+
+// calendarEvents はリソースのカレンダーにある params の期間の予約を返す
+func (h *CalDAVHandler) calendarEvents(ctx context.Context, resourceID string, params service.ExportReservationsParams) ([]*model.Reservation, error) {
+	params.ResourceID = resourceID
+	var reservations []*model.Reservation
+	err := h.reservations.StreamReservations(ctx, params, func(reservation *model.Reservation, _ string) error {
+		reservations = append(reservations, reservation)
+		return nil
+	})
+	return reservations, err
+}
+
+// calendarTag はカレンダーの予約の ID と版から、カレンダーの内容が変わるたびに変わる値（ctag）を返す
+func calendarTag(reservations []*model.Reservation) string {
+	hash := sha256.New()
+	for _, r := range reservations {
+		fmt.Fprintf(hash, "%s:%d\n", r.ID, r.Version)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// queryWindow は calendar-query の filter の time-range から予約を探す期間を返す。
+// time-range がない場合は期間を指定しない書き出しと同じ期間にし、片方だけの指定や service.MaxListWindow より長い期間は
+// MaxListWindow に収める。VEVENT 以外のコンポーネントを探す filter には false を返す。
+func queryWindow(filter *davFilter) (service.ExportReservationsParams, bool, error) {
+	var params service.ExportReservationsParams
+	if filter == nil {
+		return params, true, nil
+	}
+	if filter.CompFilter.Name != "VCALENDAR" {
+		return params, false, nil
+	}
+	var tr *davTimeRange
+	if comps := filter.CompFilter.CompFilters; len(comps) > 0 {
+		if comps[0].Name != "VEVENT" {
+			return params, false, nil
+		}
+		tr = comps[0].TimeRange
+	}
+	if tr == nil {
+		return params, true, nil
+	}
+
+	var err error
+	if tr.Start != "" {
+		if params.From, err = time.Parse("20060102T150405Z", tr.Start); err != nil {
+			return params, false, errors.New("Invalid time-range start")
+		}
+	}
+	if tr.End != "" {
+		if params.To, err = time.Parse("20060102T150405Z", tr.End); err != nil {
+			return params, false, errors.New("Invalid time-range end")
+		}
+	}
+	switch {
+	case params.From.IsZero() && params.To.IsZero():
+	case params.To.IsZero():
+		params.To = params.From.Add(service.MaxListWindow)
+	case params.From.IsZero():
+		params.From = params.To.Add(-service.MaxListWindow)
+	case !params.To.After(params.From):
+		return params, false, errors.New("time-range end must be after start")
+	case params.To.Sub(params.From) > service.MaxListWindow:
+		params.To = params.From.Add(service.MaxListWindow)
+	}
+	return params, true, nil
+}
+
+// calendarPath はリソースのカレンダーの URL のパスを返す
+func calendarPath(resourceID string) string {
+	return caldavHomePath + url.PathEscape(resourceID) + "/"
+}
+
+// eventPath はカレンダーの UID のイベントの URL のパスを返す
+func eventPath(resourceID, uid string) string {
+	return calendarPath(resourceID) + url.PathEscape(uid) + caldavEventExt
+}
+
+// eventUID はエスケープされたイベントのリソースの名前から UID を返す
+func eventUID(name string) (string, bool) {
+	escaped, ok := strings.CutSuffix(name, caldavEventExt)
+	if !ok || escaped == "" {
+		return "", false
+	}
+	uid, err := url.PathUnescape(escaped)
+	return uid, err == nil
+}
+
+// eventHrefUID は calendar-multiget の href がリソースのカレンダーのイベントであれば UID を返す
+func eventHrefUID(href, resourceID string) (string, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	rest, ok := strings.CutPrefix(u.EscapedPath(), caldavHomePath)
+	if !ok {
+		return "", false
+	}
+	calendar, name, ok := strings.Cut(rest, "/")
+	if !ok {
+		return "", false
+	}
+	if id, err := url.PathUnescape(calendar); err != nil || id != resourceID {
+		return "", false
+	}
+	return eventUID(name)
+}
+
+// eventCalendar は予約を1件のイベントの iCalendar にする
+func eventCalendar(reservation *model.Reservation, resourceName string) (string, error) {
+	var b strings.Builder
+	err := ical.Encode(&b, &ical.Calendar{
+		ProdID: icalProdID,
+		Events: []ical.Event{reservationEvent(reservation, resourceName)},
+	})
+	return b.String(), err
+}
+
+// davResource は PROPFIND と REPORT で返すリソースとそのプロパティ
+type davResource struct {
+	href  string
+	props []davProp
+}
+
+// davProp はリソースのプロパティ。value は要求された場合だけ呼ばれ、要素の内容の XML を返す。
+type davProp struct {
+	name  xml.Name
+	value func() (string, error)
+	// hidden のプロパティは allprop では返さない
+	hidden bool
+}
+
+func davValue(inner string) func() (string, error) {
+	return func() (string, error) { return inner, nil }
+}
+
+// collectionResource はコレクションのリソースを返す。プロパティの先頭は resourcetype。
+func collectionResource(href, displayName string) davResource {
+	return davResource{href: href, props: []davProp{
+		{name: davName(nsDAV, "resourcetype"), value: davValue(`<collection/>`)},
+		{name: davName(nsDAV, "displayname"), value: davValue(escapeXML(displayName))},
+		{name: davName(nsDAV, "current-user-principal"), value: davValue(hrefXML(caldavPrincipalPath))},
+	}}
+}
+
+// calendarResource はリソースのカレンダーを返す。ctag と sync-token は要求された場合だけカレンダーの予約から求める。
+func (h *CalDAVHandler) calendarResource(ctx context.Context, resource *model.Resource) davResource {
+	res := collectionResource(calendarPath(resource.ID), resource.Name)
+	res.props[0].value = davValue(`<collection/><calendar xmlns="` + nsCalDAV + `"/>`)
+	tag := func() (string, error) {
+		reservations, err := h.calendarEvents(ctx, resource.ID, service.ExportReservationsParams{})
+		if err != nil {
+			return "", err
+		}
+		return calendarTag(reservations), nil
+	}
+	res.props = append(res.props,
+		davProp{name: davName(nsCalDAV, "supported-calendar-component-set"), value: davValue(`<comp name="VEVENT"/>`)},
+		davProp{name: davName(nsDAV, "supported-report-set"), value: davValue(
+			`<supported-report><report><calendar-query xmlns="` + nsCalDAV + `"/></report></supported-report>` +
+				`<supported-report><report><calendar-multiget xmlns="` + nsCalDAV + `"/></report></supported-report>` +
+				`<supported-report><report><sync-collection/></report></supported-report>`)},
+		davProp{name: davName(nsDAV, "current-user-privilege-set"), value: davValue(privilegeSet(ctx))},
+		davProp{name: davName(nsCalendarServer, "getctag"), value: tag},
+		davProp{name: davName(nsDAV, "sync-token"), value: func() (string, error) {
+			t, err := tag()
+			return escapeXML(caldavSyncTokenPrefix + t), err
+		}},
+	)
+	return res
+}
+
+// privilegeSet は current-user-privilege-set の内容を返す。予約を作成できない権限には読み取りだけを返す。
+func privilegeSet(ctx context.Context) string {
+	privileges := `<privilege><read/></privilege><privilege><read-current-user-privilege-set/></privilege>`
+	if p, ok := auth.PrincipalFrom(ctx); ok && service.Allowed(p, service.ActionCreate, nil) {
+		privileges += `<privilege><write/></privilege><privilege><write-content/></privilege>` +
+			`<privilege><bind/></privilege><privilege><unbind/></privilege>`
+	}
+	return privileges
+}
+
+// eventResource は予約のイベントを返す。calendar-data は allprop では返さない。
+func eventResource(reservation *model.Reservation, resourceName string) davResource {
+	return davResource{href: eventPath(reservation.ResourceID, reservation.UID()), props: []davProp{
+		{name: davName(nsDAV, "resourcetype"), value: davValue("")},
+		{name: davName(nsDAV, "getetag"), value: davValue(escapeXML(reservationETag(reservation)))},
+		{name: davName(nsDAV, "getcontenttype"), value: davValue(ical.ContentType)},
+		{name: davName(nsCalDAV, "calendar-data"), hidden: true, value: func() (string, error) {
+			body, err := eventCalendar(reservation, resourceName)
+			return escapeXML(body), err
+		}},
+	}}
+}
+
+// davPropRequest は返すプロパティの指定。all は allprop、names は propname、どちらでもない場合は props のプロパティを返す。
+type davPropRequest struct {
+	all   bool
+	names bool
+	props []xml.Name
+}
+
+// response はリソースの要求されたプロパティを返す。リソースにないプロパティは 404 の propstat で返す。
+func (r davPropRequest) response(res davResource) (davResponse, error) {
+	found := davPropstat{Status: davStatus(http.StatusOK)}
+	missing := davPropstat{Status: davStatus(http.StatusNotFound)}
+	add := func(p davProp) error {
+		inner, err := p.value()
+		found.Props = append(found.Props, davProperty{Name: p.name, Inner: inner})
+		return err
+	}
+
+	switch {
+	case r.names:
+		for _, p := range res.props {
+			found.Props = append(found.Props, davProperty{Name: p.name})
+		}
+	case r.all:
+		for _, p := range res.props {
+			if p.hidden {
+				continue
+			}
+			if err := add(p); err != nil {
+				return davResponse{}, err
+			}
+		}
+	default:
+	requested:
+		for _, name := range r.props {
+			for _, p := range res.props {
+				if p.name == name {
+					if err := add(p); err != nil {
+						return davResponse{}, err
+					}
+					continue requested
+				}
+			}
+			missing.Props = append(missing.Props, davProperty{Name: name})
+		}
+	}
+
+	resp := davResponse{Href: res.href}
+	if len(found.Props) > 0 || len(missing.Props) == 0 {
+		resp.Propstats = append(resp.Propstats, found)
+	}
+	if len(missing.Props) > 0 {
+		resp.Propstats = append(resp.Propstats, missing)
+	}
+	return resp, nil
+}
+
+// writeResources はリソースのプロパティを multistatus で返す。syncToken が空でなければ multistatus に含める。
+func writeResources(c echo.Context, props davPropRequest, resources []davResource, syncToken string) error {
+	ms := &davMultistatus{SyncToken: syncToken}
+	for _, res := range resources {
+		resp, err := props.response(res)
+		if err != nil {
+			return caldavError(c, err)
+		}
+		ms.Responses = append(ms.Responses, resp)
+	}
+	return writeMultistatus(c, ms)
+}
@@ -0,0 +1,473 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// newCalDAVTestServer は room-a と room-b のカレンダーを持つ CalDAV のテスト用サーバーを返す。
+// Basic 認証のパスワード caldav は CalDAV を有効にしたフィード、room-a は room-a だけのフィード、feed は CalDAV を有効にしていないフィードのトークン。
+func newCalDAVTestServer(reservations *mockReservationService) *echo.Echo {
+	e := echo.New()
+	feeds := &mockCalendarFeedService{
+		authenticateFunc: func(token string) (*model.CalendarFeed, error) {
+			feed := model.NewCalendarFeed("user-1", model.RoleMember, "", "hash")
+			switch token {
+			case "caldav":
+				feed.CalDAV = true
+			case "room-a":
+				feed.CalDAV, feed.ResourceID = true, "room-a"
+			case "feed":
+			default:
+				return nil, service.ErrCalendarFeedNotFound
+			}
+			return feed, nil
+		},
+	}
+	resources := map[string]*model.Resource{
+		"room-a": {ID: "room-a", Name: "会議室A", Active: true},
+		"room-b": {ID: "room-b", Name: "会議室B", Active: true},
+	}
+	resourceSvc := &mockResourceService{
+		getAllResourcesFunc: func() ([]*model.Resource, error) {
+			return []*model.Resource{resources["room-a"], resources["room-b"]}, nil
+		},
+		getResourceFunc: func(id string) (*model.Resource, error) {
+			if r, ok := resources[id]; ok {
+				return r, nil
+			}
+			return nil, service.ErrResourceNotFound
+		},
+	}
+	NewCalDAVHandler(feeds, resourceSvc, reservations).RegisterRoutes(e)
+	return e
+}
+
+func TestCalDAVAuthentication(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		principal  bool
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "CalDAV を有効にしたフィードのトークン", password: "caldav", target: "/caldav/calendars/", wantStatus: http.StatusMultiStatus, wantBody: "/caldav/calendars/room-b/"},
+		{name: "認証済みのプリンシパル", principal: true, target: "/caldav/calendars/", wantStatus: http.StatusMultiStatus, wantBody: "/caldav/calendars/room-b/"},
+		{name: "リソースを指定したフィードはそのカレンダーだけを返す", password: "room-a", target: "/caldav/calendars/", wantStatus: http.StatusMultiStatus, wantBody: "/caldav/calendars/room-a/"},
+		{name: "リソースを指定したフィードで他のカレンダー", password: "room-a", target: "/caldav/calendars/room-b/", wantStatus: http.StatusNotFound},
+		{name: "CalDAV を有効にしていないフィード", password: "feed", target: "/caldav/calendars/", wantStatus: http.StatusUnauthorized},
+		{name: "無効なトークン", password: "revoked", target: "/caldav/calendars/", wantStatus: http.StatusUnauthorized},
+		{name: "認証なし", target: "/caldav/calendars/", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := newCalDAVTestServer(&mockReservationService{})
+			req := httptest.NewRequest(echo.PROPFIND, tt.target, strings.NewReader(`<propfind xmlns="DAV:"><prop><displayname/></prop></propfind>`))
+			req.Header.Set("Depth", "1")
+			if tt.password != "" {
+				req.SetBasicAuth("user", tt.password)
+			}
+			if tt.principal {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{UserID: "user-1", Role: model.RoleMember}))
+			}
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantBody != "" && !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %q, got %s", tt.wantBody, rec.Body.String())
+			}
+			if tt.password == "room-a" && strings.Contains(rec.Body.String(), "room-b") {
+				t.Errorf("Expected only the feed's calendar, got %s", rec.Body.String())
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get(echo.HeaderWWWAuthenticate) != `Basic realm="yoyaku"` {
+				t.Errorf("Expected Basic challenge, got %q", rec.Header().Get(echo.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
+
+func TestCalDAVPropfind(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	reservation := model.NewReservation("room-a", start, start.Add(time.Hour))
+	reservation.ICalUID = "a/b@example.com"
+
+	tests := []struct {
+		name       string
+		target     string
+		depth      string
+		body       string
+		wantBody   []string
+		rejectBody []string
+	}{
+		{
+			name: "プリンシパルのカレンダーの場所", target: "/caldav/principal/", depth: "0",
+			body:     `<propfind xmlns="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><prop><C:calendar-home-set/></prop></propfind>`,
+			wantBody: []string{`<calendar-home-set xmlns="urn:ietf:params:xml:ns:caldav"><href xmlns="DAV:">/caldav/calendars/</href></calendar-home-set>`},
+		},
+		{
+			name: "Depth 0 はカレンダーだけを返す", target: "/caldav/calendars/room-a", depth: "0",
+			body:       `<propfind xmlns="DAV:"><prop><displayname/><getetag/></prop></propfind>`,
+			wantBody:   []string{"<displayname xmlns=\"DAV:\">会議室A</displayname>", "<status>HTTP/1.1 404 Not Found</status>"},
+			rejectBody: []string{".ics"},
+		},
+		{
+			name: "Depth 1 はイベントも返す", target: "/caldav/calendars/room-a/", depth: "1",
+			body:     `<propfind xmlns="DAV:"><prop><getetag/></prop></propfind>`,
+			wantBody: []string{"<href>/caldav/calendars/room-a/a%2Fb@example.com.ics</href>", `<getetag xmlns="DAV:">&#34;1&#34;</getetag>`},
+		},
+		{
+			name: "本文がない場合は allprop", target: "/caldav/calendars/room-a/", depth: "1",
+			wantBody:   []string{"<getcontenttype", "<getctag"},
+			rejectBody: []string{"calendar-data"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := newCalDAVTestServer(&mockReservationService{
+				streamReservationsFunc: func(params service.ExportReservationsParams, fn func(reservation *model.Reservation, resourceName string) error) error {
+					if params.ResourceID != "room-a" {
+						t.Errorf("Expected resource room-a, got %q", params.ResourceID)
+					}
+					return fn(reservation, "会議室A")
+				},
+			})
+			req := httptest.NewRequest(echo.PROPFIND, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Depth", tt.depth)
+			req.SetBasicAuth("user", "caldav")
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != http.StatusMultiStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusMultiStatus, rec.Code, rec.Body.String())
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("Expected body to contain %q, got %s", want, rec.Body.String())
+				}
+			}
+			for _, reject := range tt.rejectBody {
+				if strings.Contains(rec.Body.String(), reject) {
+					t.Errorf("Expected body not to contain %q, got %s", reject, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCalDAVReport(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	reservation := model.NewReservation("room-a", start, start.Add(time.Hour))
+	reservation.Title = "定例会議"
+	tag := calendarTag([]*model.Reservation{reservation})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantFrom   time.Time
+		wantBody   []string
+	}{
+		{
+			name: "calendar-query は time-range の期間のイベントを返す",
+			body: `<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><D:prop><C:calendar-data/></D:prop>` +
+				`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT"><C:time-range start="20300101T000000Z"/></C:comp-filter></C:comp-filter></C:filter></C:calendar-query>`,
+			wantStatus: http.StatusMultiStatus,
+			wantFrom:   time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantBody:   []string{"SUMMARY:定例会議", "<href>/caldav/calendars/room-a/" + reservation.ID + "@yoyaku.ics</href>"},
+		},
+		{
+			name: "calendar-multiget は見つからないイベントを 404 で返す",
+			body: `<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><D:prop><D:getetag/></D:prop>` +
+				`<D:href>/caldav/calendars/room-a/` + reservation.ID + `@yoyaku.ics</D:href><D:href>/caldav/calendars/room-a/unknown.ics</D:href></C:calendar-multiget>`,
+			wantStatus: http.StatusMultiStatus,
+			wantBody:   []string{`<getetag xmlns="DAV:">&#34;1&#34;</getetag>`, "<href>/caldav/calendars/room-a/unknown.ics</href><status>HTTP/1.1 404 Not Found</status>"},
+		},
+		{
+			name:       "sync-collection の初回は全てのイベントと sync-token を返す",
+			body:       `<D:sync-collection xmlns:D="DAV:"><D:sync-token/><D:sync-level>1</D:sync-level><D:prop><D:getetag/></D:prop></D:sync-collection>`,
+			wantStatus: http.StatusMultiStatus,
+			wantBody:   []string{"@yoyaku.ics</href>", "<sync-token>urn:yoyaku:sync:" + tag + "</sync-token>"},
+		},
+		{
+			name:       "sync-collection は変更がなければイベントを返さない",
+			body:       `<D:sync-collection xmlns:D="DAV:"><D:sync-token>urn:yoyaku:sync:` + tag + `</D:sync-token><D:prop><D:getetag/></D:prop></D:sync-collection>`,
+			wantStatus: http.StatusMultiStatus,
+			wantBody:   []string{`<multistatus xmlns="DAV:"><sync-token>`},
+		},
+		{
+			name:       "sync-collection は古い sync-token を拒否する",
+			body:       `<D:sync-collection xmlns:D="DAV:"><D:sync-token>urn:yoyaku:sync:old</D:sync-token><D:prop><D:getetag/></D:prop></D:sync-collection>`,
+			wantStatus: http.StatusForbidden,
+			wantBody:   []string{"valid-sync-token"},
+		},
+		{
+			name:       "未対応の REPORT",
+			body:       `<C:free-busy-query xmlns:C="urn:ietf:params:xml:ns:caldav"/>`,
+			wantStatus: http.StatusForbidden,
+			wantBody:   []string{"supported-report"},
+		},
+		{
+			name:       "不正な XML",
+			body:       `<C:calendar-query`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := newCalDAVTestServer(&mockReservationService{
+				streamReservationsFunc: func(params service.ExportReservationsParams, fn func(reservation *model.Reservation, resourceName string) error) error {
+					if !params.From.Equal(tt.wantFrom) {
+						t.Errorf("Expected from %v, got %v", tt.wantFrom, params.From)
+					}
+					return fn(reservation, "会議室A")
+				},
+				getEventFunc: func(resourceID, uid string) (*model.Reservation, error) {
+					if uid != reservation.UID() {
+						return nil, service.ErrReservationNotFound
+					}
+					return reservation, nil
+				},
+			})
+			req := httptest.NewRequest(echo.REPORT, "/caldav/calendars/room-a/", strings.NewReader(tt.body))
+			req.Header.Set("Depth", "1")
+			req.SetBasicAuth("user", "caldav")
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("Expected body to contain %q, got %s", want, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCalDAVPutEvent(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	event := func(uid string) string {
+		return "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:" + uid + "\r\nDTSTART:20300107T100000Z\r\nDTEND:20300107T110000Z\r\nSUMMARY:定例会議\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	}
+
+	tests := []struct {
+		name        string
+		target      string
+		body        string
+		header      map[string]string
+		err         error
+		created     bool
+		wantStatus  int
+		wantVersion int64
+		wantCreate  bool
+		wantRequire bool
+	}{
+		{name: "イベントを作成する", target: "/caldav/calendars/room-a/event-1.ics", body: event("event-1"), header: map[string]string{"If-None-Match": "*"}, created: true, wantStatus: http.StatusCreated, wantCreate: true},
+		{name: "If-Match の版で変更する", target: "/caldav/calendars/room-a/event-1.ics", body: event("event-1"), header: map[string]string{"If-Match": `"3"`}, wantStatus: http.StatusNoContent, wantVersion: 3},
+		{name: "UID のエスケープ", target: "/caldav/calendars/room-a/a%2Fb@example.com.ics", body: event("a/b@example.com"), created: true, wantStatus: http.StatusCreated, wantRequire: true},
+		{name: "If-Match なしで既存のイベントを変更する", target: "/caldav/calendars/room-a/event-1.ics", body: event("event-1"), err: service.ErrVersionRequired, wantStatus: http.StatusPreconditionRequired, wantRequire: true},
+		{name: "If-Match: * で版を確認せずに変更する", target: "/caldav/calendars/room-a/event-1.ics", body: event("event-1"), header: map[string]string{"If-Match": "*"}, wantStatus: http.StatusNoContent},
+		{name: "予約の詳細が不正", target: "/caldav/calendars/room-a/event-1.ics", body: event("event-1"), header: map[string]string{"If-Match": `"3"`}, err: &service.InvalidDetailsError{Field: "title", Reason: "must be at most 200 characters"}, wantStatus: http.StatusBadRequest, wantVersion: 3},
+		{name: "権限がない", target: "/caldav/calendars/room-a/event-1.ics", body: event("event-1"), header: map[string]string{"If-None-Match": "*"}, err: service.ErrForbidden, wantStatus: http.StatusForbidden, wantCreate: true},
+		{name: "名前と UID が異なる", target: "/caldav/calendars/room-a/other.ics", body: event("event-1"), wantStatus: http.StatusBadRequest},
+		{name: "複数のイベント", target: "/caldav/calendars/room-a/event-1.ics", body: strings.Replace(event("event-1"), "END:VCALENDAR", "BEGIN:VEVENT\r\nUID:event-2\r\nDTSTART:20300107T100000Z\r\nEND:VEVENT\r\nEND:VCALENDAR", 1), wantStatus: http.StatusForbidden},
+		{name: "VEVENT がない", target: "/caldav/calendars/room-a/event-1.ics", body: "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", wantStatus: http.StatusForbidden},
+		{name: "不正な iCalendar", target: "/caldav/calendars/room-a/event-1.ics", body: "not a calendar", wantStatus: http.StatusBadRequest},
+		{name: "重なる予約がある", target: "/caldav/calendars/room-a/event-1.ics", body: event("event-1"), err: &service.ConflictError{}, wantStatus: http.StatusConflict, wantRequire: true},
+		{name: "UID が他のカレンダーで使われている", target: "/caldav/calendars/room-a/event-1.ics", body: event("event-1"), err: service.ErrUIDConflict, wantStatus: http.StatusConflict, wantRequire: true},
+		{name: "版が一致しない", target: "/caldav/calendars/room-a/event-1.ics", body: event("event-1"), err: service.ErrVersionConflict, wantStatus: http.StatusPreconditionFailed, wantRequire: true},
+		{name: "繰り返しのイベント", target: "/caldav/calendars/room-a/event-1.ics", body: event("event-1"), err: service.ErrUnsupportedEvent, wantStatus: http.StatusForbidden, wantRequire: true},
+		{name: "存在しないカレンダー", target: "/caldav/calendars/room-z/event-1.ics", body: event("event-1"), wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := newCalDAVTestServer(&mockReservationService{
+				putEventFunc: func(params service.PutEventParams) (*model.Reservation, bool, error) {
+					if params.ResourceID != "room-a" || params.Version != tt.wantVersion || params.CreateOnly != tt.wantCreate || params.RequireVersion != tt.wantRequire {
+						t.Errorf("Unexpected params %+v", params)
+					}
+					if !params.Event.Start.Equal(start) || params.Event.Summary != "定例会議" {
+						t.Errorf("Unexpected event %+v", params.Event)
+					}
+					if tt.err != nil {
+						return nil, false, tt.err
+					}
+					return model.NewReservation("room-a", start, start.Add(time.Hour)), tt.created, nil
+				},
+			})
+			req := httptest.NewRequest(http.MethodPut, tt.target, strings.NewReader(tt.body))
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			req.SetBasicAuth("user", "caldav")
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCalDAVGetAndDeleteEvent(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	reservation := model.NewReservation("room-a", start, start.Add(time.Hour))
+	reservation.Version = 2
+
+	tests := []struct {
+		name        string
+		method      string
+		header      map[string]string
+		err         error
+		wantStatus  int
+		wantVersion int64
+	}{
+		{name: "イベントを取得する", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "存在しないイベントの取得", method: http.MethodGet, err: service.ErrReservationNotFound, wantStatus: http.StatusNotFound},
+		{name: "イベントを削除する", method: http.MethodDelete, header: map[string]string{"If-Match": "*"}, wantStatus: http.StatusNoContent},
+		{name: "If-Match なしでは削除しない", method: http.MethodDelete, wantStatus: http.StatusPreconditionRequired},
+		{name: "If-Match の版で削除する", method: http.MethodDelete, header: map[string]string{"If-Match": `"2"`}, wantStatus: http.StatusNoContent, wantVersion: 2},
+		{name: "弱い ETag は一致しない", method: http.MethodDelete, header: map[string]string{"If-Match": `W/"2"`}, wantStatus: http.StatusPreconditionFailed},
+		{name: "権限がない", method: http.MethodDelete, header: map[string]string{"If-Match": `"2"`}, err: service.ErrForbidden, wantStatus: http.StatusForbidden, wantVersion: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := newCalDAVTestServer(&mockReservationService{
+				getEventFunc: func(resourceID, uid string) (*model.Reservation, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return reservation, nil
+				},
+				deleteEventFunc: func(resourceID, uid string, version int64) error {
+					if resourceID != "room-a" || uid != reservation.UID() || version != tt.wantVersion {
+						t.Errorf("Unexpected delete of %s %s version %d", resourceID, uid, version)
+					}
+					return tt.err
+				},
+			})
+			req := httptest.NewRequest(tt.method, "/caldav/calendars/room-a/"+reservation.UID()+".ics", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			req.SetBasicAuth("user", "caldav")
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.method == http.MethodGet && rec.Code == http.StatusOK {
+				if rec.Header().Get("ETag") != `"2"` || !strings.Contains(rec.Body.String(), "UID:"+reservation.UID()) {
+					t.Errorf("Unexpected event %q:\n%s", rec.Header().Get("ETag"), rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestQueryWindow(t *testing.T) {
+	from := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := func(tr *davTimeRange) *davFilter {
+		return &davFilter{CompFilter: davCompFilter{Name: "VCALENDAR", CompFilters: []davCompFilter{{Name: "VEVENT", TimeRange: tr}}}}
+	}
+
+	tests := []struct {
+		name     string
+		filter   *davFilter
+		wantOK   bool
+		wantFrom time.Time
+		wantTo   time.Time
+		wantErr  bool
+	}{
+		{name: "filter がない", wantOK: true},
+		{name: "time-range がない", filter: event(nil), wantOK: true},
+		{name: "期間を指定する", filter: event(&davTimeRange{Start: "20300101T000000Z", End: "20300201T000000Z"}), wantOK: true, wantFrom: from, wantTo: from.AddDate(0, 1, 0)},
+		{name: "開始だけを指定する", filter: event(&davTimeRange{Start: "20300101T000000Z"}), wantOK: true, wantFrom: from, wantTo: from.Add(service.MaxListWindow)},
+		{name: "長すぎる期間は収める", filter: event(&davTimeRange{Start: "20300101T000000Z", End: "20350101T000000Z"}), wantOK: true, wantFrom: from, wantTo: from.Add(service.MaxListWindow)},
+		{name: "VTODO は探さない", filter: &davFilter{CompFilter: davCompFilter{Name: "VCALENDAR", CompFilters: []davCompFilter{{Name: "VTODO"}}}}},
+		{name: "終了が開始より前", filter: event(&davTimeRange{Start: "20300201T000000Z", End: "20300101T000000Z"}), wantErr: true},
+		{name: "日時の形式が不正", filter: event(&davTimeRange{Start: "2030-01-01"}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 実行
+			params, ok, err := queryWindow(tt.filter)
+
+			// 検証
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if ok != tt.wantOK || !params.From.Equal(tt.wantFrom) || !params.To.Equal(tt.wantTo) {
+				t.Errorf("Expected %v [%v, %v), got %v [%v, %v)", tt.wantOK, tt.wantFrom, tt.wantTo, ok, params.From, params.To)
+			}
+		})
+	}
+}
+
+func TestEventHrefUID(t *testing.T) {
+	tests := []struct {
+		href    string
+		wantUID string
+		wantOK  bool
+	}{
+		{href: "/caldav/calendars/room-a/event-1.ics", wantUID: "event-1", wantOK: true},
+		{href: "https://example.com/caldav/calendars/room-a/a%2Fb%40example.com.ics", wantUID: "a/b@example.com", wantOK: true},
+		{href: "/caldav/calendars/room-b/event-1.ics"},
+		{href: "/caldav/calendars/room-a/event-1"},
+		{href: "/api/reservations/event-1.ics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.href, func(t *testing.T) {
+			uid, ok := eventHrefUID(tt.href, "room-a")
+			if uid != tt.wantUID || ok != tt.wantOK {
+				t.Errorf("Expected %q %v, got %q %v", tt.wantUID, tt.wantOK, uid, ok)
+			}
+		})
+	}
+}
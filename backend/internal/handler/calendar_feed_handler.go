@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// CalendarFeedServiceInterface はテスト時にモック可能なインターフェース
+type CalendarFeedServiceInterface interface {
+	CreateFeed(ctx context.Context, params service.CreateFeedParams) (*model.CalendarFeed, string, error)
+	ListFeeds(ctx context.Context) ([]*model.CalendarFeed, error)
+	DeleteFeed(ctx context.Context, id string) error
+	Authenticate(ctx context.Context, token string) (context.Context, *model.CalendarFeed, error)
+}
+
+// ReservationExporter はフィードで配信する予約を書き出す
+type ReservationExporter interface {
+	ExportReservations(ctx context.Context, params service.ExportReservationsParams) (*service.ReservationExport, error)
+}
+
+type CalendarFeedHandler struct {
+	service      CalendarFeedServiceInterface
+	reservations ReservationExporter
+	validate     *validator.Validate
+}
+
+func NewCalendarFeedHandler(service CalendarFeedServiceInterface, reservations ReservationExporter) *CalendarFeedHandler {
+	return &CalendarFeedHandler{
+		service:      service,
+		reservations: reservations,
+		validate:     validator.New(),
+	}
+}
+
+type calendarFeedRequest struct {
+	// ResourceID を指定するとそのリソースの予約だけを配信する
+	ResourceID string `json:"resourceId" validate:"max=36"`
+	// CalDAV を指定するとトークンで CalDAV も使えるようにする
+	CalDAV bool `json:"caldav"`
+}
+
+// calendarFeedResponse は発行したフィード。Token と URL は発行時にだけ返す。
+type calendarFeedResponse struct {
+	*model.CalendarFeed
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// RegisterRoutes はフィードのルートを登録する。middleware はフィードの管理のルートに適用する（例: auth.RequireAuth）。
+// 購読の URL はトークンで認証するため middleware を適用しない。
+func (h *CalendarFeedHandler) RegisterRoutes(e *echo.Echo, middleware ...echo.MiddlewareFunc) {
+	e.POST("/api/calendar-feeds", h.CreateFeed, middleware...)
+	e.GET("/api/calendar-feeds", h.GetFeeds, middleware...)
+	e.DELETE("/api/calendar-feeds/:id", h.DeleteFeed, middleware...)
+	e.GET("/api/feeds/:token/reservations.ics", h.GetFeed)
+}
+
+// feedPath は購読の URL のパスを返す
+func feedPath(token string) string {
+	return "/api/feeds/" + token + "/reservations.ics"
+}
+
+// CreateFeed はリクエストを送ったユーザーのフィードを発行する
+func (h *CalendarFeedHandler) CreateFeed(c echo.Context) error {
+	req := new(calendarFeedRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	feed, token, err := h.service.CreateFeed(c.Request().Context(), service.CreateFeedParams{ResourceID: req.ResourceID, CalDAV: req.CalDAV})
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusCreated, calendarFeedResponse{
+			CalendarFeed: feed,
+			Token:        token,
+			URL:          c.Scheme() + "://" + c.Request().Host + feedPath(token),
+		})
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only registered users who can view reservations can create calendar feeds"})
+	case errors.Is(err, service.ErrResourceNotFound):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Resource not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create calendar feed"})
+	}
+}
+
+// GetFeeds はリクエストを送ったユーザーが発行したフィードを返す。トークンは含めない。
+func (h *CalendarFeedHandler) GetFeeds(c echo.Context) error {
+	feeds, err := h.service.ListFeeds(c.Request().Context())
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only registered users can list calendar feeds"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get calendar feeds"})
+	}
+
+	return c.JSON(http.StatusOK, feeds)
+}
+
+// DeleteFeed はフィードを削除し、その URL での購読を止める
+func (h *CalendarFeedHandler) DeleteFeed(c echo.Context) error {
+	err := h.service.DeleteFeed(c.Request().Context(), c.Param("id"))
+	switch {
+	case err == nil:
+		return c.NoContent(http.StatusNoContent)
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Authentication required to delete calendar feeds"})
+	case errors.Is(err, service.ErrCalendarFeedNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Calendar feed not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete calendar feed"})
+	}
+}
+
+// GetFeed はトークンのフィードの予約を、フィードを発行したユーザーの権限で iCalendar として返す。
+// 期間などの条件は予約の書き出しと同じクエリパラメータで指定できる。リソースを指定したフィードではリソースを変更できない。
+func (h *CalendarFeedHandler) GetFeed(c echo.Context) error {
+	ctx, feed, err := h.service.Authenticate(c.Request().Context(), c.Param("token"))
+	if errors.Is(err, service.ErrCalendarFeedNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Calendar feed not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get calendar feed"})
+	}
+
+	params, err := exportParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if feed.ResourceID != "" {
+		params.ResourceID = feed.ResourceID
+	}
+
+	export, err := h.reservations.ExportReservations(ctx, params)
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	return writeICalendar(c, export, params.ResourceID)
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// モックサービスの実装
+type mockCalendarFeedService struct {
+	createFeedFunc   func(params service.CreateFeedParams) (*model.CalendarFeed, string, error)
+	listFeedsFunc    func() ([]*model.CalendarFeed, error)
+	deleteFeedFunc   func(id string) error
+	authenticateFunc func(token string) (*model.CalendarFeed, error)
+}
+
+func (m *mockCalendarFeedService) CreateFeed(ctx context.Context, params service.CreateFeedParams) (*model.CalendarFeed, string, error) {
+	return m.createFeedFunc(params)
+}
+
+func (m *mockCalendarFeedService) ListFeeds(ctx context.Context) ([]*model.CalendarFeed, error) {
+	return m.listFeedsFunc()
+}
+
+func (m *mockCalendarFeedService) DeleteFeed(ctx context.Context, id string) error {
+	return m.deleteFeedFunc(id)
+}
+
+func (m *mockCalendarFeedService) Authenticate(ctx context.Context, token string) (context.Context, *model.CalendarFeed, error) {
+	feed, err := m.authenticateFunc(token)
+	return ctx, feed, err
+}
+
+func TestCreateFeed(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "フィードを発行する", body: `{"resourceId":"room-a"}`, wantStatus: http.StatusCreated},
+		{name: "存在しないリソース", body: `{"resourceId":"unknown"}`, err: service.ErrResourceNotFound, wantStatus: http.StatusBadRequest},
+		{name: "ユーザーIDがない", body: `{}`, err: service.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "不正な JSON", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			feed := model.NewCalendarFeed("user-1", model.RoleMember, "room-a", "hash")
+			mockSvc := &mockCalendarFeedService{
+				createFeedFunc: func(params service.CreateFeedParams) (*model.CalendarFeed, string, error) {
+					if tt.err != nil {
+						return nil, "", tt.err
+					}
+					return feed, "secret-token", nil
+				},
+			}
+			NewCalendarFeedHandler(mockSvc, &mockReservationService{}).RegisterRoutes(e)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/calendar-feeds", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			var got map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if got["id"] != feed.ID || got["token"] != "secret-token" || got["url"] != "http://example.com/api/feeds/secret-token/reservations.ics" {
+				t.Errorf("Unexpected response: %v", got)
+			}
+			if _, ok := got["TokenHash"]; ok {
+				t.Errorf("Expected token hash to be hidden, got %v", got)
+			}
+		})
+	}
+}
+
+func TestDeleteFeed(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "フィードを削除する", wantStatus: http.StatusNoContent},
+		{name: "存在しないフィード", err: service.ErrCalendarFeedNotFound, wantStatus: http.StatusNotFound},
+		{name: "サービスのエラー", err: errors.New("database error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockCalendarFeedService{
+				deleteFeedFunc: func(id string) error {
+					if id != "feed-1" {
+						t.Errorf("Expected ID feed-1, got %s", id)
+					}
+					return tt.err
+				},
+			}
+			NewCalendarFeedHandler(mockSvc, &mockReservationService{}).RegisterRoutes(e)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/calendar-feeds/feed-1", nil)
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetFeed(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	reservation := model.NewReservation("room-a", start, start.Add(time.Hour))
+
+	tests := []struct {
+		name           string
+		token          string
+		query          string
+		feedResourceID string
+		wantStatus     int
+		wantResourceID string
+	}{
+		{name: "フィードの予約を返す", token: "valid", query: "?resourceId=room-b", wantStatus: http.StatusOK, wantResourceID: "room-b"},
+		{name: "リソースを指定したフィードはリソースを変更できない", token: "valid", query: "?resourceId=room-b", feedResourceID: "room-a", wantStatus: http.StatusOK, wantResourceID: "room-a"},
+		{name: "無効なトークン", token: "revoked", wantStatus: http.StatusNotFound},
+		{name: "to の形式が不正", token: "valid", query: "?to=tomorrow", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockCalendarFeedService{
+				authenticateFunc: func(token string) (*model.CalendarFeed, error) {
+					if token != "valid" {
+						return nil, service.ErrCalendarFeedNotFound
+					}
+					return model.NewCalendarFeed("user-1", model.RoleMember, tt.feedResourceID, "hash"), nil
+				},
+			}
+			reservations := &mockReservationService{
+				exportReservationsFunc: func(params service.ExportReservationsParams) (*service.ReservationExport, error) {
+					if params.ResourceID != tt.wantResourceID {
+						t.Errorf("Expected resource %q, got %q", tt.wantResourceID, params.ResourceID)
+					}
+					return &service.ReservationExport{Reservations: []*model.Reservation{reservation}}, nil
+				},
+			}
+			NewCalendarFeedHandler(mockSvc, reservations).RegisterRoutes(e)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/feeds/"+tt.token+"/reservations.ics"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(rec.Body.String(), "UID:"+reservation.ID+"@yoyaku\r\n") {
+				t.Errorf("Expected the reservation in the feed, got:\n%s", rec.Body.String())
+			}
+		})
+	}
+}
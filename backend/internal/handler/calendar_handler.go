@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// CalendarServiceInterface はテスト時にモック可能なインターフェース
+type CalendarServiceInterface interface {
+	GetWeeklySchedule() (*model.WeeklySchedule, error)
+	SetWeeklySchedule(ctx context.Context, schedule model.WeeklySchedule) (*model.WeeklySchedule, error)
+	CreateBlackout(ctx context.Context, params service.CreateBlackoutParams) (*model.Blackout, error)
+	ListBlackouts(params service.ListBlackoutsParams) ([]*model.Blackout, error)
+	DeleteBlackout(ctx context.Context, id string) error
+	GetClosedPeriods(params service.ClosedPeriodsParams) ([]model.ClosedPeriod, error)
+}
+
+type CalendarHandler struct {
+	service  CalendarServiceInterface
+	validate *validator.Validate
+}
+
+func NewCalendarHandler(service CalendarServiceInterface) *CalendarHandler {
+	return &CalendarHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+type businessHoursRequest struct {
+	// Weekday は 0 (日曜日) 〜 6 (土曜日)。日曜日の 0 と省略を区別するためポインタにする。
+	Weekday *int   `json:"weekday" validate:"required,min=0,max=6"`
+	Open    string `json:"open" validate:"required"`
+	Close   string `json:"close" validate:"required"`
+}
+
+type weeklyScheduleRequest struct {
+	TimeZone string                 `json:"timeZone" validate:"max=64"`
+	Hours    []businessHoursRequest `json:"hours" validate:"max=100,dive"`
+}
+
+type blackoutRequest struct {
+	ResourceID string `json:"resourceId"`
+	StartTime  string `json:"startTime" validate:"required"`
+	EndTime    string `json:"endTime" validate:"required"`
+	Reason     string `json:"reason" validate:"max=500"`
+}
+
+func (h *CalendarHandler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/calendar/hours", h.GetWeeklySchedule)
+	e.PUT("/api/calendar/hours", h.SetWeeklySchedule)
+	e.GET("/api/calendar/blackouts", h.ListBlackouts)
+	e.POST("/api/calendar/blackouts", h.CreateBlackout)
+	e.DELETE("/api/calendar/blackouts/:id", h.DeleteBlackout)
+	e.GET("/api/calendar/closed", h.GetClosedPeriods)
+}
+
+func (h *CalendarHandler) GetWeeklySchedule(c echo.Context) error {
+	schedule, err := h.service.GetWeeklySchedule()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get business hours"})
+	}
+
+	return c.JSON(http.StatusOK, schedule)
+}
+
+// SetWeeklySchedule は営業時間を置き換える。hours を空にすると終日営業に戻る。
+func (h *CalendarHandler) SetWeeklySchedule(c echo.Context) error {
+	req := new(weeklyScheduleRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	schedule := model.WeeklySchedule{TimeZone: req.TimeZone, Hours: make([]model.BusinessHours, 0, len(req.Hours))}
+	for _, hours := range req.Hours {
+		schedule.Hours = append(schedule.Hours, model.BusinessHours{
+			Weekday: time.Weekday(*hours.Weekday),
+			Open:    hours.Open,
+			Close:   hours.Close,
+		})
+	}
+
+	saved, err := h.service.SetWeeklySchedule(c.Request().Context(), schedule)
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can change business hours"})
+	}
+	if errors.Is(err, service.ErrInvalidBusinessHours) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update business hours"})
+	}
+
+	return c.JSON(http.StatusOK, saved)
+}
+
+func (h *CalendarHandler) ListBlackouts(c echo.Context) error {
+	params := service.ListBlackoutsParams{ResourceID: c.QueryParam("resourceId")}
+	if from := c.QueryParam("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from format"})
+		}
+		params.From = t
+	}
+	if to := c.QueryParam("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to format"})
+		}
+		params.To = t
+	}
+
+	blackouts, err := h.service.ListBlackouts(params)
+	if errors.Is(err, service.ErrInvalidWindow) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "from and to must be specified together and to must be after from"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get blackout periods"})
+	}
+
+	return c.JSON(http.StatusOK, blackouts)
+}
+
+func (h *CalendarHandler) CreateBlackout(c echo.Context) error {
+	req := new(blackoutRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid start time format"})
+	}
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid end time format"})
+	}
+
+	blackout, err := h.service.CreateBlackout(c.Request().Context(), service.CreateBlackoutParams{
+		ResourceID: req.ResourceID,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Reason:     req.Reason,
+	})
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusCreated, blackout)
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can change blackout periods"})
+	case errors.Is(err, service.ErrInvalidTimeRange):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "End time must be after start time"})
+	case errors.Is(err, service.ErrResourceNotFound):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Resource not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create blackout period"})
+	}
+}
+
+func (h *CalendarHandler) DeleteBlackout(c echo.Context) error {
+	err := h.service.DeleteBlackout(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can change blackout periods"})
+	}
+	if errors.Is(err, service.ErrBlackoutNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Blackout period not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete blackout period"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetClosedPeriods は営業時間外と休業期間を返す。フロントエンドのカレンダーで予約できない時間帯を表示するのに使う。
+func (h *CalendarHandler) GetClosedPeriods(c echo.Context) error {
+	params := service.ClosedPeriodsParams{ResourceID: c.QueryParam("resourceId")}
+
+	from, err := time.Parse(time.RFC3339, c.QueryParam("from"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from format"})
+	}
+	params.From = from
+
+	to, err := time.Parse(time.RFC3339, c.QueryParam("to"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to format"})
+	}
+	params.To = to
+
+	closed, err := h.service.GetClosedPeriods(params)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, closed)
+	case errors.Is(err, service.ErrInvalidWindow):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "to must be after from"})
+	case errors.Is(err, service.ErrWindowTooLarge):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Time window must not exceed %d days", int(service.MaxListWindow.Hours()/24))})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get closed periods"})
+	}
+}
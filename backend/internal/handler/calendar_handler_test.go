@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+type mockCalendarService struct {
+	getWeeklyScheduleFunc func() (*model.WeeklySchedule, error)
+	setWeeklyScheduleFunc func(schedule model.WeeklySchedule) (*model.WeeklySchedule, error)
+	createBlackoutFunc    func(params service.CreateBlackoutParams) (*model.Blackout, error)
+	listBlackoutsFunc     func(params service.ListBlackoutsParams) ([]*model.Blackout, error)
+	deleteBlackoutFunc    func(id string) error
+	getClosedPeriodsFunc  func(params service.ClosedPeriodsParams) ([]model.ClosedPeriod, error)
+}
+
+func (m *mockCalendarService) GetWeeklySchedule() (*model.WeeklySchedule, error) {
+	return m.getWeeklyScheduleFunc()
+}
+
+func (m *mockCalendarService) SetWeeklySchedule(ctx context.Context, schedule model.WeeklySchedule) (*model.WeeklySchedule, error) {
+	return m.setWeeklyScheduleFunc(schedule)
+}
+
+func (m *mockCalendarService) CreateBlackout(ctx context.Context, params service.CreateBlackoutParams) (*model.Blackout, error) {
+	return m.createBlackoutFunc(params)
+}
+
+func (m *mockCalendarService) ListBlackouts(params service.ListBlackoutsParams) ([]*model.Blackout, error) {
+	return m.listBlackoutsFunc(params)
+}
+
+func (m *mockCalendarService) DeleteBlackout(ctx context.Context, id string) error {
+	return m.deleteBlackoutFunc(id)
+}
+
+func (m *mockCalendarService) GetClosedPeriods(params service.ClosedPeriodsParams) ([]model.ClosedPeriod, error) {
+	return m.getClosedPeriodsFunc(params)
+}
+
+func TestSetWeeklySchedule(t *testing.T) {
+	// 準備
+	e := echo.New()
+	var received model.WeeklySchedule
+	mockSvc := &mockCalendarService{
+		setWeeklyScheduleFunc: func(schedule model.WeeklySchedule) (*model.WeeklySchedule, error) {
+			received = schedule
+			return &schedule, nil
+		},
+	}
+	h := NewCalendarHandler(mockSvc)
+
+	requestBody := `{"timeZone": "Asia/Tokyo", "hours": [{"weekday": 0, "open": "10:00", "close": "16:00"}, {"weekday": 1, "open": "09:00", "close": "18:00"}]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/calendar/hours", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// 実行
+	if err := h.SetWeeklySchedule(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if received.TimeZone != "Asia/Tokyo" || len(received.Hours) != 2 || received.Hours[0].Weekday != time.Sunday || received.Hours[1].Close != "18:00" {
+		t.Errorf("Unexpected schedule: %+v", received)
+	}
+}
+
+func TestSetWeeklySchedule_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "曜日の省略", body: `{"hours": [{"open": "09:00", "close": "18:00"}]}`, wantStatus: http.StatusBadRequest},
+		{name: "曜日が範囲外", body: `{"hours": [{"weekday": 7, "open": "09:00", "close": "18:00"}]}`, wantStatus: http.StatusBadRequest},
+		{name: "サービスの検証エラー", body: `{"hours": [{"weekday": 1, "open": "18:00", "close": "09:00"}]}`, serviceErr: service.ErrInvalidBusinessHours, wantStatus: http.StatusBadRequest},
+		{name: "サービスのエラー", body: `{"hours": []}`, serviceErr: errors.New("database error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockCalendarService{
+				setWeeklyScheduleFunc: func(schedule model.WeeklySchedule) (*model.WeeklySchedule, error) {
+					return nil, tt.serviceErr
+				},
+			}
+			h := NewCalendarHandler(mockSvc)
+			req := httptest.NewRequest(http.MethodPut, "/api/calendar/hours", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// 実行
+			if err := h.SetWeeklySchedule(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestCreateBlackout(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "作成", body: `{"startTime": "2024-05-03T00:00:00+09:00", "endTime": "2024-05-06T00:00:00+09:00", "reason": "GW"}`, wantStatus: http.StatusCreated},
+		{name: "時刻の形式が不正", body: `{"startTime": "2024-05-03", "endTime": "2024-05-06T00:00:00+09:00"}`, wantStatus: http.StatusBadRequest},
+		{name: "理由が長すぎる", body: `{"startTime": "2024-05-03T00:00:00+09:00", "endTime": "2024-05-06T00:00:00+09:00", "reason": "` + strings.Repeat("a", 501) + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "終了が開始より前", body: `{"startTime": "2024-05-06T00:00:00+09:00", "endTime": "2024-05-03T00:00:00+09:00"}`, serviceErr: service.ErrInvalidTimeRange, wantStatus: http.StatusBadRequest},
+		{name: "リソースが存在しない", body: `{"resourceId": "unknown", "startTime": "2024-05-03T00:00:00+09:00", "endTime": "2024-05-06T00:00:00+09:00"}`, serviceErr: service.ErrResourceNotFound, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockCalendarService{
+				createBlackoutFunc: func(params service.CreateBlackoutParams) (*model.Blackout, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					return model.NewBlackout(params.ResourceID, params.StartTime, params.EndTime, params.Reason), nil
+				},
+			}
+			h := NewCalendarHandler(mockSvc)
+			req := httptest.NewRequest(http.MethodPost, "/api/calendar/blackouts", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// 実行
+			if err := h.CreateBlackout(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestDeleteBlackout(t *testing.T) {
+	tests := []struct {
+		name       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "削除", wantStatus: http.StatusNoContent},
+		{name: "存在しない", serviceErr: service.ErrBlackoutNotFound, wantStatus: http.StatusNotFound},
+		{name: "サービスのエラー", serviceErr: errors.New("database error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockCalendarService{
+				deleteBlackoutFunc: func(id string) error { return tt.serviceErr },
+			}
+			h := NewCalendarHandler(mockSvc)
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/api/calendar/blackouts/:id")
+			c.SetParamNames("id")
+			c.SetParamValues("blackout-1")
+
+			// 実行
+			if err := h.DeleteBlackout(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestGetClosedPeriods(t *testing.T) {
+	// 準備
+	e := echo.New()
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	var received service.ClosedPeriodsParams
+	mockSvc := &mockCalendarService{
+		getClosedPeriodsFunc: func(params service.ClosedPeriodsParams) ([]model.ClosedPeriod, error) {
+			received = params
+			return []model.ClosedPeriod{{StartTime: from, EndTime: from.Add(9 * time.Hour), Reason: model.ClosedOutsideBusinessHours}}, nil
+		},
+	}
+	h := NewCalendarHandler(mockSvc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/calendar/closed?resourceId=room-a&from=2024-04-01T00:00:00Z&to=2024-04-08T00:00:00Z", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// 実行
+	if err := h.GetClosedPeriods(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if received.ResourceID != "room-a" || !received.From.Equal(from) || !received.To.Equal(from.AddDate(0, 0, 7)) {
+		t.Errorf("Unexpected params: %+v", received)
+	}
+	var closed []model.ClosedPeriod
+	if err := json.Unmarshal(rec.Body.Bytes(), &closed); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(closed) != 1 || closed[0].Reason != model.ClosedOutsideBusinessHours {
+		t.Errorf("Unexpected closed periods: %v", closed)
+	}
+}
+
+func TestGetClosedPeriods_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "from の省略", query: "to=2024-04-08T00:00:00Z", wantStatus: http.StatusBadRequest},
+		{name: "to の形式が不正", query: "from=2024-04-01T00:00:00Z&to=2024-04-08", wantStatus: http.StatusBadRequest},
+		{name: "期間が逆", query: "from=2024-04-08T00:00:00Z&to=2024-04-01T00:00:00Z", serviceErr: service.ErrInvalidWindow, wantStatus: http.StatusBadRequest},
+		{name: "期間が長すぎる", query: "from=2024-01-01T00:00:00Z&to=2026-01-01T00:00:00Z", serviceErr: service.ErrWindowTooLarge, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockCalendarService{
+				getClosedPeriodsFunc: func(params service.ClosedPeriodsParams) ([]model.ClosedPeriod, error) {
+					return nil, tt.serviceErr
+				},
+			}
+			h := NewCalendarHandler(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/api/calendar/closed?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// 実行
+			if err := h.GetClosedPeriods(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
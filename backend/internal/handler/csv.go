@@ -0,0 +1,421 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+const (
+	// csvContentType は書き出す CSV の Content-Type
+	csvContentType = "text/csv; charset=utf-8"
+	// csvBOM は Excel が UTF-8 として開くように CSV の先頭に付ける BOM
+	csvBOM = "\ufeff"
+	// csvTimeFormat は CSV の日時の形式
+	csvTimeFormat = "2006-01-02 15:04"
+	// csvFlushRows は書き出し中にレスポンスをフラッシュする行数の間隔
+	csvFlushRows = 500
+	// maxCSVImportBytes は取り込む CSV の最大バイト数
+	maxCSVImportBytes = 10 << 20
+	// maxCSVImportErrors はレスポンスに含める行のエラーの最大件数
+	maxCSVImportErrors = 1000
+	// csvAttendeeSeparator は attendees の列で参加者を区切る文字
+	csvAttendeeSeparator = ";"
+)
+
+// csvColumn は CSV に書き出せる列。key はヘッダー行と columns パラメータで使う。
+// text の列は値が数式として解釈されないように、先頭が =、+、-、@ などの値に ' を付ける。
+type csvColumn struct {
+	key   string
+	text  bool
+	value func(row csvRow) string
+}
+
+// csvRow は書き出す1行の予約と、予約のリソースの名前、日時を書き出すタイムゾーン
+type csvRow struct {
+	*model.Reservation
+	resourceName string
+	loc          *time.Location
+}
+
+// time は日時を csvTimeFormat で返す。ゼロ値の場合は空にする。
+func (row csvRow) time(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(row.loc).Format(csvTimeFormat)
+}
+
+var csvColumns = []csvColumn{
+	{key: "id", value: func(row csvRow) string { return row.ID }},
+	{key: "uid", value: func(row csvRow) string { return row.UID() }},
+	{key: "resourceId", value: func(row csvRow) string { return row.ResourceID }},
+	{key: "resourceName", text: true, value: func(row csvRow) string { return row.resourceName }},
+	{key: "title", text: true, value: func(row csvRow) string { return row.Title }},
+	{key: "description", text: true, value: func(row csvRow) string { return row.Description }},
+	{key: "attendees", text: true, value: func(row csvRow) string { return strings.Join(row.Attendees, csvAttendeeSeparator) }},
+	{key: "startTime", value: func(row csvRow) string { return row.time(row.StartTime) }},
+	{key: "endTime", value: func(row csvRow) string { return row.time(row.EndTime) }},
+	{key: "status", value: func(row csvRow) string { return string(row.Status) }},
+	{key: "userId", value: func(row csvRow) string { return row.UserID }},
+	{key: "seriesId", value: func(row csvRow) string { return row.SeriesID }},
+	{key: "createdAt", value: func(row csvRow) string { return row.time(row.CreatedAt) }},
+	{key: "updatedAt", value: func(row csvRow) string { return row.time(row.UpdatedAt) }},
+	{key: "cancelledAt", value: func(row csvRow) string {
+		if row.CancelledAt == nil {
+			return ""
+		}
+		return row.time(*row.CancelledAt)
+	}},
+	{key: "cancellationReason", text: true, value: func(row csvRow) string { return row.CancellationReason }},
+	{key: "version", value: func(row csvRow) string { return strconv.FormatInt(row.Version, 10) }},
+}
+
+// defaultCSVColumns は columns を指定しない場合に書き出す列
+var defaultCSVColumns = []string{"id", "resourceName", "title", "startTime", "endTime", "status", "attendees"}
+
+// csvExportColumns はクエリパラメータ columns（カンマ区切りの列のキー）を解析する。返すエラーのメッセージはそのままレスポンスに使う。
+func csvExportColumns(param string) ([]csvColumn, error) {
+	keys := defaultCSVColumns
+	if param != "" {
+		keys = strings.Split(param, ",")
+	}
+	columns := make([]csvColumn, 0, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		i := csvColumnIndex(key)
+		if i < 0 {
+			return nil, fmt.Errorf("Unknown column %q", key)
+		}
+		columns = append(columns, csvColumns[i])
+	}
+	return columns, nil
+}
+
+func csvColumnIndex(key string) int {
+	for i, column := range csvColumns {
+		if column.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// escapeCSVFormula は表計算ソフトが数式として解釈する文字で始まる値の先頭に ' を付ける
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCSVFormula は escapeCSVFormula で付けた ' を取り除く
+func unescapeCSVFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// ExportCSV は条件に合う予約を CSV として書き出す。予約は全て読み込まずに1行ずつ書き出す。
+// Excel で文字化けしないように UTF-8 の BOM を付け、日時は timeZone（省略した場合は service.DefaultTimeZone）の csvTimeFormat で書き出す。
+func (h *ReservationHandler) ExportCSV(c echo.Context) error {
+	params, err := exportParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	columns, err := csvExportColumns(c.QueryParam("columns"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	loc, err := timeZoneParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// レスポンスはサービスが権限と期間を確認して最初の予約を渡すまで書き始めない。エラーは JSON で返せる。
+	w := csv.NewWriter(c.Response())
+	w.UseCRLF = true
+	started := false
+	start := func() error {
+		c.Response().Header().Set(echo.HeaderContentType, csvContentType)
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="reservations.csv"`)
+		c.Response().WriteHeader(http.StatusOK)
+		started = true
+		if _, err := io.WriteString(c.Response(), csvBOM); err != nil {
+			return err
+		}
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column.key
+		}
+		return w.Write(header)
+	}
+
+	record := make([]string, len(columns))
+	rows := 0
+	err = h.service.StreamReservations(c.Request().Context(), params, func(r *model.Reservation, resourceName string) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		row := csvRow{Reservation: r, resourceName: resourceName, loc: loc}
+		for i, column := range columns {
+			record[i] = column.value(row)
+			if column.text {
+				record[i] = escapeCSVFormula(record[i])
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+		if rows++; rows%csvFlushRows == 0 {
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return err
+			}
+			c.Response().Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if started {
+			// 書き始めた後はステータスを変えられないため、途中で打ち切る
+			return err
+		}
+		return exportError(c, err)
+	}
+	if !started {
+		if err := start(); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvImportResponse は CSV の取り込みの結果。created、skipped、rejected はそれぞれの結果になった行の件数。
+// errors は拒否した行の理由で、最大 maxCSVImportErrors 件。
+type csvImportResponse struct {
+	DryRun          bool             `json:"dryRun"`
+	Created         int              `json:"created"`
+	Skipped         int              `json:"skipped"`
+	Rejected        int              `json:"rejected"`
+	Errors          []csvImportError `json:"errors"`
+	ErrorsTruncated bool             `json:"errorsTruncated,omitempty"`
+}
+
+// csvImportError は拒否した行の理由。row はファイルの行番号（ヘッダー行が 1）、column は値が不正な列のキー。
+type csvImportError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (r *csvImportResponse) reject(row int, column, message string) {
+	r.Rejected++
+	if len(r.Errors) == maxCSVImportErrors {
+		r.ErrorsTruncated = true
+		return
+	}
+	r.Errors = append(r.Errors, csvImportError{Row: row, Column: column, Message: message})
+}
+
+// csvImportColumns は CSV の列のキーごとの列の位置
+type csvImportColumns map[string]int
+
+// csvImportHeader はヘッダー行を解析する。取り込みに使わない列は無視する。返すエラーのメッセージはそのままレスポンスに使う。
+func csvImportHeader(header []string, resourceID string) (csvImportColumns, error) {
+	columns := make(csvImportColumns, len(header))
+	for i, key := range header {
+		if !utf8.ValidString(key) {
+			return nil, errors.New("CSV must be encoded in UTF-8")
+		}
+		key = strings.TrimSpace(key)
+		if _, ok := columns[key]; ok {
+			return nil, fmt.Errorf("Duplicate column %q", key)
+		}
+		columns[key] = i
+	}
+	for _, key := range []string{"startTime", "endTime"} {
+		if _, ok := columns[key]; !ok {
+			return nil, fmt.Errorf("Missing column %q", key)
+		}
+	}
+	_, hasID := columns["resourceId"]
+	_, hasName := columns["resourceName"]
+	if resourceID == "" && !hasID && !hasName {
+		return nil, errors.New(`Missing column "resourceId" or "resourceName"`)
+	}
+	return columns, nil
+}
+
+// csvImportTimeFormats は取り込む日時の形式。Excel が保存し直した日付（例: 2030/1/7 10:00）も受け付ける。
+var csvImportTimeFormats = []string{
+	"2006-1-2 15:04", "2006-1-2 15:04:05", "2006/1/2 15:04", "2006/1/2 15:04:05", "2006-01-02T15:04:05",
+}
+
+func parseCSVTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range csvImportTimeFormats {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// entry は1行を取り込む予約にする。値が不正な場合は列のキーとともにエラーを返す。
+func (columns csvImportColumns) entry(record []string, loc *time.Location) (service.ImportEntry, string, error) {
+	get := func(key string) string {
+		if i, ok := columns[key]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	for _, v := range record {
+		if !utf8.ValidString(v) {
+			return service.ImportEntry{}, "", errors.New("row is not valid UTF-8")
+		}
+	}
+
+	entry := service.ImportEntry{
+		UID:        strings.TrimSpace(get("uid")),
+		ResourceID: strings.TrimSpace(get("resourceId")),
+		Location:   unescapeCSVFormula(get("resourceName")),
+		Details: service.ReservationDetails{
+			Title:       unescapeCSVFormula(get("title")),
+			Description: unescapeCSVFormula(get("description")),
+		},
+		Status: model.ReservationStatus(strings.TrimSpace(get("status"))),
+	}
+	// uid の列がない場合は書き出した予約の ID から UID を決め、同じファイルを取り込み直しても重複しないようにする
+	if id := strings.TrimSpace(get("id")); entry.UID == "" && id != "" {
+		entry.UID = id + "@" + model.ICalUIDDomain
+	}
+	if entry.Status != "" && !entry.Status.Valid() {
+		return entry, "status", fmt.Errorf("invalid status %q", entry.Status)
+	}
+	for _, a := range strings.Split(unescapeCSVFormula(get("attendees")), csvAttendeeSeparator) {
+		if a = strings.TrimSpace(a); a != "" {
+			entry.Details.Attendees = append(entry.Details.Attendees, a)
+		}
+	}
+	// 目的と参加者は保存するときと同じ検証を行い、不正な列を報告する
+	var detailsErr *service.InvalidDetailsError
+	if err := entry.Details.Validate(); errors.As(err, &detailsErr) {
+		return entry, detailsErr.Field, err
+	}
+
+	var err error
+	if entry.StartTime, err = parseCSVTime(get("startTime"), loc); err != nil {
+		return entry, "startTime", err
+	}
+	if entry.EndTime, err = parseCSVTime(get("endTime"), loc); err != nil {
+		return entry, "endTime", err
+	}
+	return entry, "", nil
+}
+
+// ImportCSV はリクエストの本文の CSV の各行を予約として取り込み、拒否した行と理由を返す。CSV は全て読み込まずに1行ずつ取り込む。
+// 1行目は列のキー（書き出しと同じもの）のヘッダー行で、startTime、endTime と、resourceId クエリパラメータを指定しない場合は
+// resourceId か resourceName の列が必要。タイムゾーンのない日時は timeZone（省略した場合は service.DefaultTimeZone）で解釈する。
+// dryRun=true の場合は予約を保存せずに、取り込んだ場合の結果を返す。
+func (h *ReservationHandler) ImportCSV(c echo.Context) error {
+	params, loc, err := importParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	tooLargeMessage := fmt.Sprintf("CSV must not exceed %d bytes", maxCSVImportBytes)
+	if c.Request().ContentLength > maxCSVImportBytes {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": tooLargeMessage})
+	}
+
+	body := bufio.NewReader(http.MaxBytesReader(c.Response(), c.Request().Body, maxCSVImportBytes))
+	if bom, err := body.Peek(len(csvBOM)); err == nil && string(bom) == csvBOM {
+		body.Discard(len(csvBOM))
+	}
+	r := csv.NewReader(body)
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+
+	var tooLarge *http.MaxBytesError
+	header, err := r.Read()
+	switch {
+	case errors.As(err, &tooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": tooLargeMessage})
+	case errors.Is(err, io.EOF):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "CSV must have a header row"})
+	case err != nil:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid CSV: " + err.Error()})
+	}
+	columns, err := csvImportHeader(header, params.ResourceID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	res := csvImportResponse{DryRun: params.DryRun, Errors: []csvImportError{}}
+	// row は最後に next が返した行の行番号。結果は next が返した順に fn に渡される。
+	row := 0
+	next := func() (service.ImportEntry, error) {
+		for {
+			record, err := r.Read()
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				// 列の解析に失敗した行より後は正しく読めないため、取り込みを打ち切る
+				res.reject(parseErr.StartLine, "", parseErr.Err.Error()+"; the rest of the CSV was not imported")
+				return service.ImportEntry{}, io.EOF
+			}
+			if err != nil {
+				return service.ImportEntry{}, err
+			}
+			line, _ := r.FieldPos(0)
+			entry, column, err := columns.entry(record, loc)
+			if err != nil {
+				res.reject(line, column, err.Error())
+				continue
+			}
+			row = line
+			return entry, nil
+		}
+	}
+	fn := func(result service.ImportResult) {
+		switch result.Outcome {
+		case service.ImportCreated:
+			res.Created++
+		case service.ImportSkipped:
+			res.Skipped++
+		default:
+			res.reject(row, "", result.Reason)
+		}
+	}
+
+	err = h.service.ImportEntries(c.Request().Context(), service.ImportEntriesParams{ResourceID: params.ResourceID, DryRun: params.DryRun}, next, fn)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, res)
+	case errors.As(err, &tooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": tooLargeMessage})
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "You do not have permission to create reservations"})
+	case errors.Is(err, service.ErrResourceNotFound):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Resource not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to import reservations"})
+	}
+}
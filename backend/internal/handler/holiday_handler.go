@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/holiday"
+)
+
+// HolidayHandler は日本の祝日を返す。祝日はプロセス内で計算するため、サービスやリポジトリを持たない。
+type HolidayHandler struct{}
+
+func NewHolidayHandler() *HolidayHandler {
+	return &HolidayHandler{}
+}
+
+// holidayResponse の Date は "2006-01-02" 形式の日付
+type holidayResponse struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+func (h *HolidayHandler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/holidays", h.GetHolidays)
+}
+
+// GetHolidays は year 年（省略時は今年）の祝日・休日を日付順に返す
+func (h *HolidayHandler) GetHolidays(c echo.Context) error {
+	year := time.Now().In(holiday.JST).Year()
+	if value := c.QueryParam("year"); value != "" {
+		y, err := strconv.Atoi(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid year format"})
+		}
+		year = y
+	}
+
+	holidays, err := holiday.Year(year)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("year must be between %d and %d", holiday.MinYear, holiday.MaxYear)})
+	}
+
+	response := make([]holidayResponse, 0, len(holidays))
+	for _, d := range holidays {
+		response = append(response, holidayResponse{Date: d.Date.Format("2006-01-02"), Name: d.Name})
+	}
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestGetHolidays(t *testing.T) {
+	// 準備
+	e := echo.New()
+	h := NewHolidayHandler()
+	req := httptest.NewRequest(http.MethodGet, "/api/holidays?year=2024", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// 実行
+	if err := h.GetHolidays(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	var holidays []holidayResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &holidays); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(holidays) != 21 {
+		t.Errorf("Expected 21 holidays in 2024, got %d", len(holidays))
+	}
+	if holidays[0] != (holidayResponse{Date: "2024-01-01", Name: "元日"}) {
+		t.Errorf("Unexpected first holiday: %+v", holidays[0])
+	}
+}
+
+func TestGetHolidays_InvalidYear(t *testing.T) {
+	for _, query := range []string{"year=abc", "year=1800", "year=3000"} {
+		t.Run(query, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			h := NewHolidayHandler()
+			req := httptest.NewRequest(http.MethodGet, "/api/holidays?"+query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// 実行
+			if err := h.GetHolidays(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+		})
+	}
+}
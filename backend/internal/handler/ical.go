@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/ical"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+const (
+	// icalProdID は書き出す iCalendar の PRODID
+	icalProdID = "-//yoyaku//reservations//JA"
+	// icalCalendarName はカレンダーアプリに表示するカレンダーの名前
+	icalCalendarName = "予約"
+	// maxImportBytes は取り込む iCalendar の最大バイト数
+	maxImportBytes = 5 << 20
+)
+
+// exportParams は書き出しのクエリパラメータ（resourceId、from、to、status）を解析する。
+// 返すエラーのメッセージはそのままレスポンスに使う。
+func exportParams(c echo.Context) (service.ExportReservationsParams, error) {
+	params := service.ExportReservationsParams{
+		ResourceID: c.QueryParam("resourceId"),
+	}
+	if from := c.QueryParam("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return params, errors.New("Invalid from format")
+		}
+		params.From = t
+	}
+	if to := c.QueryParam("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return params, errors.New("Invalid to format")
+		}
+		params.To = t
+	}
+	if status := c.QueryParam("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			st := model.ReservationStatus(strings.TrimSpace(s))
+			if !st.Valid() {
+				return params, errors.New("Invalid status")
+			}
+			params.Statuses = append(params.Statuses, st)
+		}
+	}
+	return params, nil
+}
+
+// exportError はサービスが返した書き出しのエラーをレスポンスに変換する
+func exportError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "You do not have permission to view reservations"})
+	case errors.Is(err, service.ErrInvalidWindow):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Both from and to are required and to must be after from"})
+	case errors.Is(err, service.ErrWindowTooLarge):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Time window must not exceed %d days", int(service.MaxListWindow.Hours()/24))})
+	case errors.Is(err, service.ErrExportTooLarge):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("More than %d reservations match; narrow the time window", service.MaxExportReservations)})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to export reservations"})
+	}
+}
+
+// writeICalendar は予約を iCalendar としてレスポンスに書き出す。リソースで絞り込んだ場合はカレンダーの名前にリソースの名前を含める。
+func writeICalendar(c echo.Context, export *service.ReservationExport, resourceID string) error {
+	cal := &ical.Calendar{
+		ProdID: icalProdID,
+		Name:   icalCalendarName,
+		Events: make([]ical.Event, 0, len(export.Reservations)),
+	}
+	if name := export.ResourceNames[resourceID]; name != "" {
+		cal.Name = icalCalendarName + " - " + name
+	}
+	for _, r := range export.Reservations {
+		cal.Events = append(cal.Events, reservationEvent(r, export.ResourceNames[r.ResourceID]))
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, ical.ContentType)
+	c.Response().WriteHeader(http.StatusOK)
+	return ical.Encode(c.Response(), cal)
+}
+
+// reservationEvent は予約を VEVENT に変換する。件名のない予約はリソースの名前を件名にする。
+func reservationEvent(r *model.Reservation, resourceName string) ical.Event {
+	summary := r.Title
+	if summary == "" {
+		summary = resourceName
+	}
+	if summary == "" {
+		summary = icalCalendarName
+	}
+	return ical.Event{
+		UID:         r.UID(),
+		Stamp:       r.UpdatedAt,
+		Start:       r.StartTime,
+		End:         r.EndTime,
+		Created:     r.CreatedAt,
+		Modified:    r.UpdatedAt,
+		Sequence:    r.Version - 1,
+		Summary:     summary,
+		Description: r.Description,
+		Location:    resourceName,
+		Status:      eventStatus(r.Status),
+		Attendees:   r.Attendees,
+	}
+}
+
+// eventStatus は予約の状態を VEVENT の STATUS に変換する
+func eventStatus(status model.ReservationStatus) ical.EventStatus {
+	switch status {
+	case model.StatusPending:
+		return ical.StatusTentative
+	case model.StatusCancelled:
+		return ical.StatusCancelled
+	default:
+		return ical.StatusConfirmed
+	}
+}
+
+// importParams は取り込みのクエリパラメータ（resourceId、dryRun、timeZone）を解析し、
+// TZID のない日時と終日のイベントを解釈するタイムゾーンとともに返す。返すエラーのメッセージはそのままレスポンスに使う。
+func importParams(c echo.Context) (service.ImportReservationsParams, *time.Location, error) {
+	params := service.ImportReservationsParams{
+		ResourceID: c.QueryParam("resourceId"),
+	}
+	if dryRun := c.QueryParam("dryRun"); dryRun != "" {
+		b, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return params, nil, errors.New("Invalid dryRun")
+		}
+		params.DryRun = b
+	}
+	loc, err := timeZoneParam(c)
+	return params, loc, err
+}
+
+// timeZoneParam はクエリパラメータ timeZone のタイムゾーンを返す。省略した場合は service.DefaultTimeZone。
+func timeZoneParam(c echo.Context) (*time.Location, error) {
+	timeZone := c.QueryParam("timeZone")
+	if timeZone == "" {
+		timeZone = service.DefaultTimeZone
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil || timeZone == "Local" {
+		return nil, errors.New("Invalid timeZone")
+	}
+	return loc, nil
+}
+
+// importResponse は取り込みの結果。created、skipped、rejected はそれぞれの結果になったイベントの件数。
+type importResponse struct {
+	DryRun   bool           `json:"dryRun"`
+	Created  int            `json:"created"`
+	Skipped  int            `json:"skipped"`
+	Rejected int            `json:"rejected"`
+	Results  []importResult `json:"results"`
+}
+
+// importResult はイベントごとの取り込みの結果
+type importResult struct {
+	UID           string     `json:"uid"`
+	Summary       string     `json:"summary,omitempty"`
+	Outcome       string     `json:"outcome"`
+	Reason        string     `json:"reason,omitempty"`
+	ReservationID string     `json:"reservationId,omitempty"`
+	ResourceID    string     `json:"resourceId,omitempty"`
+	StartTime     *time.Time `json:"startTime,omitempty"`
+	EndTime       *time.Time `json:"endTime,omitempty"`
+}
+
+func newImportResponse(report *service.ImportReport) importResponse {
+	results := make([]importResult, 0, len(report.Results))
+	for _, r := range report.Results {
+		result := importResult{
+			UID:           r.UID,
+			Summary:       r.Summary,
+			Outcome:       string(r.Outcome),
+			Reason:        r.Reason,
+			ReservationID: r.ReservationID,
+			ResourceID:    r.ResourceID,
+		}
+		if !r.StartTime.IsZero() {
+			result.StartTime, result.EndTime = &r.StartTime, &r.EndTime
+		}
+		results = append(results, result)
+	}
+	return importResponse{
+		DryRun:   report.DryRun,
+		Created:  report.Count(service.ImportCreated),
+		Skipped:  report.Count(service.ImportSkipped),
+		Rejected: report.Count(service.ImportRejected),
+		Results:  results,
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+// IdempotencyKeyHeader は作成のリクエストを安全に再送するためのキーを指定するヘッダー
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotentReplayedHeader は記録したレスポンスを再送したことを表すレスポンスヘッダー
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength はキーの最大文字数
+const maxIdempotencyKeyLength = 255
+
+// idempotencyLockTimeout は処理中の記録の有効期限。処理中にサーバーが停止しても、この時間が過ぎればキーを再び使える。
+const idempotencyLockTimeout = time.Minute
+
+// replayedHeaders は記録して再送時にも返すレスポンスヘッダー
+var replayedHeaders = []string{echo.HeaderContentType, echo.HeaderLocation, "ETag"}
+
+// IdempotencyStore は Idempotency-Key の記録を保存する（repository.IdempotencyRepository）
+type IdempotencyStore interface {
+	Reserve(record *model.IdempotencyRecord) (*model.IdempotencyRecord, error)
+	Complete(record *model.IdempotencyRecord) error
+	Delete(key string) error
+}
+
+// Idempotency は IdempotencyKeyHeader を付けたリクエストへのレスポンスを ttl の間記録し、
+// 同じキーで再送されたリクエストには処理せずに記録したレスポンスを返すミドルウェアを返す。
+// キーはユーザーごとに区別する。同じキーで内容の異なるリクエストには 422 を、最初のリクエストの処理中には 409 を返す。
+// 500 番台のレスポンスは記録せず、同じキーで再試行できるようにする。ヘッダーのないリクエストはそのまま通す。
+func Idempotency(store IdempotencyStore, ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Idempotency-Key must be at most 255 characters"})
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now()
+			record := &model.IdempotencyRecord{
+				Key:         idempotencyScope(c) + ":" + key,
+				Fingerprint: requestFingerprint(c.Request(), body),
+				Header:      map[string]string{},
+				Body:        []byte{},
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(idempotencyLockTimeout),
+			}
+			existing, err := store.Reserve(record)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check Idempotency-Key"})
+			}
+			if existing != nil {
+				return replayResponse(c, existing, record.Fingerprint)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			err = next(c)
+			c.Response().Writer = recorder.ResponseWriter
+
+			status := c.Response().Status
+			if err != nil || !c.Response().Committed || status >= http.StatusInternalServerError {
+				if deleteErr := store.Delete(record.Key); deleteErr != nil {
+					c.Logger().Errorf("failed to release idempotency key: %v", deleteErr)
+				}
+				return err
+			}
+
+			record.StatusCode = status
+			for _, name := range replayedHeaders {
+				if value := c.Response().Header().Get(name); value != "" {
+					record.Header[name] = value
+				}
+			}
+			record.Body = recorder.body.Bytes()
+			record.ExpiresAt = time.Now().Add(ttl)
+			if err := store.Complete(record); err != nil {
+				c.Logger().Errorf("failed to store idempotent response: %v", err)
+			}
+			return nil
+		}
+	}
+}
+
+// idempotencyScope はキーを区別する単位としてリクエストを送ったユーザーのIDを返す。匿名のリクエストでは空文字列を返す。
+func idempotencyScope(c echo.Context) string {
+	principal, _ := auth.PrincipalFrom(c.Request().Context())
+	return principal.UserID
+}
+
+// requestFingerprint はリクエストのメソッド・パス・本文から求めた SHA-256 ハッシュを返す
+func requestFingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayResponse は記録したレスポンスを返す。内容の異なるリクエストや処理中のリクエストにはエラーを返す。
+func replayResponse(c echo.Context, record *model.IdempotencyRecord, fingerprint string) error {
+	if record.Fingerprint != fingerprint {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Idempotency-Key has already been used with a different request"})
+	}
+	if !record.Completed() {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "A request with the same Idempotency-Key is still being processed"})
+	}
+	for name, value := range record.Header {
+		c.Response().Header().Set(name, value)
+	}
+	c.Response().Header().Set(idempotentReplayedHeader, "true")
+	c.Response().WriteHeader(record.StatusCode)
+	_, err := c.Response().Write(record.Body)
+	return err
+}
+
+// responseRecorder はクライアントに書き込んだレスポンスの本文を記録する
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
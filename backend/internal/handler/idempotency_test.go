@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+func TestCreateReservation_Idempotency(t *testing.T) {
+	const body = `{"resourceId": "room-a", "startTime": "2030-04-01T10:00:00Z", "endTime": "2030-04-01T11:00:00Z"}`
+	const otherBody = `{"resourceId": "room-a", "startTime": "2030-04-01T12:00:00Z", "endTime": "2030-04-01T13:00:00Z"}`
+
+	type request struct {
+		key    string
+		userID string
+		body   string
+	}
+	tests := []struct {
+		name string
+		// failures 回目までの作成は失敗させる
+		failures   int
+		requests   []request
+		wantStatus []int
+		wantCalls  int
+		// wantSameBody が true の場合は最後のレスポンスが最初のレスポンスと同じ本文であることを確認する
+		wantSameBody bool
+	}{
+		{
+			name:         "同じキーの再送は記録したレスポンスを返す",
+			requests:     []request{{key: "k1", body: body}, {key: "k1", body: body}},
+			wantStatus:   []int{http.StatusCreated, http.StatusCreated},
+			wantCalls:    1,
+			wantSameBody: true,
+		},
+		{
+			name:       "同じキーで内容が異なる",
+			requests:   []request{{key: "k1", body: body}, {key: "k1", body: otherBody}},
+			wantStatus: []int{http.StatusCreated, http.StatusUnprocessableEntity},
+			wantCalls:  1,
+		},
+		{
+			name:       "キーなしは毎回処理する",
+			requests:   []request{{body: body}, {body: body}},
+			wantStatus: []int{http.StatusCreated, http.StatusCreated},
+			wantCalls:  2,
+		},
+		{
+			name:       "異なるキー",
+			requests:   []request{{key: "k1", body: body}, {key: "k2", body: body}},
+			wantStatus: []int{http.StatusCreated, http.StatusCreated},
+			wantCalls:  2,
+		},
+		{
+			name:       "キーはユーザーごとに区別する",
+			requests:   []request{{key: "k1", userID: "user-1", body: body}, {key: "k1", userID: "user-2", body: body}},
+			wantStatus: []int{http.StatusCreated, http.StatusCreated},
+			wantCalls:  2,
+		},
+		{
+			name:       "サーバーエラーは記録せず再試行できる",
+			failures:   1,
+			requests:   []request{{key: "k1", body: body}, {key: "k1", body: body}},
+			wantStatus: []int{http.StatusInternalServerError, http.StatusCreated},
+			wantCalls:  2,
+		},
+		{
+			name:       "長すぎるキー",
+			requests:   []request{{key: strings.Repeat("k", maxIdempotencyKeyLength+1), body: body}},
+			wantStatus: []int{http.StatusBadRequest},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			var calls int
+			mockSvc := &mockReservationService{
+				createReservationFunc: func(params service.CreateReservationParams) (*model.Reservation, error) {
+					calls++
+					if calls <= tt.failures {
+						return nil, errors.New("database error")
+					}
+					return model.NewReservation(params.ResourceID, params.StartTime, params.EndTime), nil
+				},
+			}
+			h := NewReservationHandler(mockSvc)
+			h.SetIdempotency(repository.NewInMemoryIdempotencyRepository(), time.Hour)
+			h.RegisterRoutes(e)
+
+			var first *httptest.ResponseRecorder
+			var last *httptest.ResponseRecorder
+			for i, r := range tt.requests {
+				req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(r.body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				if r.key != "" {
+					req.Header.Set(IdempotencyKeyHeader, r.key)
+				}
+				if r.userID != "" {
+					req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{UserID: r.userID, Role: model.RoleMember}))
+				}
+				rec := httptest.NewRecorder()
+
+				// 実行
+				e.ServeHTTP(rec, req)
+
+				// 検証
+				if rec.Code != tt.wantStatus[i] {
+					t.Fatalf("request %d: expected status code %d, got %d: %s", i, tt.wantStatus[i], rec.Code, rec.Body.String())
+				}
+				if first == nil {
+					first = rec
+				}
+				last = rec
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("Expected service to be called %d times, got %d", tt.wantCalls, calls)
+			}
+			if tt.wantSameBody {
+				if last.Body.String() != first.Body.String() {
+					t.Errorf("Expected replayed body %s, got %s", first.Body.String(), last.Body.String())
+				}
+				if last.Header().Get("ETag") != first.Header().Get("ETag") || last.Header().Get(echo.HeaderContentType) != first.Header().Get(echo.HeaderContentType) {
+					t.Errorf("Expected replayed headers %v, got %v", first.Header(), last.Header())
+				}
+				if last.Header().Get(idempotentReplayedHeader) != "true" {
+					t.Errorf("Expected %s header on the replayed response", idempotentReplayedHeader)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateReservation_IdempotencyInProgress(t *testing.T) {
+	// 準備 - 同じキーの最初のリクエストを処理中にする
+	e := echo.New()
+	store := repository.NewInMemoryIdempotencyRepository()
+	body := `{"resourceId": "room-a", "startTime": "2030-04-01T10:00:00Z", "endTime": "2030-04-01T11:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(body))
+	now := time.Now()
+	_, _ = store.Reserve(&model.IdempotencyRecord{
+		Key:         ":k1",
+		Fingerprint: requestFingerprint(req, []byte(body)),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Minute),
+	})
+
+	h := NewReservationHandler(&mockReservationService{
+		createReservationFunc: func(params service.CreateReservationParams) (*model.Reservation, error) {
+			t.Fatal("Expected the service not to be called")
+			return nil, nil
+		},
+	})
+	h.SetIdempotency(store, time.Hour)
+	h.RegisterRoutes(e)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(IdempotencyKeyHeader, "k1")
+	rec := httptest.NewRecorder()
+
+	// 実行
+	e.ServeHTTP(rec, req)
+
+	// 検証
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusConflict, rec.Code, rec.Body.String())
+	}
+}
@@ -1,25 +1,43 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/ical"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
 )
 
 // ReservationServiceInterface はテスト時にモック可能なインターフェース
 type ReservationServiceInterface interface {
-	CreateReservation(params service.CreateReservationParams) (*model.Reservation, error)
-	GetAllReservations() ([]*model.Reservation, error)
-	DeleteReservation(id string) error
+	CreateReservation(ctx context.Context, params service.CreateReservationParams) (*model.Reservation, error)
+	CreateRecurringReservation(ctx context.Context, params service.CreateRecurringReservationParams) (*service.RecurringReservationResult, error)
+	UpdateReservation(ctx context.Context, id string, params service.UpdateReservationParams) (*model.Reservation, error)
+	GetReservation(ctx context.Context, id string) (*model.Reservation, error)
+	GetAllReservations(ctx context.Context, params service.ListReservationsParams) (*service.ReservationPage, error)
+	ExportReservations(ctx context.Context, params service.ExportReservationsParams) (*service.ReservationExport, error)
+	StreamReservations(ctx context.Context, params service.ExportReservationsParams, fn func(reservation *model.Reservation, resourceName string) error) error
+	ImportReservations(ctx context.Context, params service.ImportReservationsParams) (*service.ImportReport, error)
+	ImportEntries(ctx context.Context, params service.ImportEntriesParams, next func() (service.ImportEntry, error), fn func(result service.ImportResult)) error
+	DeleteReservation(ctx context.Context, id string, params service.DeleteReservationParams) error
+	ChangeStatus(ctx context.Context, id string, params service.ChangeStatusParams) (*model.Reservation, error)
+	GetSeries(ctx context.Context, id string) (*service.SeriesDetail, error)
 }
 
 type ReservationHandler struct {
 	service  ReservationServiceInterface
 	validate *validator.Validate
+	// idempotency は予約の作成に適用する Idempotency ミドルウェア。nil の場合は Idempotency-Key を扱わない。
+	idempotency echo.MiddlewareFunc
 }
 
 func NewReservationHandler(service ReservationServiceInterface) *ReservationHandler {
@@ -30,14 +48,232 @@ func NewReservationHandler(service ReservationServiceInterface) *ReservationHand
 }
 
 type createReservationRequest struct {
-	StartTime string `json:"startTime" validate:"required"`
-	EndTime   string `json:"endTime" validate:"required"`
+	ResourceID string `json:"resourceId" validate:"required"`
+	StartTime  string `json:"startTime" validate:"required"`
+	EndTime    string `json:"endTime" validate:"required"`
+	// Recurrence は RFC 5545 の RRULE。指定すると繰り返し予約として作成する（作成時のみ）。
+	Recurrence string `json:"recurrence" validate:"max=255"`
+	// Title、Description、Attendees はサービスで検証する（service.ReservationDetails.Validate）
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Attendees   []string `json:"attendees"`
 }
 
-func (h *ReservationHandler) RegisterRoutes(e *echo.Echo) {
-	e.POST("/api/reservations", h.CreateReservation)
-	e.GET("/api/reservations", h.GetAllReservations)
-	e.DELETE("/api/reservations/:id", h.DeleteReservation)
+func (r *createReservationRequest) details() service.ReservationDetails {
+	return service.ReservationDetails{
+		Title:       r.Title,
+		Description: r.Description,
+		Attendees:   r.Attendees,
+	}
+}
+
+// conflictingReservation は 409 レスポンスに含める重複した予約の情報
+type conflictingReservation struct {
+	ID        string    `json:"id"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+type conflictResponse struct {
+	Error     string                   `json:"error"`
+	Conflicts []conflictingReservation `json:"conflicts"`
+}
+
+// patchReservationRequest は部分更新のリクエスト。省略したフィールドは変更しない。
+type patchReservationRequest struct {
+	ResourceID  *string   `json:"resourceId" validate:"omitempty,min=1"`
+	StartTime   *string   `json:"startTime"`
+	EndTime     *string   `json:"endTime"`
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Attendees   *[]string `json:"attendees"`
+}
+
+func newConflictResponse(conflictErr *service.ConflictError) conflictResponse {
+	return conflictResponse{
+		Error:     "Reservation overlaps with existing reservations",
+		Conflicts: newConflictingReservations(conflictErr.Conflicts),
+	}
+}
+
+// policyViolation は 422 レスポンスに含める違反した規則
+type policyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type policyViolationResponse struct {
+	Error      string            `json:"error"`
+	Violations []policyViolation `json:"violations"`
+}
+
+func newPolicyViolationResponse(policyErr *service.PolicyViolationError) policyViolationResponse {
+	violations := make([]policyViolation, 0, len(policyErr.Violations))
+	for _, v := range policyErr.Violations {
+		violations = append(violations, policyViolation{Rule: string(v.Rule), Message: v.Message})
+	}
+	return policyViolationResponse{
+		Error:      "Reservation violates booking policy",
+		Violations: violations,
+	}
+}
+
+func newConflictingReservations(reservations []*model.Reservation) []conflictingReservation {
+	conflicts := make([]conflictingReservation, 0, len(reservations))
+	for _, r := range reservations {
+		conflicts = append(conflicts, conflictingReservation{
+			ID:        r.ID,
+			StartTime: r.StartTime,
+			EndTime:   r.EndTime,
+		})
+	}
+	return conflicts
+}
+
+// skippedOccurrence は繰り返し予約のうち重複のため予約できなかった回
+type skippedOccurrence struct {
+	StartTime time.Time                `json:"startTime"`
+	EndTime   time.Time                `json:"endTime"`
+	Conflicts []conflictingReservation `json:"conflicts"`
+}
+
+type recurringReservationResponse struct {
+	Series       *model.ReservationSeries `json:"series"`
+	Reservations []*model.Reservation     `json:"reservations"`
+	Skipped      []skippedOccurrence      `json:"skipped"`
+}
+
+func newRecurringReservationResponse(result *service.RecurringReservationResult) recurringReservationResponse {
+	skipped := make([]skippedOccurrence, 0, len(result.Skipped))
+	for _, s := range result.Skipped {
+		skipped = append(skipped, skippedOccurrence{
+			StartTime: s.StartTime,
+			EndTime:   s.EndTime,
+			Conflicts: newConflictingReservations(s.Conflicts),
+		})
+	}
+	return recurringReservationResponse{
+		Series:       result.Series,
+		Reservations: result.Reservations,
+		Skipped:      skipped,
+	}
+}
+
+// SetIdempotency は予約の作成で Idempotency-Key を受け付け、レスポンスを store に ttl の間記録するように設定する。
+// RegisterRoutes より前に呼ぶこと。
+func (h *ReservationHandler) SetIdempotency(store IdempotencyStore, ttl time.Duration) {
+	h.idempotency = Idempotency(store, ttl)
+}
+
+// RegisterRoutes は予約のルートを登録する。middleware は全ての予約のルートに適用する（例: auth.RequireAuth）。
+func (h *ReservationHandler) RegisterRoutes(e *echo.Echo, middleware ...echo.MiddlewareFunc) {
+	createMiddleware := middleware
+	if h.idempotency != nil {
+		createMiddleware = append(slices.Clip(middleware), h.idempotency)
+	}
+	e.POST("/api/reservations", h.CreateReservation, createMiddleware...)
+	e.GET("/api/reservations", h.GetAllReservations, middleware...)
+	e.GET("/api/reservations.ics", h.ExportICalendar, middleware...)
+	e.POST("/api/reservations/import", h.ImportICalendar, middleware...)
+	e.GET("/api/reservations/export.csv", h.ExportCSV, middleware...)
+	e.POST("/api/reservations/import.csv", h.ImportCSV, middleware...)
+	e.GET("/api/reservations/:id", h.GetReservation, middleware...)
+	e.PUT("/api/reservations/:id", h.UpdateReservation, middleware...)
+	e.PATCH("/api/reservations/:id", h.PatchReservation, middleware...)
+	e.DELETE("/api/reservations/:id", h.DeleteReservation, middleware...)
+	e.PUT("/api/reservations/:id/status", h.ChangeStatus, middleware...)
+	e.GET("/api/reservation-series/:id", h.GetSeries, middleware...)
+}
+
+// statusRequest は予約の状態の変更リクエスト
+type statusRequest struct {
+	Status string `json:"status" validate:"required"`
+	// Reason の長さはサービスで確認する（service.MaxCancellationReasonLength）
+	Reason string `json:"reason"`
+}
+
+// invalidScopeMessage は scope クエリパラメータが不正な場合のエラーメッセージ
+const invalidScopeMessage = "Invalid scope: must be one of this, following, all"
+
+// nextCursorHeader は予約一覧の続きを取得するカーソルを返すレスポンスヘッダー
+const nextCursorHeader = "X-Next-Cursor"
+
+// errMissingIfMatch は変更のリクエストに If-Match ヘッダーがないことを表す
+var errMissingIfMatch = errors.New("missing If-Match header")
+
+// reservationETag は予約の版を表す ETag を返す
+func reservationETag(reservation *model.Reservation) string {
+	return fmt.Sprintf(`"%d"`, reservation.Version)
+}
+
+// ifMatchVersion は If-Match ヘッダーで指定された予約の版を返す。* の場合は版を確認しない 0 を返す。
+// ヘッダーがない場合は errMissingIfMatch を返す。版の ETag として読めない値（弱い ETag や複数の ETag を含む）は
+// どの版とも一致しないため service.ErrVersionConflict を返す。
+func ifMatchVersion(c echo.Context) (int64, error) {
+	header := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if header == "" {
+		return 0, errMissingIfMatch
+	}
+	if header == "*" {
+		return 0, nil
+	}
+	unquoted, err := strconv.Unquote(header)
+	if err != nil || !strings.HasPrefix(header, `"`) {
+		return 0, service.ErrVersionConflict
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 1 {
+		return 0, service.ErrVersionConflict
+	}
+	return version, nil
+}
+
+// reservationResponse は予約を ETag ヘッダーとともに返す
+func reservationResponse(c echo.Context, status int, reservation *model.Reservation) error {
+	c.Response().Header().Set("ETag", reservationETag(reservation))
+	return c.JSON(status, reservation)
+}
+
+// reservationError はサービスが返した予約のエラーをレスポンスに変換する。
+// 予期しないエラーは fallback のメッセージで 500 を返す。
+func reservationError(c echo.Context, err error, fallback string) error {
+	var conflictErr *service.ConflictError
+	var policyErr *service.PolicyViolationError
+	var detailsErr *service.InvalidDetailsError
+	switch {
+	case errors.As(err, &conflictErr):
+		return c.JSON(http.StatusConflict, newConflictResponse(conflictErr))
+	case errors.As(err, &detailsErr):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": detailsErr.Error()})
+	case errors.As(err, &policyErr):
+		return c.JSON(http.StatusUnprocessableEntity, newPolicyViolationResponse(policyErr))
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "You do not have permission to perform this action on the reservation"})
+	case errors.Is(err, errMissingIfMatch):
+		return c.JSON(http.StatusPreconditionRequired, map[string]string{"error": "If-Match header with the reservation's ETag is required"})
+	case errors.Is(err, service.ErrVersionConflict):
+		return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Reservation has been modified by another request; reload it and try again"})
+	case errors.Is(err, service.ErrReservationNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Reservation not found"})
+	case errors.Is(err, service.ErrInvalidTimeRange):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "End time must be after start time"})
+	case errors.Is(err, service.ErrResourceNotFound):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Resource not found"})
+	case errors.Is(err, service.ErrResourceInactive):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Resource is not active"})
+	case errors.Is(err, service.ErrInvalidStatus):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+	case errors.Is(err, service.ErrInvalidStatusTransition):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrReservationNotEditable):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Cancelled or finished reservations cannot be changed"})
+	case errors.Is(err, service.ErrInvalidRecurrence):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrSeriesTooLong):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Recurrence must not span more than %d days", int(service.MaxSeriesSpan.Hours()/24))})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
 }
 
 func (h *ReservationHandler) CreateReservation(c echo.Context) error {
@@ -64,26 +300,314 @@ func (h *ReservationHandler) CreateReservation(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "End time must be after start time"})
 	}
 
+	if req.Recurrence != "" {
+		result, err := h.service.CreateRecurringReservation(c.Request().Context(), service.CreateRecurringReservationParams{
+			ResourceID: req.ResourceID,
+			StartTime:  startTime,
+			EndTime:    endTime,
+			Recurrence: req.Recurrence,
+			Details:    req.details(),
+		})
+		if err != nil {
+			return reservationError(c, err, "Failed to create reservation")
+		}
+		return c.JSON(http.StatusCreated, newRecurringReservationResponse(result))
+	}
+
 	params := service.CreateReservationParams{
-		StartTime: startTime,
-		EndTime:   endTime,
+		ResourceID: req.ResourceID,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Details:    req.details(),
 	}
 
-	reservation, err := h.service.CreateReservation(params)
+	reservation, err := h.service.CreateReservation(c.Request().Context(), params)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create reservation"})
+		return reservationError(c, err, "Failed to create reservation")
 	}
 
-	return c.JSON(http.StatusCreated, reservation)
+	return reservationResponse(c, http.StatusCreated, reservation)
+}
+
+// UpdateReservation は予約の内容を丸ごと置き換える（PUT）
+func (h *ReservationHandler) UpdateReservation(c echo.Context) error {
+	req := new(createReservationRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid start time format"})
+	}
+
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid end time format"})
+	}
+
+	if req.Recurrence != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Recurrence can only be set when creating a reservation"})
+	}
+
+	scope, err := service.ParseScope(c.QueryParam("scope"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": invalidScopeMessage})
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return reservationError(c, err, "Failed to update reservation")
+	}
+
+	params := service.UpdateReservationParams{
+		ResourceID:  &req.ResourceID,
+		StartTime:   &startTime,
+		EndTime:     &endTime,
+		Title:       &req.Title,
+		Description: &req.Description,
+		Attendees:   &req.Attendees,
+		Scope:       scope,
+		Version:     version,
+	}
+
+	reservation, err := h.service.UpdateReservation(c.Request().Context(), c.Param("id"), params)
+	if err != nil {
+		return reservationError(c, err, "Failed to update reservation")
+	}
+
+	return reservationResponse(c, http.StatusOK, reservation)
+}
+
+// PatchReservation は指定されたフィールドだけを変更する（PATCH）
+func (h *ReservationHandler) PatchReservation(c echo.Context) error {
+	req := new(patchReservationRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	scope, err := service.ParseScope(c.QueryParam("scope"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": invalidScopeMessage})
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return reservationError(c, err, "Failed to update reservation")
+	}
+
+	params := service.UpdateReservationParams{
+		ResourceID:  req.ResourceID,
+		Title:       req.Title,
+		Description: req.Description,
+		Attendees:   req.Attendees,
+		Scope:       scope,
+		Version:     version,
+	}
+	if req.StartTime != nil {
+		startTime, err := time.Parse(time.RFC3339, *req.StartTime)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid start time format"})
+		}
+		params.StartTime = &startTime
+	}
+	if req.EndTime != nil {
+		endTime, err := time.Parse(time.RFC3339, *req.EndTime)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid end time format"})
+		}
+		params.EndTime = &endTime
+	}
+
+	reservation, err := h.service.UpdateReservation(c.Request().Context(), c.Param("id"), params)
+	if err != nil {
+		return reservationError(c, err, "Failed to update reservation")
+	}
+
+	return reservationResponse(c, http.StatusOK, reservation)
 }
 
 func (h *ReservationHandler) GetAllReservations(c echo.Context) error {
-	reservations, err := h.service.GetAllReservations()
+	params := service.ListReservationsParams{
+		ResourceID: c.QueryParam("resourceId"),
+	}
+
+	if from := c.QueryParam("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from format"})
+		}
+		params.From = t
+	}
+
+	if to := c.QueryParam("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to format"})
+		}
+		params.To = t
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			st := model.ReservationStatus(strings.TrimSpace(s))
+			if !st.Valid() {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+			}
+			params.Statuses = append(params.Statuses, st)
+		}
+	}
+
+	if createdAfter := c.QueryParam("createdAfter"); createdAfter != "" {
+		t, err := time.Parse(time.RFC3339, createdAfter)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid createdAfter format"})
+		}
+		params.CreatedAfter = t
+	}
+
+	if minDuration := c.QueryParam("minDuration"); minDuration != "" {
+		d, err := time.ParseDuration(minDuration)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid minDuration format"})
+		}
+		params.MinDuration = d
+	}
+
+	if maxDuration := c.QueryParam("maxDuration"); maxDuration != "" {
+		d, err := time.ParseDuration(maxDuration)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid maxDuration format"})
+		}
+		params.MaxDuration = d
+	}
+
+	params.SortBy = c.QueryParam("sort")
+
+	switch c.QueryParam("order") {
+	case "", "asc":
+	case "desc":
+		params.Descending = true
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Order must be asc or desc"})
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid limit format"})
+		}
+		if n < 1 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Limit must be between 1 and %d", service.MaxListLimit)})
+		}
+		params.Limit = n
+	}
+
+	params.Cursor = c.QueryParam("cursor")
+
+	page, err := h.service.GetAllReservations(c.Request().Context(), params)
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "You do not have permission to view reservations"})
+	}
+	if errors.Is(err, service.ErrInvalidWindow) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Both from and to are required and to must be after from"})
+	}
+	if errors.Is(err, service.ErrWindowTooLarge) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Time window must not exceed %d days", int(service.MaxListWindow.Hours()/24))})
+	}
+	if errors.Is(err, service.ErrInvalidDuration) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "minDuration and maxDuration must be positive and minDuration must not exceed maxDuration"})
+	}
+	if errors.Is(err, service.ErrInvalidSort) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Sort must be startTime or createdAt"})
+	}
+	if errors.Is(err, service.ErrInvalidLimit) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Limit must be between 1 and %d", service.MaxListLimit)})
+	}
+	if errors.Is(err, service.ErrInvalidCursor) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get reservations"})
 	}
 
-	return c.JSON(http.StatusOK, reservations)
+	if page.NextCursor != "" {
+		// 続きのページの URL はカーソル以外の条件を引き継ぐ
+		next := *c.Request().URL
+		query := next.Query()
+		query.Set("cursor", page.NextCursor)
+		next.RawQuery = query.Encode()
+		c.Response().Header().Set(nextCursorHeader, page.NextCursor)
+		c.Response().Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+	return c.JSON(http.StatusOK, page.Reservations)
+}
+
+// ExportICalendar は条件に合う予約を iCalendar（.ics）として返す
+func (h *ReservationHandler) ExportICalendar(c echo.Context) error {
+	params, err := exportParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	export, err := h.service.ExportReservations(c.Request().Context(), params)
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="reservations.ics"`)
+	return writeICalendar(c, export, params.ResourceID)
+}
+
+// ImportICalendar はリクエストの本文の iCalendar のイベントを予約として取り込み、イベントごとの結果を返す。
+// dryRun=true の場合は予約を保存せずに、取り込んだ場合の結果を返す。
+func (h *ReservationHandler) ImportICalendar(c echo.Context) error {
+	params, loc, err := importParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	events, err := ical.Decode(http.MaxBytesReader(c.Response(), c.Request().Body, maxImportBytes), loc)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": fmt.Sprintf("iCalendar must not exceed %d bytes", maxImportBytes)})
+	case err != nil:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid iCalendar: " + err.Error()})
+	}
+	params.Events = events
+
+	report, err := h.service.ImportReservations(c.Request().Context(), params)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, newImportResponse(report))
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "You do not have permission to create reservations"})
+	case errors.Is(err, service.ErrImportTooLarge):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("iCalendar must not contain more than %d events", service.MaxImportEvents)})
+	case errors.Is(err, service.ErrResourceNotFound):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Resource not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to import reservations"})
+	}
+}
+
+// GetReservation は予約を ETag ヘッダーとともに返す
+func (h *ReservationHandler) GetReservation(c echo.Context) error {
+	reservation, err := h.service.GetReservation(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return reservationError(c, err, "Failed to get reservation")
+	}
+
+	return reservationResponse(c, http.StatusOK, reservation)
 }
 
 func (h *ReservationHandler) DeleteReservation(c echo.Context) error {
@@ -92,9 +616,75 @@ func (h *ReservationHandler) DeleteReservation(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ID is required"})
 	}
 
-	if err := h.service.DeleteReservation(id); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete reservation"})
+	scope, err := service.ParseScope(c.QueryParam("scope"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": invalidScopeMessage})
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return reservationError(c, err, "Failed to delete reservation")
+	}
+
+	if err := h.service.DeleteReservation(c.Request().Context(), id, service.DeleteReservationParams{Scope: scope, Reason: c.QueryParam("reason"), Version: version}); err != nil {
+		return reservationError(c, err, "Failed to delete reservation")
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+type seriesResponse struct {
+	Series       *model.ReservationSeries `json:"series"`
+	Reservations []*model.Reservation     `json:"reservations"`
+	Exceptions   []*model.SeriesException `json:"exceptions"`
+}
+
+// GetSeries は繰り返し予約と残っている回、変更・取り消しされた回の履歴を返す
+func (h *ReservationHandler) GetSeries(c echo.Context) error {
+	detail, err := h.service.GetSeries(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "You do not have permission to view reservation details"})
+	}
+	if errors.Is(err, service.ErrSeriesNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Reservation series not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get reservation series"})
+	}
+
+	return c.JSON(http.StatusOK, seriesResponse{
+		Series:       detail.Series,
+		Reservations: detail.Reservations,
+		Exceptions:   detail.Exceptions,
+	})
+}
+
+// ChangeStatus は予約の状態を変更する（例: confirmed から completed）
+func (h *ReservationHandler) ChangeStatus(c echo.Context) error {
+	req := new(statusRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return reservationError(c, err, "Failed to change reservation status")
+	}
+
+	params := service.ChangeStatusParams{
+		Status:  model.ReservationStatus(req.Status),
+		Reason:  req.Reason,
+		Version: version,
+	}
+
+	reservation, err := h.service.ChangeStatus(c.Request().Context(), c.Param("id"), params)
+	if err != nil {
+		return reservationError(c, err, "Failed to change reservation status")
+	}
+
+	return reservationResponse(c, http.StatusOK, reservation)
+}
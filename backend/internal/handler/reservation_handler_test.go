@@ -1,38 +1,105 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/ical"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
 )
 
 // テスト用なのでインターフェースは削除（handler.goに定義済み）
 
-// モックサービスの実装
+// モックサービスの実装。コンテキストはハンドラーのテストでは使わないため func フィールドには渡さない。
 type mockReservationService struct {
-	createReservationFunc  func(params service.CreateReservationParams) (*model.Reservation, error)
-	getAllReservationsFunc func() ([]*model.Reservation, error)
-	deleteReservationFunc  func(id string) error
+	createReservationFunc          func(params service.CreateReservationParams) (*model.Reservation, error)
+	createRecurringReservationFunc func(params service.CreateRecurringReservationParams) (*service.RecurringReservationResult, error)
+	updateReservationFunc          func(id string, params service.UpdateReservationParams) (*model.Reservation, error)
+	getReservationFunc             func(id string) (*model.Reservation, error)
+	getAllReservationsFunc         func(params service.ListReservationsParams) (*service.ReservationPage, error)
+	exportReservationsFunc         func(params service.ExportReservationsParams) (*service.ReservationExport, error)
+	streamReservationsFunc         func(params service.ExportReservationsParams, fn func(reservation *model.Reservation, resourceName string) error) error
+	importReservationsFunc         func(params service.ImportReservationsParams) (*service.ImportReport, error)
+	importEntriesFunc              func(params service.ImportEntriesParams, next func() (service.ImportEntry, error), fn func(result service.ImportResult)) error
+	deleteReservationFunc          func(id string, params service.DeleteReservationParams) error
+	changeStatusFunc               func(id string, params service.ChangeStatusParams) (*model.Reservation, error)
+	getSeriesFunc                  func(id string) (*service.SeriesDetail, error)
+	getEventFunc                   func(resourceID, uid string) (*model.Reservation, error)
+	putEventFunc                   func(params service.PutEventParams) (*model.Reservation, bool, error)
+	deleteEventFunc                func(resourceID, uid string, version int64) error
 }
 
-func (m *mockReservationService) CreateReservation(params service.CreateReservationParams) (*model.Reservation, error) {
+func (m *mockReservationService) GetEvent(ctx context.Context, resourceID, uid string) (*model.Reservation, error) {
+	return m.getEventFunc(resourceID, uid)
+}
+
+func (m *mockReservationService) PutEvent(ctx context.Context, params service.PutEventParams) (*model.Reservation, bool, error) {
+	return m.putEventFunc(params)
+}
+
+func (m *mockReservationService) DeleteEvent(ctx context.Context, resourceID, uid string, version int64) error {
+	return m.deleteEventFunc(resourceID, uid, version)
+}
+
+func (m *mockReservationService) CreateReservation(ctx context.Context, params service.CreateReservationParams) (*model.Reservation, error) {
 	return m.createReservationFunc(params)
 }
 
-func (m *mockReservationService) GetAllReservations() ([]*model.Reservation, error) {
-	return m.getAllReservationsFunc()
+func (m *mockReservationService) CreateRecurringReservation(ctx context.Context, params service.CreateRecurringReservationParams) (*service.RecurringReservationResult, error) {
+	return m.createRecurringReservationFunc(params)
+}
+
+func (m *mockReservationService) UpdateReservation(ctx context.Context, id string, params service.UpdateReservationParams) (*model.Reservation, error) {
+	return m.updateReservationFunc(id, params)
+}
+
+func (m *mockReservationService) GetReservation(ctx context.Context, id string) (*model.Reservation, error) {
+	return m.getReservationFunc(id)
+}
+
+func (m *mockReservationService) GetAllReservations(ctx context.Context, params service.ListReservationsParams) (*service.ReservationPage, error) {
+	return m.getAllReservationsFunc(params)
+}
+
+func (m *mockReservationService) ExportReservations(ctx context.Context, params service.ExportReservationsParams) (*service.ReservationExport, error) {
+	return m.exportReservationsFunc(params)
+}
+
+func (m *mockReservationService) StreamReservations(ctx context.Context, params service.ExportReservationsParams, fn func(reservation *model.Reservation, resourceName string) error) error {
+	return m.streamReservationsFunc(params, fn)
 }
 
-func (m *mockReservationService) DeleteReservation(id string) error {
-	return m.deleteReservationFunc(id)
+func (m *mockReservationService) ImportReservations(ctx context.Context, params service.ImportReservationsParams) (*service.ImportReport, error) {
+	return m.importReservationsFunc(params)
+}
+
+func (m *mockReservationService) ImportEntries(ctx context.Context, params service.ImportEntriesParams, next func() (service.ImportEntry, error), fn func(result service.ImportResult)) error {
+	return m.importEntriesFunc(params, next, fn)
+}
+
+func (m *mockReservationService) DeleteReservation(ctx context.Context, id string, params service.DeleteReservationParams) error {
+	return m.deleteReservationFunc(id, params)
+}
+
+func (m *mockReservationService) ChangeStatus(ctx context.Context, id string, params service.ChangeStatusParams) (*model.Reservation, error) {
+	return m.changeStatusFunc(id, params)
+}
+
+func (m *mockReservationService) GetSeries(ctx context.Context, id string) (*service.SeriesDetail, error) {
+	return m.getSeriesFunc(id)
 }
 
 func TestCreateReservation(t *testing.T) {
@@ -42,7 +109,7 @@ func TestCreateReservation(t *testing.T) {
 	// モックサービスの準備
 	mockSvc := &mockReservationService{
 		createReservationFunc: func(params service.CreateReservationParams) (*model.Reservation, error) {
-			return model.NewReservation(params.StartTime, params.EndTime), nil
+			return model.NewReservation(params.ResourceID, params.StartTime, params.EndTime), nil
 		},
 	}
 
@@ -53,7 +120,7 @@ func TestCreateReservation(t *testing.T) {
 	now := time.Now()
 	startTime := now.Add(time.Hour).Format(time.RFC3339)
 	endTime := now.Add(2 * time.Hour).Format(time.RFC3339)
-	requestBody := `{"startTime": "` + startTime + `", "endTime": "` + endTime + `"}`
+	requestBody := `{"resourceId": "resource-1", "startTime": "` + startTime + `", "endTime": "` + endTime + `"}`
 
 	// リクエストの準備
 	req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(requestBody))
@@ -81,6 +148,57 @@ func TestCreateReservation(t *testing.T) {
 	if response.ID == "" {
 		t.Error("Expected ID to be set, got empty string")
 	}
+	if etag := rec.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf("Expected ETag %q, got %q", `"1"`, etag)
+	}
+}
+
+func TestCreateReservation_Details(t *testing.T) {
+	// モックサービスの準備 - 受け取った目的と参加者を記録する
+	var received service.ReservationDetails
+	mockSvc := &mockReservationService{
+		createReservationFunc: func(params service.CreateReservationParams) (*model.Reservation, error) {
+			received = params.Details
+			// 目的と参加者の検証はサービスで行う
+			if err := params.Details.Validate(); err != nil {
+				return nil, err
+			}
+			return model.NewReservation(params.ResourceID, params.StartTime, params.EndTime), nil
+		},
+	}
+	h := NewReservationHandler(mockSvc)
+
+	send := func(details string) *httptest.ResponseRecorder {
+		requestBody := `{"resourceId": "resource-1", "startTime": "2023-01-01T10:00:00Z", "endTime": "2023-01-01T11:00:00Z", ` + details + `}`
+		req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(requestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := h.CreateReservation(echo.New().NewContext(req, rec)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return rec
+	}
+
+	// 目的と参加者はサービスに渡される
+	rec := send(`"title": "定例会議", "description": "週次の進捗確認", "attendees": ["a@example.com", "b@example.com"]`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	if received.Title != "定例会議" || received.Description != "週次の進捗確認" || len(received.Attendees) != 2 {
+		t.Errorf("Unexpected details %+v", received)
+	}
+
+	// 長さや形式が不正な場合は 400
+	for _, details := range []string{
+		`"title": "` + strings.Repeat("あ", 201) + `"`,
+		`"description": "` + strings.Repeat("a", 2001) + `"`,
+		`"attendees": ["not-an-email"]`,
+		`"attendees": [` + strings.Repeat(`"a@example.com", `, 50) + `"b@example.com"]`,
+	} {
+		if rec := send(details); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %.40s, got %d", http.StatusBadRequest, details, rec.Code)
+		}
+	}
 }
 
 func TestCreateReservation_InvalidRequest(t *testing.T) {
@@ -145,7 +263,7 @@ func TestCreateReservation_InvalidTimeFormat(t *testing.T) {
 	h := NewReservationHandler(nil) // サービスは使用しないのでnilでOK
 
 	// 不正な時間フォーマットのリクエストボディ
-	invalidTimeFormat := `{"startTime": "invalid-time", "endTime": "2023-01-01T12:00:00Z"}`
+	invalidTimeFormat := `{"resourceId": "resource-1", "startTime": "invalid-time", "endTime": "2023-01-01T12:00:00Z"}`
 
 	// リクエストの準備
 	req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(invalidTimeFormat))
@@ -164,7 +282,7 @@ func TestCreateReservation_InvalidTimeFormat(t *testing.T) {
 	}
 
 	// エンドタイムが不正な場合のテスト
-	invalidEndTime := `{"startTime": "2023-01-01T12:00:00Z", "endTime": "invalid-time"}`
+	invalidEndTime := `{"resourceId": "resource-1", "startTime": "2023-01-01T12:00:00Z", "endTime": "invalid-time"}`
 	req = httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(invalidEndTime))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec = httptest.NewRecorder()
@@ -192,7 +310,7 @@ func TestCreateReservation_EndTimeBeforeStartTime(t *testing.T) {
 	now := time.Now()
 	endTime := now.Format(time.RFC3339)
 	startTime := now.Add(time.Hour).Format(time.RFC3339)
-	invalidRequestBody := `{"startTime": "` + startTime + `", "endTime": "` + endTime + `"}`
+	invalidRequestBody := `{"resourceId": "resource-1", "startTime": "` + startTime + `", "endTime": "` + endTime + `"}`
 
 	// リクエストの準備
 	req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(invalidRequestBody))
@@ -229,7 +347,7 @@ func TestCreateReservation_ServiceError(t *testing.T) {
 	now := time.Now()
 	startTime := now.Add(time.Hour).Format(time.RFC3339)
 	endTime := now.Add(2 * time.Hour).Format(time.RFC3339)
-	requestBody := `{"startTime": "` + startTime + `", "endTime": "` + endTime + `"}`
+	requestBody := `{"resourceId": "resource-1", "startTime": "` + startTime + `", "endTime": "` + endTime + `"}`
 
 	// リクエストの準備
 	req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(requestBody))
@@ -248,62 +366,174 @@ func TestCreateReservation_ServiceError(t *testing.T) {
 	}
 }
 
-func TestGetAllReservations(t *testing.T) {
+func TestCreateReservation_ResourceError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"存在しないリソース", service.ErrResourceNotFound},
+		{"無効化されたリソース", service.ErrResourceInactive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Echoのインスタンスを作成
+			e := echo.New()
+
+			// モックサービスの準備 - リソースのエラーを返す
+			mockSvc := &mockReservationService{
+				createReservationFunc: func(params service.CreateReservationParams) (*model.Reservation, error) {
+					return nil, tt.err
+				},
+			}
+
+			// ハンドラーの作成
+			h := NewReservationHandler(mockSvc)
+
+			// リクエストの準備
+			requestBody := `{"resourceId": "resource-1", "startTime": "2023-01-01T12:00:00Z", "endTime": "2023-01-01T13:00:00Z"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// ハンドラーを実行
+			if err := h.CreateReservation(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// レスポンスの検証
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+		})
+	}
+}
+
+func TestCreateReservation_Conflict(t *testing.T) {
 	// Echoのインスタンスを作成
 	e := echo.New()
 
-	// モックデータの準備
+	// モックサービスの準備 - 重複エラーを返す
 	now := time.Now()
-	expectedReservations := []*model.Reservation{
-		model.NewReservation(now, now.Add(time.Hour)),
-		model.NewReservation(now.Add(2*time.Hour), now.Add(3*time.Hour)),
+	existing := model.NewReservation("resource-1", now.Add(30*time.Minute), now.Add(90*time.Minute))
+	mockSvc := &mockReservationService{
+		createReservationFunc: func(params service.CreateReservationParams) (*model.Reservation, error) {
+			return nil, &service.ConflictError{Conflicts: []*model.Reservation{existing}}
+		},
 	}
 
-	// モックサービスの準備
+	// ハンドラーの作成
+	h := NewReservationHandler(mockSvc)
+
+	// リクエストボディの作成
+	startTime := now.Add(time.Hour).Format(time.RFC3339)
+	endTime := now.Add(2 * time.Hour).Format(time.RFC3339)
+	requestBody := `{"resourceId": "resource-1", "startTime": "` + startTime + `", "endTime": "` + endTime + `"}`
+
+	// リクエストの準備
+	req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// ハンドラーを実行
+	if err := h.CreateReservation(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// レスポンスの検証
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d", http.StatusConflict, rec.Code)
+	}
+
+	var response conflictResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(response.Conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %d", len(response.Conflicts))
+	}
+	if response.Conflicts[0].ID != existing.ID {
+		t.Errorf("Expected conflict ID %s, got %s", existing.ID, response.Conflicts[0].ID)
+	}
+	if !response.Conflicts[0].StartTime.Equal(existing.StartTime) || !response.Conflicts[0].EndTime.Equal(existing.EndTime) {
+		t.Errorf("Expected conflict times %v-%v, got %v-%v",
+			existing.StartTime, existing.EndTime, response.Conflicts[0].StartTime, response.Conflicts[0].EndTime)
+	}
+}
+
+func TestCreateReservation_PolicyViolation(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// モックサービスの準備 - ポリシー違反を返す
 	mockSvc := &mockReservationService{
-		getAllReservationsFunc: func() ([]*model.Reservation, error) {
-			return expectedReservations, nil
+		createReservationFunc: func(params service.CreateReservationParams) (*model.Reservation, error) {
+			return nil, &service.PolicyViolationError{Violations: []service.PolicyViolation{
+				{Rule: service.RuleMaxDuration, Message: "reservation must be at most 4h0m0s long"},
+			}}
 		},
 	}
 
 	// ハンドラーの作成
 	h := NewReservationHandler(mockSvc)
 
+	// リクエストボディの作成
+	now := time.Now()
+	startTime := now.Add(time.Hour).Format(time.RFC3339)
+	endTime := now.Add(9 * time.Hour).Format(time.RFC3339)
+	requestBody := `{"resourceId": "resource-1", "startTime": "` + startTime + `", "endTime": "` + endTime + `"}`
+
 	// リクエストの準備
-	req := httptest.NewRequest(http.MethodGet, "/api/reservations", nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
 	// ハンドラーを実行
-	if err := h.GetAllReservations(c); err != nil {
+	if err := h.CreateReservation(c); err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
 	// レスポンスの検証
-	if rec.Code != http.StatusOK {
-		t.Errorf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnprocessableEntity, rec.Code)
 	}
 
-	// レスポンスボディの検証
-	var response []*model.Reservation
-	err := json.Unmarshal(rec.Body.Bytes(), &response)
-	if err != nil {
+	var response policyViolationResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
 		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
-
-	if len(response) != len(expectedReservations) {
-		t.Errorf("Expected %d reservations, got %d", len(expectedReservations), len(response))
+	if len(response.Violations) != 1 || response.Violations[0].Rule != "max_duration" {
+		t.Errorf("Expected max_duration violation, got %v", response.Violations)
+	}
+	if response.Violations[0].Message == "" {
+		t.Error("Expected violation message")
 	}
 }
 
-func TestGetAllReservations_ServiceError(t *testing.T) {
+func TestCreateReservation_Recurring(t *testing.T) {
 	// Echoのインスタンスを作成
 	e := echo.New()
 
-	// モックサービスの準備 - エラーを返す
+	// モックサービスの準備 - 2回目が重複した結果を返す
+	start := time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+	series := model.NewReservationSeries("resource-1", "FREQ=WEEKLY;COUNT=2", start, start.Add(time.Hour))
+	existing := model.NewReservation("resource-1", start.AddDate(0, 0, 7), start.AddDate(0, 0, 7).Add(time.Hour))
+	var received service.CreateRecurringReservationParams
 	mockSvc := &mockReservationService{
-		getAllReservationsFunc: func() ([]*model.Reservation, error) {
-			return nil, errors.New("service error")
+		createRecurringReservationFunc: func(params service.CreateRecurringReservationParams) (*service.RecurringReservationResult, error) {
+			received = params
+			return &service.RecurringReservationResult{
+				Series:       series,
+				Reservations: []*model.Reservation{series.NewOccurrence(start)},
+				Skipped: []service.SkippedOccurrence{{
+					StartTime: existing.StartTime,
+					EndTime:   existing.EndTime,
+					Conflicts: []*model.Reservation{existing},
+				}},
+			}, nil
 		},
 	}
 
@@ -311,35 +541,82 @@ func TestGetAllReservations_ServiceError(t *testing.T) {
 	h := NewReservationHandler(mockSvc)
 
 	// リクエストの準備
-	req := httptest.NewRequest(http.MethodGet, "/api/reservations", nil)
+	requestBody := `{"resourceId": "resource-1", "startTime": "2024-04-02T10:00:00Z", "endTime": "2024-04-02T11:00:00Z", "recurrence": "FREQ=WEEKLY;COUNT=2"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
 	// ハンドラーを実行
-	if err := h.GetAllReservations(c); err != nil {
+	if err := h.CreateReservation(c); err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
 	// レスポンスの検証
-	if rec.Code != http.StatusInternalServerError {
-		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, rec.Code)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status code %d, got %d", http.StatusCreated, rec.Code)
+	}
+	if received.Recurrence != "FREQ=WEEKLY;COUNT=2" || !received.StartTime.Equal(start) {
+		t.Errorf("Unexpected params passed to service: %+v", received)
+	}
+
+	var response recurringReservationResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Series == nil || response.Series.ID != series.ID {
+		t.Errorf("Expected series %s, got %v", series.ID, response.Series)
+	}
+	if len(response.Reservations) != 1 || response.Reservations[0].SeriesID != series.ID {
+		t.Errorf("Expected 1 reservation in series, got %v", response.Reservations)
+	}
+	if len(response.Skipped) != 1 || len(response.Skipped[0].Conflicts) != 1 || response.Skipped[0].Conflicts[0].ID != existing.ID {
+		t.Errorf("Expected skipped occurrence conflicting with %s, got %v", existing.ID, response.Skipped)
 	}
 }
 
-func TestDeleteReservation(t *testing.T) {
+func TestCreateReservation_InvalidRecurrence(t *testing.T) {
 	// Echoのインスタンスを作成
 	e := echo.New()
 
-	// テスト用ID
-	testID := "test-id"
+	mockSvc := &mockReservationService{
+		createRecurringReservationFunc: func(params service.CreateRecurringReservationParams) (*service.RecurringReservationResult, error) {
+			return nil, fmt.Errorf("%w: unsupported FREQ", service.ErrInvalidRecurrence)
+		},
+	}
+	h := NewReservationHandler(mockSvc)
 
-	// モックサービスの準備
+	requestBody := `{"resourceId": "resource-1", "startTime": "2024-04-02T10:00:00Z", "endTime": "2024-04-02T11:00:00Z", "recurrence": "FREQ=HOURLY"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/reservations", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// ハンドラーを実行
+	if err := h.CreateReservation(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// レスポンスの検証
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestUpdateReservation(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// モックサービスの準備 - 受け取った変更内容を記録する
+	var receivedID string
+	var received service.UpdateReservationParams
 	mockSvc := &mockReservationService{
-		deleteReservationFunc: func(id string) error {
-			if id != testID {
-				t.Errorf("Expected ID %s, got %s", testID, id)
-			}
-			return nil
+		updateReservationFunc: func(id string, params service.UpdateReservationParams) (*model.Reservation, error) {
+			receivedID = id
+			received = params
+			reservation := model.NewReservation(*params.ResourceID, *params.StartTime, *params.EndTime)
+			reservation.ID = id
+			return reservation, nil
 		},
 	}
 
@@ -347,39 +624,54 @@ func TestDeleteReservation(t *testing.T) {
 	h := NewReservationHandler(mockSvc)
 
 	// リクエストの準備
-	req := httptest.NewRequest(http.MethodDelete, "/api/reservations/"+testID, nil)
+	requestBody := `{"resourceId": "resource-1", "startTime": "2023-01-01T12:00:00Z", "endTime": "2023-01-01T13:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/reservations/test-id", strings.NewReader(requestBody))
+	req.Header.Set("If-Match", `"1"`)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 	c.SetParamNames("id")
-	c.SetParamValues(testID)
+	c.SetParamValues("test-id")
 
 	// ハンドラーを実行
-	if err := h.DeleteReservation(c); err != nil {
+	if err := h.UpdateReservation(c); err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
 	// レスポンスの検証
-	if rec.Code != http.StatusNoContent {
-		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if receivedID != "test-id" {
+		t.Errorf("Expected ID test-id, got %s", receivedID)
+	}
+	if received.ResourceID == nil || received.StartTime == nil || received.EndTime == nil {
+		t.Fatal("Expected all fields to be set for PUT")
+	}
+	if !received.StartTime.Equal(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected start time %v", received.StartTime)
 	}
 }
 
-func TestDeleteReservation_EmptyID(t *testing.T) {
+func TestUpdateReservation_ValidationError(t *testing.T) {
 	// Echoのインスタンスを作成
 	e := echo.New()
 
 	// ハンドラーの作成
 	h := NewReservationHandler(nil) // サービスは使用しないのでnilでOK
 
-	// リクエストの準備 - 空のID
-	req := httptest.NewRequest(http.MethodDelete, "/api/reservations/", nil)
+	// PUTでは全てのフィールドが必須
+	requestBody := `{"startTime": "2023-01-01T12:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/reservations/test-id", strings.NewReader(requestBody))
+	req.Header.Set("If-Match", `"1"`)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 	c.SetParamNames("id")
-	c.SetParamValues("") // 空のID
+	c.SetParamValues("test-id")
 
 	// ハンドラーを実行
-	if err := h.DeleteReservation(c); err != nil {
+	if err := h.UpdateReservation(c); err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
@@ -389,83 +681,1367 @@ func TestDeleteReservation_EmptyID(t *testing.T) {
 	}
 }
 
-func TestDeleteReservation_ServiceError(t *testing.T) {
+func TestPatchReservation(t *testing.T) {
 	// Echoのインスタンスを作成
 	e := echo.New()
 
-	// テスト用ID
-	testID := "test-id"
-
-	// モックサービスの準備 - エラーを返す
+	// モックサービスの準備 - 受け取った変更内容を記録する
+	var received service.UpdateReservationParams
 	mockSvc := &mockReservationService{
-		deleteReservationFunc: func(id string) error {
-			return errors.New("service error")
+		updateReservationFunc: func(id string, params service.UpdateReservationParams) (*model.Reservation, error) {
+			received = params
+			return model.NewReservation("resource-1", time.Now(), *params.EndTime), nil
 		},
 	}
 
 	// ハンドラーの作成
 	h := NewReservationHandler(mockSvc)
 
-	// リクエストの準備
-	req := httptest.NewRequest(http.MethodDelete, "/api/reservations/"+testID, nil)
+	// 終了時刻だけを変更するリクエスト
+	requestBody := `{"endTime": "2023-01-01T14:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPatch, "/api/reservations/test-id", strings.NewReader(requestBody))
+	req.Header.Set("If-Match", `"1"`)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 	c.SetParamNames("id")
-	c.SetParamValues(testID)
+	c.SetParamValues("test-id")
 
 	// ハンドラーを実行
-	if err := h.DeleteReservation(c); err != nil {
+	if err := h.PatchReservation(c); err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
 	// レスポンスの検証
-	if rec.Code != http.StatusInternalServerError {
-		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, rec.Code)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if received.ResourceID != nil || received.StartTime != nil {
+		t.Error("Expected omitted fields to be left unchanged")
+	}
+	if received.EndTime == nil || !received.EndTime.Equal(time.Date(2023, 1, 1, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected end time %v", received.EndTime)
 	}
 }
 
-func TestRegisterRoutes(t *testing.T) {
+func TestPatchReservation_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"不正な時間フォーマット", `{"startTime": "invalid-time"}`, nil, http.StatusBadRequest},
+		{"存在しない予約", `{"endTime": "2023-01-01T14:00:00Z"}`, service.ErrReservationNotFound, http.StatusNotFound},
+		{"終了時刻が開始時刻より前", `{"endTime": "2023-01-01T14:00:00Z"}`, service.ErrInvalidTimeRange, http.StatusBadRequest},
+		{"他の予約と重なる", `{"endTime": "2023-01-01T14:00:00Z"}`, &service.ConflictError{}, http.StatusConflict},
+		{"サービスエラー", `{"endTime": "2023-01-01T14:00:00Z"}`, errors.New("service error"), http.StatusInternalServerError},
+		{"長すぎるタイトル", `{"title": "` + strings.Repeat("あ", 201) + `"}`, &service.InvalidDetailsError{Field: "title", Reason: "must be at most 200 characters"}, http.StatusBadRequest},
+		{"不正な参加者のメールアドレス", `{"attendees": ["not-an-email"]}`, &service.InvalidDetailsError{Field: "attendees", Reason: `invalid email address "not-an-email"`}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Echoのインスタンスを作成
+			e := echo.New()
+
+			// モックサービスの準備
+			mockSvc := &mockReservationService{
+				updateReservationFunc: func(id string, params service.UpdateReservationParams) (*model.Reservation, error) {
+					return nil, tt.err
+				},
+			}
+
+			// ハンドラーの作成
+			h := NewReservationHandler(mockSvc)
+
+			// リクエストの準備
+			req := httptest.NewRequest(http.MethodPatch, "/api/reservations/test-id", strings.NewReader(tt.body))
+			req.Header.Set("If-Match", `"1"`)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("test-id")
+
+			// ハンドラーを実行
+			if err := h.PatchReservation(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// レスポンスの検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestGetAllReservations(t *testing.T) {
 	// Echoのインスタンスを作成
 	e := echo.New()
 
+	// モックデータの準備
+	now := time.Now()
+	expectedReservations := []*model.Reservation{
+		model.NewReservation("resource-1", now, now.Add(time.Hour)),
+		model.NewReservation("resource-1", now.Add(2*time.Hour), now.Add(3*time.Hour)),
+	}
+
 	// モックサービスの準備
-	mockSvc := &mockReservationService{}
+	mockSvc := &mockReservationService{
+		getAllReservationsFunc: func(params service.ListReservationsParams) (*service.ReservationPage, error) {
+			return &service.ReservationPage{Reservations: expectedReservations}, nil
+		},
+	}
 
 	// ハンドラーの作成
 	h := NewReservationHandler(mockSvc)
 
-	// ルート登録
-	h.RegisterRoutes(e)
+	// リクエストの準備
+	req := httptest.NewRequest(http.MethodGet, "/api/reservations", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
 
-	// 登録されたルートを検証
-	routes := e.Routes()
-	
-	// 期待するルート
-	expectedRoutes := []struct {
-		path   string
-		method string
-	}{
-		{"/api/reservations", "POST"},
-		{"/api/reservations", "GET"},
-		{"/api/reservations/:id", "DELETE"},
+	// ハンドラーを実行
+	if err := h.GetAllReservations(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
 	}
-	
-	// ルートが正しく登録されているか確認
-	routeFound := make(map[string]bool)
-	for _, er := range expectedRoutes {
-		for _, route := range routes {
-			if route.Path == er.path && route.Method == er.method {
-				routeFound[er.path+":"+er.method] = true
-				break
-			}
-		}
+
+	// レスポンスの検証
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, rec.Code)
 	}
-	
-	// 全てのルートが見つかったか確認
-	for _, er := range expectedRoutes {
+
+	// レスポンスボディの検証
+	var response []*model.Reservation
+	err := json.Unmarshal(rec.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(response) != len(expectedReservations) {
+		t.Errorf("Expected %d reservations, got %d", len(expectedReservations), len(response))
+	}
+}
+
+func TestGetAllReservations_ByResource(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// モックサービスの準備 - 受け取った絞り込み条件を記録する
+	var received service.ListReservationsParams
+	mockSvc := &mockReservationService{
+		getAllReservationsFunc: func(params service.ListReservationsParams) (*service.ReservationPage, error) {
+			received = params
+			return &service.ReservationPage{Reservations: []*model.Reservation{}}, nil
+		},
+	}
+
+	// ハンドラーの作成
+	h := NewReservationHandler(mockSvc)
+
+	// リクエストの準備
+	req := httptest.NewRequest(http.MethodGet, "/api/reservations?resourceId=room-a", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// ハンドラーを実行
+	if err := h.GetAllReservations(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if received.ResourceID != "room-a" {
+		t.Errorf("Expected resourceId room-a, got %q", received.ResourceID)
+	}
+}
+
+func TestGetAllReservations_Window(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+	}{
+		{"期間指定", "?from=2024-04-01T00:00:00%2B09:00&to=2024-04-08T00:00:00%2B09:00", nil, http.StatusOK},
+		{"fromの形式が不正", "?from=2024-04-01&to=2024-04-08T00:00:00Z", nil, http.StatusBadRequest},
+		{"toの形式が不正", "?from=2024-04-01T00:00:00Z&to=next-week", nil, http.StatusBadRequest},
+		{"期間が不正", "?from=2024-04-08T00:00:00Z&to=2024-04-01T00:00:00Z", service.ErrInvalidWindow, http.StatusBadRequest},
+		{"期間が長すぎる", "?from=2000-01-01T00:00:00Z&to=2030-01-01T00:00:00Z", service.ErrWindowTooLarge, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Echoのインスタンスを作成
+			e := echo.New()
+
+			// モックサービスの準備
+			var received service.ListReservationsParams
+			mockSvc := &mockReservationService{
+				getAllReservationsFunc: func(params service.ListReservationsParams) (*service.ReservationPage, error) {
+					received = params
+					return &service.ReservationPage{Reservations: []*model.Reservation{}}, tt.err
+				},
+			}
+
+			// ハンドラーの作成
+			h := NewReservationHandler(mockSvc)
+
+			// リクエストの準備
+			req := httptest.NewRequest(http.MethodGet, "/api/reservations"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// ハンドラーを実行
+			if err := h.GetAllReservations(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusOK {
+				wantFrom := time.Date(2024, 3, 31, 15, 0, 0, 0, time.UTC)
+				if !received.From.Equal(wantFrom) || !received.To.Equal(wantFrom.AddDate(0, 0, 7)) {
+					t.Errorf("Unexpected window %v - %v", received.From, received.To)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAllReservations_ServiceError(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// モックサービスの準備 - エラーを返す
+	mockSvc := &mockReservationService{
+		getAllReservationsFunc: func(params service.ListReservationsParams) (*service.ReservationPage, error) {
+			return nil, errors.New("service error")
+		},
+	}
+
+	// ハンドラーの作成
+	h := NewReservationHandler(mockSvc)
+
+	// リクエストの準備
+	req := httptest.NewRequest(http.MethodGet, "/api/reservations", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// ハンドラーを実行
+	if err := h.GetAllReservations(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// レスポンスの検証
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+}
+
+func TestGetReservation(t *testing.T) {
+	reservation := model.NewReservation("room-a", time.Now(), time.Now().Add(time.Hour))
+	reservation.Version = 2
+
+	tests := []struct {
+		name       string
+		id         string
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{name: "予約を返す", id: reservation.ID, wantStatus: http.StatusOK},
+		{name: "存在しない予約", id: "unknown", err: service.ErrReservationNotFound, wantStatus: http.StatusNotFound, wantError: "Reservation not found"},
+		{name: "権限がない", id: reservation.ID, err: service.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "サービスのエラー", id: reservation.ID, err: errors.New("database error"), wantStatus: http.StatusInternalServerError, wantError: "Failed to get reservation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockReservationService{
+				getReservationFunc: func(id string) (*model.Reservation, error) {
+					if id != tt.id {
+						t.Errorf("Expected ID %s, got %s", tt.id, id)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return reservation, nil
+				},
+			}
+			h := NewReservationHandler(mockSvc)
+			h.RegisterRoutes(e)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/reservations/"+tt.id, nil)
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				var body map[string]string
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["error"] == "" {
+					t.Fatalf("Expected structured error, got %s", rec.Body.String())
+				}
+				if tt.wantError != "" && body["error"] != tt.wantError {
+					t.Errorf("Expected error %q, got %q", tt.wantError, body["error"])
+				}
+				return
+			}
+			var got model.Reservation
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if got.ID != reservation.ID {
+				t.Errorf("Expected reservation %s, got %s", reservation.ID, got.ID)
+			}
+			if rec.Header().Get("ETag") != `"2"` {
+				t.Errorf("Expected ETag %q, got %q", `"2"`, rec.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestExportICalendar(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	reservation := model.NewReservation("room-a", start, start.Add(time.Hour))
+	reservation.Title = "定例会議"
+	export := &service.ReservationExport{
+		Reservations:  []*model.Reservation{reservation},
+		ResourceNames: map[string]string{"room-a": "会議室A"},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+		wantParams service.ExportReservationsParams
+	}{
+		{
+			name:       "期間とリソースを指定して書き出す",
+			query:      "?resourceId=room-a&from=2030-01-07T00:00:00Z&to=2030-01-14T00:00:00Z",
+			wantStatus: http.StatusOK,
+			wantParams: service.ExportReservationsParams{ResourceID: "room-a", From: start.Add(-10 * time.Hour), To: start.Add(7*24*time.Hour - 10*time.Hour)},
+		},
+		{name: "期間を省略", wantStatus: http.StatusOK},
+		{name: "from の形式が不正", query: "?from=2030-01-07", wantStatus: http.StatusBadRequest},
+		{name: "未定義の状態", query: "?status=done", wantStatus: http.StatusBadRequest},
+		{name: "期間が不正", query: "?from=2030-01-07T00:00:00Z", err: service.ErrInvalidWindow, wantStatus: http.StatusBadRequest},
+		{name: "件数が多すぎる", err: service.ErrExportTooLarge, wantStatus: http.StatusBadRequest},
+		{name: "権限がない", err: service.ErrForbidden, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockReservationService{
+				exportReservationsFunc: func(params service.ExportReservationsParams) (*service.ReservationExport, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					if !reflect.DeepEqual(params, tt.wantParams) {
+						t.Errorf("Expected params %+v, got %+v", tt.wantParams, params)
+					}
+					return export, nil
+				},
+			}
+			h := NewReservationHandler(mockSvc)
+			h.RegisterRoutes(e)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/reservations.ics"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if ct := rec.Header().Get(echo.HeaderContentType); ct != "text/calendar; charset=utf-8" {
+				t.Errorf("Expected iCalendar content type, got %q", ct)
+			}
+			body := rec.Body.String()
+			for _, want := range []string{
+				"UID:" + reservation.ID + "@yoyaku\r\n",
+				"DTSTART:20300107T100000Z\r\n",
+				"DTEND:20300107T110000Z\r\n",
+				"SUMMARY:定例会議\r\n",
+				"LOCATION:会議室A\r\n",
+				"STATUS:CONFIRMED\r\n",
+			} {
+				if !strings.Contains(body, want) {
+					t.Errorf("Expected body to contain %q, got:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestReservationEvent(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	reservation := model.NewReservation("room-a", start, start.Add(time.Hour))
+	reservation.Status = model.StatusPending
+	reservation.Version = 3
+
+	// 件名のない予約はリソースの名前を件名にする
+	event := reservationEvent(reservation, "会議室A")
+
+	if event.Summary != "会議室A" || event.Status != ical.StatusTentative || event.Sequence != 2 {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if !event.Stamp.Equal(reservation.UpdatedAt) {
+		t.Errorf("Expected DTSTAMP %v, got %v", reservation.UpdatedAt, event.Stamp)
+	}
+}
+
+func TestImportICalendar(t *testing.T) {
+	start := time.Date(2030, 1, 7, 1, 0, 0, 0, time.UTC)
+	body := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:event-1@example.com",
+		"DTSTART:20300107T100000",
+		"DTEND:20300107T110000",
+		"SUMMARY:定例会議",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	report := &service.ImportReport{Results: []service.ImportResult{
+		{UID: "event-1@example.com", Outcome: service.ImportCreated, ReservationID: "r-1", ResourceID: "room-a", StartTime: start, EndTime: start.Add(time.Hour)},
+		{UID: "event-2@example.com", Outcome: service.ImportRejected, Reason: "missing DTSTART"},
+	}}
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		err        error
+		wantStatus int
+		wantStart  time.Time
+		wantDryRun bool
+	}{
+		{name: "既定のタイムゾーンで取り込む", body: body, wantStatus: http.StatusOK, wantStart: start},
+		{name: "タイムゾーンを指定してドライラン", query: "?dryRun=true&timeZone=UTC&resourceId=room-a", body: body, wantStatus: http.StatusOK, wantStart: start.Add(9 * time.Hour), wantDryRun: true},
+		{name: "未知のタイムゾーン", query: "?timeZone=Mars/Olympus", body: body, wantStatus: http.StatusBadRequest},
+		{name: "dryRun の形式が不正", query: "?dryRun=maybe", body: body, wantStatus: http.StatusBadRequest},
+		{name: "iCalendar ではない本文", body: "hello", wantStatus: http.StatusBadRequest},
+		{name: "大きすぎる本文", body: "BEGIN:VCALENDAR\r\n" + strings.Repeat("X-PAD:1\r\n", maxImportBytes/9+1), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "イベントが多すぎる", body: body, err: service.ErrImportTooLarge, wantStatus: http.StatusBadRequest},
+		{name: "権限がない", body: body, err: service.ErrForbidden, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockReservationService{
+				importReservationsFunc: func(params service.ImportReservationsParams) (*service.ImportReport, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					if len(params.Events) != 1 || !params.Events[0].Start.Equal(tt.wantStart) {
+						t.Errorf("Expected 1 event starting at %v, got %+v", tt.wantStart, params.Events)
+					}
+					if params.DryRun != tt.wantDryRun {
+						t.Errorf("Expected dry run %v, got %v", tt.wantDryRun, params.DryRun)
+					}
+					return report, nil
+				},
+			}
+			NewReservationHandler(mockSvc).RegisterRoutes(e)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/reservations/import"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, "text/calendar")
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got importResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if got.Created != 1 || got.Skipped != 0 || got.Rejected != 1 || len(got.Results) != 2 {
+				t.Errorf("Unexpected counts: %+v", got)
+			}
+			if got.Results[0].ReservationID != "r-1" || got.Results[0].StartTime == nil || got.Results[1].StartTime != nil || got.Results[1].Reason != "missing DTSTART" {
+				t.Errorf("Unexpected results: %+v", got.Results)
+			}
+		})
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	start := time.Date(2030, 1, 7, 1, 0, 0, 0, time.UTC)
+	reservation := model.NewReservation("room-a", start, start.Add(time.Hour))
+	reservation.Title = "=HYPERLINK(\"http://example.com\")"
+	reservation.Attendees = []string{"a@example.com", "b@example.com"}
+
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		empty      bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "既定の列を書き出す",
+			wantStatus: http.StatusOK,
+			wantBody: "\ufeffid,resourceName,title,startTime,endTime,status,attendees\r\n" +
+				reservation.ID + `,会議室A,"'=HYPERLINK(""http://example.com"")",2030-01-07 10:00,2030-01-07 11:00,confirmed,a@example.com;b@example.com` + "\r\n",
+		},
+		{
+			name:       "列とタイムゾーンを指定する",
+			query:      "?columns=resourceId,startTime,version&timeZone=UTC",
+			wantStatus: http.StatusOK,
+			wantBody:   "\ufeffresourceId,startTime,version\r\nroom-a,2030-01-07 01:00,1\r\n",
+		},
+		{name: "予約がない場合はヘッダー行だけ", query: "?columns=id,title", empty: true, wantStatus: http.StatusOK, wantBody: "\ufeffid,title\r\n"},
+		{name: "未知の列", query: "?columns=id,secret", wantStatus: http.StatusBadRequest},
+		{name: "未知のタイムゾーン", query: "?timeZone=Mars/Olympus", wantStatus: http.StatusBadRequest},
+		{name: "期間が不正", err: service.ErrInvalidWindow, wantStatus: http.StatusBadRequest},
+		{name: "権限がない", err: service.ErrForbidden, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockReservationService{
+				streamReservationsFunc: func(params service.ExportReservationsParams, fn func(reservation *model.Reservation, resourceName string) error) error {
+					if tt.err != nil || tt.empty {
+						return tt.err
+					}
+					return fn(reservation, "会議室A")
+				},
+			}
+			NewReservationHandler(mockSvc).RegisterRoutes(e)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/reservations/export.csv"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get(echo.HeaderContentType); got != csvContentType {
+				t.Errorf("Expected content type %q, got %q", csvContentType, got)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("Expected body %q, got %q", tt.wantBody, got)
+			}
+		})
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	body := "\ufeffid,resourceName,title,startTime,endTime,status,attendees\r\n" +
+		",会議室A,'=1+1,2030-01-07 10:00,2030-01-07 11:00,pending,a@example.com;b@example.com\r\n" +
+		"r-2,会議室A,定例会議,2030/1/7 12:00,2030/1/7 13:00,,\r\n" +
+		",会議室A,開始が不正,tomorrow,2030-01-07 13:00,,\r\n" +
+		",会議室A,状態が不正,2030-01-07 14:00,2030-01-07 15:00,done,\r\n" +
+		",会議室A,重なる,2030-01-07 10:30,2030-01-07 11:30,,\r\n" +
+		",会議室A,参加者が不正,2030-01-07 16:00,2030-01-07 17:00,,a@example.com;not-an-email\r\n"
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		err        error
+		wantStatus int
+		wantStart  time.Time
+		wantDryRun bool
+	}{
+		{name: "既定のタイムゾーンで取り込む", body: body, wantStatus: http.StatusOK, wantStart: time.Date(2030, 1, 7, 1, 0, 0, 0, time.UTC)},
+		{name: "タイムゾーンを指定してドライラン", query: "?dryRun=true&timeZone=UTC", body: body, wantStatus: http.StatusOK, wantStart: time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC), wantDryRun: true},
+		{name: "必須の列がない", body: "resourceName,startTime\r\n会議室A,2030-01-07 10:00\r\n", wantStatus: http.StatusBadRequest},
+		{name: "リソースの列がない", body: "startTime,endTime\r\n", wantStatus: http.StatusBadRequest},
+		{name: "重複する列", body: "startTime,endTime,resourceId,endTime\r\n", wantStatus: http.StatusBadRequest},
+		{name: "空の本文", body: "", wantStatus: http.StatusBadRequest},
+		{name: "UTF-8 ではない本文", body: "\x83\x8a\x83\x5c\x81\x5b\x83\x58,startTime,endTime\r\n", wantStatus: http.StatusBadRequest},
+		{name: "大きすぎる本文", body: "startTime,endTime,resourceId\r\n" + strings.Repeat("x", maxCSVImportBytes), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "存在しないリソース", query: "?resourceId=unknown", body: body, err: service.ErrResourceNotFound, wantStatus: http.StatusBadRequest},
+		{name: "権限がない", body: body, err: service.ErrForbidden, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備: 重なる行だけを拒否するサービス
+			e := echo.New()
+			var entries []service.ImportEntry
+			mockSvc := &mockReservationService{
+				importEntriesFunc: func(params service.ImportEntriesParams, next func() (service.ImportEntry, error), fn func(result service.ImportResult)) error {
+					if tt.err != nil {
+						return tt.err
+					}
+					if params.DryRun != tt.wantDryRun {
+						t.Errorf("Expected dry run %v, got %v", tt.wantDryRun, params.DryRun)
+					}
+					for {
+						entry, err := next()
+						if errors.Is(err, io.EOF) {
+							return nil
+						}
+						if err != nil {
+							return err
+						}
+						entries = append(entries, entry)
+						if entry.Details.Title == "重なる" {
+							fn(service.ImportResult{Outcome: service.ImportRejected, Reason: "reservation conflicts"})
+							continue
+						}
+						fn(service.ImportResult{Outcome: service.ImportCreated})
+					}
+				},
+			}
+			NewReservationHandler(mockSvc).RegisterRoutes(e)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/reservations/import.csv"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, "text/csv")
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got csvImportResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			wantErrors := []csvImportError{
+				{Row: 4, Column: "startTime", Message: `invalid time "tomorrow"`},
+				{Row: 5, Column: "status", Message: `invalid status "done"`},
+				{Row: 6, Message: "reservation conflicts"},
+				{Row: 7, Column: "attendees", Message: `attendees invalid email address "not-an-email"`},
+			}
+			if got.DryRun != tt.wantDryRun || got.Created != 2 || got.Rejected != 4 || !reflect.DeepEqual(got.Errors, wantErrors) {
+				t.Errorf("Unexpected response: %+v", got)
+			}
+			if len(entries) != 3 {
+				t.Fatalf("Expected 3 entries, got %+v", entries)
+			}
+			first, second := entries[0], entries[1]
+			if !first.StartTime.Equal(tt.wantStart) || first.Location != "会議室A" || first.Details.Title != "=1+1" || first.Status != model.StatusPending ||
+				!reflect.DeepEqual(first.Details.Attendees, []string{"a@example.com", "b@example.com"}) {
+				t.Errorf("Unexpected first entry: %+v", first)
+			}
+			if second.UID != "r-2@yoyaku" || second.EndTime.Sub(second.StartTime) != time.Hour {
+				t.Errorf("Unexpected second entry: %+v", second)
+			}
+		})
+	}
+}
+
+func TestDeleteReservation(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// テスト用ID
+	testID := "test-id"
+
+	// モックサービスの準備
+	mockSvc := &mockReservationService{
+		deleteReservationFunc: func(id string, params service.DeleteReservationParams) error {
+			if id != testID {
+				t.Errorf("Expected ID %s, got %s", testID, id)
+			}
+			return nil
+		},
+	}
+
+	// ハンドラーの作成
+	h := NewReservationHandler(mockSvc)
+
+	// リクエストの準備
+	req := httptest.NewRequest(http.MethodDelete, "/api/reservations/"+testID, nil)
+	req.Header.Set("If-Match", `"1"`)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(testID)
+
+	// ハンドラーを実行
+	if err := h.DeleteReservation(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// レスポンスの検証
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, rec.Code)
+	}
+}
+
+func TestDeleteReservation_Scope(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantScope  service.Scope
+		wantStatus int
+	}{
+		{name: "省略時はこの回のみ", query: "", wantScope: service.ScopeThis, wantStatus: http.StatusNoContent},
+		{name: "以降の回", query: "?scope=following", wantScope: service.ScopeFollowing, wantStatus: http.StatusNoContent},
+		{name: "全ての回", query: "?scope=all", wantScope: service.ScopeAll, wantStatus: http.StatusNoContent},
+		{name: "不正な範囲", query: "?scope=others", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			var received service.Scope
+			mockSvc := &mockReservationService{
+				deleteReservationFunc: func(id string, params service.DeleteReservationParams) error {
+					received = params.Scope
+					return nil
+				},
+			}
+			h := NewReservationHandler(mockSvc)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/reservations/test-id"+tt.query, nil)
+			req.Header.Set("If-Match", `"1"`)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("test-id")
+
+			// 実行
+			if err := h.DeleteReservation(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if received != tt.wantScope {
+				t.Errorf("Expected scope %q, got %q", tt.wantScope, received)
+			}
+		})
+	}
+}
+
+func TestDeleteReservation_Forbidden(t *testing.T) {
+	// 準備
+	e := echo.New()
+	mockSvc := &mockReservationService{
+		deleteReservationFunc: func(id string, params service.DeleteReservationParams) error {
+			return service.ErrForbidden
+		},
+	}
+	h := NewReservationHandler(mockSvc)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/reservations/test-id", nil)
+	req.Header.Set("If-Match", `"1"`)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("test-id")
+
+	// 実行
+	if err := h.DeleteReservation(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, rec.Code)
+	}
+}
+
+func TestPatchReservation_Scope(t *testing.T) {
+	// 準備
+	e := echo.New()
+	var received service.UpdateReservationParams
+	mockSvc := &mockReservationService{
+		updateReservationFunc: func(id string, params service.UpdateReservationParams) (*model.Reservation, error) {
+			received = params
+			return model.NewReservation("resource-1", *params.StartTime, time.Now().Add(time.Hour)), nil
+		},
+	}
+	h := NewReservationHandler(mockSvc)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/reservations/test-id?scope=following", strings.NewReader(`{"startTime": "2024-04-02T10:30:00Z"}`))
+	req.Header.Set("If-Match", `"1"`)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("test-id")
+
+	// 実行
+	if err := h.PatchReservation(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if received.Scope != service.ScopeFollowing {
+		t.Errorf("Expected scope %q, got %q", service.ScopeFollowing, received.Scope)
+	}
+}
+
+func TestGetSeries(t *testing.T) {
+	// 準備
+	start := time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+	series := model.NewReservationSeries("resource-1", "FREQ=WEEKLY;COUNT=2", start, start.Add(time.Hour))
+	first := series.NewOccurrence(start)
+	cancelled := model.NewSeriesException(series.ID, start.AddDate(0, 0, 7), model.ExceptionCancelled, "reservation-2")
+	mockSvc := &mockReservationService{
+		getSeriesFunc: func(id string) (*service.SeriesDetail, error) {
+			if id != series.ID {
+				return nil, service.ErrSeriesNotFound
+			}
+			return &service.SeriesDetail{
+				Series:       series,
+				Reservations: []*model.Reservation{first},
+				Exceptions:   []*model.SeriesException{cancelled},
+			}, nil
+		},
+	}
+	h := NewReservationHandler(mockSvc)
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{name: "存在するシリーズ", id: series.ID, wantStatus: http.StatusOK},
+		{name: "存在しないシリーズ", id: "unknown", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/reservation-series/"+tt.id, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			// 実行
+			if err := h.GetSeries(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var response seriesResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if len(response.Reservations) != 1 || len(response.Exceptions) != 1 || response.Exceptions[0].Type != model.ExceptionCancelled {
+				t.Errorf("Unexpected response: %+v", response)
+			}
+		})
+	}
+}
+
+func TestDeleteReservation_EmptyID(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// ハンドラーの作成
+	h := NewReservationHandler(nil) // サービスは使用しないのでnilでOK
+
+	// リクエストの準備 - 空のID
+	req := httptest.NewRequest(http.MethodDelete, "/api/reservations/", nil)
+	req.Header.Set("If-Match", `"1"`)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("") // 空のID
+
+	// ハンドラーを実行
+	if err := h.DeleteReservation(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// レスポンスの検証
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestDeleteReservation_ServiceError(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// テスト用ID
+	testID := "test-id"
+
+	// モックサービスの準備 - エラーを返す
+	mockSvc := &mockReservationService{
+		deleteReservationFunc: func(id string, params service.DeleteReservationParams) error {
+			return errors.New("service error")
+		},
+	}
+
+	// ハンドラーの作成
+	h := NewReservationHandler(mockSvc)
+
+	// リクエストの準備
+	req := httptest.NewRequest(http.MethodDelete, "/api/reservations/"+testID, nil)
+	req.Header.Set("If-Match", `"1"`)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(testID)
+
+	// ハンドラーを実行
+	if err := h.DeleteReservation(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// レスポンスの検証
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+}
+
+func TestDeleteReservation_Reason(t *testing.T) {
+	tests := []struct {
+		name       string
+		reason     string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "理由つきの取り消し", reason: "会議が中止になった", wantStatus: http.StatusNoContent},
+		{name: "長すぎる理由", reason: strings.Repeat("あ", 501), serviceErr: &service.InvalidDetailsError{Field: "reason", Reason: "must be at most 500 characters"}, wantStatus: http.StatusBadRequest},
+		{name: "完了した予約", serviceErr: fmt.Errorf("%w: completed to cancelled", service.ErrInvalidStatusTransition), wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			var received service.DeleteReservationParams
+			mockSvc := &mockReservationService{
+				deleteReservationFunc: func(id string, params service.DeleteReservationParams) error {
+					received = params
+					return tt.serviceErr
+				},
+			}
+			h := NewReservationHandler(mockSvc)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/reservations/test-id?reason="+url.QueryEscape(tt.reason), nil)
+			req.Header.Set("If-Match", `"1"`)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("test-id")
+
+			// 実行
+			if err := h.DeleteReservation(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusNoContent && received.Reason != tt.reason {
+				t.Errorf("Expected reason %q, got %q", tt.reason, received.Reason)
+			}
+		})
+	}
+}
+
+func TestChangeStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "完了にする", body: `{"status": "completed"}`, wantStatus: http.StatusOK},
+		{name: "状態の指定なし", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "未定義の状態", body: `{"status": "deleted"}`, serviceErr: service.ErrInvalidStatus, wantStatus: http.StatusBadRequest},
+		{name: "許可されていない遷移", body: `{"status": "confirmed"}`, serviceErr: fmt.Errorf("%w: cancelled to confirmed", service.ErrInvalidStatusTransition), wantStatus: http.StatusConflict},
+		{name: "存在しない予約", body: `{"status": "completed"}`, serviceErr: service.ErrReservationNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockReservationService{
+				changeStatusFunc: func(id string, params service.ChangeStatusParams) (*model.Reservation, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					reservation := model.NewReservation("resource-1", time.Now(), time.Now().Add(time.Hour))
+					reservation.Status = params.Status
+					return reservation, nil
+				},
+			}
+			h := NewReservationHandler(mockSvc)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/reservations/test-id/status", strings.NewReader(tt.body))
+			req.Header.Set("If-Match", `"1"`)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("test-id")
+
+			// 実行
+			if err := h.ChangeStatus(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestGetAllReservations_Status(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		wantStatuses []model.ReservationStatus
+		wantStatus   int
+	}{
+		{name: "指定なし", query: "", wantStatus: http.StatusOK},
+		{name: "複数指定", query: "?status=cancelled,no_show", wantStatuses: []model.ReservationStatus{model.StatusCancelled, model.StatusNoShow}, wantStatus: http.StatusOK},
+		{name: "未定義の状態", query: "?status=deleted", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			var received service.ListReservationsParams
+			mockSvc := &mockReservationService{
+				getAllReservationsFunc: func(params service.ListReservationsParams) (*service.ReservationPage, error) {
+					received = params
+					return &service.ReservationPage{Reservations: []*model.Reservation{}}, nil
+				},
+			}
+			h := NewReservationHandler(mockSvc)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/reservations"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// 実行
+			if err := h.GetAllReservations(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if !slices.Equal(received.Statuses, tt.wantStatuses) {
+				t.Errorf("Expected statuses %v, got %v", tt.wantStatuses, received.Statuses)
+			}
+		})
+	}
+}
+
+func TestGetAllReservations_Query(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		want       service.ListReservationsParams
+		wantStatus int
+	}{
+		{name: "指定なし", query: "", wantStatus: http.StatusOK},
+		{
+			name:       "すべて指定",
+			query:      "?createdAfter=2024-04-01T00:00:00Z&minDuration=30m&maxDuration=2h&sort=createdAt&order=desc&limit=20&cursor=abc",
+			want:       service.ListReservationsParams{CreatedAfter: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), MinDuration: 30 * time.Minute, MaxDuration: 2 * time.Hour, SortBy: "createdAt", Descending: true, Limit: 20, Cursor: "abc"},
+			wantStatus: http.StatusOK,
+		},
+		{name: "昇順", query: "?order=asc", wantStatus: http.StatusOK},
+		{name: "createdAfterの形式が不正", query: "?createdAfter=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "minDurationの形式が不正", query: "?minDuration=30", wantStatus: http.StatusBadRequest},
+		{name: "maxDurationの形式が不正", query: "?maxDuration=long", wantStatus: http.StatusBadRequest},
+		{name: "未定義の順序", query: "?order=random", wantStatus: http.StatusBadRequest},
+		{name: "limitの形式が不正", query: "?limit=ten", wantStatus: http.StatusBadRequest},
+		{name: "limitが0", query: "?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "limitが多すぎる", query: "?limit=1000", want: service.ListReservationsParams{Limit: 1000}, err: service.ErrInvalidLimit, wantStatus: http.StatusBadRequest},
+		{name: "未定義の並べ替え", query: "?sort=title", want: service.ListReservationsParams{SortBy: "title"}, err: service.ErrInvalidSort, wantStatus: http.StatusBadRequest},
+		{name: "不正なカーソル", query: "?cursor=abc", want: service.ListReservationsParams{Cursor: "abc"}, err: service.ErrInvalidCursor, wantStatus: http.StatusBadRequest},
+		{name: "長さの範囲が不正", query: "?minDuration=2h&maxDuration=1h", want: service.ListReservationsParams{MinDuration: 2 * time.Hour, MaxDuration: time.Hour}, err: service.ErrInvalidDuration, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			var received *service.ListReservationsParams
+			mockSvc := &mockReservationService{
+				getAllReservationsFunc: func(params service.ListReservationsParams) (*service.ReservationPage, error) {
+					received = &params
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &service.ReservationPage{Reservations: []*model.Reservation{}}, nil
+				},
+			}
+			h := NewReservationHandler(mockSvc)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/reservations"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// 実行
+			if err := h.GetAllReservations(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusOK || tt.err != nil {
+				if received == nil {
+					t.Fatal("Expected service to be called")
+				}
+				if !reflect.DeepEqual(*received, tt.want) {
+					t.Errorf("Expected params %+v, got %+v", tt.want, *received)
+				}
+			} else if received != nil {
+				t.Error("Expected service not to be called")
+			}
+		})
+	}
+}
+
+func TestGetAllReservations_NextPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		nextCursor string
+		wantLink   string
+	}{
+		{name: "続きがある", nextCursor: "next-1", wantLink: `</api/reservations?cursor=next-1&limit=2&resourceId=room-a>; rel="next"`},
+		{name: "最後のページ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockReservationService{
+				getAllReservationsFunc: func(params service.ListReservationsParams) (*service.ReservationPage, error) {
+					return &service.ReservationPage{Reservations: []*model.Reservation{}, NextCursor: tt.nextCursor}, nil
+				},
+			}
+			h := NewReservationHandler(mockSvc)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/reservations?resourceId=room-a&limit=2&cursor=prev", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// 実行
+			if err := h.GetAllReservations(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+			}
+			if got := rec.Header().Get("X-Next-Cursor"); got != tt.nextCursor {
+				t.Errorf("Expected X-Next-Cursor %q, got %q", tt.nextCursor, got)
+			}
+			if got := rec.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("Expected Link %q, got %q", tt.wantLink, got)
+			}
+		})
+	}
+}
+
+func TestReservationPreconditions(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifMatch     string
+		err         error
+		wantVersion int64
+		wantStatus  int
+		wantCalled  bool
+	}{
+		{name: "版を指定", ifMatch: `"3"`, wantVersion: 3, wantStatus: http.StatusOK, wantCalled: true},
+		{name: "任意の版", ifMatch: "*", wantStatus: http.StatusOK, wantCalled: true},
+		{name: "If-Matchなし", wantStatus: http.StatusPreconditionRequired},
+		{name: "弱いETag", ifMatch: `W/"3"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "版でないETag", ifMatch: `"abc"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "版が一致しない", ifMatch: `"2"`, err: service.ErrVersionConflict, wantVersion: 2, wantStatus: http.StatusPreconditionFailed, wantCalled: true},
+	}
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+		okCode int
+	}{
+		{method: http.MethodPut, path: "/api/reservations/test-id", body: `{"resourceId": "room-a", "startTime": "2024-04-01T10:00:00Z", "endTime": "2024-04-01T11:00:00Z"}`, okCode: http.StatusOK},
+		{method: http.MethodPatch, path: "/api/reservations/test-id", body: `{"title": "定例会議"}`, okCode: http.StatusOK},
+		{method: http.MethodPut, path: "/api/reservations/test-id/status", body: `{"status": "completed"}`, okCode: http.StatusOK},
+		{method: http.MethodDelete, path: "/api/reservations/test-id", okCode: http.StatusNoContent},
+	}
+
+	for _, r := range requests {
+		for _, tt := range tests {
+			t.Run(r.method+" "+r.path+" "+tt.name, func(t *testing.T) {
+				// 準備 - 受け取った版を記録する
+				e := echo.New()
+				reservation := model.NewReservation("room-a", time.Now(), time.Now().Add(time.Hour))
+				reservation.Version = 4
+				var called bool
+				var received int64
+				mockSvc := &mockReservationService{
+					updateReservationFunc: func(id string, params service.UpdateReservationParams) (*model.Reservation, error) {
+						called, received = true, params.Version
+						return reservation, tt.err
+					},
+					changeStatusFunc: func(id string, params service.ChangeStatusParams) (*model.Reservation, error) {
+						called, received = true, params.Version
+						return reservation, tt.err
+					},
+					deleteReservationFunc: func(id string, params service.DeleteReservationParams) error {
+						called, received = true, params.Version
+						return tt.err
+					},
+				}
+				h := NewReservationHandler(mockSvc)
+				h.RegisterRoutes(e)
+
+				req := httptest.NewRequest(r.method, r.path, strings.NewReader(r.body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				if tt.ifMatch != "" {
+					req.Header.Set("If-Match", tt.ifMatch)
+				}
+				rec := httptest.NewRecorder()
+
+				// 実行
+				e.ServeHTTP(rec, req)
+
+				// 検証
+				wantStatus := tt.wantStatus
+				if wantStatus == http.StatusOK {
+					wantStatus = r.okCode
+				}
+				if rec.Code != wantStatus {
+					t.Fatalf("Expected status code %d, got %d: %s", wantStatus, rec.Code, rec.Body.String())
+				}
+				if called != tt.wantCalled {
+					t.Fatalf("Expected service called = %v, got %v", tt.wantCalled, called)
+				}
+				if called && received != tt.wantVersion {
+					t.Errorf("Expected version %d, got %d", tt.wantVersion, received)
+				}
+				if wantStatus == http.StatusOK && rec.Header().Get("ETag") != `"4"` {
+					t.Errorf("Expected ETag %q, got %q", `"4"`, rec.Header().Get("ETag"))
+				}
+			})
+		}
+	}
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// モックサービスの準備
+	mockSvc := &mockReservationService{}
+
+	// ハンドラーの作成
+	h := NewReservationHandler(mockSvc)
+
+	// ルート登録
+	h.RegisterRoutes(e)
+
+	// 登録されたルートを検証
+	routes := e.Routes()
+
+	// 期待するルート
+	expectedRoutes := []struct {
+		path   string
+		method string
+	}{
+		{"/api/reservations", "POST"},
+		{"/api/reservations", "GET"},
+		{"/api/reservations.ics", "GET"},
+		{"/api/reservations/import", "POST"},
+		{"/api/reservations/export.csv", "GET"},
+		{"/api/reservations/import.csv", "POST"},
+		{"/api/reservations/:id", "GET"},
+		{"/api/reservations/:id", "PUT"},
+		{"/api/reservations/:id", "PATCH"},
+		{"/api/reservations/:id", "DELETE"},
+		{"/api/reservations/:id/status", "PUT"},
+		{"/api/reservation-series/:id", "GET"},
+	}
+
+	// ルートが正しく登録されているか確認
+	routeFound := make(map[string]bool)
+	for _, er := range expectedRoutes {
+		for _, route := range routes {
+			if route.Path == er.path && route.Method == er.method {
+				routeFound[er.path+":"+er.method] = true
+				break
+			}
+		}
+	}
+
+	// 全てのルートが見つかったか確認
+	for _, er := range expectedRoutes {
 		key := er.path + ":" + er.method
 		if !routeFound[key] {
 			t.Errorf("Expected route %s %s to be registered, but it was not found", er.method, er.path)
 		}
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// ResourceServiceInterface はテスト時にモック可能なインターフェース
+type ResourceServiceInterface interface {
+	CreateResource(ctx context.Context, params service.ResourceParams) (*model.Resource, error)
+	GetAllResources() ([]*model.Resource, error)
+	GetResource(id string) (*model.Resource, error)
+	UpdateResource(ctx context.Context, id string, params service.ResourceParams) (*model.Resource, error)
+	DeleteResource(ctx context.Context, id string) error
+}
+
+type ResourceHandler struct {
+	service  ResourceServiceInterface
+	validate *validator.Validate
+}
+
+func NewResourceHandler(service ResourceServiceInterface) *ResourceHandler {
+	return &ResourceHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+type resourceRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+	Capacity    int    `json:"capacity" validate:"min=0"`
+	// Active を省略した場合は有効として扱う
+	Active *bool `json:"active"`
+}
+
+func (r *resourceRequest) params() service.ResourceParams {
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+	return service.ResourceParams{
+		Name:        r.Name,
+		Description: r.Description,
+		Capacity:    r.Capacity,
+		Active:      active,
+	}
+}
+
+func (h *ResourceHandler) RegisterRoutes(e *echo.Echo) {
+	e.POST("/api/resources", h.CreateResource)
+	e.GET("/api/resources", h.GetAllResources)
+	e.GET("/api/resources/:id", h.GetResource)
+	e.PUT("/api/resources/:id", h.UpdateResource)
+	e.DELETE("/api/resources/:id", h.DeleteResource)
+}
+
+func (h *ResourceHandler) CreateResource(c echo.Context) error {
+	req := new(resourceRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	resource, err := h.service.CreateResource(c.Request().Context(), req.params())
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can change resources"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create resource"})
+	}
+
+	return c.JSON(http.StatusCreated, resource)
+}
+
+func (h *ResourceHandler) GetAllResources(c echo.Context) error {
+	resources, err := h.service.GetAllResources()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get resources"})
+	}
+
+	return c.JSON(http.StatusOK, resources)
+}
+
+func (h *ResourceHandler) GetResource(c echo.Context) error {
+	resource, err := h.service.GetResource(c.Param("id"))
+	if errors.Is(err, service.ErrResourceNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Resource not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get resource"})
+	}
+
+	return c.JSON(http.StatusOK, resource)
+}
+
+func (h *ResourceHandler) UpdateResource(c echo.Context) error {
+	req := new(resourceRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	resource, err := h.service.UpdateResource(c.Request().Context(), c.Param("id"), req.params())
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can change resources"})
+	}
+	if errors.Is(err, service.ErrResourceNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Resource not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update resource"})
+	}
+
+	return c.JSON(http.StatusOK, resource)
+}
+
+func (h *ResourceHandler) DeleteResource(c echo.Context) error {
+	err := h.service.DeleteResource(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can change resources"})
+	}
+	if errors.Is(err, service.ErrResourceNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Resource not found"})
+	}
+	if errors.Is(err, service.ErrResourceInUse) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Resource has reservations; deactivate it instead"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete resource"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// モックリソースサービスの実装
+type mockResourceService struct {
+	createResourceFunc  func(params service.ResourceParams) (*model.Resource, error)
+	getAllResourcesFunc func() ([]*model.Resource, error)
+	getResourceFunc     func(id string) (*model.Resource, error)
+	updateResourceFunc  func(id string, params service.ResourceParams) (*model.Resource, error)
+	deleteResourceFunc  func(id string) error
+}
+
+func (m *mockResourceService) CreateResource(ctx context.Context, params service.ResourceParams) (*model.Resource, error) {
+	return m.createResourceFunc(params)
+}
+
+func (m *mockResourceService) GetAllResources() ([]*model.Resource, error) {
+	return m.getAllResourcesFunc()
+}
+
+func (m *mockResourceService) GetResource(id string) (*model.Resource, error) {
+	return m.getResourceFunc(id)
+}
+
+func (m *mockResourceService) UpdateResource(ctx context.Context, id string, params service.ResourceParams) (*model.Resource, error) {
+	return m.updateResourceFunc(id, params)
+}
+
+func (m *mockResourceService) DeleteResource(ctx context.Context, id string) error {
+	return m.deleteResourceFunc(id)
+}
+
+func TestCreateResource(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// モックサービスの準備
+	var received service.ResourceParams
+	mockSvc := &mockResourceService{
+		createResourceFunc: func(params service.ResourceParams) (*model.Resource, error) {
+			received = params
+			return model.NewResource(params.Name, params.Description, params.Capacity, params.Active), nil
+		},
+	}
+
+	// ハンドラーの作成
+	h := NewResourceHandler(mockSvc)
+
+	// リクエストの準備（activeを省略）
+	requestBody := `{"name": "会議室A", "description": "プロジェクター付き", "capacity": 8}`
+	req := httptest.NewRequest(http.MethodPost, "/api/resources", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// ハンドラーを実行
+	if err := h.CreateResource(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// レスポンスの検証
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status code %d, got %d", http.StatusCreated, rec.Code)
+	}
+	if !received.Active {
+		t.Error("Expected resource to be active when active is omitted")
+	}
+
+	var response model.Resource
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Name != "会議室A" || response.Capacity != 8 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestCreateResource_ValidationError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"名前がない", `{"capacity": 8}`},
+		{"定員が負の値", `{"name": "会議室A", "capacity": -1}`},
+		{"不正なJSON", `{"name": `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Echoのインスタンスを作成
+			e := echo.New()
+
+			// ハンドラーの作成
+			h := NewResourceHandler(nil) // サービスは使用しないのでnilでOK
+
+			// リクエストの準備
+			req := httptest.NewRequest(http.MethodPost, "/api/resources", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// ハンドラーを実行
+			if err := h.CreateResource(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// レスポンスの検証
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+		})
+	}
+}
+
+func TestGetResource_NotFound(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// モックサービスの準備
+	mockSvc := &mockResourceService{
+		getResourceFunc: func(id string) (*model.Resource, error) {
+			return nil, service.ErrResourceNotFound
+		},
+	}
+
+	// ハンドラーの作成
+	h := NewResourceHandler(mockSvc)
+
+	// リクエストの準備
+	req := httptest.NewRequest(http.MethodGet, "/api/resources/missing", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("missing")
+
+	// ハンドラーを実行
+	if err := h.GetResource(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// レスポンスの検証
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestUpdateResource(t *testing.T) {
+	// Echoのインスタンスを作成
+	e := echo.New()
+
+	// モックサービスの準備
+	var receivedID string
+	var received service.ResourceParams
+	mockSvc := &mockResourceService{
+		updateResourceFunc: func(id string, params service.ResourceParams) (*model.Resource, error) {
+			receivedID = id
+			received = params
+			return model.NewResource(params.Name, params.Description, params.Capacity, params.Active), nil
+		},
+	}
+
+	// ハンドラーの作成
+	h := NewResourceHandler(mockSvc)
+
+	// リクエストの準備
+	requestBody := `{"name": "会議室A", "capacity": 8, "active": false}`
+	req := httptest.NewRequest(http.MethodPut, "/api/resources/room-a", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("room-a")
+
+	// ハンドラーを実行
+	if err := h.UpdateResource(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// レスポンスの検証
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if receivedID != "room-a" {
+		t.Errorf("Expected ID room-a, got %s", receivedID)
+	}
+	if received.Active {
+		t.Error("Expected resource to be deactivated")
+	}
+}
+
+func TestDeleteResource(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"削除成功", nil, http.StatusNoContent},
+		{"存在しないリソース", service.ErrResourceNotFound, http.StatusNotFound},
+		{"予約が残っているリソース", service.ErrResourceInUse, http.StatusConflict},
+		{"サービスエラー", errors.New("service error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Echoのインスタンスを作成
+			e := echo.New()
+
+			// モックサービスの準備
+			mockSvc := &mockResourceService{
+				deleteResourceFunc: func(id string) error {
+					return tt.err
+				},
+			}
+
+			// ハンドラーの作成
+			h := NewResourceHandler(mockSvc)
+
+			// リクエストの準備
+			req := httptest.NewRequest(http.MethodDelete, "/api/resources/room-a", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("room-a")
+
+			// ハンドラーを実行
+			if err := h.DeleteResource(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// レスポンスの検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// UserServiceInterface はテスト時にモック可能なインターフェース
+type UserServiceInterface interface {
+	CreateUser(ctx context.Context, params service.CreateUserParams) (*model.User, error)
+	GetAllUsers(ctx context.Context) ([]*model.User, error)
+	GetUser(ctx context.Context, id string) (*model.User, error)
+}
+
+type UserHandler struct {
+	service  UserServiceInterface
+	validate *validator.Validate
+}
+
+func NewUserHandler(service UserServiceInterface) *UserHandler {
+	return &UserHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+type userRequest struct {
+	DisplayName string `json:"displayName" validate:"required,max=100"`
+	Email       string `json:"email" validate:"required,email,max=254"`
+	// Role を省略した場合は member として扱う
+	Role string `json:"role"`
+}
+
+// RegisterRoutes はユーザーのルートを登録する。middleware は全てのルートに適用する（例: auth.RequireAuth）。
+func (h *UserHandler) RegisterRoutes(e *echo.Echo, middleware ...echo.MiddlewareFunc) {
+	e.POST("/api/users", h.CreateUser, middleware...)
+	e.GET("/api/users", h.GetAllUsers, middleware...)
+	e.GET("/api/users/:id", h.GetUser, middleware...)
+}
+
+func (h *UserHandler) CreateUser(c echo.Context) error {
+	req := new(userRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	user, err := h.service.CreateUser(c.Request().Context(), service.CreateUserParams{
+		DisplayName: req.DisplayName,
+		Email:       req.Email,
+		Role:        model.UserRole(req.Role),
+	})
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusCreated, user)
+	case errors.Is(err, service.ErrInvalidRole):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid role"})
+	case errors.Is(err, service.ErrEmailTaken):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Email is already registered"})
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can create users other than self-registered members"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create user"})
+	}
+}
+
+func (h *UserHandler) GetAllUsers(c echo.Context) error {
+	users, err := h.service.GetAllUsers(c.Request().Context())
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can list users"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get users"})
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *UserHandler) GetUser(c echo.Context) error {
+	user, err := h.service.GetUser(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "You do not have permission to view this user"})
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get user"})
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
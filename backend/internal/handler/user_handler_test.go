@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// モックユーザーサービスの実装
+type mockUserService struct {
+	createUserFunc  func(params service.CreateUserParams) (*model.User, error)
+	getAllUsersFunc func() ([]*model.User, error)
+	getUserFunc     func(id string) (*model.User, error)
+}
+
+func (m *mockUserService) CreateUser(ctx context.Context, params service.CreateUserParams) (*model.User, error) {
+	return m.createUserFunc(params)
+}
+
+func (m *mockUserService) GetAllUsers(ctx context.Context) ([]*model.User, error) {
+	return m.getAllUsersFunc()
+}
+
+func (m *mockUserService) GetUser(ctx context.Context, id string) (*model.User, error) {
+	return m.getUserFunc(id)
+}
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "作成できる", body: `{"displayName": "山田太郎", "email": "taro@example.com"}`, wantStatus: http.StatusCreated},
+		{name: "メールアドレスの形式が不正", body: `{"displayName": "山田太郎", "email": "taro"}`, wantStatus: http.StatusBadRequest},
+		{name: "表示名がない", body: `{"email": "taro@example.com"}`, wantStatus: http.StatusBadRequest},
+		{name: "未定義の権限", body: `{"displayName": "山田太郎", "email": "taro@example.com", "role": "owner"}`, serviceErr: service.ErrInvalidRole, wantStatus: http.StatusBadRequest},
+		{name: "メールアドレスの重複", body: `{"displayName": "山田太郎", "email": "taro@example.com"}`, serviceErr: service.ErrEmailTaken, wantStatus: http.StatusConflict},
+		{name: "管理者を作成する権限がない", body: `{"displayName": "山田太郎", "email": "taro@example.com", "role": "admin"}`, serviceErr: service.ErrForbidden, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockUserService{
+				createUserFunc: func(params service.CreateUserParams) (*model.User, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					return model.NewUser(params.DisplayName, params.Email, model.RoleMember), nil
+				},
+			}
+			h := NewUserHandler(mockSvc)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// 実行
+			if err := h.CreateUser(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetUser_NotFound(t *testing.T) {
+	// 準備
+	e := echo.New()
+	mockSvc := &mockUserService{
+		getUserFunc: func(id string) (*model.User, error) {
+			return nil, service.ErrUserNotFound
+		},
+	}
+	h := NewUserHandler(mockSvc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/missing", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("missing")
+
+	// 実行
+	if err := h.GetUser(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestGetAllUsers_Forbidden(t *testing.T) {
+	// 準備
+	e := echo.New()
+	mockSvc := &mockUserService{
+		getAllUsersFunc: func() ([]*model.User, error) {
+			return nil, service.ErrForbidden
+		},
+	}
+	NewUserHandler(mockSvc).RegisterRoutes(e)
+	rec := httptest.NewRecorder()
+
+	// 実行
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+	// 検証
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, rec.Code)
+	}
+}
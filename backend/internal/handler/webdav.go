@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WebDAV（RFC 4918）と CalDAV（RFC 4791）の XML の名前空間
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	// nsCalendarServer は getctag を定義する Calendar Server の拡張の名前空間
+	nsCalendarServer = "http://calendarserver.org/ns/"
+)
+
+// maxDAVRequestBytes は PROPFIND と REPORT のリクエストの本文の最大バイト数
+const maxDAVRequestBytes = 1 << 20
+
+// davContentType は multistatus などの XML のレスポンスの Content-Type
+const davContentType = "application/xml; charset=utf-8"
+
+func davName(space, local string) xml.Name {
+	return xml.Name{Space: space, Local: local}
+}
+
+// davNames は要素の子要素の名前を読み込む。prop に列挙されたプロパティの名前に使う。
+type davNames []xml.Name
+
+func (n *davNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			*n = append(*n, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// davPropfind は PROPFIND のリクエスト。本文がない場合は allprop として扱う。
+type davPropfind struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     davNames  `xml:"DAV: prop"`
+}
+
+// davReport は REPORT のリクエスト。XMLName で calendar-query、calendar-multiget、sync-collection を区別する。
+type davReport struct {
+	XMLName   xml.Name
+	AllProp   *struct{}  `xml:"DAV: allprop"`
+	Prop      davNames   `xml:"DAV: prop"`
+	Hrefs     []string   `xml:"DAV: href"`
+	SyncToken string     `xml:"DAV: sync-token"`
+	Filter    *davFilter `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// davFilter は calendar-query の filter
+type davFilter struct {
+	CompFilter davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// davCompFilter は calendar-query の comp-filter。time-range の日時は UTC の DATE-TIME（例: 20260101T000000Z）。
+type davCompFilter struct {
+	Name        string          `xml:"name,attr"`
+	TimeRange   *davTimeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	CompFilters []davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type davTimeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+// readDAVRequest はリクエストの本文の XML を v に読み込む。本文がない場合は false を返す。
+func readDAVRequest(c echo.Context, v any) (bool, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, maxDAVRequestBytes))
+	if err != nil {
+		return false, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return false, nil
+	}
+	return true, xml.Unmarshal(body, v)
+}
+
+// davMultistatus は 207 Multi-Status のレスポンス
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"DAV: multistatus"`
+	Responses []davResponse `xml:"response"`
+	SyncToken string        `xml:"sync-token,omitempty"`
+}
+
+// davResponse は multistatus のリソースごとの結果。プロパティを返さない場合は Status を設定する。
+type davResponse struct {
+	Href      string        `xml:"href"`
+	Status    string        `xml:"status,omitempty"`
+	Propstats []davPropstat `xml:"propstat"`
+}
+
+type davPropstat struct {
+	Props  []davProperty `xml:"prop>property"`
+	Status string        `xml:"status"`
+}
+
+// davProperty はプロパティの要素。Inner は要素の内容の XML で、名前空間を含めて書く。
+type davProperty struct {
+	Name  xml.Name
+	Inner string
+}
+
+func (p davProperty) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Inner string `xml:",innerxml"`
+	}{p.Inner}, xml.StartElement{Name: p.Name})
+}
+
+// davStatus は multistatus の status の値を返す
+func davStatus(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+// writeMultistatus は 207 Multi-Status のレスポンスを書き出す
+func writeMultistatus(c echo.Context, ms *davMultistatus) error {
+	body, err := xml.Marshal(ms)
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusMultiStatus, davContentType, append([]byte(xml.Header), body...))
+}
+
+// writeDAVError は満たさなかった事前条件（precondition）の要素を含む error のレスポンスを書き出す
+func writeDAVError(c echo.Context, status int, precondition xml.Name) error {
+	body := xml.Header + `<error xmlns="DAV:"><` + precondition.Local + ` xmlns="` + precondition.Space + `"/></error>`
+	return c.Blob(status, davContentType, []byte(body))
+}
+
+// escapeXML は文字列を XML の文字データとしてエスケープする
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// hrefXML は DAV の href 要素を返す
+func hrefXML(href string) string {
+	return `<href xmlns="DAV:">` + escapeXML(href) + `</href>`
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// WebhookServiceInterface はテスト時にモック可能なインターフェース
+type WebhookServiceInterface interface {
+	CreateSubscription(ctx context.Context, params service.CreateWebhookParams) (*model.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, subscriptionID string) ([]*model.WebhookDelivery, error)
+	Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*model.WebhookDelivery, error)
+}
+
+type WebhookHandler struct {
+	service  WebhookServiceInterface
+	validate *validator.Validate
+}
+
+func NewWebhookHandler(service WebhookServiceInterface) *WebhookHandler {
+	return &WebhookHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+type webhookRequest struct {
+	URL string `json:"url" validate:"required,max=2048"`
+	// Secret を省略すると署名の鍵を生成する
+	Secret string                   `json:"secret" validate:"max=255"`
+	Events []model.WebhookEventType `json:"events" validate:"required,min=1"`
+}
+
+// webhookResponse は登録した Webhook。Secret は登録時にだけ返す。
+type webhookResponse struct {
+	*model.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// RegisterRoutes は Webhook のルートを登録する。middleware は全てのルートに適用する（例: auth.RequireAuth）。
+func (h *WebhookHandler) RegisterRoutes(e *echo.Echo, middleware ...echo.MiddlewareFunc) {
+	e.POST("/api/webhooks", h.CreateWebhook, middleware...)
+	e.GET("/api/webhooks", h.GetWebhooks, middleware...)
+	e.DELETE("/api/webhooks/:id", h.DeleteWebhook, middleware...)
+	e.GET("/api/webhooks/:id/deliveries", h.GetDeliveries, middleware...)
+	e.POST("/api/webhooks/:id/deliveries/:deliveryId/redeliver", h.Redeliver, middleware...)
+}
+
+// CreateWebhook は予約のイベントを通知する Webhook を登録する
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	req := new(webhookRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	subscription, err := h.service.CreateSubscription(c.Request().Context(), service.CreateWebhookParams{
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+	})
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusCreated, webhookResponse{WebhookSubscription: subscription, Secret: subscription.Secret})
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can manage webhooks"})
+	case errors.Is(err, service.ErrInvalidWebhookURL), errors.Is(err, service.ErrInvalidWebhookEvents):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create webhook"})
+	}
+}
+
+// GetWebhooks は登録された Webhook を返す。署名の鍵は含めない。
+func (h *WebhookHandler) GetWebhooks(c echo.Context) error {
+	subscriptions, err := h.service.ListSubscriptions(c.Request().Context())
+	if errors.Is(err, service.ErrForbidden) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can manage webhooks"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get webhooks"})
+	}
+
+	return c.JSON(http.StatusOK, subscriptions)
+}
+
+// DeleteWebhook は Webhook の登録と配送の記録を削除する
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	err := h.service.DeleteSubscription(c.Request().Context(), c.Param("id"))
+	switch {
+	case err == nil:
+		return c.NoContent(http.StatusNoContent)
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can manage webhooks"})
+	case errors.Is(err, service.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Webhook not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete webhook"})
+	}
+}
+
+// GetDeliveries は Webhook の配送の記録を新しい順に返す
+func (h *WebhookHandler) GetDeliveries(c echo.Context) error {
+	deliveries, err := h.service.ListDeliveries(c.Request().Context(), c.Param("id"))
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, deliveries)
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can manage webhooks"})
+	case errors.Is(err, service.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Webhook not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get webhook deliveries"})
+	}
+}
+
+// Redeliver は配送と同じイベントを送り直す。送信は非同期に行い、結果は配送の記録で確認する。
+func (h *WebhookHandler) Redeliver(c echo.Context) error {
+	delivery, err := h.service.Redeliver(c.Request().Context(), c.Param("id"), c.Param("deliveryId"))
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusAccepted, delivery)
+	case errors.Is(err, service.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Only administrators can manage webhooks"})
+	case errors.Is(err, service.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Webhook not found"})
+	case errors.Is(err, service.ErrWebhookDeliveryNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Webhook delivery not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to redeliver webhook"})
+	}
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
+)
+
+// モックサービスの実装
+type mockWebhookService struct {
+	createSubscriptionFunc func(params service.CreateWebhookParams) (*model.WebhookSubscription, error)
+	listSubscriptionsFunc  func() ([]*model.WebhookSubscription, error)
+	deleteSubscriptionFunc func(id string) error
+	listDeliveriesFunc     func(subscriptionID string) ([]*model.WebhookDelivery, error)
+	redeliverFunc          func(subscriptionID, deliveryID string) (*model.WebhookDelivery, error)
+}
+
+func (m *mockWebhookService) CreateSubscription(ctx context.Context, params service.CreateWebhookParams) (*model.WebhookSubscription, error) {
+	return m.createSubscriptionFunc(params)
+}
+
+func (m *mockWebhookService) ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	return m.listSubscriptionsFunc()
+}
+
+func (m *mockWebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	return m.deleteSubscriptionFunc(id)
+}
+
+func (m *mockWebhookService) ListDeliveries(ctx context.Context, subscriptionID string) ([]*model.WebhookDelivery, error) {
+	return m.listDeliveriesFunc(subscriptionID)
+}
+
+func (m *mockWebhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*model.WebhookDelivery, error) {
+	return m.redeliverFunc(subscriptionID, deliveryID)
+}
+
+func TestCreateWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "Webhook を登録する", body: `{"url":"https://example.com/hook","events":["reservation.created"]}`, wantStatus: http.StatusCreated},
+		{name: "イベントの指定がない", body: `{"url":"https://example.com/hook","events":[]}`, wantStatus: http.StatusBadRequest},
+		{name: "未定義のイベント", body: `{"url":"https://example.com/hook","events":["reservation.moved"]}`, err: service.ErrInvalidWebhookEvents, wantStatus: http.StatusBadRequest},
+		{name: "不正な URL", body: `{"url":"ftp://example.com","events":["reservation.created"]}`, err: service.ErrInvalidWebhookURL, wantStatus: http.StatusBadRequest},
+		{name: "管理者以外", body: `{"url":"https://example.com/hook","events":["reservation.created"]}`, err: service.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "不正な JSON", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			subscription := model.NewWebhookSubscription("https://example.com/hook", "generated-secret", []model.WebhookEventType{model.EventReservationCreated})
+			mockSvc := &mockWebhookService{
+				createSubscriptionFunc: func(params service.CreateWebhookParams) (*model.WebhookSubscription, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return subscription, nil
+				},
+			}
+			NewWebhookHandler(mockSvc).RegisterRoutes(e)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/webhooks", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, req)
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			var got map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if got["id"] != subscription.ID || got["secret"] != "generated-secret" {
+				t.Errorf("Unexpected response: %v", got)
+			}
+		})
+	}
+}
+
+func TestGetWebhooks_HidesSecret(t *testing.T) {
+	// 準備
+	e := echo.New()
+	mockSvc := &mockWebhookService{
+		listSubscriptionsFunc: func() ([]*model.WebhookSubscription, error) {
+			return []*model.WebhookSubscription{model.NewWebhookSubscription("https://example.com/hook", "secret", model.WebhookEventTypes)}, nil
+		},
+	}
+	NewWebhookHandler(mockSvc).RegisterRoutes(e)
+	rec := httptest.NewRecorder()
+
+	// 実行
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/webhooks", nil))
+
+	// 検証
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("Expected secret to be hidden, got %s", rec.Body.String())
+	}
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "再配送を受け付ける", wantStatus: http.StatusAccepted},
+		{name: "存在しない Webhook", err: service.ErrWebhookNotFound, wantStatus: http.StatusNotFound},
+		{name: "存在しない配送", err: service.ErrWebhookDeliveryNotFound, wantStatus: http.StatusNotFound},
+		{name: "管理者以外", err: service.ErrForbidden, wantStatus: http.StatusForbidden},
+		{name: "サービスのエラー", err: errors.New("database error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			e := echo.New()
+			mockSvc := &mockWebhookService{
+				redeliverFunc: func(subscriptionID, deliveryID string) (*model.WebhookDelivery, error) {
+					if subscriptionID != "hook-1" || deliveryID != "delivery-1" {
+						t.Errorf("Expected hook-1 and delivery-1, got %s and %s", subscriptionID, deliveryID)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return model.NewWebhookDelivery("hook-1", "event-1", model.EventReservationCreated, json.RawMessage(`{}`), time.Now()), nil
+				},
+			}
+			NewWebhookHandler(mockSvc).RegisterRoutes(e)
+			rec := httptest.NewRecorder()
+
+			// 実行
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/webhooks/hook-1/deliveries/delivery-1/redeliver", nil))
+
+			// 検証
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status code %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
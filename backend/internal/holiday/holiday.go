@@ -0,0 +1,260 @@
+// Package holiday は「国民の祝日に関する法律」に基づく日本の祝日を計算する。
+// 振替休日と国民の休日、法改正による祝日の変更（ハッピーマンデー制度など）、
+// 皇室の慶弔や東京オリンピックに伴う特例の休日を含む。ネットワークには接続しない。
+package holiday
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MinYear と MaxYear は計算できる年の範囲。祝日法の施行から、春分日・秋分日の近似式が使える年まで。
+const (
+	MinYear = 1949
+	MaxYear = 2150
+)
+
+// JST は祝日の日付を判定するタイムゾーン
+var JST = time.FixedZone("JST", 9*60*60)
+
+// Holiday は祝日・休日の1日
+type Holiday struct {
+	// Date はその日の0時（JST）
+	Date time.Time
+	Name string
+}
+
+const (
+	nameSubstitute = "振替休日"
+	nameCitizens   = "国民の休日"
+)
+
+// 法改正などで一度だけ設けられた休日
+var specialHolidays = map[string]string{
+	"1959-04-10": "皇太子明仁親王の結婚の儀",
+	"1989-02-24": "昭和天皇の大喪の礼",
+	"1990-11-12": "即位礼正殿の儀",
+	"1993-06-09": "皇太子徳仁親王の結婚の儀",
+	"2019-05-01": "天皇の即位の日",
+	"2019-10-22": "即位礼正殿の儀",
+}
+
+var (
+	cacheMutex sync.RWMutex
+	cache      = make(map[int][]Holiday)
+)
+
+// Year は year 年の祝日・休日を日付の昇順で返す。year が MinYear〜MaxYear の範囲外の場合はエラーを返す。
+func Year(year int) ([]Holiday, error) {
+	if year < MinYear || year > MaxYear {
+		return nil, fmt.Errorf("year must be between %d and %d", MinYear, MaxYear)
+	}
+
+	cacheMutex.RLock()
+	holidays, ok := cache[year]
+	cacheMutex.RUnlock()
+	if !ok {
+		holidays = compute(year)
+		cacheMutex.Lock()
+		cache[year] = holidays
+		cacheMutex.Unlock()
+	}
+
+	return append([]Holiday{}, holidays...), nil
+}
+
+// Lookup は t の日付（JST）が祝日・休日であればその祝日を返す。範囲外の年は祝日ではないものとして扱う。
+func Lookup(t time.Time) (Holiday, bool) {
+	t = t.In(JST)
+	holidays, err := Year(t.Year())
+	if err != nil {
+		return Holiday{}, false
+	}
+	day := date(t.Year(), t.Month(), t.Day())
+	for _, h := range holidays {
+		if h.Date.Equal(day) {
+			return h, true
+		}
+	}
+	return Holiday{}, false
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, JST)
+}
+
+// nthWeekday は year 年 month 月の n 番目の weekday の日付を返す
+func nthWeekday(year int, month time.Month, n int, weekday time.Weekday) time.Time {
+	first := date(year, month, 1)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+7*(n-1))
+}
+
+// vernalEquinoxDay と autumnalEquinoxDay は春分日・秋分日の近似式（国立天文台の暦要項と一致する範囲）
+func vernalEquinoxDay(year int) int {
+	return equinoxDay(year, 20.8357, 20.8431, 21.8510)
+}
+
+func autumnalEquinoxDay(year int) int {
+	return equinoxDay(year, 23.2588, 23.2488, 24.2488)
+}
+
+func equinoxDay(year int, before1980, before2100, after2100 float64) int {
+	base := before2100
+	switch {
+	case year < 1980:
+		base = before1980
+	case year >= 2100:
+		base = after2100
+	}
+	y := year - 1980
+	return int(base+0.242194*float64(y)) - floorDiv(y, 4)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// nationalHolidays は「国民の祝日」（振替休日と国民の休日を除く）を返す
+func nationalHolidays(year int) map[time.Time]string {
+	holidays := make(map[time.Time]string)
+	add := func(t time.Time, name string) {
+		holidays[t] = name
+	}
+
+	add(date(year, time.January, 1), "元日")
+
+	if year >= 2000 {
+		add(nthWeekday(year, time.January, 2, time.Monday), "成人の日")
+	} else {
+		add(date(year, time.January, 15), "成人の日")
+	}
+
+	if year >= 1967 {
+		add(date(year, time.February, 11), "建国記念の日")
+	}
+
+	switch {
+	case year >= 2020:
+		add(date(year, time.February, 23), "天皇誕生日")
+	case year >= 1989 && year <= 2018:
+		add(date(year, time.December, 23), "天皇誕生日")
+	case year <= 1988:
+		add(date(year, time.April, 29), "天皇誕生日")
+	}
+
+	add(date(year, time.March, vernalEquinoxDay(year)), "春分の日")
+
+	switch {
+	case year >= 2007:
+		add(date(year, time.April, 29), "昭和の日")
+		add(date(year, time.May, 4), "みどりの日")
+	case year >= 1989:
+		add(date(year, time.April, 29), "みどりの日")
+	}
+
+	add(date(year, time.May, 3), "憲法記念日")
+	add(date(year, time.May, 5), "こどもの日")
+
+	// 東京オリンピック・パラリンピックの開催に伴う2020年と2021年の特例
+	switch {
+	case year == 2020:
+		add(date(year, time.July, 23), "海の日")
+		add(date(year, time.July, 24), "スポーツの日")
+		add(date(year, time.August, 10), "山の日")
+	case year == 2021:
+		add(date(year, time.July, 22), "海の日")
+		add(date(year, time.July, 23), "スポーツの日")
+		add(date(year, time.August, 8), "山の日")
+	default:
+		switch {
+		case year >= 2003:
+			add(nthWeekday(year, time.July, 3, time.Monday), "海の日")
+		case year >= 1996:
+			add(date(year, time.July, 20), "海の日")
+		}
+		if year >= 2016 {
+			add(date(year, time.August, 11), "山の日")
+		}
+		switch {
+		case year >= 2020:
+			add(nthWeekday(year, time.October, 2, time.Monday), "スポーツの日")
+		case year >= 2000:
+			add(nthWeekday(year, time.October, 2, time.Monday), "体育の日")
+		case year >= 1966:
+			add(date(year, time.October, 10), "体育の日")
+		}
+	}
+
+	switch {
+	case year >= 2003:
+		add(nthWeekday(year, time.September, 3, time.Monday), "敬老の日")
+	case year >= 1966:
+		add(date(year, time.September, 15), "敬老の日")
+	}
+
+	add(date(year, time.September, autumnalEquinoxDay(year)), "秋分の日")
+	add(date(year, time.November, 3), "文化の日")
+	add(date(year, time.November, 23), "勤労感謝の日")
+
+	for day, name := range specialHolidays {
+		t, _ := time.ParseInLocation("2006-01-02", day, JST)
+		if t.Year() == year {
+			add(t, name)
+		}
+	}
+
+	return holidays
+}
+
+func compute(year int) []Holiday {
+	holidays := nationalHolidays(year)
+	national := make(map[time.Time]bool, len(holidays))
+	for day := range holidays {
+		national[day] = true
+	}
+
+	// 振替休日（1973年4月12日施行）: 祝日が日曜日に当たるときは、2006年までは翌日（月曜日）、
+	// 2007年以降はその後の最も近い祝日でない日を休日とする
+	for day := range national {
+		if day.Weekday() != time.Sunday || day.Before(date(1973, time.April, 12)) {
+			continue
+		}
+		substitute := day.AddDate(0, 0, 1)
+		if year >= 2007 {
+			for national[substitute] {
+				substitute = substitute.AddDate(0, 0, 1)
+			}
+		} else if national[substitute] {
+			continue
+		}
+		if substitute.Year() == year {
+			holidays[substitute] = nameSubstitute
+		}
+	}
+
+	// 国民の休日（1985年12月27日施行）: 前日と翌日が祝日である日（日曜日と他の休日を除く）を休日とする
+	if year >= 1986 {
+		for day := range national {
+			between := day.AddDate(0, 0, 1)
+			if national[between.AddDate(0, 0, 1)] && !national[between] && between.Weekday() != time.Sunday {
+				if _, ok := holidays[between]; !ok {
+					holidays[between] = nameCitizens
+				}
+			}
+		}
+	}
+
+	result := make([]Holiday, 0, len(holidays))
+	for day, name := range holidays {
+		result = append(result, Holiday{Date: day, Name: name})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result
+}
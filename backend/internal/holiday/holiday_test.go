@@ -0,0 +1,238 @@
+package holiday
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// 内閣府が公表している「国民の祝日」の一覧（振替休日・国民の休日を含む）
+var publishedHolidays = map[int]string{
+	1989: `
+1989-01-01 元日
+1989-01-02 振替休日
+1989-01-15 成人の日
+1989-01-16 振替休日
+1989-02-11 建国記念の日
+1989-02-24 昭和天皇の大喪の礼
+1989-03-21 春分の日
+1989-04-29 みどりの日
+1989-05-03 憲法記念日
+1989-05-04 国民の休日
+1989-05-05 こどもの日
+1989-09-15 敬老の日
+1989-09-23 秋分の日
+1989-10-10 体育の日
+1989-11-03 文化の日
+1989-11-23 勤労感謝の日
+1989-12-23 天皇誕生日`,
+	2015: `
+2015-01-01 元日
+2015-01-12 成人の日
+2015-02-11 建国記念の日
+2015-03-21 春分の日
+2015-04-29 昭和の日
+2015-05-03 憲法記念日
+2015-05-04 みどりの日
+2015-05-05 こどもの日
+2015-05-06 振替休日
+2015-07-20 海の日
+2015-09-21 敬老の日
+2015-09-22 国民の休日
+2015-09-23 秋分の日
+2015-10-12 体育の日
+2015-11-03 文化の日
+2015-11-23 勤労感謝の日
+2015-12-23 天皇誕生日`,
+	2019: `
+2019-01-01 元日
+2019-01-14 成人の日
+2019-02-11 建国記念の日
+2019-03-21 春分の日
+2019-04-29 昭和の日
+2019-04-30 国民の休日
+2019-05-01 天皇の即位の日
+2019-05-02 国民の休日
+2019-05-03 憲法記念日
+2019-05-04 みどりの日
+2019-05-05 こどもの日
+2019-05-06 振替休日
+2019-07-15 海の日
+2019-08-11 山の日
+2019-08-12 振替休日
+2019-09-16 敬老の日
+2019-09-23 秋分の日
+2019-10-14 体育の日
+2019-10-22 即位礼正殿の儀
+2019-11-03 文化の日
+2019-11-04 振替休日
+2019-11-23 勤労感謝の日`,
+	2020: `
+2020-01-01 元日
+2020-01-13 成人の日
+2020-02-11 建国記念の日
+2020-02-23 天皇誕生日
+2020-02-24 振替休日
+2020-03-20 春分の日
+2020-04-29 昭和の日
+2020-05-03 憲法記念日
+2020-05-04 みどりの日
+2020-05-05 こどもの日
+2020-05-06 振替休日
+2020-07-23 海の日
+2020-07-24 スポーツの日
+2020-08-10 山の日
+2020-09-21 敬老の日
+2020-09-22 秋分の日
+2020-11-03 文化の日
+2020-11-23 勤労感謝の日`,
+	2021: `
+2021-01-01 元日
+2021-01-11 成人の日
+2021-02-11 建国記念の日
+2021-02-23 天皇誕生日
+2021-03-20 春分の日
+2021-04-29 昭和の日
+2021-05-03 憲法記念日
+2021-05-04 みどりの日
+2021-05-05 こどもの日
+2021-07-22 海の日
+2021-07-23 スポーツの日
+2021-08-08 山の日
+2021-08-09 振替休日
+2021-09-20 敬老の日
+2021-09-23 秋分の日
+2021-11-03 文化の日
+2021-11-23 勤労感謝の日`,
+	2023: `
+2023-01-01 元日
+2023-01-02 振替休日
+2023-01-09 成人の日
+2023-02-11 建国記念の日
+2023-02-23 天皇誕生日
+2023-03-21 春分の日
+2023-04-29 昭和の日
+2023-05-03 憲法記念日
+2023-05-04 みどりの日
+2023-05-05 こどもの日
+2023-07-17 海の日
+2023-08-11 山の日
+2023-09-18 敬老の日
+2023-09-23 秋分の日
+2023-10-09 スポーツの日
+2023-11-03 文化の日
+2023-11-23 勤労感謝の日`,
+	2024: `
+2024-01-01 元日
+2024-01-08 成人の日
+2024-02-11 建国記念の日
+2024-02-12 振替休日
+2024-02-23 天皇誕生日
+2024-03-20 春分の日
+2024-04-29 昭和の日
+2024-05-03 憲法記念日
+2024-05-04 みどりの日
+2024-05-05 こどもの日
+2024-05-06 振替休日
+2024-07-15 海の日
+2024-08-11 山の日
+2024-08-12 振替休日
+2024-09-16 敬老の日
+2024-09-22 秋分の日
+2024-09-23 振替休日
+2024-10-14 スポーツの日
+2024-11-03 文化の日
+2024-11-04 振替休日
+2024-11-23 勤労感謝の日`,
+}
+
+func TestYear(t *testing.T) {
+	for year, list := range publishedHolidays {
+		t.Run(time.Date(year, 1, 1, 0, 0, 0, 0, JST).Format("2006"), func(t *testing.T) {
+			// 実行
+			holidays, err := Year(year)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// 検証
+			var got []string
+			for _, h := range holidays {
+				got = append(got, h.Date.Format("2006-01-02")+" "+h.Name)
+			}
+			want := strings.Split(strings.TrimSpace(list), "\n")
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("Holidays of %d do not match\nexpected:\n%s\ngot:\n%s", year, strings.Join(want, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestYear_OutOfRange(t *testing.T) {
+	for _, year := range []int{MinYear - 1, MaxYear + 1} {
+		if _, err := Year(year); err == nil {
+			t.Errorf("Expected error for %d", year)
+		}
+	}
+}
+
+func TestYear_ReturnsCopy(t *testing.T) {
+	// 準備
+	holidays, _ := Year(2024)
+	holidays[0].Name = "changed"
+
+	// 実行
+	again, _ := Year(2024)
+
+	// 検証
+	if again[0].Name != "元日" {
+		t.Errorf("Expected cached holidays to be unaffected, got %s", again[0].Name)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		time     time.Time
+		wantName string
+		wantOK   bool
+	}{
+		{name: "祝日", time: time.Date(2024, 5, 3, 10, 0, 0, 0, JST), wantName: "憲法記念日", wantOK: true},
+		{name: "振替休日", time: time.Date(2024, 5, 6, 23, 59, 0, 0, JST), wantName: "振替休日", wantOK: true},
+		{name: "UTCでは前日でもJSTで判定する", time: time.Date(2024, 5, 2, 15, 0, 0, 0, time.UTC), wantName: "憲法記念日", wantOK: true},
+		{name: "平日", time: time.Date(2024, 5, 7, 10, 0, 0, 0, JST)},
+		{name: "範囲外の年", time: time.Date(1900, 1, 1, 0, 0, 0, 0, JST)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, ok := Lookup(tt.time)
+			if ok != tt.wantOK || h.Name != tt.wantName {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.wantName, tt.wantOK, h.Name, ok)
+			}
+		})
+	}
+}
+
+func TestEquinoxDays(t *testing.T) {
+	// 国立天文台の暦要項による春分日・秋分日
+	tests := []struct {
+		year             int
+		vernal, autumnal int
+	}{
+		{1979, 21, 24},
+		{2000, 20, 23},
+		{2012, 20, 22},
+		{2025, 20, 23},
+		{2026, 20, 23},
+	}
+
+	for _, tt := range tests {
+		if got := vernalEquinoxDay(tt.year); got != tt.vernal {
+			t.Errorf("Expected vernal equinox on March %d in %d, got %d", tt.vernal, tt.year, got)
+		}
+		if got := autumnalEquinoxDay(tt.year); got != tt.autumnal {
+			t.Errorf("Expected autumnal equinox on September %d in %d, got %d", tt.autumnal, tt.year, got)
+		}
+	}
+}
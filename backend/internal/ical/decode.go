@@ -0,0 +1,437 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLineBytes は読み込む1行（折り返しを戻した後）の最大バイト数
+const maxLineBytes = 1 << 20
+
+// localFormat と dateFormat は TZID 付きまたはフローティングの DATE-TIME と、DATE の形式
+const (
+	localFormat = "20060102T150405"
+	dateFormat  = "20060102"
+)
+
+// DecodedEvent は読み込んだ VEVENT。
+// イベントを予約として解釈できなかった場合は Err に理由を設定し、Event には読み込めたプロパティだけを設定する。
+type DecodedEvent struct {
+	Event
+	Err error
+}
+
+// Decode は r の iCalendar を読み込み、VCALENDAR に含まれる VEVENT を現れた順に返す。
+// TZID のない日時（フローティング）と終日のイベントの日付は loc の日時として解釈する。
+// TZID は IANA のタイムゾーン名か、同じ VCALENDAR の VTIMEZONE で定義された名前で指定する。
+// DTEND のないイベントは DURATION から、それもない場合は終日のイベントなら1日、それ以外は長さ0として終了時刻を決める。
+// 行の形式や BEGIN / END の対応が不正な場合と VCALENDAR がない場合はエラーを返し、個々のイベントの誤りは DecodedEvent.Err で返す。
+func Decode(r io.Reader, loc *time.Location) ([]DecodedEvent, error) {
+	roots, err := readComponents(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []DecodedEvent
+	found := false
+	for _, cal := range roots {
+		if cal.name != "VCALENDAR" {
+			continue
+		}
+		found = true
+		d := &eventDecoder{loc: loc, zones: make(map[string]*zone)}
+		for _, child := range cal.children {
+			if child.name == "VTIMEZONE" {
+				z := parseZone(child)
+				d.zones[z.id] = z
+			}
+		}
+		for _, child := range cal.children {
+			if child.name == "VEVENT" {
+				events = append(events, d.event(child))
+			}
+		}
+	}
+	if !found {
+		return nil, errors.New("no VCALENDAR found")
+	}
+	return events, nil
+}
+
+// property は1行のプロパティ。name とパラメータの名前は大文字にそろえる。
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// component は BEGIN から END までのコンポーネント
+type component struct {
+	name       string
+	properties []property
+	children   []*component
+}
+
+// get は name のプロパティのうち最初のものを返す
+func (c *component) get(name string) (property, bool) {
+	for _, p := range c.properties {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return property{}, false
+}
+
+// text は name のプロパティの TEXT の値をエスケープを戻して返す。プロパティがない場合は空文字列を返す。
+func (c *component) text(name string) string {
+	p, _ := c.get(name)
+	return unescapeText(p.value)
+}
+
+// readComponents は r を読み込み、最上位のコンポーネントを返す
+func readComponents(r io.Reader) ([]*component, error) {
+	var roots, stack []*component
+	err := unfold(r, func(number int, line string) error {
+		p, err := parseProperty(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", number, err)
+		}
+		switch p.name {
+		case "BEGIN":
+			c := &component{name: strings.ToUpper(p.value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, c)
+			} else {
+				roots = append(roots, c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].name != strings.ToUpper(p.value) {
+				return fmt.Errorf("line %d: unexpected END:%s", number, p.value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return fmt.Errorf("line %d: property %s outside of a component", number, p.name)
+			}
+			top := stack[len(stack)-1]
+			top.properties = append(top.properties, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].name)
+	}
+	return roots, nil
+}
+
+// unfold は r の折り返された行を戻し、空でない論理行ごとに fn を呼ぶ。number は論理行が始まる物理行の番号。
+// 改行は CRLF と LF のどちらも受け付ける。
+func unfold(r io.Reader, fn func(number int, line string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineBytes)
+
+	var current strings.Builder
+	start, number := 0, 0
+	flush := func() error {
+		if current.Len() == 0 {
+			return nil
+		}
+		line := current.String()
+		current.Reset()
+		return fn(start, line)
+	}
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if number == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
+			if current.Len()+len(line) > maxLineBytes {
+				return fmt.Errorf("line %d: line too long", start)
+			}
+			current.WriteString(line[1:])
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		start = number
+		current.WriteString(line)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("line %d: line too long", number+1)
+		}
+		return err
+	}
+	return flush()
+}
+
+// parseProperty は "NAME;PARAM=value:value" の形式の論理行を解析する。
+// パラメータの値の引用符は取り除き、複数の値はカンマ区切りのまま返す。
+func parseProperty(line string) (property, error) {
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return property{}, errors.New("invalid content line")
+	}
+	p := property{name: strings.ToUpper(line[:end]), params: make(map[string]string)}
+	rest := line[end:]
+	for rest[0] == ';' {
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return property{}, fmt.Errorf("invalid parameter in %s", p.name)
+		}
+		name := strings.ToUpper(rest[1:eq])
+		rest = rest[eq+1:]
+
+		var value strings.Builder
+		for len(rest) > 0 && rest[0] != ';' && rest[0] != ':' {
+			if rest[0] == '"' {
+				closing := strings.IndexByte(rest[1:], '"')
+				if closing < 0 {
+					return property{}, fmt.Errorf("unterminated quoted parameter in %s", p.name)
+				}
+				value.WriteString(rest[1 : closing+1])
+				rest = rest[closing+2:]
+				continue
+			}
+			value.WriteByte(rest[0])
+			rest = rest[1:]
+		}
+		if len(rest) == 0 {
+			return property{}, fmt.Errorf("missing value in %s", p.name)
+		}
+		p.params[name] = value.String()
+	}
+	p.value = rest[1:]
+	return p, nil
+}
+
+// unescapeText は TEXT の値のエスケープを戻す
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// eventDecoder は1つの VCALENDAR の VEVENT を解釈する
+type eventDecoder struct {
+	loc   *time.Location
+	zones map[string]*zone
+}
+
+// event は VEVENT を解釈する
+func (d *eventDecoder) event(c *component) DecodedEvent {
+	var decoded DecodedEvent
+	e := &decoded.Event
+	e.UID = strings.TrimSpace(c.text("UID"))
+	e.Summary = c.text("SUMMARY")
+	e.Description = c.text("DESCRIPTION")
+	e.Location = c.text("LOCATION")
+	e.Status = EventStatus(strings.ToUpper(strings.TrimSpace(c.text("STATUS"))))
+	if p, ok := c.get("RRULE"); ok {
+		e.RRule = p.value
+	}
+	for _, p := range c.properties {
+		if p.name != "ATTENDEE" {
+			continue
+		}
+		address := strings.TrimSpace(p.value)
+		if len(address) >= len("mailto:") && strings.EqualFold(address[:len("mailto:")], "mailto:") {
+			address = address[len("mailto:"):]
+		}
+		if address != "" {
+			e.Attendees = append(e.Attendees, address)
+		}
+	}
+	// DTSTAMP などの参考情報は解釈できなくてもイベントを拒否しない
+	e.Stamp, _ = d.optionalTime(c, "DTSTAMP")
+	e.Created, _ = d.optionalTime(c, "CREATED")
+	e.Modified, _ = d.optionalTime(c, "LAST-MODIFIED")
+	if p, ok := c.get("SEQUENCE"); ok {
+		e.Sequence, _ = strconv.ParseInt(strings.TrimSpace(p.value), 10, 64)
+	}
+
+	decoded.Err = d.period(c, e)
+	return decoded
+}
+
+// period は DTSTART、DTEND、DURATION から e の Start、End、AllDay を設定する
+func (d *eventDecoder) period(c *component, e *Event) error {
+	startProp, ok := c.get("DTSTART")
+	if !ok {
+		return errors.New("missing DTSTART")
+	}
+	start, allDay, err := d.time(startProp)
+	if err != nil {
+		return err
+	}
+	e.Start, e.AllDay = start, allDay
+
+	if endProp, ok := c.get("DTEND"); ok {
+		end, endAllDay, err := d.time(endProp)
+		if err != nil {
+			return err
+		}
+		if endAllDay != allDay {
+			return errors.New("DTSTART and DTEND must both be dates or both be date-times")
+		}
+		e.End = end
+	} else if p, ok := c.get("DURATION"); ok {
+		days, clock, err := parseDuration(p.value)
+		if err != nil {
+			return err
+		}
+		e.End = start.AddDate(0, 0, days).Add(clock)
+	} else if allDay {
+		e.End = start.AddDate(0, 0, 1)
+	} else {
+		e.End = start
+	}
+	if e.End.Before(e.Start) {
+		return errors.New("DTEND must not be before DTSTART")
+	}
+	return nil
+}
+
+// optionalTime は name のプロパティの日時を返す。プロパティがない場合はゼロ値を返す。
+func (d *eventDecoder) optionalTime(c *component, name string) (time.Time, error) {
+	p, ok := c.get(name)
+	if !ok {
+		return time.Time{}, nil
+	}
+	t, _, err := d.time(p)
+	return t, err
+}
+
+// time は DATE または DATE-TIME のプロパティの値を解釈し、日付だけの値であるかとともに返す
+func (d *eventDecoder) time(p property) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.value)
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(value) == len(dateFormat) {
+		t, err := time.ParseInLocation(dateFormat, value, d.loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s %q", p.name, value)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcFormat, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s %q", p.name, value)
+		}
+		return t, false, nil
+	}
+
+	wall, err := time.Parse(localFormat, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s %q", p.name, value)
+	}
+	tzid, ok := p.params["TZID"]
+	if !ok {
+		return inLocation(wall, d.loc), false, nil
+	}
+	t, err := d.inZone(wall, tzid)
+	return t, false, err
+}
+
+// inZone は壁時計の時刻 wall を TZID のタイムゾーンの時刻にする。
+// IANA の名前として解釈できる場合は、VTIMEZONE よりも正確な tz データベースの規則を使う。
+func (d *eventDecoder) inZone(wall time.Time, tzid string) (time.Time, error) {
+	name := strings.TrimPrefix(tzid, "/")
+	if name != "" && name != "Local" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return inLocation(wall, loc), nil
+		}
+	}
+	z, ok := d.zones[tzid]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown TZID %q", tzid)
+	}
+	if z.err != nil {
+		return time.Time{}, fmt.Errorf("invalid VTIMEZONE %q: %w", tzid, z.err)
+	}
+	return z.at(wall), nil
+}
+
+// inLocation は UTC として解析した壁時計の時刻 wall を loc の同じ壁時計の時刻にする
+func inLocation(wall time.Time, loc *time.Location) time.Time {
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+}
+
+// parseDuration は DURATION の値（例: PT1H30M、P1D、P1W）を日数と時間に分けて返す。
+// 日数は夏時間の切り替えをまたいでも同じ時刻になるよう、時間とは別に暦の上で加える。
+func parseDuration(s string) (int, time.Duration, error) {
+	invalid := fmt.Errorf("invalid DURATION %q", s)
+	value := strings.TrimPrefix(strings.TrimSpace(s), "+")
+	if !strings.HasPrefix(value, "P") || len(value) < 3 {
+		return 0, 0, invalid
+	}
+	value = value[1:]
+
+	days := 0
+	var clock time.Duration
+	inTime := false
+	for len(value) > 0 {
+		if value[0] == 'T' {
+			if inTime || len(value) == 1 {
+				return 0, 0, invalid
+			}
+			inTime = true
+			value = value[1:]
+			continue
+		}
+		i := 0
+		for i < len(value) && value[i] >= '0' && value[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(value) {
+			return 0, 0, invalid
+		}
+		n, err := strconv.Atoi(value[:i])
+		if err != nil {
+			return 0, 0, invalid
+		}
+		switch unit := value[i]; {
+		case !inTime && unit == 'W':
+			days += 7 * n
+		case !inTime && unit == 'D':
+			days += n
+		case inTime && unit == 'H':
+			clock += time.Duration(n) * time.Hour
+		case inTime && unit == 'M':
+			clock += time.Duration(n) * time.Minute
+		case inTime && unit == 'S':
+			clock += time.Duration(n) * time.Second
+		default:
+			return 0, 0, invalid
+		}
+		value = value[i+1:]
+	}
+	return days, clock, nil
+}
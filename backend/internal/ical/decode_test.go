@@ -0,0 +1,295 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// calendar は VEVENT などの行を VCALENDAR で囲んだ iCalendar を返す
+func calendar(lines ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+}
+
+func TestDecode(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		lines      []string
+		wantStart  time.Time
+		wantEnd    time.Time
+		wantAllDay bool
+		wantErr    string
+	}{
+		{
+			name:      "UTC の日時",
+			lines:     []string{"DTSTART:20300107T010000Z", "DTEND:20300107T020000Z"},
+			wantStart: time.Date(2030, 1, 7, 10, 0, 0, 0, jst),
+			wantEnd:   time.Date(2030, 1, 7, 11, 0, 0, 0, jst),
+		},
+		{
+			name:      "IANA の TZID",
+			lines:     []string{"DTSTART;TZID=America/New_York:20300707T090000", "DTEND;TZID=America/New_York:20300707T100000"},
+			wantStart: time.Date(2030, 7, 7, 9, 0, 0, 0, ny),
+			wantEnd:   time.Date(2030, 7, 7, 10, 0, 0, 0, ny),
+		},
+		{
+			name:      "フローティングの日時は既定のタイムゾーンで解釈する",
+			lines:     []string{"DTSTART:20300107T100000", "DTEND:20300107T110000"},
+			wantStart: time.Date(2030, 1, 7, 10, 0, 0, 0, jst),
+			wantEnd:   time.Date(2030, 1, 7, 11, 0, 0, 0, jst),
+		},
+		{
+			name:       "終日のイベント",
+			lines:      []string{"DTSTART;VALUE=DATE:20300107", "DTEND;VALUE=DATE:20300109"},
+			wantStart:  time.Date(2030, 1, 7, 0, 0, 0, 0, jst),
+			wantEnd:    time.Date(2030, 1, 9, 0, 0, 0, 0, jst),
+			wantAllDay: true,
+		},
+		{
+			name:       "DTEND のない終日のイベントは1日",
+			lines:      []string{"DTSTART;VALUE=DATE:20300107"},
+			wantStart:  time.Date(2030, 1, 7, 0, 0, 0, 0, jst),
+			wantEnd:    time.Date(2030, 1, 8, 0, 0, 0, 0, jst),
+			wantAllDay: true,
+		},
+		{
+			name:      "DURATION で終了時刻を決める",
+			lines:     []string{"DTSTART:20300107T010000Z", "DURATION:PT1H30M"},
+			wantStart: time.Date(2030, 1, 7, 10, 0, 0, 0, jst),
+			wantEnd:   time.Date(2030, 1, 7, 11, 30, 0, 0, jst),
+		},
+		{name: "DTSTART がない", lines: []string{"DTEND:20300107T020000Z"}, wantErr: "missing DTSTART"},
+		{name: "日時の形式が不正", lines: []string{"DTSTART:2030-01-07"}, wantErr: "invalid DTSTART"},
+		{name: "未知の TZID", lines: []string{"DTSTART;TZID=Nowhere:20300107T100000"}, wantErr: `unknown TZID "Nowhere"`},
+		{name: "終日と日時が混在している", lines: []string{"DTSTART;VALUE=DATE:20300107", "DTEND:20300107T020000Z"}, wantErr: "both be dates"},
+		{name: "終了時刻が開始時刻より前", lines: []string{"DTSTART:20300107T020000Z", "DTEND:20300107T010000Z"}, wantErr: "DTEND must not be before DTSTART"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			lines := append([]string{"BEGIN:VEVENT", "UID:event-1@example.com"}, tt.lines...)
+			input := calendar(append(lines, "END:VEVENT")...)
+
+			// 実行
+			events, err := Decode(strings.NewReader(input), jst)
+
+			// 検証
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(events))
+			}
+			got := events[0]
+			if tt.wantErr != "" {
+				if got.Err == nil || !strings.Contains(got.Err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, got.Err)
+				}
+				return
+			}
+			if got.Err != nil {
+				t.Fatalf("Expected no event error, got %v", got.Err)
+			}
+			if !got.Start.Equal(tt.wantStart) || !got.End.Equal(tt.wantEnd) || got.AllDay != tt.wantAllDay {
+				t.Errorf("Expected %v - %v (all day %v), got %v - %v (all day %v)", tt.wantStart, tt.wantEnd, tt.wantAllDay, got.Start, got.End, got.AllDay)
+			}
+		})
+	}
+}
+
+func TestDecode_Properties(t *testing.T) {
+	// 準備: 折り返し、エスケープ、引用符付きのパラメータを含むイベント
+	input := calendar(
+		"BEGIN:VEVENT",
+		"UID:event-1@example.com",
+		"DTSTART:20300107T010000Z",
+		"DTEND:20300107T020000Z",
+		"SUMMARY:定例会議\\; 進捗\\, 課",
+		" 題",
+		"DESCRIPTION:議題\\n1. 報告",
+		"LOCATION:会議室A",
+		"STATUS:tentative",
+		`ATTENDEE;CN="Sato, Hanako":MAILTO:a@example.com`,
+		"ATTENDEE:mailto:b@example.com",
+		"RRULE:FREQ=WEEKLY;COUNT=3",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:通知",
+		"END:VALARM",
+		"END:VEVENT",
+	)
+
+	// 実行
+	events, err := Decode(strings.NewReader(input), time.UTC)
+
+	// 検証
+	if err != nil || len(events) != 1 || events[0].Err != nil {
+		t.Fatalf("Expected 1 valid event, got %v, %v", events, err)
+	}
+	got := events[0]
+	if got.Summary != "定例会議; 進捗, 課題" {
+		t.Errorf("Unexpected summary %q", got.Summary)
+	}
+	if got.Description != "議題\n1. 報告" {
+		t.Errorf("Expected the description of the event rather than the alarm, got %q", got.Description)
+	}
+	if got.Location != "会議室A" || got.Status != StatusTentative || got.RRule != "FREQ=WEEKLY;COUNT=3" {
+		t.Errorf("Unexpected event %+v", got.Event)
+	}
+	if len(got.Attendees) != 2 || got.Attendees[0] != "a@example.com" || got.Attendees[1] != "b@example.com" {
+		t.Errorf("Unexpected attendees %v", got.Attendees)
+	}
+}
+
+func TestDecode_VTIMEZONE(t *testing.T) {
+	// 準備: IANA の名前ではない TZID を VTIMEZONE で定義する（米国東部時間）
+	timezone := []string{
+		"BEGIN:VTIMEZONE",
+		"TZID:Eastern Standard Time",
+		"BEGIN:STANDARD",
+		"DTSTART:16010101T020000",
+		"TZOFFSETFROM:-0400",
+		"TZOFFSETTO:-0500",
+		"RRULE:FREQ=YEARLY;BYDAY=1SU;BYMONTH=11",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"DTSTART:16010101T020000",
+		"TZOFFSETFROM:-0500",
+		"TZOFFSETTO:-0400",
+		"RRULE:FREQ=YEARLY;BYDAY=2SU;BYMONTH=3",
+		"END:DAYLIGHT",
+		"END:VTIMEZONE",
+	}
+
+	tests := []struct {
+		name  string
+		start string
+		want  time.Time
+	}{
+		{name: "標準時", start: "20300107T090000", want: time.Date(2030, 1, 7, 14, 0, 0, 0, time.UTC)},
+		{name: "夏時間", start: "20300707T090000", want: time.Date(2030, 7, 7, 13, 0, 0, 0, time.UTC)},
+		{name: "夏時間の開始日（3月の第2日曜日）", start: "20300310T090000", want: time.Date(2030, 3, 10, 13, 0, 0, 0, time.UTC)},
+		{name: "夏時間の開始の前日", start: "20300309T090000", want: time.Date(2030, 3, 9, 14, 0, 0, 0, time.UTC)},
+		{name: "標準時の開始日（11月の第1日曜日）", start: "20301103T090000", want: time.Date(2030, 11, 3, 14, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append([]string{}, timezone...)
+			lines = append(lines,
+				"BEGIN:VEVENT",
+				"UID:event-1@example.com",
+				`DTSTART;TZID="Eastern Standard Time":`+tt.start,
+				"DURATION:PT1H",
+				"END:VEVENT",
+			)
+
+			// 実行
+			events, err := Decode(strings.NewReader(calendar(lines...)), time.UTC)
+
+			// 検証
+			if err != nil || len(events) != 1 || events[0].Err != nil {
+				t.Fatalf("Expected 1 valid event, got %v, %v", events, err)
+			}
+			if !events[0].Start.Equal(tt.want) {
+				t.Errorf("Expected start %v, got %v", tt.want, events[0].Start.UTC())
+			}
+		})
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "VCALENDAR がない", input: "BEGIN:VEVENT\r\nEND:VEVENT\r\n", wantErr: "no VCALENDAR"},
+		{name: "END が対応しない", input: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n", wantErr: "line 3: unexpected END:VCALENDAR"},
+		{name: "END がない", input: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n", wantErr: "missing END:VCALENDAR"},
+		{name: "コロンのない行", input: "BEGIN:VCALENDAR\r\nVERSION\r\nEND:VCALENDAR\r\n", wantErr: "line 2: invalid content line"},
+		{name: "空の入力", input: "", wantErr: "no VCALENDAR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tt.input), time.UTC)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	// 準備: 書き出した iCalendar を読み込むと同じイベントになる
+	start := time.Date(2030, 1, 2, 1, 0, 0, 0, time.UTC)
+	want := Event{
+		UID:         "r-1@yoyaku",
+		Stamp:       start.Add(-time.Hour),
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Sequence:    1,
+		Summary:     strings.Repeat("長い件名, ", 10),
+		Description: "議題\n1. 報告",
+		Status:      StatusConfirmed,
+		Attendees:   []string{"a@example.com"},
+	}
+	var out bytes.Buffer
+	if err := Encode(&out, &Calendar{ProdID: "-//test//JA", Events: []Event{want}}); err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	// 実行
+	events, err := Decode(&out, time.UTC)
+
+	// 検証
+	if err != nil || len(events) != 1 || events[0].Err != nil {
+		t.Fatalf("Expected 1 valid event, got %v, %v", events, err)
+	}
+	got := events[0].Event
+	if got.UID != want.UID || got.Summary != want.Summary || got.Description != want.Description ||
+		!got.Start.Equal(want.Start) || !got.End.Equal(want.End) || !got.Stamp.Equal(want.Stamp) ||
+		got.Sequence != want.Sequence || got.Status != want.Status || len(got.Attendees) != 1 {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantDays  int
+		wantClock time.Duration
+		wantErr   bool
+	}{
+		{value: "PT1H30M", wantClock: 90 * time.Minute},
+		{value: "P1D", wantDays: 1},
+		{value: "P2W", wantDays: 14},
+		{value: "P1DT12H", wantDays: 1, wantClock: 12 * time.Hour},
+		{value: "+PT45S", wantClock: 45 * time.Second},
+		{value: "-PT1H", wantErr: true},
+		{value: "PT", wantErr: true},
+		{value: "P1H", wantErr: true},
+		{value: "1H", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			days, clock, err := parseDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDuration(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if days != tt.wantDays || clock != tt.wantClock {
+				t.Errorf("parseDuration(%q) = %d, %v, want %d, %v", tt.value, days, clock, tt.wantDays, tt.wantClock)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Package ical は予約をカレンダーアプリと交換するための iCalendar（RFC 5545）の書き出しと読み込みを扱う。
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType は iCalendar のメディアタイプ
+const ContentType = "text/calendar; charset=utf-8"
+
+// maxLineOctets は折り返す前の1行の最大バイト数（改行を除く）
+const maxLineOctets = 75
+
+// utcFormat は UTC の DATE-TIME の形式（例: 20300101T090000Z）
+const utcFormat = "20060102T150405Z"
+
+// EventStatus は VEVENT の STATUS
+type EventStatus string
+
+const (
+	StatusTentative EventStatus = "TENTATIVE"
+	StatusConfirmed EventStatus = "CONFIRMED"
+	StatusCancelled EventStatus = "CANCELLED"
+)
+
+// Calendar は VCALENDAR
+type Calendar struct {
+	// ProdID はカレンダーを作成した製品の識別子
+	ProdID string
+	// Name はカレンダーアプリに表示する名前（X-WR-CALNAME）。空の場合は出力しない。
+	Name   string
+	Events []Event
+}
+
+// Event は VEVENT。時刻は全て UTC で書き出す。
+type Event struct {
+	// UID はイベントを識別する値。同じイベントを書き出すたびに同じ値にする。
+	UID string
+	// Stamp は DTSTAMP（イベントの情報を作成した日時）
+	Stamp    time.Time
+	Start    time.Time
+	End      time.Time
+	Created  time.Time
+	Modified time.Time
+	// Sequence はイベントを変更するたびに増やす番号
+	Sequence    int64
+	Summary     string
+	Description string
+	Location    string
+	Status      EventStatus
+	// Attendees は参加者のメールアドレス
+	Attendees []string
+	// AllDay は日付だけで指定された終日のイベントであることを表す。読み込み時にだけ設定し、書き出しでは使わない。
+	AllDay bool
+	// RRule は繰り返すイベントの RRULE。読み込み時にだけ設定し、書き出しでは使わない。
+	RRule string
+}
+
+// Encode は cal を CRLF の改行と 75 バイトでの折り返しで w に書き出す
+func Encode(w io.Writer, cal *Calendar) error {
+	enc := &encoder{w: bufio.NewWriter(w)}
+	enc.line("BEGIN", "VCALENDAR")
+	enc.line("VERSION", "2.0")
+	enc.line("PRODID", escapeText(cal.ProdID))
+	enc.line("CALSCALE", "GREGORIAN")
+	enc.line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		enc.line("X-WR-CALNAME", escapeText(cal.Name))
+	}
+	for i := range cal.Events {
+		enc.event(&cal.Events[i])
+	}
+	enc.line("END", "VCALENDAR")
+	if enc.err != nil {
+		return enc.err
+	}
+	return enc.w.Flush()
+}
+
+// encoder は最初のエラーを保持し、以降の書き込みを行わない
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) event(event *Event) {
+	e.line("BEGIN", "VEVENT")
+	e.line("UID", escapeText(event.UID))
+	e.line("DTSTAMP", formatUTC(event.Stamp))
+	e.line("DTSTART", formatUTC(event.Start))
+	e.line("DTEND", formatUTC(event.End))
+	if !event.Created.IsZero() {
+		e.line("CREATED", formatUTC(event.Created))
+	}
+	if !event.Modified.IsZero() {
+		e.line("LAST-MODIFIED", formatUTC(event.Modified))
+	}
+	e.line("SEQUENCE", strconv.FormatInt(event.Sequence, 10))
+	e.line("SUMMARY", escapeText(event.Summary))
+	if event.Description != "" {
+		e.line("DESCRIPTION", escapeText(event.Description))
+	}
+	if event.Location != "" {
+		e.line("LOCATION", escapeText(event.Location))
+	}
+	if event.Status != "" {
+		e.line("STATUS", string(event.Status))
+	}
+	for _, attendee := range event.Attendees {
+		e.line("ATTENDEE", "mailto:"+attendee)
+	}
+	e.line("END", "VEVENT")
+}
+
+// line は "name:value" の1行を折り返して書き出す
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = e.w.WriteString(fold(name+":"+value) + "\r\n")
+}
+
+// fold は line を 75 バイトごとに折り返す。継続行は空白で始め、UTF-8 の文字の途中では折り返さない。
+func fold(line string) string {
+	if len(line) <= maxLineOctets {
+		return line
+	}
+	var b strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// 継続行の先頭の空白も 75 バイトに含める
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+// escapeText は TEXT の値のバックスラッシュ、セミコロン、カンマ、改行をエスケープする
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format(utcFormat)
+}
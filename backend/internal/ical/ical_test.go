@@ -0,0 +1,93 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	// 準備
+	jst := time.FixedZone("JST", 9*60*60)
+	cal := &Calendar{
+		ProdID: "-//yoyaku//reservations//JA",
+		Name:   "会議室A",
+		Events: []Event{{
+			UID:         "r-1@yoyaku",
+			Stamp:       time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			Start:       time.Date(2030, 1, 2, 10, 0, 0, 0, jst),
+			End:         time.Date(2030, 1, 2, 11, 0, 0, 0, jst),
+			Sequence:    2,
+			Summary:     "定例会議; 進捗, 課題",
+			Description: "議題\n1. 報告",
+			Location:    "会議室A",
+			Status:      StatusConfirmed,
+			Attendees:   []string{"a@example.com"},
+		}},
+	}
+	var out bytes.Buffer
+
+	// 実行
+	if err := Encode(&out, cal); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//yoyaku//reservations//JA",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:会議室A",
+		"BEGIN:VEVENT",
+		"UID:r-1@yoyaku",
+		"DTSTAMP:20300101T000000Z",
+		"DTSTART:20300102T010000Z",
+		"DTEND:20300102T020000Z",
+		"SEQUENCE:2",
+		`SUMMARY:定例会議\; 進捗\, 課題`,
+		`DESCRIPTION:議題\n1. 報告`,
+		"LOCATION:会議室A",
+		"STATUS:CONFIRMED",
+		"ATTENDEE:mailto:a@example.com",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	if out.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, out.String())
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "75バイト以下は折り返さない", line: "SUMMARY:" + strings.Repeat("a", 67)},
+		{name: "ASCII の長い行", line: "DESCRIPTION:" + strings.Repeat("a", 200)},
+		{name: "マルチバイト文字を含む長い行", line: "SUMMARY:" + strings.Repeat("予約", 60)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := fold(tt.line)
+
+			// 各行は 75 バイト以下で、継続行は空白で始まる
+			for i, line := range strings.Split(folded, "\r\n") {
+				if len(line) > maxLineOctets {
+					t.Errorf("Line %d is %d octets long", i, len(line))
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("Continuation line %d does not start with a space: %q", i, line)
+				}
+			}
+			// 折り返しを戻すと元の行になる
+			if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("Expected unfolded line %q, got %q", tt.line, unfolded)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zone は VTIMEZONE で定義されたタイムゾーン。定義を解釈できなかった場合は err を設定する。
+type zone struct {
+	id          string
+	observances []observance
+	err         error
+}
+
+// observance は VTIMEZONE の STANDARD または DAYLIGHT。onset の時刻（切り替え前の壁時計）から UTC との差が offsetTo になる。
+// 時刻は全て壁時計の時刻を UTC として保持する。
+type observance struct {
+	start      time.Time
+	offsetFrom int
+	offsetTo   int
+	rdates     []time.Time
+	rule       *yearlyRule
+}
+
+// yearlyRule は切り替えの日を決める FREQ=YEARLY の RRULE。
+// 日は BYDAY の第n曜日（負の場合は月末から数える）、BYMONTHDAY の候補のうち BYDAY の曜日に当たる日、または DTSTART と同じ日で決める。
+type yearlyRule struct {
+	month      time.Month
+	week       int
+	weekday    time.Weekday
+	hasWeekday bool
+	monthDays  []int
+	// until は最後の切り替えの壁時計の時刻。ゼロ値の場合は期限がない。
+	until time.Time
+	// count は切り替えの回数。0 の場合は回数の制限がない。
+	count int
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseZone は VTIMEZONE を解釈する
+func parseZone(c *component) *zone {
+	z := &zone{id: c.text("TZID")}
+	for _, child := range c.children {
+		if child.name != "STANDARD" && child.name != "DAYLIGHT" {
+			continue
+		}
+		o, err := parseObservance(child)
+		if err != nil {
+			z.err = fmt.Errorf("%s: %w", child.name, err)
+			return z
+		}
+		z.observances = append(z.observances, o)
+	}
+	if len(z.observances) == 0 {
+		z.err = errors.New("no STANDARD or DAYLIGHT")
+	}
+	return z
+}
+
+func parseObservance(c *component) (observance, error) {
+	var o observance
+	p, ok := c.get("DTSTART")
+	if !ok {
+		return o, errors.New("missing DTSTART")
+	}
+	start, err := time.Parse(localFormat, strings.TrimSpace(p.value))
+	if err != nil {
+		return o, fmt.Errorf("invalid DTSTART %q", p.value)
+	}
+	o.start = start
+
+	if p, ok = c.get("TZOFFSETFROM"); !ok {
+		return o, errors.New("missing TZOFFSETFROM")
+	}
+	if o.offsetFrom, err = parseOffset(p.value); err != nil {
+		return o, err
+	}
+	if p, ok = c.get("TZOFFSETTO"); !ok {
+		return o, errors.New("missing TZOFFSETTO")
+	}
+	if o.offsetTo, err = parseOffset(p.value); err != nil {
+		return o, err
+	}
+
+	for _, p := range c.properties {
+		if p.name != "RDATE" {
+			continue
+		}
+		for _, v := range strings.Split(p.value, ",") {
+			t, err := time.Parse(localFormat, strings.TrimSpace(v))
+			if err != nil {
+				return o, fmt.Errorf("invalid RDATE %q", v)
+			}
+			o.rdates = append(o.rdates, t)
+		}
+	}
+	if p, ok := c.get("RRULE"); ok {
+		if o.rule, err = parseYearlyRule(p.value, o.offsetFrom); err != nil {
+			return o, err
+		}
+	}
+	return o, nil
+}
+
+// parseOffset は UTC との差（例: +0900、-0430、+053000）を秒数で返す
+func parseOffset(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if (len(s) != 5 && len(s) != 7) || (s[0] != '+' && s[0] != '-') {
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	seconds := 0
+	for i, unit := range []int{3600, 60, 1}[:(len(s)-1)/2] {
+		n, err := strconv.Atoi(s[1+2*i : 3+2*i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid UTC offset %q", s)
+		}
+		seconds += n * unit
+	}
+	if s[0] == '-' {
+		seconds = -seconds
+	}
+	return seconds, nil
+}
+
+// parseYearlyRule は VTIMEZONE の RRULE を解釈する。UNTIL が UTC の場合は切り替え前の UTC との差 offsetFrom で壁時計の時刻にする。
+func parseYearlyRule(value string, offsetFrom int) (*yearlyRule, error) {
+	invalid := fmt.Errorf("unsupported RRULE %q", value)
+	rule := &yearlyRule{}
+	freq := ""
+	for _, part := range strings.Split(value, ";") {
+		key, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, invalid
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			freq = strings.ToUpper(v)
+		case "BYMONTH":
+			month, err := strconv.Atoi(v)
+			if err != nil || month < 1 || month > 12 {
+				return nil, invalid
+			}
+			rule.month = time.Month(month)
+		case "BYDAY":
+			v = strings.ToUpper(v)
+			if len(v) < 2 {
+				return nil, invalid
+			}
+			weekday, ok := weekdays[v[len(v)-2:]]
+			if !ok {
+				return nil, invalid
+			}
+			rule.weekday, rule.hasWeekday = weekday, true
+			if ordinal := strings.TrimPrefix(v[:len(v)-2], "+"); ordinal != "" {
+				week, err := strconv.Atoi(ordinal)
+				if err != nil || week == 0 || week < -5 || week > 5 {
+					return nil, invalid
+				}
+				rule.week = week
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(v, ",") {
+				day, err := strconv.Atoi(d)
+				if err != nil || day < 1 || day > 31 {
+					return nil, invalid
+				}
+				rule.monthDays = append(rule.monthDays, day)
+			}
+		case "UNTIL":
+			if strings.HasSuffix(v, "Z") {
+				until, err := time.Parse(utcFormat, v)
+				if err != nil {
+					return nil, invalid
+				}
+				rule.until = until.Add(time.Duration(offsetFrom) * time.Second)
+			} else {
+				until, err := time.Parse(localFormat, v)
+				if err != nil {
+					return nil, invalid
+				}
+				rule.until = until
+			}
+		case "COUNT":
+			count, err := strconv.Atoi(v)
+			if err != nil || count < 1 {
+				return nil, invalid
+			}
+			rule.count = count
+		case "INTERVAL":
+			if v != "1" {
+				return nil, invalid
+			}
+		case "WKST":
+		default:
+			return nil, invalid
+		}
+	}
+	if freq != "YEARLY" || rule.month == 0 {
+		return nil, invalid
+	}
+	return rule, nil
+}
+
+// onset は year の切り替えの壁時計の時刻を返す。該当する日がない場合は false を返す。
+func (r *yearlyRule) onset(year int, start time.Time) (time.Time, bool) {
+	at := func(day int) time.Time {
+		return time.Date(year, r.month, day, start.Hour(), start.Minute(), start.Second(), 0, time.UTC)
+	}
+	switch {
+	case r.week > 0:
+		first := at(1)
+		t := first.AddDate(0, 0, (int(r.weekday)-int(first.Weekday())+7)%7+7*(r.week-1))
+		return t, t.Month() == r.month
+	case r.week < 0:
+		last := at(1).AddDate(0, 1, -1)
+		t := last.AddDate(0, 0, -((int(last.Weekday())-int(r.weekday)+7)%7)+7*(r.week+1))
+		return t, t.Month() == r.month
+	case len(r.monthDays) > 0:
+		for _, day := range r.monthDays {
+			t := at(day)
+			if t.Month() == r.month && (!r.hasWeekday || t.Weekday() == r.weekday) {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	default:
+		t := at(start.Day())
+		return t, t.Month() == r.month
+	}
+}
+
+// latestOnset は wall 以前で最後の切り替えの壁時計の時刻を返す。wall 以前に切り替えがない場合は false を返す。
+func (o *observance) latestOnset(wall time.Time) (time.Time, bool) {
+	var latest time.Time
+	found := false
+	consider := func(t time.Time) {
+		if !t.After(wall) && (!found || t.After(latest)) {
+			latest, found = t, true
+		}
+	}
+	consider(o.start)
+	for _, t := range o.rdates {
+		consider(t)
+	}
+	if r := o.rule; r != nil {
+		for year := wall.Year(); year >= wall.Year()-1 && year >= o.start.Year(); year-- {
+			t, ok := r.onset(year, o.start)
+			if !ok || t.Before(o.start) ||
+				(!r.until.IsZero() && t.After(r.until)) ||
+				(r.count > 0 && year-o.start.Year() >= r.count) {
+				continue
+			}
+			consider(t)
+		}
+	}
+	return latest, found
+}
+
+// at は壁時計の時刻 wall をこのタイムゾーンの時刻にする。
+// 切り替えの直後の存在しない時刻や重複する時刻は、切り替え前の壁時計で比べて決める。
+func (z *zone) at(wall time.Time) time.Time {
+	var current *observance
+	var currentOnset time.Time
+	for i := range z.observances {
+		o := &z.observances[i]
+		if t, ok := o.latestOnset(wall); ok && (current == nil || t.After(currentOnset)) {
+			current, currentOnset = o, t
+		}
+	}
+
+	var offset int
+	if current != nil {
+		offset = current.offsetTo
+	} else {
+		// 最初の切り替えより前の時刻は、最初の切り替えの前の UTC との差を使う
+		first := &z.observances[0]
+		for i := range z.observances {
+			if z.observances[i].start.Before(first.start) {
+				first = &z.observances[i]
+			}
+		}
+		offset = first.offsetFrom
+	}
+	return inLocation(wall, time.FixedZone(z.id, offset))
+}
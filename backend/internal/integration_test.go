@@ -2,144 +2,1601 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/handler"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/service"
 )
 
-func setupTest() *echo.Echo {
+// setupTest はテスト用サーバーと予約可能なリソースのIDを返す。
+// auth.UserIDHeader のないリクエストは登録済みの管理者からのリクエストとして扱う。
+func setupTest() (*echo.Echo, string) {
+	userRepo := repository.NewInMemoryUserRepository()
+	admin := model.NewUser("管理者", "admin@example.com", model.RoleAdmin)
+	_ = userRepo.Create(admin)
+
+	e, resourceID, _ := setupServer(userRepo)
+	e.Pre(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get(auth.UserIDHeader) == "" {
+				c.Request().Header.Set(auth.UserIDHeader, admin.ID)
+			}
+			return next(c)
+		}
+	})
+	return e, resourceID
+}
+
+// setupServer は auth.UserIDHeader で userRepo のユーザーを識別するテスト用サーバーと予約可能なリソースのIDを返す
+func setupServer(userRepo repository.UserRepository) (*echo.Echo, string, *service.WebhookService) {
 	e := echo.New()
-	
+
 	// リポジトリ、サービス、ハンドラーの初期化
+	e.Use(auth.UserHeader(userRepo))
 	repo := repository.NewInMemoryReservationRepository()
-	svc := service.NewReservationService(repo)
+	resourceRepo := repository.NewInMemoryResourceRepository()
+	calendarService := service.NewCalendarService(repository.NewInMemoryCalendarRepository(), resourceRepo)
+	svc := service.NewReservationService(repo, resourceRepo, repository.NewInMemoryReservationSeriesRepository())
+	svc.SetCalendar(calendarService)
+	availabilityService := service.NewAvailabilityService(repo, resourceRepo)
+	availabilityService.SetCalendar(calendarService)
 	h := handler.NewReservationHandler(svc)
-	
+	h.SetIdempotency(repository.NewInMemoryIdempotencyRepository(), time.Hour)
+	resourceHandler := handler.NewResourceHandler(service.NewResourceService(resourceRepo, repo))
+	availabilityHandler := handler.NewAvailabilityHandler(availabilityService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+	holidayHandler := handler.NewHolidayHandler()
+	userHandler := handler.NewUserHandler(service.NewUserService(userRepo))
+	feedService := service.NewCalendarFeedService(repository.NewInMemoryCalendarFeedRepository(), userRepo, resourceRepo)
+	feedHandler := handler.NewCalendarFeedHandler(feedService, svc)
+	calDAVHandler := handler.NewCalDAVHandler(feedService, service.NewResourceService(resourceRepo, repo), svc)
+	// 配送は DeliverDue でテストから送る
+	webhookService := service.NewWebhookService(repository.NewInMemoryWebhookRepository(), nil)
+	svc.SetEvents(webhookService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
 	// ルートの登録
 	h.RegisterRoutes(e)
-	
-	return e
+	resourceHandler.RegisterRoutes(e)
+	availabilityHandler.RegisterRoutes(e)
+	calendarHandler.RegisterRoutes(e)
+	holidayHandler.RegisterRoutes(e)
+	userHandler.RegisterRoutes(e)
+	feedHandler.RegisterRoutes(e)
+	calDAVHandler.RegisterRoutes(e)
+	webhookHandler.RegisterRoutes(e)
+
+	// 予約対象のリソースを用意
+	resource := model.NewResource("会議室A", "", 6, true)
+	_ = resourceRepo.Create(resource)
+
+	return e, resource.ID, webhookService
 }
 
 func TestIntegrationCreateAndGetReservation(t *testing.T) {
 	// テスト用サーバーのセットアップ
-	e := setupTest()
-	
+	e, resourceID := setupTest()
+
 	// 予約作成リクエストの準備
 	now := time.Now()
 	startTime := now.Add(time.Hour).Format(time.RFC3339)
 	endTime := now.Add(2 * time.Hour).Format(time.RFC3339)
-	payload := map[string]string{
-		"startTime": startTime,
-		"endTime":   endTime,
+	payload := map[string]any{
+		"resourceId":  resourceID,
+		"startTime":   startTime,
+		"endTime":     endTime,
+		"title":       "定例会議",
+		"description": "週次の進捗確認",
+		"attendees":   []string{"Taro@Example.com"},
 	}
-	
+
 	payloadBytes, _ := json.Marshal(payload)
-	
+
 	// 予約作成リクエストの送信
 	req := httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payloadBytes))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
-	
+
 	e.ServeHTTP(rec, req)
-	
+
 	// レスポンスの検証
 	if rec.Code != http.StatusCreated {
 		t.Errorf("Expected status code %d, got %d", http.StatusCreated, rec.Code)
 	}
-	
+
 	// 作成された予約のIDを取得
 	var createdReservation model.Reservation
 	if err := json.Unmarshal(rec.Body.Bytes(), &createdReservation); err != nil {
 		t.Fatalf("Failed to unmarshal created reservation: %v", err)
 	}
-	
+
 	// 予約一覧取得リクエストの送信
 	req = httptest.NewRequest(http.MethodGet, "/api/reservations", nil)
 	rec = httptest.NewRecorder()
-	
+
 	e.ServeHTTP(rec, req)
-	
+
 	// レスポンスの検証
 	if rec.Code != http.StatusOK {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, rec.Code)
 	}
-	
+
 	// 予約一覧の検証
 	var reservations []model.Reservation
 	if err := json.Unmarshal(rec.Body.Bytes(), &reservations); err != nil {
 		t.Fatalf("Failed to unmarshal reservations: %v", err)
 	}
-	
+
 	if len(reservations) != 1 {
 		t.Errorf("Expected 1 reservation, got %d", len(reservations))
 	}
-	
+
 	if reservations[0].ID != createdReservation.ID {
 		t.Errorf("Expected reservation ID %s, got %s", createdReservation.ID, reservations[0].ID)
 	}
+	if r := reservations[0]; r.Title != "定例会議" || r.Description != "週次の進捗確認" || len(r.Attendees) != 1 || r.Attendees[0] != "taro@example.com" {
+		t.Errorf("Expected title, description and attendees to be returned, got %+v", r)
+	}
+
+	// IDを指定して取得できる
+	req = httptest.NewRequest(http.MethodGet, "/api/reservations/"+createdReservation.ID, nil)
+	rec = httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	var found model.Reservation
+	if err := json.Unmarshal(rec.Body.Bytes(), &found); err != nil {
+		t.Fatalf("Failed to unmarshal reservation: %v", err)
+	}
+	if found.ID != createdReservation.ID || found.Title != "定例会議" {
+		t.Errorf("Expected reservation %s, got %+v", createdReservation.ID, found)
+	}
+	if rec.Header().Get("ETag") != `"1"` {
+		t.Errorf("Expected ETag %q, got %q", `"1"`, rec.Header().Get("ETag"))
+	}
+
+	// 存在しない予約は404
+	req = httptest.NewRequest(http.MethodGet, "/api/reservations/unknown", nil)
+	rec = httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rec.Code)
+	}
 }
 
 func TestIntegrationDeleteReservation(t *testing.T) {
 	// テスト用サーバーのセットアップ
-	e := setupTest()
-	
+	e, resourceID := setupTest()
+
 	// 予約作成リクエストの準備
 	now := time.Now()
 	startTime := now.Add(time.Hour).Format(time.RFC3339)
 	endTime := now.Add(2 * time.Hour).Format(time.RFC3339)
 	payload := map[string]string{
-		"startTime": startTime,
-		"endTime":   endTime,
+		"resourceId": resourceID,
+		"startTime":  startTime,
+		"endTime":    endTime,
 	}
-	
+
 	payloadBytes, _ := json.Marshal(payload)
-	
+
 	// 予約作成リクエストの送信
 	req := httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payloadBytes))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
-	
+
 	e.ServeHTTP(rec, req)
-	
+
 	// 作成された予約のIDを取得
 	var createdReservation model.Reservation
 	if err := json.Unmarshal(rec.Body.Bytes(), &createdReservation); err != nil {
 		t.Fatalf("Failed to unmarshal created reservation: %v", err)
 	}
-	
-	// 予約削除リクエストの送信
+
+	// 予約削除リクエストの送信（作成時の ETag で版を指定する）
+	etag := rec.Header().Get("ETag")
 	req = httptest.NewRequest(http.MethodDelete, "/api/reservations/"+createdReservation.ID, nil)
+	req.Header.Set("If-Match", etag)
 	rec = httptest.NewRecorder()
-	
+
 	e.ServeHTTP(rec, req)
-	
+
 	// レスポンスの検証
 	if rec.Code != http.StatusNoContent {
 		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, rec.Code)
 	}
-	
+
 	// 予約が削除されたことを確認
 	req = httptest.NewRequest(http.MethodGet, "/api/reservations", nil)
 	rec = httptest.NewRecorder()
-	
+
 	e.ServeHTTP(rec, req)
-	
+
 	// 予約一覧の検証
 	var reservations []model.Reservation
 	if err := json.Unmarshal(rec.Body.Bytes(), &reservations); err != nil {
 		t.Fatalf("Failed to unmarshal reservations: %v", err)
 	}
-	
+
 	if len(reservations) != 0 {
 		t.Errorf("Expected 0 reservations after deletion, got %d", len(reservations))
 	}
-}
\ No newline at end of file
+
+	// 取り消した予約は状態を指定すると取得でき、取り消し日時が記録されている
+	req = httptest.NewRequest(http.MethodGet, "/api/reservations?status=cancelled", nil)
+	rec = httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &reservations); err != nil {
+		t.Fatalf("Failed to unmarshal reservations: %v", err)
+	}
+	if len(reservations) != 1 || reservations[0].ID != createdReservation.ID {
+		t.Fatalf("Expected cancelled reservation to be kept, got %v", reservations)
+	}
+	if reservations[0].Status != model.StatusCancelled || reservations[0].CancelledAt == nil {
+		t.Errorf("Expected reservation to be cancelled with cancelledAt, got %+v", reservations[0])
+	}
+
+	// 取り消した時間帯は再び予約できる
+	req = httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payloadBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status code %d, got %d", http.StatusCreated, rec.Code)
+	}
+
+	// 存在しない予約の取り消しは404
+	req = httptest.NewRequest(http.MethodDelete, "/api/reservations/unknown", nil)
+	req.Header.Set("If-Match", "*")
+	rec = httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestIntegrationCreateOverlappingReservation(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, resourceID := setupTest()
+
+	base := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	post := func(start, end time.Time) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(map[string]string{
+			"resourceId": resourceID,
+			"startTime":  start.Format(time.RFC3339),
+			"endTime":    end.Format(time.RFC3339),
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 最初の予約は成功する
+	rec := post(base, base.Add(1*time.Hour))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", http.StatusCreated, rec.Code)
+	}
+	var first model.Reservation
+	if err := json.Unmarshal(rec.Body.Bytes(), &first); err != nil {
+		t.Fatalf("Failed to unmarshal created reservation: %v", err)
+	}
+
+	// 重なる予約は409になり、重複した予約のIDが返る
+	rec = post(base.Add(30*time.Minute), base.Add(90*time.Minute))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("Expected status code %d, got %d", http.StatusConflict, rec.Code)
+	}
+	var conflict struct {
+		Conflicts []struct {
+			ID string `json:"id"`
+		} `json:"conflicts"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &conflict); err != nil {
+		t.Fatalf("Failed to unmarshal conflict response: %v", err)
+	}
+	if len(conflict.Conflicts) != 1 || conflict.Conflicts[0].ID != first.ID {
+		t.Errorf("Expected conflict with %s, got %+v", first.ID, conflict.Conflicts)
+	}
+
+	// 直後に連続する予約は成功する
+	rec = post(base.Add(1*time.Hour), base.Add(2*time.Hour))
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status code %d for back-to-back reservation, got %d", http.StatusCreated, rec.Code)
+	}
+}
+
+func TestIntegrationIdempotentCreate(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, resourceID := setupTest()
+
+	base := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	post := func(key string, start time.Time) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(map[string]string{
+			"resourceId": resourceID,
+			"startTime":  start.Format(time.RFC3339),
+			"endTime":    start.Add(time.Hour).Format(time.RFC3339),
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(handler.IdempotencyKeyHeader, key)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 最初のリクエストで予約が作成される
+	rec := post("retry-1", base)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var created model.Reservation
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal created reservation: %v", err)
+	}
+
+	// 再送しても重複した予約にならず、同じ予約が返る
+	rec = post("retry-1", base)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected replayed status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var replayed model.Reservation
+	if err := json.Unmarshal(rec.Body.Bytes(), &replayed); err != nil {
+		t.Fatalf("Failed to unmarshal replayed reservation: %v", err)
+	}
+	if replayed.ID != created.ID {
+		t.Errorf("Expected replayed reservation %s, got %s", created.ID, replayed.ID)
+	}
+
+	// 同じキーで別の時間帯を指定すると422になる
+	rec = post("retry-1", base.Add(2*time.Hour))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	}
+
+	// 予約は1件だけ作成されている
+	req := httptest.NewRequest(http.MethodGet, "/api/reservations", nil)
+	listRec := httptest.NewRecorder()
+	e.ServeHTTP(listRec, req)
+	var reservations []model.Reservation
+	if err := json.Unmarshal(listRec.Body.Bytes(), &reservations); err != nil {
+		t.Fatalf("Failed to unmarshal reservations: %v", err)
+	}
+	if len(reservations) != 1 {
+		t.Errorf("Expected 1 reservation, got %d", len(reservations))
+	}
+}
+
+func TestIntegrationResourceScopedReservations(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, roomA := setupTest()
+
+	// 2つ目のリソースをAPI経由で作成
+	req := httptest.NewRequest(http.MethodPost, "/api/resources", bytes.NewReader([]byte(`{"name": "会議室B", "capacity": 10}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", http.StatusCreated, rec.Code)
+	}
+	var roomB model.Resource
+	if err := json.Unmarshal(rec.Body.Bytes(), &roomB); err != nil {
+		t.Fatalf("Failed to unmarshal created resource: %v", err)
+	}
+
+	// 同じ時間帯でも別のリソースなら予約できる
+	base := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	for _, resourceID := range []string{roomA, roomB.ID} {
+		payload, _ := json.Marshal(map[string]string{
+			"resourceId": resourceID,
+			"startTime":  base.Format(time.RFC3339),
+			"endTime":    base.Add(time.Hour).Format(time.RFC3339),
+		})
+		req = httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status code %d for %s, got %d", http.StatusCreated, resourceID, rec.Code)
+		}
+	}
+
+	// リソースで絞り込んだ一覧には、そのリソースの予約だけが含まれる
+	req = httptest.NewRequest(http.MethodGet, "/api/reservations?resourceId="+roomB.ID, nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	var reservations []model.Reservation
+	if err := json.Unmarshal(rec.Body.Bytes(), &reservations); err != nil {
+		t.Fatalf("Failed to unmarshal reservations: %v", err)
+	}
+	if len(reservations) != 1 || reservations[0].ResourceID != roomB.ID {
+		t.Errorf("Expected 1 reservation of %s, got %+v", roomB.ID, reservations)
+	}
+
+	// 予約が残っているリソースは削除できない
+	req = httptest.NewRequest(http.MethodDelete, "/api/resources/"+roomB.ID, nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d", http.StatusConflict, rec.Code)
+	}
+}
+
+func TestIntegrationPaginateReservations(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, resourceID := setupTest()
+
+	// 1時間ずつずらして5件予約する
+	base := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	var created []string
+	for i := range 5 {
+		payload, _ := json.Marshal(map[string]string{
+			"resourceId": resourceID,
+			"startTime":  base.Add(time.Duration(i) * time.Hour).Format(time.RFC3339),
+			"endTime":    base.Add(time.Duration(i+1) * time.Hour).Format(time.RFC3339),
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got %d", http.StatusCreated, rec.Code)
+		}
+		var reservation model.Reservation
+		if err := json.Unmarshal(rec.Body.Bytes(), &reservation); err != nil {
+			t.Fatalf("Failed to unmarshal created reservation: %v", err)
+		}
+		created = append(created, reservation.ID)
+	}
+
+	// Link ヘッダーをたどって開始時刻の降順に2件ずつ取得する
+	var got []string
+	next := "/api/reservations?sort=startTime&order=desc&limit=2"
+	for pages := 0; next != ""; pages++ {
+		if pages > len(created) {
+			t.Fatal("Expected paging to finish")
+		}
+		req := httptest.NewRequest(http.MethodGet, next, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		var reservations []model.Reservation
+		if err := json.Unmarshal(rec.Body.Bytes(), &reservations); err != nil {
+			t.Fatalf("Failed to unmarshal reservations: %v", err)
+		}
+		for _, r := range reservations {
+			got = append(got, r.ID)
+		}
+
+		next = ""
+		if link := rec.Header().Get("Link"); link != "" {
+			next = strings.TrimPrefix(strings.TrimSuffix(link, `>; rel="next"`), "<")
+		}
+	}
+
+	slices.Reverse(created)
+	if !slices.Equal(got, created) {
+		t.Errorf("Expected %v, got %v", created, got)
+	}
+
+	// 長さで絞り込むと条件に合う予約はない
+	req := httptest.NewRequest(http.MethodGet, "/api/reservations?minDuration=2h", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("Expected no reservations, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestIntegrationRescheduleReservation(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, resourceID := setupTest()
+
+	base := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	send := func(method, path, ifMatch string, payload map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 2件の予約を作成
+	var created []model.Reservation
+	var etags []string
+	for _, offset := range []time.Duration{0, 2 * time.Hour} {
+		rec := send(http.MethodPost, "/api/reservations", "", map[string]string{
+			"resourceId": resourceID,
+			"startTime":  base.Add(offset).Format(time.RFC3339),
+			"endTime":    base.Add(offset + time.Hour).Format(time.RFC3339),
+		})
+		var r model.Reservation
+		if err := json.Unmarshal(rec.Body.Bytes(), &r); err != nil {
+			t.Fatalf("Failed to unmarshal created reservation: %v", err)
+		}
+		created = append(created, r)
+		etags = append(etags, rec.Header().Get("ETag"))
+	}
+
+	// If-Match のない変更は428
+	rec := send(http.MethodPatch, "/api/reservations/"+created[0].ID, "", map[string]string{"title": "定例会議"})
+	if rec.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected status code %d, got %d", http.StatusPreconditionRequired, rec.Code)
+	}
+
+	// PATCHで1件目を後ろにずらす（IDと作成日時は保持される）
+	rec = send(http.MethodPatch, "/api/reservations/"+created[0].ID, etags[0], map[string]string{
+		"startTime": base.Add(1 * time.Hour).Format(time.RFC3339),
+		"endTime":   base.Add(2 * time.Hour).Format(time.RFC3339),
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	var moved model.Reservation
+	if err := json.Unmarshal(rec.Body.Bytes(), &moved); err != nil {
+		t.Fatalf("Failed to unmarshal updated reservation: %v", err)
+	}
+	if moved.ID != created[0].ID || !moved.CreatedAt.Equal(created[0].CreatedAt) {
+		t.Error("Expected ID and createdAt to be preserved")
+	}
+	if !moved.StartTime.Equal(base.Add(1 * time.Hour)) {
+		t.Errorf("Expected start time %v, got %v", base.Add(1*time.Hour), moved.StartTime)
+	}
+	movedETag := rec.Header().Get("ETag")
+	if movedETag == etags[0] {
+		t.Errorf("Expected ETag to change after update, got %s", movedETag)
+	}
+
+	// 変更前の ETag を指定した変更は他の変更を上書きしないよう412
+	rec = send(http.MethodPatch, "/api/reservations/"+created[0].ID, etags[0], map[string]string{"title": "古い内容からの変更"})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected status code %d, got %d", http.StatusPreconditionFailed, rec.Code)
+	}
+
+	// 2件目と重なるように延ばすと409
+	rec = send(http.MethodPatch, "/api/reservations/"+created[0].ID, movedETag, map[string]string{
+		"endTime": base.Add(150 * time.Minute).Format(time.RFC3339),
+	})
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d", http.StatusConflict, rec.Code)
+	}
+
+	// 存在しない予約は404
+	rec = send(http.MethodPut, "/api/reservations/non-existent-id", "*", map[string]string{
+		"resourceId": resourceID,
+		"startTime":  base.Format(time.RFC3339),
+		"endTime":    base.Add(time.Hour).Format(time.RFC3339),
+	})
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestIntegrationRecurringReservation(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, resourceID := setupTest()
+
+	// 3回目と重なる単発の予約を先に作成
+	start := time.Now().Truncate(time.Hour).Add(24 * time.Hour)
+	third := start.AddDate(0, 0, 14)
+	payloadBytes, _ := json.Marshal(map[string]string{
+		"resourceId": resourceID,
+		"startTime":  third.Format(time.RFC3339),
+		"endTime":    third.Add(time.Hour).Format(time.RFC3339),
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payloadBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", http.StatusCreated, rec.Code)
+	}
+
+	// 毎週4回の繰り返し予約を作成
+	payloadBytes, _ = json.Marshal(map[string]string{
+		"resourceId": resourceID,
+		"startTime":  start.Format(time.RFC3339),
+		"endTime":    start.Add(time.Hour).Format(time.RFC3339),
+		"recurrence": "FREQ=WEEKLY;COUNT=4",
+	})
+	req = httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payloadBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	// 3回目だけ予約できなかったことを検証
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var result struct {
+		Series       model.ReservationSeries `json:"series"`
+		Reservations []model.Reservation     `json:"reservations"`
+		Skipped      []struct {
+			StartTime time.Time `json:"startTime"`
+		} `json:"skipped"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(result.Reservations) != 3 {
+		t.Errorf("Expected 3 reservations, got %d", len(result.Reservations))
+	}
+	if len(result.Skipped) != 1 || !result.Skipped[0].StartTime.Equal(third) {
+		t.Errorf("Expected occurrence at %v to be skipped, got %v", third, result.Skipped)
+	}
+
+	// 期間を指定して一覧を取得すると該当する回だけが返る
+	query := "/api/reservations?from=" + url.QueryEscape(start.AddDate(0, 0, 7).Format(time.RFC3339)) +
+		"&to=" + url.QueryEscape(start.AddDate(0, 0, 8).Format(time.RFC3339))
+	req = httptest.NewRequest(http.MethodGet, query, nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var reservations []model.Reservation
+	if err := json.Unmarshal(rec.Body.Bytes(), &reservations); err != nil {
+		t.Fatalf("Failed to unmarshal reservations: %v", err)
+	}
+	if len(reservations) != 1 || reservations[0].SeriesID != result.Series.ID {
+		t.Errorf("Expected the second occurrence of the series, got %v", reservations)
+	}
+}
+
+func TestIntegrationCancelFollowingOccurrences(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, resourceID := setupTest()
+
+	// 毎週8回の繰り返し予約を作成
+	start := time.Now().Truncate(time.Hour).Add(24 * time.Hour)
+	payloadBytes, _ := json.Marshal(map[string]string{
+		"resourceId": resourceID,
+		"startTime":  start.Format(time.RFC3339),
+		"endTime":    start.Add(time.Hour).Format(time.RFC3339),
+		"recurrence": "FREQ=WEEKLY;COUNT=8",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payloadBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", http.StatusCreated, rec.Code)
+	}
+	var created struct {
+		Series       model.ReservationSeries `json:"series"`
+		Reservations []model.Reservation     `json:"reservations"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	// 7回目以降を取り消す
+	req = httptest.NewRequest(http.MethodDelete, "/api/reservations/"+created.Reservations[6].ID+"?scope=following", nil)
+	req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, created.Reservations[6].Version))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, rec.Code)
+	}
+
+	// シリーズには6回が残り、取り消した回が本来の開始時刻で記録される
+	req = httptest.NewRequest(http.MethodGet, "/api/reservation-series/"+created.Series.ID, nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	var detail struct {
+		Reservations []model.Reservation     `json:"reservations"`
+		Exceptions   []model.SeriesException `json:"exceptions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(detail.Reservations) != 6 {
+		t.Errorf("Expected 6 remaining occurrences, got %d", len(detail.Reservations))
+	}
+	if len(detail.Exceptions) != 2 || !detail.Exceptions[0].OriginalStartTime.Equal(created.Reservations[6].StartTime) {
+		t.Errorf("Expected the 7th and 8th occurrences to be recorded as cancelled, got %+v", detail.Exceptions)
+	}
+}
+
+func TestIntegrationAvailability(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, resourceID := setupTest()
+
+	// 10:00-11:00 に予約を入れる
+	day := time.Now().Truncate(24*time.Hour).AddDate(0, 0, 1).UTC()
+	payloadBytes, _ := json.Marshal(map[string]string{
+		"resourceId": resourceID,
+		"startTime":  day.Add(10 * time.Hour).Format(time.RFC3339),
+		"endTime":    day.Add(11 * time.Hour).Format(time.RFC3339),
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/reservations", bytes.NewReader(payloadBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", http.StatusCreated, rec.Code)
+	}
+
+	// 9:00-18:00 の1時間以上の空きを検索
+	query := url.Values{
+		"resourceId": {resourceID},
+		"from":       {day.Add(9 * time.Hour).Format(time.RFC3339)},
+		"to":         {day.Add(18 * time.Hour).Format(time.RFC3339)},
+		"duration":   {"1h"},
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/availability?"+query.Encode(), nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	// 予約の前後の2つの空きが返る
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var slots []model.TimeRange
+	if err := json.Unmarshal(rec.Body.Bytes(), &slots); err != nil {
+		t.Fatalf("Failed to unmarshal slots: %v", err)
+	}
+	if len(slots) != 2 || !slots[0].EndTime.Equal(day.Add(10*time.Hour)) || !slots[1].StartTime.Equal(day.Add(11*time.Hour)) {
+		t.Errorf("Expected free slots around the reservation, got %v", slots)
+	}
+}
+
+func TestIntegrationBusinessHoursAndBlackouts(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, resourceID := setupTest()
+	send := func(method, target string, body any) *httptest.ResponseRecorder {
+		payloadBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, target, bytes.NewReader(payloadBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 毎日 9:00-18:00（UTC）を営業時間にする
+	hours := make([]map[string]any, 0, 7)
+	for weekday := 0; weekday < 7; weekday++ {
+		hours = append(hours, map[string]any{"weekday": weekday, "open": "09:00", "close": "18:00"})
+	}
+	if rec := send(http.MethodPut, "/api/calendar/hours", map[string]any{"timeZone": "UTC", "hours": hours}); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	// 翌日 12:00-13:00 を休業期間にする
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	rec := send(http.MethodPost, "/api/calendar/blackouts", map[string]string{
+		"startTime": day.Add(12 * time.Hour).Format(time.RFC3339),
+		"endTime":   day.Add(13 * time.Hour).Format(time.RFC3339),
+		"reason":    "点検",
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	// 営業時間外と休業期間の予約は 422 になる
+	for _, window := range [][2]time.Duration{{7 * time.Hour, 8 * time.Hour}, {12 * time.Hour, 13 * time.Hour}} {
+		rec := send(http.MethodPost, "/api/reservations", map[string]string{
+			"resourceId": resourceID,
+			"startTime":  day.Add(window[0]).Format(time.RFC3339),
+			"endTime":    day.Add(window[1]).Format(time.RFC3339),
+		})
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status code %d for %v, got %d", http.StatusUnprocessableEntity, window, rec.Code)
+		}
+	}
+
+	// 営業時間内は予約できる
+	rec = send(http.MethodPost, "/api/reservations", map[string]string{
+		"resourceId": resourceID,
+		"startTime":  day.Add(10 * time.Hour).Format(time.RFC3339),
+		"endTime":    day.Add(11 * time.Hour).Format(time.RFC3339),
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	// 休業時間の一覧
+	query := url.Values{
+		"resourceId": {resourceID},
+		"from":       {day.Format(time.RFC3339)},
+		"to":         {day.AddDate(0, 0, 1).Format(time.RFC3339)},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/calendar/closed?"+query.Encode(), nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	var closed []model.ClosedPeriod
+	if err := json.Unmarshal(rec.Body.Bytes(), &closed); err != nil {
+		t.Fatalf("Failed to unmarshal closed periods: %v", err)
+	}
+	if len(closed) != 3 || closed[1].Reason != model.ClosedBlackout || closed[1].Note != "点検" {
+		t.Errorf("Expected before-opening, blackout and after-closing periods, got %+v", closed)
+	}
+
+	// 空き時間からも除かれる
+	query.Set("duration", "1h")
+	req = httptest.NewRequest(http.MethodGet, "/api/availability?"+query.Encode(), nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	var slots []model.TimeRange
+	if err := json.Unmarshal(rec.Body.Bytes(), &slots); err != nil {
+		t.Fatalf("Failed to unmarshal slots: %v", err)
+	}
+	want := []model.TimeRange{
+		{StartTime: day.Add(9 * time.Hour), EndTime: day.Add(10 * time.Hour)},
+		{StartTime: day.Add(11 * time.Hour), EndTime: day.Add(12 * time.Hour)},
+		{StartTime: day.Add(13 * time.Hour), EndTime: day.Add(18 * time.Hour)},
+	}
+	if len(slots) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, slots)
+	}
+	for i := range want {
+		if !slots[i].StartTime.Equal(want[i].StartTime) || !slots[i].EndTime.Equal(want[i].EndTime) {
+			t.Errorf("Expected %v, got %v", want, slots)
+		}
+	}
+}
+
+func TestIntegrationReservationOwnership(t *testing.T) {
+	// ユーザーのいないテスト用サーバーのセットアップ
+	e, resourceID, _ := setupServer(repository.NewInMemoryUserRepository())
+	send := func(method, target, userID string, body any) *httptest.ResponseRecorder {
+		payloadBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, target, bytes.NewReader(payloadBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if userID != "" {
+			req.Header.Set(auth.UserIDHeader, userID)
+		}
+		// 権限の確認が目的なので版は問わない
+		req.Header.Set("If-Match", "*")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 最初のユーザーを管理者として登録し、一般ユーザーは自分で、それ以外の権限のユーザーは管理者が登録する
+	createUser := func(callerID, name, email, role string) model.User {
+		rec := send(http.MethodPost, "/api/users", callerID, map[string]string{"displayName": name, "email": email, "role": role})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+		}
+		var user model.User
+		_ = json.Unmarshal(rec.Body.Bytes(), &user)
+		return user
+	}
+	admin := createUser("", "管理者", "admin@example.com", "admin")
+	owner := createUser("", "山田", "yamada@example.com", "")
+	other := createUser("", "鈴木", "suzuki@example.com", "")
+	viewer := createUser(admin.ID, "佐藤", "sato@example.com", "viewer")
+	approver := createUser(admin.ID, "田中", "tanaka@example.com", "approver")
+
+	// 匿名では一般ユーザー以外を登録できない
+	if rec := send(http.MethodPost, "/api/users", "", map[string]string{"displayName": "伊藤", "email": "ito@example.com", "role": "approver"}); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	// 存在しないユーザーのIDは 401
+	if rec := send(http.MethodGet, "/api/reservations", "missing", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	// 予約すると所有者が記録される
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	createReservation := func() model.Reservation {
+		rec := send(http.MethodPost, "/api/reservations", owner.ID, map[string]string{
+			"resourceId": resourceID,
+			"startTime":  start.Format(time.RFC3339),
+			"endTime":    start.Add(time.Hour).Format(time.RFC3339),
+		})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+		}
+		var reservation model.Reservation
+		_ = json.Unmarshal(rec.Body.Bytes(), &reservation)
+		if reservation.UserID != owner.ID {
+			t.Fatalf("Expected owner %s, got %q", owner.ID, reservation.UserID)
+		}
+		return reservation
+	}
+	reservation := createReservation()
+
+	// 他のユーザーと匿名のリクエストは取り消せない
+	for _, userID := range []string{other.ID, ""} {
+		if rec := send(http.MethodDelete, "/api/reservations/"+reservation.ID, userID, nil); rec.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, rec.Code)
+		}
+	}
+
+	// 所有者は取り消せる
+	if rec := send(http.MethodDelete, "/api/reservations/"+reservation.ID, owner.ID, nil); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+
+	// 管理者は他人の予約を変更できる
+	reservation = createReservation()
+	rec := send(http.MethodPatch, "/api/reservations/"+reservation.ID, admin.ID, map[string]string{
+		"endTime": start.Add(2 * time.Hour).Format(time.RFC3339),
+	})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	// 閲覧者は予約できず、一覧では埋まっている時間帯だけが見える
+	if rec := send(http.MethodPost, "/api/reservations", viewer.ID, map[string]string{
+		"resourceId": resourceID,
+		"startTime":  start.Add(3 * time.Hour).Format(time.RFC3339),
+		"endTime":    start.Add(4 * time.Hour).Format(time.RFC3339),
+	}); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, rec.Code)
+	}
+	rec = send(http.MethodGet, "/api/reservations", viewer.ID, nil)
+	var listed []model.Reservation
+	_ = json.Unmarshal(rec.Body.Bytes(), &listed)
+	if rec.Code != http.StatusOK || len(listed) != 1 || listed[0].UserID != "" {
+		t.Errorf("Expected 1 reservation without owner, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// 匿名のリクエストは一覧も空き時間も参照できない
+	availability := "/api/availability?" + url.Values{
+		"resourceId": {resourceID},
+		"from":       {start.Format(time.RFC3339)},
+		"to":         {start.Add(8 * time.Hour).Format(time.RFC3339)},
+		"duration":   {"1h"},
+	}.Encode()
+	for _, path := range []string{"/api/reservations", availability} {
+		if rec := send(http.MethodGet, path, "", nil); rec.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusForbidden, path, rec.Code)
+		}
+	}
+	if rec := send(http.MethodGet, availability, viewer.ID, nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	// 承認者は他人の予約を変更でき、取り消せる
+	if rec := send(http.MethodPatch, "/api/reservations/"+reservation.ID, approver.ID, map[string]string{
+		"endTime": start.Add(3 * time.Hour).Format(time.RFC3339),
+	}); rec.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodDelete, "/api/reservations/"+reservation.ID, approver.ID, nil); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+
+	// 設定の変更は管理者だけができる
+	for _, userID := range []string{owner.ID, approver.ID} {
+		if rec := send(http.MethodPost, "/api/resources", userID, map[string]string{"name": "会議室B"}); rec.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, rec.Code)
+		}
+	}
+	if rec := send(http.MethodPost, "/api/resources", admin.ID, map[string]string{"name": "会議室B"}); rec.Code != http.StatusCreated {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+}
+
+func TestIntegrationCalendarFeed(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	userRepo := repository.NewInMemoryUserRepository()
+	member := model.NewUser("山田", "yamada@example.com", model.RoleMember)
+	_ = userRepo.Create(member)
+	e, resourceID, _ := setupServer(userRepo)
+	send := func(method, target, userID string, body any) *httptest.ResponseRecorder {
+		payloadBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, target, bytes.NewReader(payloadBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if userID != "" {
+			req.Header.Set(auth.UserIDHeader, userID)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 予約を作成する
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	rec := send(http.MethodPost, "/api/reservations", member.ID, map[string]string{
+		"resourceId": resourceID,
+		"startTime":  start.Format(time.RFC3339),
+		"endTime":    start.Add(time.Hour).Format(time.RFC3339),
+		"title":      "定例会議",
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var reservation model.Reservation
+	_ = json.Unmarshal(rec.Body.Bytes(), &reservation)
+	wantEvent := "UID:" + reservation.ID + "@yoyaku\r\nDTSTAMP:" + reservation.UpdatedAt.UTC().Format("20060102T150405Z") +
+		"\r\nDTSTART:" + start.UTC().Format("20060102T150405Z") + "\r\n"
+
+	// 認証したリクエストで .ics を書き出せる
+	rec = send(http.MethodGet, "/api/reservations.ics", member.ID, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), wantEvent) {
+		t.Fatalf("Expected the reservation in the export, got %d:\n%s", rec.Code, rec.Body.String())
+	}
+	// 匿名では書き出せない
+	if rec := send(http.MethodGet, "/api/reservations.ics", "", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	// フィードを発行すると、トークンの URL を認証なしで購読できる
+	rec = send(http.MethodPost, "/api/calendar-feeds", member.ID, map[string]string{"resourceId": resourceID})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var feed struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &feed)
+	feedURL, err := url.Parse(feed.URL)
+	if err != nil {
+		t.Fatalf("Invalid feed URL %q: %v", feed.URL, err)
+	}
+	rec = send(http.MethodGet, feedURL.Path, "", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), wantEvent) {
+		t.Fatalf("Expected the reservation in the feed, got %d:\n%s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "X-WR-CALNAME:予約 - 会議室A\r\n") {
+		t.Errorf("Expected the calendar to be named after the resource, got:\n%s", rec.Body.String())
+	}
+
+	// 削除したフィードは購読できない
+	if rec := send(http.MethodDelete, "/api/calendar-feeds/"+feed.ID, member.ID, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodGet, feedURL.Path, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestIntegrationCalDAV(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	userRepo := repository.NewInMemoryUserRepository()
+	member := model.NewUser("山田", "yamada@example.com", model.RoleMember)
+	_ = userRepo.Create(member)
+	e, resourceID, _ := setupServer(userRepo)
+
+	// CalDAV を有効にしたフィードを発行する
+	payload, _ := json.Marshal(map[string]any{"resourceId": resourceID, "caldav": true})
+	req := httptest.NewRequest(http.MethodPost, "/api/calendar-feeds", bytes.NewReader(payload))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(auth.UserIDHeader, member.ID)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var feed struct {
+		Token string `json:"token"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &feed)
+
+	send := func(method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.SetBasicAuth("yamada", feed.Token)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	calendar := "/caldav/calendars/" + resourceID + "/"
+
+	// トークンがない場合は Basic 認証を求める
+	req = httptest.NewRequest(echo.PROPFIND, calendar, nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || !strings.HasPrefix(rec.Header().Get(echo.HeaderWWWAuthenticate), "Basic") {
+		t.Fatalf("Expected Basic authentication challenge, got %d %q", rec.Code, rec.Header().Get(echo.HeaderWWWAuthenticate))
+	}
+
+	// カレンダーの一覧にフィードのリソースのカレンダーがある
+	rec = send(echo.PROPFIND, "/caldav/calendars/", `<propfind xmlns="DAV:"><prop><displayname/><resourcetype/></prop></propfind>`, map[string]string{"Depth": "1"})
+	if rec.Code != http.StatusMultiStatus || !strings.Contains(rec.Body.String(), "<href>"+calendar+"</href>") ||
+		!strings.Contains(rec.Body.String(), "会議室A") {
+		t.Fatalf("Expected the calendar in the home set, got %d:\n%s", rec.Code, rec.Body.String())
+	}
+	ctag := func() string {
+		rec := send(echo.PROPFIND, calendar, `<propfind xmlns="DAV:" xmlns:CS="http://calendarserver.org/ns/"><prop><CS:getctag/></prop></propfind>`, map[string]string{"Depth": "0"})
+		if rec.Code != http.StatusMultiStatus {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusMultiStatus, rec.Code, rec.Body.String())
+		}
+		return rec.Body.String()
+	}
+	before := ctag()
+
+	// イベントを PUT すると予約が作成される
+	start := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Hour)
+	event := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//test//EN\r\nBEGIN:VEVENT\r\nUID:caldav-event-1\r\n" +
+		"DTSTAMP:" + start.Format("20060102T150405Z") + "\r\nDTSTART:" + start.Format("20060102T150405Z") +
+		"\r\nDTEND:" + start.Add(time.Hour).Format("20060102T150405Z") + "\r\nSUMMARY:設計レビュー\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	eventURL := calendar + "caldav-event-1.ics"
+	rec = send(http.MethodPut, eventURL, event, map[string]string{"If-None-Match": "*", echo.HeaderContentType: "text/calendar"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	if after := ctag(); after == before {
+		t.Error("Expected the ctag to change after creating an event")
+	}
+
+	// 同じ時間帯に重なるイベントは REST API と同じく作成できない
+	overlapping := strings.ReplaceAll(event, "caldav-event-1", "caldav-event-2")
+	if rec := send(http.MethodPut, calendar+"caldav-event-2.ics", overlapping, nil); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusConflict, rec.Code, rec.Body.String())
+	}
+
+	// calendar-query で期間のイベントを取得できる
+	query := `<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><D:prop><D:getetag/><C:calendar-data/></D:prop>` +
+		`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT"><C:time-range start="` + start.Add(-time.Hour).Format("20060102T150405Z") +
+		`" end="` + start.Add(2*time.Hour).Format("20060102T150405Z") + `"/></C:comp-filter></C:comp-filter></C:filter></C:calendar-query>`
+	rec = send(echo.REPORT, calendar, query, map[string]string{"Depth": "1"})
+	if rec.Code != http.StatusMultiStatus || !strings.Contains(rec.Body.String(), "<href>"+eventURL+"</href>") ||
+		!strings.Contains(rec.Body.String(), "SUMMARY:設計レビュー") {
+		t.Fatalf("Expected the event in the calendar-query, got %d:\n%s", rec.Code, rec.Body.String())
+	}
+
+	// GET の ETag で変更し、古い ETag での削除は失敗する
+	rec = send(http.MethodGet, eventURL, "", nil)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected the event with an ETag, got %d %q", rec.Code, etag)
+	}
+	moved := strings.ReplaceAll(event, "設計レビュー", "設計レビュー（延期）")
+	if rec := send(http.MethodPut, eventURL, moved, nil); rec.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected status code %d without If-Match, got %d: %s", http.StatusPreconditionRequired, rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodPut, eventURL, moved, map[string]string{"If-Match": etag}); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodDelete, eventURL, "", map[string]string{"If-Match": etag}); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusPreconditionFailed, rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodDelete, eventURL, "", nil); rec.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected status code %d without If-Match, got %d: %s", http.StatusPreconditionRequired, rec.Code, rec.Body.String())
+	}
+
+	// イベントを削除すると予約が取り消され、カレンダーからなくなる
+	etag = send(http.MethodGet, eventURL, "", nil).Header().Get("ETag")
+	if rec := send(http.MethodDelete, eventURL, "", map[string]string{"If-Match": etag}); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodGet, eventURL, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rec.Code)
+	}
+	rec = send(http.MethodGet, "/api/reservations?status=cancelled", "", map[string]string{auth.UserIDHeader: member.ID})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"icalUid":"caldav-event-1"`) {
+		t.Errorf("Expected the reservation to be cancelled, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestIntegrationICalendarImport(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, resourceID := setupTest()
+	send := func(method, target string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, "text/calendar")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	type importResponse struct {
+		DryRun   bool `json:"dryRun"`
+		Created  int  `json:"created"`
+		Skipped  int  `json:"skipped"`
+		Rejected int  `json:"rejected"`
+		Results  []struct {
+			UID           string    `json:"uid"`
+			Outcome       string    `json:"outcome"`
+			Reason        string    `json:"reason"`
+			ReservationID string    `json:"reservationId"`
+			StartTime     time.Time `json:"startTime"`
+		} `json:"results"`
+	}
+	importCalendar := func(query, body string) importResponse {
+		t.Helper()
+		rec := send(http.MethodPost, "/api/reservations/import"+query, body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		var got importResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return got
+	}
+
+	// 旧システムの書き出し: VTIMEZONE で定義した TZID の予約、終日の予約、リソースが一致しない予約
+	year := time.Now().Year() + 1
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Old Booking Tool//EN",
+		"BEGIN:VTIMEZONE",
+		"TZID:Tokyo Standard Time",
+		"BEGIN:STANDARD",
+		"DTSTART:16010101T000000",
+		"TZOFFSETFROM:+0900",
+		"TZOFFSETTO:+0900",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:meeting-1@old.example.com",
+		fmt.Sprintf("DTSTART;TZID=Tokyo Standard Time:%d0107T100000", year),
+		fmt.Sprintf("DTEND;TZID=Tokyo Standard Time:%d0107T110000", year),
+		"SUMMARY:定例会議",
+		"LOCATION:会議室A",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:offsite-1@old.example.com",
+		fmt.Sprintf("DTSTART;VALUE=DATE:%d0108", year),
+		"SUMMARY:終日研修",
+		"LOCATION:会議室A",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:other-1@old.example.com",
+		fmt.Sprintf("DTSTART:%d0109T010000Z", year),
+		fmt.Sprintf("DTEND:%d0109T020000Z", year),
+		"LOCATION:会議室B",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	// ドライランでは結果を返すだけで予約を作成しない
+	got := importCalendar("?dryRun=true", calendar)
+	if !got.DryRun || got.Created != 2 || got.Rejected != 1 {
+		t.Fatalf("Unexpected dry run report: %+v", got)
+	}
+	wantStart := time.Date(year, 1, 7, 1, 0, 0, 0, time.UTC)
+	if !got.Results[0].StartTime.Equal(wantStart) {
+		t.Errorf("Expected the TZID time to start at %v, got %v", wantStart, got.Results[0].StartTime)
+	}
+	rec := send(http.MethodGet, "/api/reservations?resourceId="+resourceID, "")
+	var listed []model.Reservation
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil || len(listed) != 0 {
+		t.Fatalf("Expected no reservations after a dry run, got %s", rec.Body.String())
+	}
+
+	// 取り込むと予約が作成される
+	got = importCalendar("", calendar)
+	if got.DryRun || got.Created != 2 || got.Rejected != 1 || !strings.Contains(got.Results[2].Reason, "会議室B") {
+		t.Fatalf("Unexpected import report: %+v", got)
+	}
+	rec = send(http.MethodGet, "/api/reservations/"+got.Results[1].ReservationID, "")
+	var allDay model.Reservation
+	_ = json.Unmarshal(rec.Body.Bytes(), &allDay)
+	jst := time.FixedZone("JST", 9*60*60)
+	if !allDay.StartTime.Equal(time.Date(year, 1, 8, 0, 0, 0, 0, jst)) || !allDay.EndTime.Equal(time.Date(year, 1, 9, 0, 0, 0, 0, jst)) {
+		t.Errorf("Expected the all-day event to cover the day in Asia/Tokyo, got %v - %v", allDay.StartTime, allDay.EndTime)
+	}
+	if allDay.ICalUID != "offsite-1@old.example.com" || allDay.Title != "終日研修" {
+		t.Errorf("Unexpected imported reservation: %+v", allDay)
+	}
+
+	// 同じファイルをもう一度取り込むと UID の重複でスキップする
+	got = importCalendar("", calendar)
+	if got.Created != 0 || got.Skipped != 2 || got.Rejected != 1 {
+		t.Errorf("Expected duplicates to be skipped, got %+v", got)
+	}
+
+	// 書き出した iCalendar を取り込んでも予約は重複しない
+	rec = send(http.MethodGet, fmt.Sprintf("/api/reservations.ics?from=%d-01-01T00:00:00Z&to=%d-02-01T00:00:00Z", year, year), "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "UID:meeting-1@old.example.com\r\n") {
+		t.Fatalf("Expected the imported UID in the export, got %d:\n%s", rec.Code, rec.Body.String())
+	}
+	got = importCalendar("", rec.Body.String())
+	if got.Created != 0 || got.Skipped != 2 {
+		t.Errorf("Expected the exported reservations to be skipped, got %+v", got)
+	}
+}
+
+func TestIntegrationCSVExportImport(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	e, _ := setupTest()
+	send := func(method, target string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, "text/csv")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	type csvImportResponse struct {
+		DryRun   bool `json:"dryRun"`
+		Created  int  `json:"created"`
+		Skipped  int  `json:"skipped"`
+		Rejected int  `json:"rejected"`
+		Errors   []struct {
+			Row     int    `json:"row"`
+			Column  string `json:"column"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	importCSV := func(query, body string) csvImportResponse {
+		t.Helper()
+		rec := send(http.MethodPost, "/api/reservations/import.csv"+query, body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		var got csvImportResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return got
+	}
+
+	// Excel で保存した CSV: BOM、Excel 形式の日付、終了が開始より前の行、重なる行
+	year := time.Now().Year() + 1
+	body := "\ufeffresourceName,title,startTime,endTime,attendees\r\n" +
+		fmt.Sprintf("会議室A,定例会議,%d/1/7 10:00,%d/1/7 11:00,a@example.com;b@example.com\r\n", year, year) +
+		fmt.Sprintf("会議室A,\"=SUM(A1:A2)\",%d-01-07 13:00,%d-01-07 14:00,\r\n", year, year) +
+		fmt.Sprintf("会議室A,逆転,%d-01-07 16:00,%d-01-07 15:00,\r\n", year, year) +
+		fmt.Sprintf("会議室A,重なる,%d-01-07 10:30,%d-01-07 11:30,\r\n", year, year)
+
+	// ドライランでは予約を作成しない
+	got := importCSV("?dryRun=true", body)
+	if !got.DryRun || got.Created != 2 || got.Rejected != 2 || len(got.Errors) != 2 || got.Errors[0].Row != 4 || got.Errors[1].Row != 5 {
+		t.Fatalf("Unexpected dry run report: %+v", got)
+	}
+	window := fmt.Sprintf("from=%d-01-01T00:00:00Z&to=%d-02-01T00:00:00Z", year, year)
+	rec := send(http.MethodGet, "/api/reservations/export.csv?columns=id&"+window, "")
+	if rec.Code != http.StatusOK || rec.Body.String() != "\ufeffid\r\n" {
+		t.Fatalf("Expected no reservations after a dry run, got %d: %q", rec.Code, rec.Body.String())
+	}
+
+	// 取り込むと予約が作成される
+	got = importCSV("", body)
+	if got.DryRun || got.Created != 2 || got.Rejected != 2 {
+		t.Fatalf("Unexpected import report: %+v", got)
+	}
+
+	// 書き出した CSV は BOM 付きで、数式になる値を無効にする
+	rec = send(http.MethodGet, "/api/reservations/export.csv?columns=id,resourceName,title,startTime,endTime,attendees&"+window, "")
+	if rec.Code != http.StatusOK || rec.Header().Get(echo.HeaderContentDisposition) != `attachment; filename="reservations.csv"` {
+		t.Fatalf("Unexpected export response %d: %v", rec.Code, rec.Header())
+	}
+	exported := rec.Body.String()
+	lines := strings.Split(strings.TrimSuffix(exported, "\r\n"), "\r\n")
+	if len(lines) != 3 || lines[0] != "\ufeffid,resourceName,title,startTime,endTime,attendees" {
+		t.Fatalf("Unexpected export:\n%s", exported)
+	}
+	if !strings.HasSuffix(lines[1], fmt.Sprintf(",会議室A,定例会議,%d-01-07 10:00,%d-01-07 11:00,a@example.com;b@example.com", year, year)) ||
+		!strings.Contains(lines[2], ",'=SUM(A1:A2),") {
+		t.Errorf("Unexpected export rows:\n%s", exported)
+	}
+
+	// 書き出した CSV を取り込んでも予約は重複しない
+	got = importCSV("", exported)
+	if got.Created != 0 || got.Skipped != 2 || got.Rejected != 0 {
+		t.Errorf("Expected the exported reservations to be skipped, got %+v", got)
+	}
+
+	// 必須の列がない CSV は取り込まない
+	if rec := send(http.MethodPost, "/api/reservations/import.csv", "title,startTime\r\n"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestIntegrationJWTAuthentication(t *testing.T) {
+	// JWT 認証を有効にしたサーバーのセットアップ
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	verifier, err := auth.NewVerifier(auth.VerifierConfig{Keys: []auth.VerificationKey{auth.HS256Key(secret)}})
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	e := echo.New()
+	e.Use(auth.JWT(verifier))
+	repo := repository.NewInMemoryReservationRepository()
+	resourceRepo := repository.NewInMemoryResourceRepository()
+	resource := model.NewResource("会議室A", "", 6, true)
+	_ = resourceRepo.Create(resource)
+	svc := service.NewReservationService(repo, resourceRepo, repository.NewInMemoryReservationSeriesRepository())
+	handler.NewReservationHandler(svc).RegisterRoutes(e, auth.RequireAuth)
+	e.GET("/health", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	send := func(method, target, token string, body any) *httptest.ResponseRecorder {
+		payloadBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, target, bytes.NewReader(payloadBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// ヘルスチェックは認証なしで呼べる
+	if rec := send(http.MethodGet, "/health", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	// 予約のルートは認証が必要
+	if rec := send(http.MethodGet, "/api/reservations", "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	// トークンの sub が予約の所有者になる
+	token, err := auth.Sign(auth.Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   "user-1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}, auth.AlgorithmHS256, "", secret)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	rec := send(http.MethodPost, "/api/reservations", token, map[string]string{
+		"resourceId": resource.ID,
+		"startTime":  start.Format(time.RFC3339),
+		"endTime":    start.Add(time.Hour).Format(time.RFC3339),
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var reservation model.Reservation
+	_ = json.Unmarshal(rec.Body.Bytes(), &reservation)
+	if reservation.UserID != "user-1" {
+		t.Errorf("Expected owner user-1, got %q", reservation.UserID)
+	}
+}
+
+func TestIntegrationWebhooks(t *testing.T) {
+	// テスト用サーバーのセットアップ
+	userRepo := repository.NewInMemoryUserRepository()
+	admin := model.NewUser("管理者", "admin@example.com", model.RoleAdmin)
+	member := model.NewUser("山田", "yamada@example.com", model.RoleMember)
+	_ = userRepo.Create(admin)
+	_ = userRepo.Create(member)
+	e, resourceID, webhooks := setupServer(userRepo)
+	send := func(method, target, userID string, body any) *httptest.ResponseRecorder {
+		payloadBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, target, bytes.NewReader(payloadBytes))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if userID != "" {
+			req.Header.Set(auth.UserIDHeader, userID)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 通知を受け取るサーバー。最初の通知には 500 を返す。
+	var mutex sync.Mutex
+	var received []http.Header
+	var bodies [][]byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, r.Header.Clone())
+		bodies = append(bodies, body)
+		if len(received) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	// 管理者だけが登録できる
+	hook := map[string]any{"url": receiver.URL, "secret": "s3cret", "events": []string{"reservation.created", "reservation.deleted"}}
+	if rec := send(http.MethodPost, "/api/webhooks", member.ID, hook); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, rec.Code)
+	}
+	rec := send(http.MethodPost, "/api/webhooks", admin.ID, hook)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var subscription struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &subscription)
+
+	// 予約を作成して取り消す
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	rec = send(http.MethodPost, "/api/reservations", member.ID, map[string]string{
+		"resourceId": resourceID,
+		"startTime":  start.Format(time.RFC3339),
+		"endTime":    start.Add(time.Hour).Format(time.RFC3339),
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var reservation model.Reservation
+	_ = json.Unmarshal(rec.Body.Bytes(), &reservation)
+	req := httptest.NewRequest(http.MethodDelete, "/api/reservations/"+reservation.ID, nil)
+	req.Header.Set(auth.UserIDHeader, member.ID)
+	req.Header.Set("If-Match", "*")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+	if err := webhooks.DeliverDue(context.Background(), time.Now()); err != nil {
+		t.Fatalf("Failed to deliver webhooks: %v", err)
+	}
+
+	// 署名した通知を送り、失敗した通知は配送の記録に残る
+	mutex.Lock()
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", len(received))
+	}
+	for i, header := range received {
+		timestamp, _ := strconv.ParseInt(header.Get(service.WebhookTimestampHeader), 10, 64)
+		if header.Get(service.WebhookSignatureHeader) != service.SignWebhookPayload("s3cret", timestamp, bodies[i]) {
+			t.Errorf("Invalid signature for %s", header.Get(service.WebhookEventHeader))
+		}
+	}
+	mutex.Unlock()
+	rec = send(http.MethodGet, "/api/webhooks/"+subscription.ID+"/deliveries", admin.ID, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var deliveries []model.WebhookDelivery
+	_ = json.Unmarshal(rec.Body.Bytes(), &deliveries)
+	if len(deliveries) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", len(deliveries))
+	}
+	var failed *model.WebhookDelivery
+	for i, d := range deliveries {
+		if d.Status == model.DeliveryPending && d.ResponseStatus == http.StatusInternalServerError {
+			failed = &deliveries[i]
+		}
+	}
+	if failed == nil || failed.NextAttemptAt == nil {
+		t.Fatalf("Expected a delivery waiting for retry, got %+v", deliveries)
+	}
+
+	// 手動で再配送できる
+	rec = send(http.MethodPost, "/api/webhooks/"+subscription.ID+"/deliveries/"+failed.ID+"/redeliver", admin.ID, nil)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusAccepted, rec.Code, rec.Body.String())
+	}
+	if err := webhooks.DeliverDue(context.Background(), time.Now()); err != nil {
+		t.Fatalf("Failed to deliver webhooks: %v", err)
+	}
+	mutex.Lock()
+	if len(received) != 3 || string(bodies[2]) != string(bodies[0]) {
+		t.Errorf("Expected the failed event to be sent again, got %d notifications", len(received))
+	}
+	mutex.Unlock()
+}
@@ -0,0 +1,330 @@
+// Package migration はデータベースのスキーマを版ごとの SQL ファイルで変更する。
+//
+// マイグレーションは sql ディレクトリの NNNN_名前.up.sql（適用）と NNNN_名前.down.sql（取り消し）の組で、
+// 版の番号の順に適用し、適用済みの版を schema_migrations テーブルに記録する。
+// 1つのファイルには行末の ; で区切った文を複数書ける。MySQL の DDL はトランザクションで取り消せないため、
+// 途中の文で失敗したマイグレーションは記録されず、スキーマを手動で直してから再実行する必要がある。
+package migration
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+const (
+	// lockName はマイグレーション中に取得する名前付きロックの名前。複数のインスタンスが同時にマイグレーションしないようにする。
+	lockName = "yoyaku.schema_migrations"
+	// lockTimeout はロックの取得を待つ秒数
+	lockTimeout = 60
+)
+
+// ErrLocked は他のインスタンスがマイグレーション中でロックを取得できなかったことを表す
+var ErrLocked = errors.New("another migration is in progress")
+
+// Migration は1つの版のスキーマの変更
+type Migration struct {
+	Version int64
+	Name    string
+	// Up と Down は適用・取り消しで実行する文
+	Up   []string
+	Down []string
+}
+
+// Status はマイグレーションの適用状況。AppliedAt が nil の場合は未適用。
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// fileName はマイグレーションのファイル名（例: 0001_initial_schema.up.sql）
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Embedded はバイナリに埋め込んだマイグレーションを版の昇順で返す
+func Embedded() ([]Migration, error) {
+	sub, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+}
+
+// Load は fsys の直下の SQL ファイルからマイグレーションを読み込み、版の昇順で返す。
+// 名前の形式が不正なファイル、同じ版の重複、up と down の一方しかない版はエラーにする。
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, migration.Name, match[2])
+		}
+		statements := splitStatements(string(data))
+		if match[3] == "up" {
+			migration.Up = statements
+		} else {
+			migration.Down = statements
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == nil || migration.Down == nil {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return migrations, nil
+}
+
+// splitStatements は SQL を行末の ; で区切った文に分ける。-- で始まる行と空の文は除く。
+func splitStatements(script string) []string {
+	statements := make([]string, 0)
+	var current []string
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current = append(current, strings.TrimRight(line, " \t\r"))
+		if strings.HasSuffix(trimmed, ";") {
+			statement := strings.TrimSuffix(strings.TrimSpace(strings.Join(current, "\n")), ";")
+			if strings.TrimSpace(statement) != "" {
+				statements = append(statements, statement)
+			}
+			current = nil
+		}
+	}
+	if statement := strings.TrimSpace(strings.Join(current, "\n")); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// Migrator はマイグレーションを MySQL のデータベースに適用する
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator は migrations を db に適用する Migrator を返す
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	sorted := slices.Clone(migrations)
+	slices.SortFunc(sorted, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return &Migrator{db: db, migrations: sorted}
+}
+
+// Up は未適用のマイグレーションを版の昇順で全て適用し、適用したマイグレーションを返す。
+// 他のインスタンスがマイグレーション中の場合は終わるまで待ち、待ちきれない場合は ErrLocked を返す。
+// このバイナリが知らない新しい版が適用済みでも、知っている版だけを適用する。
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			if err := execAll(ctx, conn, migration, migration.Up); err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+				migration.Version, migration.Name, time.Now(),
+			); err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down は適用済みのマイグレーションを新しい版から steps 件取り消し、取り消したマイグレーションを返す。
+// このバイナリが知らない版が適用済みの場合は何もせずにエラーを返す。
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.checkKnown(versions); err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+			if err := execAll(ctx, conn, migration, migration.Down); err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", migration.Version); err != nil {
+				return fmt.Errorf("failed to remove migration %d: %w", migration.Version, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status は全てのマイグレーションの適用状況を版の昇順で返す
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := createTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	versions, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := versions[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// checkKnown はデータベースに適用済みの版が全てこのバイナリのマイグレーションに含まれるかを確認する。
+// 新しいバイナリで適用された版を残したまま、古いバイナリで前の版を取り消さないようにする。
+func (m *Migrator) checkKnown(versions map[int64]time.Time) error {
+	for version := range versions {
+		if !slices.ContainsFunc(m.migrations, func(migration Migration) bool { return migration.Version == version }) {
+			return fmt.Errorf("database has unknown migration %d applied; use a newer binary", version)
+		}
+	}
+	return nil
+}
+
+// withLock は名前付きロックを取得した専用コネクションで fn を実行する。schema_migrations テーブルはロックの取得後に作成する。
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&acquired); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return ErrLocked
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+	}()
+
+	if err := createTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func createTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions は適用済みの版と適用日時を返す
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		versions[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return versions, nil
+}
+
+// execAll は statements を順に実行する
+func execAll(ctx context.Context, conn *sql.Conn, migration Migration, statements []string) error {
+	for i, statement := range statements {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migration %d_%s failed at statement %d: %w", migration.Version, migration.Name, i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,422 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestEmbedded(t *testing.T) {
+	migrations, err := Embedded()
+	if err != nil {
+		t.Fatalf("Failed to load embedded migrations: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 || migrations[0].Name != "initial_schema" {
+		t.Fatalf("Expected migration 0001_initial_schema first, got %+v", migrations)
+	}
+	for i, migration := range migrations {
+		if i > 0 && migration.Version <= migrations[i-1].Version {
+			t.Errorf("Expected migrations in ascending order, got %d after %d", migration.Version, migrations[i-1].Version)
+		}
+		if len(migration.Up) == 0 || len(migration.Down) == 0 {
+			t.Errorf("Expected migration %d to have up and down statements", migration.Version)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []Migration
+		wantErr bool
+	}{
+		{
+			name: "版の昇順に読み込む",
+			files: fstest.MapFS{
+				"0002_add_index.up.sql":   {Data: []byte("CREATE INDEX idx ON t (a);\n")},
+				"0002_add_index.down.sql": {Data: []byte("DROP INDEX idx ON t;\n")},
+				"0001_create.up.sql":      {Data: []byte("-- テーブルを作る\nCREATE TABLE t (\n\ta INT\n);\nINSERT INTO t VALUES (1);\n")},
+				"0001_create.down.sql":    {Data: []byte("DROP TABLE t;")},
+				"README.md":               {Data: []byte("SQL 以外のファイルは無視する")},
+			},
+			want: []Migration{
+				{Version: 1, Name: "create", Up: []string{"CREATE TABLE t (\n\ta INT\n)", "INSERT INTO t VALUES (1)"}, Down: []string{"DROP TABLE t"}},
+				{Version: 2, Name: "add_index", Up: []string{"CREATE INDEX idx ON t (a)"}, Down: []string{"DROP INDEX idx ON t"}},
+			},
+		},
+		{
+			name:    "down がない",
+			files:   fstest.MapFS{"0001_create.up.sql": {Data: []byte("CREATE TABLE t (a INT);")}},
+			wantErr: true,
+		},
+		{
+			name: "同じ版に異なる名前",
+			files: fstest.MapFS{
+				"0001_create.up.sql":  {Data: []byte("CREATE TABLE t (a INT);")},
+				"0001_other.down.sql": {Data: []byte("DROP TABLE t;")},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "ファイル名の形式が不正",
+			files:   fstest.MapFS{"create.sql": {Data: []byte("CREATE TABLE t (a INT);")}},
+			wantErr: true,
+		},
+		{
+			name:    "版が0",
+			files:   fstest.MapFS{"0000_create.up.sql": {Data: []byte("")}, "0000_create.down.sql": {Data: []byte("")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.files)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// testMigrations はテスト用の2つの版のマイグレーション
+var testMigrations = []Migration{
+	{Version: 2, Name: "add_column", Up: []string{"ALTER TABLE t ADD COLUMN b INT"}, Down: []string{"ALTER TABLE t DROP COLUMN b"}},
+	{Version: 1, Name: "create", Up: []string{"CREATE TABLE t (a INT)"}, Down: []string{"DROP TABLE t"}},
+}
+
+// expectLock はロックの取得と schema_migrations テーブルの作成を期待する
+func expectLock(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).WithArgs(lockName, lockTimeout).
+		WillReturnRows(sqlmock.NewRows([]string{"GET_LOCK"}).AddRow(1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WithArgs(lockName).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestMigrator_Up(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// 版1は適用済み
+	expectLock(mock)
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE t ADD COLUMN b INT")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)")).
+		WithArgs(int64(2), "add_column", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectUnlock(mock)
+
+	// 実行
+	applied, err := NewMigrator(db, testMigrations).Up(context.Background())
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 2 {
+		t.Errorf("Expected migration 2 to be applied, got %+v", applied)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMigrator_Up_Failure(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// 版1の途中で失敗した場合は記録せず、版2も適用しない
+	expectLock(mock)
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE t (a INT)")).WillReturnError(errors.New("syntax error"))
+	expectUnlock(mock)
+
+	// 実行
+	applied, err := NewMigrator(db, testMigrations).Up(context.Background())
+
+	// 検証
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected no migration to be applied, got %+v", applied)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMigrator_Up_Locked(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// 他のインスタンスがロックを持ったままタイムアウトする
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).WithArgs(lockName, lockTimeout).
+		WillReturnRows(sqlmock.NewRows([]string{"GET_LOCK"}).AddRow(0))
+
+	// 実行
+	_, err = NewMigrator(db, testMigrations).Up(context.Background())
+
+	// 検証
+	if !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// 新しい版から1件取り消す
+	expectLock(mock)
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()).AddRow(2, time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE t DROP COLUMN b")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = ?")).
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectUnlock(mock)
+
+	// 実行
+	reverted, err := NewMigrator(db, testMigrations).Down(context.Background(), 1)
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Errorf("Expected migration 2 to be reverted, got %+v", reverted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMigrator_Down_UnknownVersion(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// 新しいバイナリで適用された版3が残っている場合は何も取り消さない
+	expectLock(mock)
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()).AddRow(2, time.Now()).AddRow(3, time.Now()))
+	expectUnlock(mock)
+
+	// 実行
+	reverted, err := NewMigrator(db, testMigrations).Down(context.Background(), 1)
+
+	// 検証
+	if err == nil || len(reverted) != 0 {
+		t.Errorf("Expected error without reverting, got %+v, %v", reverted, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMigrator_Status(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	appliedAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, appliedAt))
+
+	// 実行
+	statuses, err := NewMigrator(db, testMigrations).Status(context.Background())
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Version != 1 || statuses[1].Version != 2 {
+		t.Fatalf("Expected statuses for versions 1 and 2, got %+v", statuses)
+	}
+	if statuses[0].AppliedAt == nil || !statuses[0].AppliedAt.Equal(appliedAt) {
+		t.Errorf("Expected version 1 applied at %v, got %v", appliedAt, statuses[0].AppliedAt)
+	}
+	if statuses[1].AppliedAt != nil {
+		t.Errorf("Expected version 2 to be pending, got %v", statuses[1].AppliedAt)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+// baselineSchema は版管理の導入前にリポジトリの初期化時に作成していた予約のテーブル
+const baselineSchema = `CREATE TABLE IF NOT EXISTS reservations (
+	id VARCHAR(36) PRIMARY KEY,
+	start_time DATETIME NOT NULL,
+	end_time DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+)`
+
+// schema はテーブルごとの列の名前。マイグレーションの CREATE TABLE、DROP TABLE、ALTER TABLE の列の追加と削除だけを再現する。
+// UPDATE などのデータの変更は列を変えないため無視する。
+type schema map[string][]string
+
+// apply は文を適用する。既にある列の追加やない列の削除など、MySQL で失敗する変更はエラーにする。
+func (s schema) apply(statement string) error {
+	lines := strings.Split(statement, "\n")
+	// 1行目は「CREATE TABLE [IF NOT EXISTS] 名前 (」「DROP TABLE [IF EXISTS] 名前」「ALTER TABLE 名前」
+	header := strings.Fields(strings.TrimSuffix(strings.TrimSpace(lines[0]), "("))
+	words := strings.Fields(strings.ToUpper(strings.Join(header, " ")))
+	name := header[len(header)-1]
+	ifExists := slices.Contains(words, "EXISTS")
+	switch {
+	case len(words) >= 2 && words[0] == "CREATE" && words[1] == "TABLE":
+		if _, ok := s[name]; ok {
+			if ifExists {
+				return nil
+			}
+			return fmt.Errorf("table %s already exists", name)
+		}
+		columns := make([]string, 0)
+		for _, line := range lines[1:] {
+			field := strings.Fields(strings.TrimSpace(line))
+			if len(field) == 0 || field[0] == ")" || slices.Contains([]string{"PRIMARY", "INDEX", "UNIQUE", "KEY", "CONSTRAINT"}, field[0]) {
+				continue
+			}
+			columns = append(columns, field[0])
+		}
+		s[name] = columns
+	case len(words) >= 2 && words[0] == "DROP" && words[1] == "TABLE":
+		if _, ok := s[name]; !ok && !ifExists {
+			return fmt.Errorf("table %s does not exist", name)
+		}
+		delete(s, name)
+	case len(words) >= 2 && words[0] == "ALTER" && words[1] == "TABLE":
+		columns, ok := s[name]
+		if !ok {
+			return fmt.Errorf("table %s does not exist", name)
+		}
+		for _, line := range lines[1:] {
+			field := strings.Fields(strings.TrimSuffix(strings.TrimSpace(line), ","))
+			if len(field) < 3 || field[1] != "COLUMN" {
+				continue
+			}
+			switch field[0] {
+			case "ADD":
+				if slices.Contains(columns, field[2]) {
+					return fmt.Errorf("duplicate column %s.%s", name, field[2])
+				}
+				columns = append(columns, field[2])
+			case "DROP":
+				if !slices.Contains(columns, field[2]) {
+					return fmt.Errorf("unknown column %s.%s", name, field[2])
+				}
+				columns = slices.DeleteFunc(columns, func(c string) bool { return c == field[2] })
+			}
+		}
+		s[name] = columns
+	case len(words) >= 1 && words[0] == "UPDATE":
+	default:
+		return fmt.Errorf("unsupported statement %q", lines[0])
+	}
+	return nil
+}
+
+// sorted は列の順序を除いたスキーマを返す
+func (s schema) sorted() map[string][]string {
+	sorted := make(map[string][]string, len(s))
+	for table, columns := range s {
+		sorted[table] = slices.Sorted(slices.Values(columns))
+	}
+	return sorted
+}
+
+func TestEmbedded_Schema(t *testing.T) {
+	migrations, err := Embedded()
+	if err != nil {
+		t.Fatalf("Failed to load embedded migrations: %v", err)
+	}
+	up := func(s schema) {
+		t.Helper()
+		for _, migration := range migrations {
+			for _, statement := range migration.Up {
+				if err := s.apply(statement); err != nil {
+					t.Fatalf("Migration %d_%s failed: %v", migration.Version, migration.Name, err)
+				}
+			}
+		}
+	}
+
+	// 空のデータベースに全ての版を適用する
+	fresh := schema{}
+	up(fresh)
+
+	// 版管理の導入前のデータベースに適用しても同じスキーマになる
+	baseline := schema{}
+	if err := baseline.apply(baselineSchema); err != nil {
+		t.Fatalf("Failed to create baseline schema: %v", err)
+	}
+	up(baseline)
+	if !reflect.DeepEqual(baseline.sorted(), fresh.sorted()) {
+		t.Errorf("Expected the baseline database to reach\n%v\ngot\n%v", fresh.sorted(), baseline.sorted())
+	}
+
+	// 全ての版を取り消すと空になる
+	for i := len(migrations) - 1; i >= 0; i-- {
+		for _, statement := range migrations[i].Down {
+			if err := fresh.apply(statement); err != nil {
+				t.Fatalf("Migration %d_%s down failed: %v", migrations[i].Version, migrations[i].Name, err)
+			}
+		}
+	}
+	if len(fresh) != 0 {
+		t.Errorf("Expected no tables after reverting every migration, got %v", fresh)
+	}
+}
@@ -0,0 +1,10 @@
+-- 版管理の導入前にリポジトリの初期化時に作成していたテーブル。既存の環境では作成済みのため、IF NOT EXISTS でそのまま取り込む。
+-- 以降の列やテーブルは 0002 からの版で追加する。
+
+CREATE TABLE IF NOT EXISTS reservations (
+	id VARCHAR(36) PRIMARY KEY,
+	start_time DATETIME NOT NULL,
+	end_time DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
@@ -0,0 +1,24 @@
+DROP TABLE idempotency_keys;
+DROP TABLE users;
+DROP TABLE blackout_periods;
+DROP TABLE business_hours;
+DROP TABLE reservation_series_exceptions;
+DROP TABLE reservation_series;
+DROP TABLE resources;
+
+ALTER TABLE reservations
+	DROP INDEX idx_reservations_created,
+	DROP INDEX idx_reservations_user,
+	DROP INDEX idx_reservations_series,
+	DROP INDEX idx_reservations_time,
+	DROP INDEX idx_reservations_resource_time,
+	DROP COLUMN version,
+	DROP COLUMN cancellation_reason,
+	DROP COLUMN cancelled_at,
+	DROP COLUMN status,
+	DROP COLUMN attendees,
+	DROP COLUMN description,
+	DROP COLUMN title,
+	DROP COLUMN user_id,
+	DROP COLUMN series_id,
+	DROP COLUMN resource_id;
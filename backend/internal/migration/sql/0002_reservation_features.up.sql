@@ -0,0 +1,88 @@
+-- 0001 の予約にリソース、繰り返し、所有者、目的と参加者、状態、版を追加し、それらのテーブルを作成する。
+-- 既存の予約はリソースのない（resource_id が空の）予約になる。
+
+ALTER TABLE reservations
+	ADD COLUMN resource_id VARCHAR(36) NOT NULL DEFAULT '' AFTER id,
+	ADD COLUMN series_id VARCHAR(36) NULL AFTER resource_id,
+	ADD COLUMN user_id VARCHAR(36) NULL AFTER series_id,
+	ADD COLUMN title VARCHAR(200) NOT NULL DEFAULT '' AFTER user_id,
+	ADD COLUMN description VARCHAR(2000) NOT NULL DEFAULT '' AFTER title,
+	ADD COLUMN attendees JSON NULL AFTER description,
+	ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'confirmed' AFTER end_time,
+	ADD COLUMN cancelled_at DATETIME NULL AFTER status,
+	ADD COLUMN cancellation_reason VARCHAR(500) NOT NULL DEFAULT '' AFTER cancelled_at,
+	ADD COLUMN version BIGINT NOT NULL DEFAULT 1,
+	ADD INDEX idx_reservations_resource_time (resource_id, start_time, end_time),
+	ADD INDEX idx_reservations_time (start_time, end_time),
+	ADD INDEX idx_reservations_series (series_id),
+	ADD INDEX idx_reservations_user (user_id),
+	ADD INDEX idx_reservations_created (created_at, id);
+
+CREATE TABLE resources (
+	id VARCHAR(36) PRIMARY KEY,
+	name VARCHAR(100) NOT NULL,
+	description TEXT NOT NULL,
+	capacity INT NOT NULL DEFAULT 0,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE reservation_series (
+	id VARCHAR(36) PRIMARY KEY,
+	resource_id VARCHAR(36) NOT NULL,
+	recurrence VARCHAR(255) NOT NULL,
+	start_time DATETIME NOT NULL,
+	end_time DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE reservation_series_exceptions (
+	series_id VARCHAR(36) NOT NULL,
+	original_start_time DATETIME NOT NULL,
+	type VARCHAR(16) NOT NULL,
+	reservation_id VARCHAR(36) NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (series_id, original_start_time)
+);
+
+CREATE TABLE business_hours (
+	weekday TINYINT NOT NULL,
+	open_time CHAR(5) NOT NULL,
+	close_time CHAR(5) NOT NULL,
+	time_zone VARCHAR(64) NOT NULL,
+	PRIMARY KEY (weekday, open_time)
+);
+
+CREATE TABLE blackout_periods (
+	id VARCHAR(36) PRIMARY KEY,
+	resource_id VARCHAR(36) NOT NULL DEFAULT '',
+	start_time DATETIME NOT NULL,
+	end_time DATETIME NOT NULL,
+	reason VARCHAR(500) NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL,
+	INDEX idx_blackout_periods_time (start_time, end_time)
+);
+
+CREATE TABLE users (
+	id VARCHAR(36) PRIMARY KEY,
+	display_name VARCHAR(100) NOT NULL,
+	email VARCHAR(254) NOT NULL,
+	role VARCHAR(16) NOT NULL DEFAULT 'member',
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	UNIQUE KEY uq_users_email (email)
+);
+
+CREATE TABLE idempotency_keys (
+	idempotency_key VARCHAR(320) PRIMARY KEY,
+	fingerprint CHAR(64) NOT NULL,
+	status_code INT NOT NULL DEFAULT 0,
+	header TEXT NOT NULL,
+	body MEDIUMBLOB NOT NULL,
+	created_at DATETIME NOT NULL,
+	expires_at DATETIME NOT NULL,
+	INDEX idx_idempotency_keys_expires (expires_at)
+);
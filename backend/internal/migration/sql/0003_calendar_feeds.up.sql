@@ -0,0 +1,10 @@
+CREATE TABLE calendar_feeds (
+	id VARCHAR(36) PRIMARY KEY,
+	user_id VARCHAR(36) NOT NULL,
+	role VARCHAR(16) NOT NULL,
+	resource_id VARCHAR(36) NULL,
+	token_hash CHAR(64) NOT NULL,
+	created_at DATETIME NOT NULL,
+	UNIQUE KEY uq_calendar_feeds_token (token_hash),
+	INDEX idx_calendar_feeds_user (user_id, created_at)
+);
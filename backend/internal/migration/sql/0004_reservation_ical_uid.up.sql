@@ -0,0 +1,3 @@
+ALTER TABLE reservations
+	ADD COLUMN ical_uid VARCHAR(255) NULL,
+	ADD INDEX idx_reservations_ical_uid (ical_uid);
@@ -0,0 +1,2 @@
+ALTER TABLE calendar_feeds
+	DROP COLUMN caldav;
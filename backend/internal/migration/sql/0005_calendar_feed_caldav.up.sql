@@ -0,0 +1,2 @@
+ALTER TABLE calendar_feeds
+	ADD COLUMN caldav BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,26 @@
+CREATE TABLE webhook_subscriptions (
+	id VARCHAR(36) PRIMARY KEY,
+	url VARCHAR(2048) NOT NULL,
+	secret VARCHAR(255) NOT NULL,
+	events JSON NOT NULL,
+	created_by VARCHAR(36) NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+	id VARCHAR(36) PRIMARY KEY,
+	subscription_id VARCHAR(36) NOT NULL,
+	event_id VARCHAR(36) NOT NULL,
+	event VARCHAR(32) NOT NULL,
+	payload JSON NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	attempts INT NOT NULL DEFAULT 0,
+	response_status INT NOT NULL DEFAULT 0,
+	last_error VARCHAR(1000) NOT NULL DEFAULT '',
+	next_attempt_at DATETIME NULL,
+	redelivery_of VARCHAR(36) NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+	INDEX idx_webhook_deliveries_subscription (subscription_id, created_at)
+);
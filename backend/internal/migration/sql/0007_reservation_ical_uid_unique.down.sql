@@ -0,0 +1,3 @@
+ALTER TABLE reservations
+	DROP INDEX idx_reservations_ical_uid,
+	ADD INDEX idx_reservations_ical_uid (ical_uid);
@@ -0,0 +1,9 @@
+-- 同じ UID の予約を同時に取り込めないよう、UID の索引を一意にする。
+-- 既に同じ UID の予約がある場合は、最初に作成した予約だけに UID を残す。
+UPDATE reservations r
+	JOIN reservations earlier ON earlier.ical_uid = r.ical_uid
+		AND (earlier.created_at < r.created_at OR (earlier.created_at = r.created_at AND earlier.id < r.id))
+	SET r.ical_uid = NULL;
+ALTER TABLE reservations
+	DROP INDEX idx_reservations_ical_uid,
+	ADD UNIQUE INDEX idx_reservations_ical_uid (ical_uid);
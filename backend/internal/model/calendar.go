@@ -0,0 +1,87 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BusinessHours は1つの曜日の営業時間帯。Open と Close は "09:00" 形式の時刻で、Close には "24:00" を指定できる。
+type BusinessHours struct {
+	// Weekday は 0 (日曜日) 〜 6 (土曜日)
+	Weekday time.Weekday `json:"weekday"`
+	Open    string       `json:"open"`
+	Close   string       `json:"close"`
+}
+
+// WeeklySchedule は曜日ごとの営業時間。Hours が空の場合は終日営業として扱い、
+// Hours がある場合は含まれない曜日を休業日として扱う。同じ曜日に複数の時間帯（昼休みなど）を指定できる。
+type WeeklySchedule struct {
+	// TimeZone は営業時間を解釈する IANA タイムゾーン名（例: Asia/Tokyo）
+	TimeZone string          `json:"timeZone"`
+	Hours    []BusinessHours `json:"hours"`
+}
+
+// ParseClock は "09:00" 形式の時刻をその日の0時からの経過時間に変換する。"24:00" は24時間になる。
+func ParseClock(s string) (time.Duration, error) {
+	if len(s) != 5 || s[2] != ':' {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	for _, i := range []int{0, 1, 3, 4} {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, fmt.Errorf("invalid time of day %q", s)
+		}
+	}
+	hour := int(s[0]-'0')*10 + int(s[1]-'0')
+	min := int(s[3]-'0')*10 + int(s[4]-'0')
+	if min > 59 || hour > 24 || (hour == 24 && min != 0) {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute, nil
+}
+
+// Blackout は予約を受け付けない期間（メンテナンス日や会社の休業日など）
+type Blackout struct {
+	ID string `json:"id"`
+	// ResourceID が空の場合は全てのリソースに適用する
+	ResourceID string    `json:"resourceId,omitempty"`
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func NewBlackout(resourceID string, startTime, endTime time.Time, reason string) *Blackout {
+	return &Blackout{
+		ID:         uuid.New().String(),
+		ResourceID: resourceID,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// AppliesTo はリソースにこの期間が適用されるかを返す
+func (b *Blackout) AppliesTo(resourceID string) bool {
+	return b.ResourceID == "" || b.ResourceID == resourceID
+}
+
+// ClosedReason は予約を受け付けない理由
+type ClosedReason string
+
+const (
+	ClosedOutsideBusinessHours ClosedReason = "outside_business_hours"
+	ClosedBlackout             ClosedReason = "blackout"
+)
+
+// ClosedPeriod は予約を受け付けない時間帯
+type ClosedPeriod struct {
+	StartTime time.Time    `json:"startTime"`
+	EndTime   time.Time    `json:"endTime"`
+	Reason    ClosedReason `json:"reason"`
+	// BlackoutID と Note は Reason が blackout の場合だけ設定する
+	BlackoutID string `json:"blackoutId,omitempty"`
+	Note       string `json:"note,omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeed はカレンダーアプリが認証なしで予約を購読するための URL の登録。
+// URL に含めるトークンは発行時にだけ返し、保存するのはそのハッシュだけにする。
+type CalendarFeed struct {
+	ID string `json:"id"`
+	// UserID と Role はフィードを発行したユーザーと発行時の権限。
+	// フィードはユーザーの現在の権限で予約を配信し、ユーザーが登録されていない場合（JWT だけで認証する場合など）は Role を使う。
+	UserID string   `json:"userId"`
+	Role   UserRole `json:"role"`
+	// ResourceID を指定したフィードはそのリソースの予約だけを配信する
+	ResourceID string `json:"resourceId,omitempty"`
+	// CalDAV のフィードはトークンを CalDAV の Basic 認証のパスワードとしても使え、カレンダーアプリから予約を作成・変更・取り消しできる
+	CalDAV    bool      `json:"caldav"`
+	TokenHash string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewCalendarFeed(userID string, role UserRole, resourceID, tokenHash string) *CalendarFeed {
+	return &CalendarFeed{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Role:       role,
+		ResourceID: resourceID,
+		TokenHash:  tokenHash,
+		CreatedAt:  time.Now(),
+	}
+}
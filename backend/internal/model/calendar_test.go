@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "00:00", want: 0},
+		{input: "09:30", want: 9*time.Hour + 30*time.Minute},
+		{input: "24:00", want: 24 * time.Hour},
+		{input: "9:00", wantErr: true},
+		{input: " 9:00", wantErr: true},
+		{input: "24:30", wantErr: true},
+		{input: "12:60", wantErr: true},
+		{input: "ab:cd", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseClock(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %v", tt.input, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Expected %v, got %v (err %v)", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestBlackout_AppliesTo(t *testing.T) {
+	now := time.Now()
+	global := NewBlackout("", now, now.Add(time.Hour), "")
+	scoped := NewBlackout("room-a", now, now.Add(time.Hour), "")
+
+	if !global.AppliesTo("room-b") {
+		t.Error("Expected global blackout to apply to every resource")
+	}
+	if !scoped.AppliesTo("room-a") || scoped.AppliesTo("room-b") {
+		t.Error("Expected resource blackout to apply only to its resource")
+	}
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// IdempotencyRecord は Idempotency-Key を付けたリクエストと、そのリクエストへの最初のレスポンスの記録
+type IdempotencyRecord struct {
+	// Key はリクエストを送ったユーザーごとに区別したキー
+	Key string
+	// Fingerprint はリクエストのメソッド・パス・本文から求めたハッシュ。同じキーで別のリクエストが送られたことを検出する。
+	Fingerprint string
+	// StatusCode が 0 の記録は最初のリクエストを処理中であることを表す
+	StatusCode int
+	// Header は再送時にも返すレスポンスヘッダー（Content-Type、ETag など）
+	Header map[string]string
+	Body   []byte
+	// CreatedAt は最初のリクエストを受け付けた日時
+	CreatedAt time.Time
+	// ExpiresAt を過ぎた記録は存在しないものとして扱う
+	ExpiresAt time.Time
+}
+
+// Completed はレスポンスが記録済みかを返す
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// Expired は now の時点で記録の有効期限が切れているかを返す
+func (r *IdempotencyRecord) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
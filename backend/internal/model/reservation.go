@@ -6,21 +6,88 @@ import (
 	"github.com/google/uuid"
 )
 
+// ReservationStatus は予約の状態
+type ReservationStatus string
+
+const (
+	StatusPending   ReservationStatus = "pending"
+	StatusConfirmed ReservationStatus = "confirmed"
+	StatusCancelled ReservationStatus = "cancelled"
+	StatusCompleted ReservationStatus = "completed"
+	StatusNoShow    ReservationStatus = "no_show"
+)
+
+// ReservationStatuses は全ての予約の状態
+var ReservationStatuses = []ReservationStatus{StatusPending, StatusConfirmed, StatusCancelled, StatusCompleted, StatusNoShow}
+
+// Valid は定義済みの状態かを返す
+func (s ReservationStatus) Valid() bool {
+	for _, status := range ReservationStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Reservation struct {
-	ID        string    `json:"id"`
-	StartTime time.Time `json:"startTime"`
-	EndTime   time.Time `json:"endTime"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID         string `json:"id"`
+	ResourceID string `json:"resourceId"`
+	// SeriesID は繰り返し予約の回である場合に、そのシリーズのIDを表す
+	SeriesID string `json:"seriesId,omitempty"`
+	// UserID は予約したユーザーのID。所有者のいない予約（ユーザー機能の導入前の予約など）では空になる。
+	UserID string `json:"userId,omitempty"`
+	// Title、Description、Attendees は予約の目的と参加者。Attendees は参加者のメールアドレス。
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Attendees   []string          `json:"attendees"`
+	StartTime   time.Time         `json:"startTime"`
+	EndTime     time.Time         `json:"endTime"`
+	Status      ReservationStatus `json:"status"`
+	// CancelledAt と CancellationReason は取り消された予約にだけ設定する
+	CancelledAt        *time.Time `json:"cancelledAt,omitempty"`
+	CancellationReason string     `json:"cancellationReason,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+	// Version は予約を保存するたびに 1 ずつ増える版。同時に行われた変更の上書きを検出するために使う。
+	Version int64 `json:"version"`
+	// ICalUID は iCalendar から取り込んだ予約や CalDAV で作成した予約の、元のイベントの UID。それらの予約だけに設定する。
+	ICalUID string `json:"icalUid,omitempty"`
 }
 
-func NewReservation(startTime, endTime time.Time) *Reservation {
+func NewReservation(resourceID string, startTime, endTime time.Time) *Reservation {
 	now := time.Now()
 	return &Reservation{
-		ID:        uuid.New().String(),
-		StartTime: startTime,
-		EndTime:   endTime,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:         uuid.New().String(),
+		ResourceID: resourceID,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Attendees:  []string{},
+		Status:     StatusConfirmed,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Version:    1,
 	}
 }
+
+// ICalUIDDomain は取り込み元の UID を持たない予約の iCalendar の UID の @ より後ろの部分
+const ICalUIDDomain = "yoyaku"
+
+// UID は予約の iCalendar の UID を返す。取り込んだ予約は取り込み元の UID を、それ以外は予約IDから決めた UID を返す。
+func (r *Reservation) UID() string {
+	if r.ICalUID != "" {
+		return r.ICalUID
+	}
+	return r.ID + "@" + ICalUIDDomain
+}
+
+// Overlaps は予約が [start, end) の区間と重なるかを返す。
+// 区間は半開区間として扱うため、終了時刻と開始時刻が一致するだけの連続した予約は重ならない。
+func (r *Reservation) Overlaps(start, end time.Time) bool {
+	return r.StartTime.Before(end) && start.Before(r.EndTime)
+}
+
+// Active は予約が時間帯を占有しているか（取り消されていないか）を返す
+func (r *Reservation) Active() bool {
+	return r.Status != StatusCancelled
+}
@@ -12,13 +12,17 @@ func TestNewReservation(t *testing.T) {
 	endTime := now.Add(2 * time.Hour)
 
 	// 実行
-	reservation := NewReservation(startTime, endTime)
+	reservation := NewReservation("resource-1", startTime, endTime)
 
 	// 検証
 	if reservation.ID == "" {
 		t.Error("Expected ID to be set, got empty string")
 	}
 
+	if reservation.ResourceID != "resource-1" {
+		t.Errorf("Expected ResourceID to be %s, got %s", "resource-1", reservation.ResourceID)
+	}
+
 	if !reservation.StartTime.Equal(startTime) {
 		t.Errorf("Expected StartTime to be %v, got %v", startTime, reservation.StartTime)
 	}
@@ -27,6 +31,10 @@ func TestNewReservation(t *testing.T) {
 		t.Errorf("Expected EndTime to be %v, got %v", endTime, reservation.EndTime)
 	}
 
+	if reservation.Status != StatusConfirmed {
+		t.Errorf("Expected Status to be %s, got %s", StatusConfirmed, reservation.Status)
+	}
+
 	if reservation.CreatedAt.IsZero() {
 		t.Error("Expected CreatedAt to be set, got zero time")
 	}
@@ -34,4 +42,68 @@ func TestNewReservation(t *testing.T) {
 	if reservation.UpdatedAt.IsZero() {
 		t.Error("Expected UpdatedAt to be set, got zero time")
 	}
-}
\ No newline at end of file
+}
+func TestReservation_Overlaps(t *testing.T) {
+	base := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	reservation := NewReservation("resource-1", base, base.Add(1*time.Hour))
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  bool
+	}{
+		{"同じ時間帯", base, base.Add(1 * time.Hour), true},
+		{"前半が重なる", base.Add(-30 * time.Minute), base.Add(30 * time.Minute), true},
+		{"後半が重なる", base.Add(30 * time.Minute), base.Add(90 * time.Minute), true},
+		{"内側に含まれる", base.Add(15 * time.Minute), base.Add(45 * time.Minute), true},
+		{"外側から包含する", base.Add(-1 * time.Hour), base.Add(2 * time.Hour), true},
+		{"直前に連続する", base.Add(-1 * time.Hour), base, false},
+		{"直後に連続する", base.Add(1 * time.Hour), base.Add(2 * time.Hour), false},
+		{"離れている", base.Add(3 * time.Hour), base.Add(4 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reservation.Overlaps(tt.start, tt.end); got != tt.want {
+				t.Errorf("Overlaps(%v, %v) = %v, want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReservationStatus_Valid(t *testing.T) {
+	for _, status := range ReservationStatuses {
+		if !status.Valid() {
+			t.Errorf("Expected %s to be valid", status)
+		}
+	}
+	for _, status := range []ReservationStatus{"", "deleted", "CONFIRMED"} {
+		if status.Valid() {
+			t.Errorf("Expected %q to be invalid", status)
+		}
+	}
+}
+
+func TestReservation_Active(t *testing.T) {
+	reservation := NewReservation("resource-1", time.Now(), time.Now().Add(time.Hour))
+	for _, status := range ReservationStatuses {
+		reservation.Status = status
+		if got, want := reservation.Active(), status != StatusCancelled; got != want {
+			t.Errorf("Active() with status %s = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestReservation_UID(t *testing.T) {
+	reservation := NewReservation("resource-1", time.Now(), time.Now().Add(time.Hour))
+	if got, want := reservation.UID(), reservation.ID+"@yoyaku"; got != want {
+		t.Errorf("UID() = %q, want %q", got, want)
+	}
+
+	// 取り込んだ予約は取り込み元の UID を返す
+	reservation.ICalUID = "event-1@example.com"
+	if got := reservation.UID(); got != "event-1@example.com" {
+		t.Errorf("UID() = %q, want %q", got, "event-1@example.com")
+	}
+}
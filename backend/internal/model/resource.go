@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Resource は会議室や備品など予約の対象となるリソース
+type Resource struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Capacity    int       `json:"capacity"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func NewResource(name, description string, capacity int, active bool) *Resource {
+	now := time.Now()
+	return &Resource{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: description,
+		Capacity:    capacity,
+		Active:      active,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
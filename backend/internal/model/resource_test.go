@@ -0,0 +1,28 @@
+package model
+
+import "testing"
+
+func TestNewResource(t *testing.T) {
+	// 実行
+	resource := NewResource("会議室A", "プロジェクター付き", 8, true)
+
+	// 検証
+	if resource.ID == "" {
+		t.Error("Expected ID to be set, got empty string")
+	}
+	if resource.Name != "会議室A" {
+		t.Errorf("Expected Name to be %s, got %s", "会議室A", resource.Name)
+	}
+	if resource.Description != "プロジェクター付き" {
+		t.Errorf("Expected Description to be %s, got %s", "プロジェクター付き", resource.Description)
+	}
+	if resource.Capacity != 8 {
+		t.Errorf("Expected Capacity to be 8, got %d", resource.Capacity)
+	}
+	if !resource.Active {
+		t.Error("Expected Active to be true")
+	}
+	if resource.CreatedAt.IsZero() || resource.UpdatedAt.IsZero() {
+		t.Error("Expected CreatedAt and UpdatedAt to be set")
+	}
+}
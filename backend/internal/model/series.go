@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReservationSeries は RRULE で繰り返す予約のまとまり。
+// StartTime と EndTime は最初の回の時間帯で、各回の予約は SeriesID でこのシリーズを参照する。
+type ReservationSeries struct {
+	ID         string    `json:"id"`
+	ResourceID string    `json:"resourceId"`
+	Recurrence string    `json:"recurrence"`
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func NewReservationSeries(resourceID, recurrence string, startTime, endTime time.Time) *ReservationSeries {
+	now := time.Now()
+	return &ReservationSeries{
+		ID:         uuid.New().String(),
+		ResourceID: resourceID,
+		Recurrence: recurrence,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// Duration は各回の予約の長さを返す
+func (s *ReservationSeries) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// NewOccurrence は開始時刻 startTime の回の予約を作成する
+func (s *ReservationSeries) NewOccurrence(startTime time.Time) *Reservation {
+	reservation := NewReservation(s.ResourceID, startTime, startTime.Add(s.Duration()))
+	reservation.SeriesID = s.ID
+	return reservation
+}
+
+// ExceptionType は繰り返し予約の例外の種類
+type ExceptionType string
+
+const (
+	// ExceptionModified は回の時間帯やリソースが変更されたことを表す
+	ExceptionModified ExceptionType = "modified"
+	// ExceptionCancelled は回が取り消されたことを表す
+	ExceptionCancelled ExceptionType = "cancelled"
+)
+
+// SeriesException は繰り返し予約のうち、RRULE から展開した通りではなくなった回の記録。
+// 回は変更後も OriginalStartTime（展開した本来の開始時刻）で識別する。
+type SeriesException struct {
+	SeriesID          string        `json:"seriesId"`
+	OriginalStartTime time.Time     `json:"originalStartTime"`
+	Type              ExceptionType `json:"type"`
+	// ReservationID はこの回の予約のID。取り消された回でも履歴として残す。
+	ReservationID string    `json:"reservationId"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+func NewSeriesException(seriesID string, originalStartTime time.Time, exceptionType ExceptionType, reservationID string) *SeriesException {
+	now := time.Now()
+	return &SeriesException{
+		SeriesID:          seriesID,
+		OriginalStartTime: originalStartTime,
+		Type:              exceptionType,
+		ReservationID:     reservationID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+}
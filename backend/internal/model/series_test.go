@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReservationSeries_NewOccurrence(t *testing.T) {
+	// 準備
+	start := time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+	series := NewReservationSeries("resource-1", "FREQ=WEEKLY;COUNT=8", start, start.Add(90*time.Minute))
+
+	// 実行
+	occurrence := series.NewOccurrence(start.AddDate(0, 0, 7))
+
+	// 検証
+	if occurrence.SeriesID != series.ID {
+		t.Errorf("Expected SeriesID %s, got %s", series.ID, occurrence.SeriesID)
+	}
+	if occurrence.ResourceID != series.ResourceID {
+		t.Errorf("Expected ResourceID %s, got %s", series.ResourceID, occurrence.ResourceID)
+	}
+	if !occurrence.StartTime.Equal(start.AddDate(0, 0, 7)) {
+		t.Errorf("Unexpected start time %v", occurrence.StartTime)
+	}
+	if occurrence.EndTime.Sub(occurrence.StartTime) != 90*time.Minute {
+		t.Errorf("Expected duration 90m, got %v", occurrence.EndTime.Sub(occurrence.StartTime))
+	}
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// TimeRange は [StartTime, EndTime) の半開区間の時間帯
+type TimeRange struct {
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+// Duration は時間帯の長さを返す
+func (r TimeRange) Duration() time.Duration {
+	return r.EndTime.Sub(r.StartTime)
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserRole はユーザーの権限
+type UserRole string
+
+const (
+	// RoleViewer は予約の埋まっている時間帯だけを参照できる
+	RoleViewer UserRole = "viewer"
+	// RoleMember は予約を作成し、自分の予約を管理できる
+	RoleMember UserRole = "member"
+	// RoleApprover は一般ユーザーの操作に加えて、全員の予約の承認・取り消し・利用結果の記録ができる
+	RoleApprover UserRole = "approver"
+	// RoleAdmin は全員の予約と設定（リソース・営業カレンダー・ユーザー）を管理できる
+	RoleAdmin UserRole = "admin"
+)
+
+// UserRoles は全てのユーザーの権限
+var UserRoles = []UserRole{RoleViewer, RoleMember, RoleApprover, RoleAdmin}
+
+// Valid は定義済みの権限かを返す
+func (r UserRole) Valid() bool {
+	for _, role := range UserRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// User は予約を行う利用者
+type User struct {
+	ID          string    `json:"id"`
+	DisplayName string    `json:"displayName"`
+	Email       string    `json:"email"`
+	Role        UserRole  `json:"role"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func NewUser(displayName, email string, role UserRole) *User {
+	now := time.Now()
+	return &User{
+		ID:          uuid.New().String(),
+		DisplayName: displayName,
+		Email:       email,
+		Role:        role,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import "testing"
+
+func TestNewUser(t *testing.T) {
+	// 実行
+	user := NewUser("山田太郎", "taro@example.com", RoleMember)
+
+	// 検証
+	if user.ID == "" {
+		t.Error("Expected ID to be set, got empty string")
+	}
+	if user.DisplayName != "山田太郎" {
+		t.Errorf("Expected DisplayName to be %s, got %s", "山田太郎", user.DisplayName)
+	}
+	if user.Email != "taro@example.com" {
+		t.Errorf("Expected Email to be %s, got %s", "taro@example.com", user.Email)
+	}
+	if user.Role != RoleMember {
+		t.Errorf("Expected Role to be %s, got %s", RoleMember, user.Role)
+	}
+	if user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+		t.Error("Expected CreatedAt and UpdatedAt to be set")
+	}
+}
+
+func TestUserRole_Valid(t *testing.T) {
+	for _, role := range UserRoles {
+		if !role.Valid() {
+			t.Errorf("Expected %s to be valid", role)
+		}
+	}
+	if UserRole("owner").Valid() {
+		t.Error("Expected unknown role to be invalid")
+	}
+}
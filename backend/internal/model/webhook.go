@@ -0,0 +1,104 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEventType は Webhook で通知するイベントの種類
+type WebhookEventType string
+
+const (
+	EventReservationCreated WebhookEventType = "reservation.created"
+	EventReservationUpdated WebhookEventType = "reservation.updated"
+	// EventReservationDeleted は予約の取り消し。予約は削除せずに取り消し済みにするため、通知する予約の status は cancelled になる。
+	EventReservationDeleted WebhookEventType = "reservation.deleted"
+)
+
+// WebhookEventTypes は全てのイベントの種類
+var WebhookEventTypes = []WebhookEventType{EventReservationCreated, EventReservationUpdated, EventReservationDeleted}
+
+// Valid は定義済みのイベントの種類かを返す
+func (t WebhookEventType) Valid() bool {
+	return slices.Contains(WebhookEventTypes, t)
+}
+
+// WebhookSubscription は予約のイベントを通知する Webhook の登録
+type WebhookSubscription struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Secret は通知の本文の署名（HMAC-SHA256）の鍵。登録時にだけ返す。
+	Secret string             `json:"-"`
+	Events []WebhookEventType `json:"events"`
+	// CreatedBy は登録したユーザーのID
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewWebhookSubscription(url, secret string, events []WebhookEventType) *WebhookSubscription {
+	return &WebhookSubscription{
+		ID:        uuid.New().String(),
+		URL:       url,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Subscribes は event を通知する登録かを返す
+func (s *WebhookSubscription) Subscribes(event WebhookEventType) bool {
+	return slices.Contains(s.Events, event)
+}
+
+// WebhookDeliveryStatus は通知の配送の状態
+type WebhookDeliveryStatus string
+
+const (
+	// DeliveryPending は配送前か、失敗して再試行を待っている
+	DeliveryPending WebhookDeliveryStatus = "pending"
+	// DeliverySucceeded は受信側が 2xx を返した
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// DeliveryFailed は再試行の上限まで失敗した
+	DeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery は登録した URL への1件の通知の配送と、その結果の記録
+type WebhookDelivery struct {
+	ID             string `json:"id"`
+	SubscriptionID string `json:"subscriptionId"`
+	// EventID は通知するイベントのID。再配送しても変わらないため、受信側で重複を除くのに使える。
+	EventID string           `json:"eventId"`
+	Event   WebhookEventType `json:"event"`
+	// Payload は送る JSON の本文
+	Payload json.RawMessage       `json:"payload"`
+	Status  WebhookDeliveryStatus `json:"status"`
+	// Attempts は送信を試みた回数
+	Attempts int `json:"attempts"`
+	// ResponseStatus と LastError は最後の試行の HTTP ステータスとエラー。応答がなかった場合の ResponseStatus は 0。
+	ResponseStatus int    `json:"responseStatus,omitempty"`
+	LastError      string `json:"lastError,omitempty"`
+	// NextAttemptAt は次に送信する日時。配送が終わった（pending でない）場合は nil。
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	// RedeliveryOf は手動で再配送した場合に、元の配送のID
+	RedeliveryOf string    `json:"redeliveryOf,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// NewWebhookDelivery は now に送信する配送を返す
+func NewWebhookDelivery(subscriptionID, eventID string, event WebhookEventType, payload json.RawMessage, now time.Time) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:             uuid.New().String(),
+		SubscriptionID: subscriptionID,
+		EventID:        eventID,
+		Event:          event,
+		Payload:        payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  &now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
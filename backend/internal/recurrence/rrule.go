@@ -0,0 +1,311 @@
+// Package recurrence は RFC 5545 の RRULE のうち、予約の繰り返しに必要なサブセット
+// （FREQ, INTERVAL, BYDAY, COUNT, UNTIL）を解析・展開する。
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum は BYDAY の1要素。N が 0 の場合は全ての該当曜日、
+// 正の値は月の先頭から N 番目、負の値は月末から数えた曜日を表す（MONTHLY のみ）。
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Rule は解析済みの RRULE
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []WeekdayNum
+	// Count と Until は同時には指定できない。どちらもゼロ値の場合は無期限に繰り返す。
+	Count int
+	Until time.Time
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse は "FREQ=WEEKLY;BYDAY=TU;COUNT=8" の形式の RRULE を解析する。
+// 先頭の "RRULE:" は省略できる。
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("empty RRULE")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		name = strings.ToUpper(name)
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate RRULE part %s", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			switch f := Frequency(strings.ToUpper(value)); f {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = f
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", value)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, err := parseWeekdayNum(code)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part %s", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL must not both be specified")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return nil, fmt.Errorf("BYDAY with an ordinal is only supported with FREQ=MONTHLY")
+		}
+	}
+	if rule.Freq == Yearly && len(rule.ByDay) > 0 {
+		return nil, fmt.Errorf("BYDAY is not supported with FREQ=YEARLY")
+	}
+
+	return rule, nil
+}
+
+// parseUntil は UNTIL の値を解析する。日付のみの形式はその日の終わりまでを含める。
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+}
+
+func parseWeekdayNum(code string) (WeekdayNum, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+	}
+	weekday, ok := weekdayCodes[code[len(code)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+	}
+
+	day := WeekdayNum{Weekday: weekday}
+	if prefix := code[:len(code)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+		}
+		day.N = n
+	}
+	return day, nil
+}
+
+// String は RRULE の文字列表現を返す
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = weekdayNames[day.Weekday]
+			if day.N != 0 {
+				codes[i] = strconv.Itoa(day.N) + codes[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between は dtstart から始まる繰り返しのうち、開始時刻が [from, to) に含まれるものを昇順で返す。
+// 時刻は dtstart のタイムゾーンの壁時計で展開する。COUNT は dtstart からの通し番号で数える。
+func (r *Rule) Between(dtstart, from, to time.Time) []time.Time {
+	var occurrences []time.Time
+	count := 0
+	for period := 0; ; period++ {
+		periodStart, candidates := r.period(dtstart, period)
+		if !periodStart.Before(to) || (!r.Until.IsZero() && periodStart.After(r.Until)) {
+			return occurrences
+		}
+
+		for _, t := range candidates {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return occurrences
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return occurrences
+			}
+			if !t.Before(to) {
+				return occurrences
+			}
+			if !t.Before(from) {
+				occurrences = append(occurrences, t)
+			}
+		}
+	}
+}
+
+// period は n 番目の繰り返し周期の開始時刻と、その周期に含まれる候補の時刻を昇順で返す
+func (r *Rule) period(dtstart time.Time, n int) (time.Time, []time.Time) {
+	loc := dtstart.Location()
+	hour, min, sec := dtstart.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, dtstart.Nanosecond(), loc)
+	}
+	step := n * r.Interval
+
+	switch r.Freq {
+	case Daily:
+		day := dtstart.AddDate(0, 0, step)
+		if len(r.ByDay) > 0 && !r.hasWeekday(day.Weekday()) {
+			return day, nil
+		}
+		return day, []time.Time{day}
+
+	case Weekly:
+		// 週は月曜日から始まるものとして扱う（RFC 5545 の WKST の既定値）
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		weekStart := at(dtstart.Year(), dtstart.Month(), dtstart.Day()-offset).AddDate(0, 0, 7*step)
+		if len(r.ByDay) == 0 {
+			return weekStart, []time.Time{weekStart.AddDate(0, 0, offset)}
+		}
+		var candidates []time.Time
+		for _, day := range r.ByDay {
+			candidates = append(candidates, weekStart.AddDate(0, 0, (int(day.Weekday)+6)%7))
+		}
+		return weekStart, sortUnique(candidates)
+
+	case Monthly:
+		first := at(dtstart.Year(), dtstart.Month()+time.Month(step), 1)
+		if len(r.ByDay) == 0 {
+			// 指定日が存在しない月（31日など）はスキップする
+			day := at(first.Year(), first.Month(), dtstart.Day())
+			if day.Month() != first.Month() {
+				return first, nil
+			}
+			return first, []time.Time{day}
+		}
+		var candidates []time.Time
+		for _, day := range r.ByDay {
+			candidates = append(candidates, weekdaysInMonth(first, day)...)
+		}
+		return first, sortUnique(candidates)
+
+	default: // Yearly
+		first := at(dtstart.Year()+step, time.January, 1)
+		day := at(first.Year(), dtstart.Month(), dtstart.Day())
+		if day.Month() != dtstart.Month() {
+			return first, nil
+		}
+		return first, []time.Time{day}
+	}
+}
+
+func (r *Rule) hasWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// weekdaysInMonth は first の月に含まれる day に該当する日を返す
+func weekdaysInMonth(first time.Time, day WeekdayNum) []time.Time {
+	var days []time.Time
+	for d := first.AddDate(0, 0, (int(day.Weekday)-int(first.Weekday())+7)%7); d.Month() == first.Month(); d = d.AddDate(0, 0, 7) {
+		days = append(days, d)
+	}
+
+	switch {
+	case day.N == 0:
+		return days
+	case day.N > 0 && day.N <= len(days):
+		return []time.Time{days[day.N-1]}
+	case day.N < 0 && -day.N <= len(days):
+		return []time.Time{days[len(days)+day.N]}
+	default:
+		return nil
+	}
+}
+
+func sortUnique(times []time.Time) []time.Time {
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	unique := times[:0]
+	for i, t := range times {
+		if i == 0 || !t.Equal(times[i-1]) {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
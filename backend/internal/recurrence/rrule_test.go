@@ -0,0 +1,203 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+func dates(times []time.Time) []string {
+	s := make([]string, len(times))
+	for i, t := range times {
+		s[i] = t.Format("2006-01-02 15:04 Mon")
+	}
+	return s
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"毎週火曜日8回", "FREQ=WEEKLY;BYDAY=TU;COUNT=8", "FREQ=WEEKLY;BYDAY=TU;COUNT=8"},
+		{"RRULE:接頭辞と小文字", "RRULE:freq=daily;interval=2;count=3", "FREQ=DAILY;INTERVAL=2;COUNT=3"},
+		{"毎月第1月曜日", "FREQ=MONTHLY;BYDAY=1MO;UNTIL=20241231T150000Z", "FREQ=MONTHLY;BYDAY=1MO;UNTIL=20241231T150000Z"},
+		{"UNTILが日付のみ", "FREQ=DAILY;UNTIL=20240410", "FREQ=DAILY;UNTIL=20240410T235959Z"},
+		{"INTERVAL=1は省略される", "FREQ=YEARLY;INTERVAL=1;COUNT=2", "FREQ=YEARLY;COUNT=2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"空文字列", ""},
+		{"FREQがない", "COUNT=3"},
+		{"未対応のFREQ", "FREQ=HOURLY"},
+		{"未対応のパート", "FREQ=WEEKLY;BYMONTH=1"},
+		{"COUNTとUNTILの両方", "FREQ=DAILY;COUNT=3;UNTIL=20240410"},
+		{"INTERVALが0", "FREQ=DAILY;INTERVAL=0"},
+		{"COUNTが数値でない", "FREQ=DAILY;COUNT=many"},
+		{"不正なBYDAY", "FREQ=WEEKLY;BYDAY=XX"},
+		{"WEEKLYで序数付きBYDAY", "FREQ=WEEKLY;BYDAY=1MO"},
+		{"序数が範囲外", "FREQ=MONTHLY;BYDAY=6MO"},
+		{"不正なUNTIL", "FREQ=DAILY;UNTIL=tomorrow"},
+		{"重複したパート", "FREQ=DAILY;FREQ=WEEKLY"},
+		{"値がない", "FREQ=DAILY;COUNT="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.input); err == nil {
+				t.Errorf("Expected error for %q, got nil", tt.input)
+			}
+		})
+	}
+}
+
+func TestRule_Between(t *testing.T) {
+	// 2024-04-02 は火曜日
+	dtstart := time.Date(2024, 4, 2, 10, 0, 0, 0, jst)
+	horizon := dtstart.AddDate(1, 0, 0)
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []string
+	}{
+		{
+			name:    "毎週火曜日を4回",
+			rule:    "FREQ=WEEKLY;BYDAY=TU;COUNT=4",
+			dtstart: dtstart,
+			want:    []string{"2024-04-02 10:00 Tue", "2024-04-09 10:00 Tue", "2024-04-16 10:00 Tue", "2024-04-23 10:00 Tue"},
+		},
+		{
+			name:    "BYDAYを省略した毎週",
+			rule:    "FREQ=WEEKLY;COUNT=2",
+			dtstart: dtstart,
+			want:    []string{"2024-04-02 10:00 Tue", "2024-04-09 10:00 Tue"},
+		},
+		{
+			name:    "隔週の月水金（開始日より前の曜日は含めない）",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR;COUNT=5",
+			dtstart: dtstart,
+			want:    []string{"2024-04-03 10:00 Wed", "2024-04-05 10:00 Fri", "2024-04-15 10:00 Mon", "2024-04-17 10:00 Wed", "2024-04-19 10:00 Fri"},
+		},
+		{
+			name:    "平日毎日（UNTILの日付を含む）",
+			rule:    "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20240408",
+			dtstart: dtstart,
+			want:    []string{"2024-04-02 10:00 Tue", "2024-04-03 10:00 Wed", "2024-04-04 10:00 Thu", "2024-04-05 10:00 Fri", "2024-04-08 10:00 Mon"},
+		},
+		{
+			name:    "2日おき",
+			rule:    "FREQ=DAILY;INTERVAL=2;COUNT=3",
+			dtstart: dtstart,
+			want:    []string{"2024-04-02 10:00 Tue", "2024-04-04 10:00 Thu", "2024-04-06 10:00 Sat"},
+		},
+		{
+			name:    "毎月第1月曜日",
+			rule:    "FREQ=MONTHLY;BYDAY=1MO;COUNT=3",
+			dtstart: dtstart,
+			want:    []string{"2024-05-06 10:00 Mon", "2024-06-03 10:00 Mon", "2024-07-01 10:00 Mon"},
+		},
+		{
+			name:    "毎月最終金曜日",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			dtstart: dtstart,
+			want:    []string{"2024-04-26 10:00 Fri", "2024-05-31 10:00 Fri", "2024-06-28 10:00 Fri"},
+		},
+		{
+			name:    "毎月31日（31日がない月はスキップ）",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: time.Date(2024, 1, 31, 9, 0, 0, 0, jst),
+			want:    []string{"2024-01-31 09:00 Wed", "2024-03-31 09:00 Sun", "2024-05-31 09:00 Fri"},
+		},
+		{
+			name:    "毎年（うるう日はうるう年だけ）",
+			rule:    "FREQ=YEARLY;COUNT=2",
+			dtstart: time.Date(2024, 2, 29, 9, 0, 0, 0, jst),
+			want:    []string{"2024-02-29 09:00 Thu", "2028-02-29 09:00 Tue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Failed to parse rule: %v", err)
+			}
+
+			to := horizon
+			if tt.dtstart.AddDate(5, 0, 0).After(to) {
+				to = tt.dtstart.AddDate(5, 0, 0)
+			}
+			got := dates(rule.Between(tt.dtstart, tt.dtstart, to))
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d occurrences %v, got %d %v", len(tt.want), tt.want, len(got), got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("occurrence %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRule_Between_Window(t *testing.T) {
+	// 準備：毎週火曜日を8回
+	dtstart := time.Date(2024, 4, 2, 10, 0, 0, 0, jst)
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=TU;COUNT=8")
+	if err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+
+	// 実行：5月の分だけを展開
+	got := dates(rule.Between(dtstart, time.Date(2024, 5, 1, 0, 0, 0, 0, jst), time.Date(2024, 6, 1, 0, 0, 0, 0, jst)))
+
+	// 検証：COUNTは開始日から数えるので、4月の5回を除いた3回（5/21が8回目）
+	want := []string{"2024-05-07 10:00 Tue", "2024-05-14 10:00 Tue", "2024-05-21 10:00 Tue"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("occurrence %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRule_Between_Unbounded(t *testing.T) {
+	// 準備：終了条件のない毎日の繰り返し
+	dtstart := time.Date(2024, 4, 1, 10, 0, 0, 0, jst)
+	rule, err := Parse("FREQ=DAILY")
+	if err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+
+	// 実行：展開は to で打ち切られる
+	got := rule.Between(dtstart, dtstart, dtstart.AddDate(0, 0, 10))
+
+	// 検証
+	if len(got) != 10 {
+		t.Errorf("Expected 10 occurrences, got %d", len(got))
+	}
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+type CalendarFeedRepository interface {
+	Create(feed *model.CalendarFeed) error
+	// FindByUserID はユーザーが発行したフィードを発行順に返す
+	FindByUserID(userID string) ([]*model.CalendarFeed, error)
+	// FindByID と FindByTokenHash は存在しない場合に nil を返す
+	FindByID(id string) (*model.CalendarFeed, error)
+	FindByTokenHash(tokenHash string) (*model.CalendarFeed, error)
+	Delete(id string) error
+}
+
+// InMemoryCalendarFeedRepository - In-memory implementation for testing
+type InMemoryCalendarFeedRepository struct {
+	feeds map[string]*model.CalendarFeed
+	mutex sync.RWMutex
+}
+
+func NewInMemoryCalendarFeedRepository() *InMemoryCalendarFeedRepository {
+	return &InMemoryCalendarFeedRepository{
+		feeds: make(map[string]*model.CalendarFeed),
+	}
+}
+
+func (r *InMemoryCalendarFeedRepository) Create(feed *model.CalendarFeed) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.feeds[feed.ID] = feed
+	return nil
+}
+
+func (r *InMemoryCalendarFeedRepository) FindByUserID(userID string) ([]*model.CalendarFeed, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	feeds := make([]*model.CalendarFeed, 0)
+	for _, feed := range r.feeds {
+		if feed.UserID == userID {
+			feeds = append(feeds, feed)
+		}
+	}
+	sort.Slice(feeds, func(i, j int) bool {
+		return feeds[i].CreatedAt.Before(feeds[j].CreatedAt)
+	})
+
+	return feeds, nil
+}
+
+func (r *InMemoryCalendarFeedRepository) FindByID(id string) (*model.CalendarFeed, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	feed, ok := r.feeds[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return feed, nil
+}
+
+func (r *InMemoryCalendarFeedRepository) FindByTokenHash(tokenHash string) (*model.CalendarFeed, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, feed := range r.feeds {
+		if feed.TokenHash == tokenHash {
+			return feed, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *InMemoryCalendarFeedRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.feeds, id)
+	return nil
+}
+
+// MySQLCalendarFeedRepository - MySQL implementation
+type MySQLCalendarFeedRepository struct {
+	db *sql.DB
+}
+
+const calendarFeedColumns = "id, user_id, role, resource_id, token_hash, created_at, caldav"
+
+// NewMySQLCalendarFeedRepository creates a new MySQL repository
+func NewMySQLCalendarFeedRepository(db *sql.DB) *MySQLCalendarFeedRepository {
+	return &MySQLCalendarFeedRepository{
+		db: db,
+	}
+}
+
+// scanCalendarFeed は calendarFeedColumns の順に並んだ1行をフィードとして読み込む
+func scanCalendarFeed(row rowScanner) (*model.CalendarFeed, error) {
+	var feed model.CalendarFeed
+	var resourceID sql.NullString
+	if err := row.Scan(
+		&feed.ID,
+		&feed.UserID,
+		&feed.Role,
+		&resourceID,
+		&feed.TokenHash,
+		&feed.CreatedAt,
+		&feed.CalDAV,
+	); err != nil {
+		return nil, err
+	}
+	feed.ResourceID = resourceID.String
+	return &feed, nil
+}
+
+// Create inserts a new calendar feed into the database
+func (r *MySQLCalendarFeedRepository) Create(feed *model.CalendarFeed) error {
+	_, err := r.db.Exec(
+		"INSERT INTO calendar_feeds ("+calendarFeedColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		feed.ID,
+		feed.UserID,
+		feed.Role,
+		sql.NullString{String: feed.ResourceID, Valid: feed.ResourceID != ""},
+		feed.TokenHash,
+		feed.CreatedAt,
+		feed.CalDAV,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create calendar feed: %w", err)
+	}
+	return nil
+}
+
+// FindByUserID returns the feeds of a user ordered by creation time
+func (r *MySQLCalendarFeedRepository) FindByUserID(userID string) ([]*model.CalendarFeed, error) {
+	rows, err := r.db.Query("SELECT "+calendarFeedColumns+" FROM calendar_feeds WHERE user_id = ? ORDER BY created_at", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find calendar feeds: %w", err)
+	}
+	defer rows.Close()
+
+	feeds := make([]*model.CalendarFeed, 0)
+	for rows.Next() {
+		feed, err := scanCalendarFeed(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar feed: %w", err)
+		}
+		feeds = append(feeds, feed)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return feeds, nil
+}
+
+// FindByID returns a calendar feed by ID
+func (r *MySQLCalendarFeedRepository) FindByID(id string) (*model.CalendarFeed, error) {
+	return r.findOne("id", id)
+}
+
+// FindByTokenHash returns a calendar feed by the hash of its token
+func (r *MySQLCalendarFeedRepository) FindByTokenHash(tokenHash string) (*model.CalendarFeed, error) {
+	return r.findOne("token_hash", tokenHash)
+}
+
+// findOne は column の値が value に一致するフィードを返す。column は呼び出し側で固定の列名を渡すこと。
+func (r *MySQLCalendarFeedRepository) findOne(column, value string) (*model.CalendarFeed, error) {
+	feed, err := scanCalendarFeed(r.db.QueryRow(
+		"SELECT "+calendarFeedColumns+" FROM calendar_feeds WHERE "+column+" = ?",
+		value,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find calendar feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// Delete removes a calendar feed from the database
+func (r *MySQLCalendarFeedRepository) Delete(id string) error {
+	if _, err := r.db.Exec("DELETE FROM calendar_feeds WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+func TestInMemoryCalendarFeedRepository(t *testing.T) {
+	// 準備
+	repo := NewInMemoryCalendarFeedRepository()
+	first := model.NewCalendarFeed("user-1", model.RoleMember, "", "hash-1")
+	second := model.NewCalendarFeed("user-1", model.RoleMember, "room-1", "hash-2")
+	second.CreatedAt = first.CreatedAt.Add(time.Second)
+	other := model.NewCalendarFeed("user-2", model.RoleMember, "", "hash-3")
+
+	// 作成
+	for _, f := range []*model.CalendarFeed{second, other, first} {
+		if err := repo.Create(f); err != nil {
+			t.Fatalf("Failed to create feed: %v", err)
+		}
+	}
+
+	// ユーザーのフィードを発行順に取得
+	feeds, err := repo.FindByUserID("user-1")
+	if err != nil || len(feeds) != 2 || feeds[0].ID != first.ID {
+		t.Errorf("Expected feeds of user-1 ordered by creation time, got %v, %v", feeds, err)
+	}
+
+	// トークンのハッシュで検索
+	found, err := repo.FindByTokenHash("hash-2")
+	if err != nil || found == nil || found.ID != second.ID {
+		t.Errorf("Expected to find the feed by token hash, got %v, %v", found, err)
+	}
+
+	// 削除したフィードは見つからない
+	if err := repo.Delete(second.ID); err != nil {
+		t.Fatalf("Failed to delete feed: %v", err)
+	}
+	if found, _ = repo.FindByTokenHash("hash-2"); found != nil {
+		t.Errorf("Expected nil for deleted feed, got %v", found)
+	}
+	if found, _ = repo.FindByID(second.ID); found != nil {
+		t.Errorf("Expected nil for deleted feed, got %v", found)
+	}
+}
+
+func TestMySQLCalendarFeedRepository_Create(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLCalendarFeedRepository(db)
+
+	// リソースを指定しないフィードは resource_id を NULL で保存する
+	feed := model.NewCalendarFeed("user-1", model.RoleMember, "", "hash-1")
+	mock.ExpectExec("INSERT INTO calendar_feeds").
+		WithArgs(feed.ID, feed.UserID, model.RoleMember, nil, feed.TokenHash, feed.CreatedAt, false).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// 実行
+	err = repo.Create(feed)
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLCalendarFeedRepository_FindByTokenHash(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLCalendarFeedRepository(db)
+
+	createdAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	columns := []string{"id", "user_id", "role", "resource_id", "token_hash", "created_at", "caldav"}
+	mock.ExpectQuery("SELECT (.+) FROM calendar_feeds WHERE token_hash = \\?").
+		WithArgs("hash-1").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("feed-1", "user-1", "member", "room-1", "hash-1", createdAt, true))
+	mock.ExpectQuery("SELECT (.+) FROM calendar_feeds WHERE token_hash = \\?").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	// 実行
+	found, err := repo.FindByTokenHash("hash-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	missing, err := repo.FindByTokenHash("missing")
+
+	// 検証
+	if found == nil || found.ID != "feed-1" || found.ResourceID != "room-1" || !found.CalDAV {
+		t.Errorf("Expected CalDAV feed-1 for room-1, got %v", found)
+	}
+	if err != nil || missing != nil {
+		t.Errorf("Expected nil for unknown token, got %v, %v", missing, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
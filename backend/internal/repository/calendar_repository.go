@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+type CalendarRepository interface {
+	// GetWeeklySchedule は営業時間を返す。設定されていない場合は Hours が空の WeeklySchedule を返す。
+	GetWeeklySchedule() (*model.WeeklySchedule, error)
+	// SaveWeeklySchedule は営業時間を全て置き換える
+	SaveWeeklySchedule(schedule *model.WeeklySchedule) error
+	CreateBlackout(blackout *model.Blackout) error
+	// FindBlackouts は [from, to) と重なる休業期間を開始時刻の昇順で返す。from と to が両方ゼロ値の場合は全て返す。
+	FindBlackouts(from, to time.Time) ([]*model.Blackout, error)
+	FindBlackoutByID(id string) (*model.Blackout, error)
+	DeleteBlackout(id string) error
+}
+
+// InMemoryCalendarRepository - In-memory implementation for testing
+type InMemoryCalendarRepository struct {
+	schedule  model.WeeklySchedule
+	blackouts map[string]*model.Blackout
+	mutex     sync.RWMutex
+}
+
+func NewInMemoryCalendarRepository() *InMemoryCalendarRepository {
+	return &InMemoryCalendarRepository{
+		blackouts: make(map[string]*model.Blackout),
+	}
+}
+
+func (r *InMemoryCalendarRepository) GetWeeklySchedule() (*model.WeeklySchedule, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	schedule := model.WeeklySchedule{
+		TimeZone: r.schedule.TimeZone,
+		Hours:    append([]model.BusinessHours{}, r.schedule.Hours...),
+	}
+	return &schedule, nil
+}
+
+func (r *InMemoryCalendarRepository) SaveWeeklySchedule(schedule *model.WeeklySchedule) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.schedule = model.WeeklySchedule{
+		TimeZone: schedule.TimeZone,
+		Hours:    append([]model.BusinessHours{}, schedule.Hours...),
+	}
+	return nil
+}
+
+func (r *InMemoryCalendarRepository) CreateBlackout(blackout *model.Blackout) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.blackouts[blackout.ID] = blackout
+	return nil
+}
+
+func (r *InMemoryCalendarRepository) FindBlackouts(from, to time.Time) ([]*model.Blackout, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	all := from.IsZero() && to.IsZero()
+	blackouts := make([]*model.Blackout, 0)
+	for _, b := range r.blackouts {
+		if all || (b.StartTime.Before(to) && b.EndTime.After(from)) {
+			blackouts = append(blackouts, b)
+		}
+	}
+	sort.Slice(blackouts, func(i, j int) bool {
+		return blackouts[i].StartTime.Before(blackouts[j].StartTime)
+	})
+
+	return blackouts, nil
+}
+
+func (r *InMemoryCalendarRepository) FindBlackoutByID(id string) (*model.Blackout, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	blackout, ok := r.blackouts[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return blackout, nil
+}
+
+func (r *InMemoryCalendarRepository) DeleteBlackout(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.blackouts, id)
+	return nil
+}
+
+// MySQLCalendarRepository - MySQL implementation
+type MySQLCalendarRepository struct {
+	db *sql.DB
+}
+
+const blackoutColumns = "id, resource_id, start_time, end_time, reason, created_at"
+
+func NewMySQLCalendarRepository(db *sql.DB) *MySQLCalendarRepository {
+	return &MySQLCalendarRepository{
+		db: db,
+	}
+}
+
+// GetWeeklySchedule returns the business hours ordered by weekday and opening time.
+// Every row carries the same time zone, so it is taken from the first one.
+func (r *MySQLCalendarRepository) GetWeeklySchedule() (*model.WeeklySchedule, error) {
+	rows, err := r.db.Query("SELECT weekday, open_time, close_time, time_zone FROM business_hours ORDER BY weekday, open_time")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business hours: %w", err)
+	}
+	defer rows.Close()
+
+	schedule := &model.WeeklySchedule{Hours: make([]model.BusinessHours, 0)}
+	for rows.Next() {
+		var hours model.BusinessHours
+		if err := rows.Scan(&hours.Weekday, &hours.Open, &hours.Close, &schedule.TimeZone); err != nil {
+			return nil, fmt.Errorf("failed to scan business hours: %w", err)
+		}
+		schedule.Hours = append(schedule.Hours, hours)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// SaveWeeklySchedule replaces all business hours in a single transaction
+func (r *MySQLCalendarRepository) SaveWeeklySchedule(schedule *model.WeeklySchedule) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM business_hours"); err != nil {
+		return fmt.Errorf("failed to delete business hours: %w", err)
+	}
+	for _, hours := range schedule.Hours {
+		if _, err := tx.Exec(
+			"INSERT INTO business_hours (weekday, open_time, close_time, time_zone) VALUES (?, ?, ?, ?)",
+			int(hours.Weekday),
+			hours.Open,
+			hours.Close,
+			schedule.TimeZone,
+		); err != nil {
+			return fmt.Errorf("failed to insert business hours: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CreateBlackout inserts a new blackout period into the database
+func (r *MySQLCalendarRepository) CreateBlackout(blackout *model.Blackout) error {
+	_, err := r.db.Exec(
+		"INSERT INTO blackout_periods ("+blackoutColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		blackout.ID,
+		blackout.ResourceID,
+		blackout.StartTime,
+		blackout.EndTime,
+		blackout.Reason,
+		blackout.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create blackout period: %w", err)
+	}
+	return nil
+}
+
+// FindBlackouts returns the blackout periods overlapping [from, to), or all of them when both are zero
+func (r *MySQLCalendarRepository) FindBlackouts(from, to time.Time) ([]*model.Blackout, error) {
+	query := "SELECT " + blackoutColumns + " FROM blackout_periods"
+	var args []any
+	if !from.IsZero() || !to.IsZero() {
+		query += " WHERE start_time < ? AND end_time > ?"
+		args = append(args, to, from)
+	}
+	query += " ORDER BY start_time"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blackout periods: %w", err)
+	}
+	defer rows.Close()
+
+	blackouts := make([]*model.Blackout, 0)
+	for rows.Next() {
+		var blackout model.Blackout
+		if err := rows.Scan(
+			&blackout.ID,
+			&blackout.ResourceID,
+			&blackout.StartTime,
+			&blackout.EndTime,
+			&blackout.Reason,
+			&blackout.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan blackout period: %w", err)
+		}
+		blackouts = append(blackouts, &blackout)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return blackouts, nil
+}
+
+// FindBlackoutByID returns a blackout period by ID
+func (r *MySQLCalendarRepository) FindBlackoutByID(id string) (*model.Blackout, error) {
+	var blackout model.Blackout
+	err := r.db.QueryRow(
+		"SELECT "+blackoutColumns+" FROM blackout_periods WHERE id = ?",
+		id,
+	).Scan(
+		&blackout.ID,
+		&blackout.ResourceID,
+		&blackout.StartTime,
+		&blackout.EndTime,
+		&blackout.Reason,
+		&blackout.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blackout period: %w", err)
+	}
+
+	return &blackout, nil
+}
+
+// DeleteBlackout deletes a blackout period by ID
+func (r *MySQLCalendarRepository) DeleteBlackout(id string) error {
+	_, err := r.db.Exec("DELETE FROM blackout_periods WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete blackout period: %w", err)
+	}
+	return nil
+}
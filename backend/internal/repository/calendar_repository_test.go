@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+func TestInMemoryCalendarRepository_WeeklySchedule(t *testing.T) {
+	// 準備
+	repo := NewInMemoryCalendarRepository()
+	schedule := &model.WeeklySchedule{
+		TimeZone: "Asia/Tokyo",
+		Hours:    []model.BusinessHours{{Weekday: time.Monday, Open: "09:00", Close: "18:00"}},
+	}
+
+	// 実行
+	empty, err := repo.GetWeeklySchedule()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := repo.SaveWeeklySchedule(schedule); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	schedule.Hours[0].Close = "12:00"
+	saved, err := repo.GetWeeklySchedule()
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(empty.Hours) != 0 {
+		t.Errorf("Expected empty schedule, got %v", empty)
+	}
+	if saved.TimeZone != "Asia/Tokyo" || len(saved.Hours) != 1 || saved.Hours[0].Close != "18:00" {
+		t.Errorf("Expected saved copy of the schedule, got %v", saved)
+	}
+}
+
+func TestInMemoryCalendarRepository_Blackouts(t *testing.T) {
+	// 準備
+	repo := NewInMemoryCalendarRepository()
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	later := model.NewBlackout("", base.AddDate(0, 0, 10), base.AddDate(0, 0, 11), "点検")
+	earlier := model.NewBlackout("room-a", base, base.AddDate(0, 0, 1), "")
+	for _, b := range []*model.Blackout{later, earlier} {
+		if err := repo.CreateBlackout(b); err != nil {
+			t.Fatalf("Failed to create blackout: %v", err)
+		}
+	}
+
+	// 実行
+	all, err := repo.FindBlackouts(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	inRange, err := repo.FindBlackouts(base.AddDate(0, 0, 1), base.AddDate(0, 0, 20))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	if len(all) != 2 || all[0].ID != earlier.ID {
+		t.Errorf("Expected both blackouts ordered by start, got %v", all)
+	}
+	if len(inRange) != 1 || inRange[0].ID != later.ID {
+		t.Errorf("Expected only the later blackout (touching end is not an overlap), got %v", inRange)
+	}
+
+	if err := repo.DeleteBlackout(earlier.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	found, err := repo.FindBlackoutByID(earlier.ID)
+	if err != nil || found != nil {
+		t.Errorf("Expected deleted blackout to be gone, got %v, %v", found, err)
+	}
+}
+
+func newMockCalendarRepository(t *testing.T) (*MySQLCalendarRepository, sqlmock.Sqlmock, func()) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+
+	repo := NewMySQLCalendarRepository(db)
+	return repo, mock, func() { db.Close() }
+}
+
+func TestMySQLCalendarRepository_WeeklySchedule(t *testing.T) {
+	repo, mock, closeDB := newMockCalendarRepository(t)
+	defer closeDB()
+
+	schedule := &model.WeeklySchedule{
+		TimeZone: "Asia/Tokyo",
+		Hours: []model.BusinessHours{
+			{Weekday: time.Monday, Open: "09:00", Close: "12:00"},
+			{Weekday: time.Monday, Open: "13:00", Close: "18:00"},
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM business_hours").WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO business_hours").WithArgs(1, "09:00", "12:00", "Asia/Tokyo").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO business_hours").WithArgs(1, "13:00", "18:00", "Asia/Tokyo").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	rows := sqlmock.NewRows([]string{"weekday", "open_time", "close_time", "time_zone"}).
+		AddRow(1, "09:00", "12:00", "Asia/Tokyo").
+		AddRow(1, "13:00", "18:00", "Asia/Tokyo")
+	mock.ExpectQuery("SELECT weekday, open_time, close_time, time_zone FROM business_hours ORDER BY weekday, open_time").
+		WillReturnRows(rows)
+
+	// 実行
+	if err := repo.SaveWeeklySchedule(schedule); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	found, err := repo.GetWeeklySchedule()
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if found.TimeZone != "Asia/Tokyo" || len(found.Hours) != 2 || found.Hours[1] != schedule.Hours[1] {
+		t.Errorf("Expected schedule %v, got %v", schedule, found)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLCalendarRepository_Blackouts(t *testing.T) {
+	repo, mock, closeDB := newMockCalendarRepository(t)
+	defer closeDB()
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	blackout := model.NewBlackout("room-a", from.AddDate(0, 0, 2), from.AddDate(0, 0, 3), "点検")
+
+	mock.ExpectExec("INSERT INTO blackout_periods").
+		WithArgs(blackout.ID, blackout.ResourceID, blackout.StartTime, blackout.EndTime, blackout.Reason, blackout.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	rows := sqlmock.NewRows([]string{"id", "resource_id", "start_time", "end_time", "reason", "created_at"}).
+		AddRow(blackout.ID, blackout.ResourceID, blackout.StartTime, blackout.EndTime, blackout.Reason, blackout.CreatedAt)
+	mock.ExpectQuery("SELECT (.+) FROM blackout_periods WHERE start_time < \\? AND end_time > \\? ORDER BY start_time").
+		WithArgs(to, from).
+		WillReturnRows(rows)
+
+	mock.ExpectQuery("SELECT (.+) FROM blackout_periods WHERE id = \\?").
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "resource_id", "start_time", "end_time", "reason", "created_at"}))
+
+	mock.ExpectExec("DELETE FROM blackout_periods WHERE id = \\?").
+		WithArgs(blackout.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// 実行
+	if err := repo.CreateBlackout(blackout); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	found, err := repo.FindBlackouts(from, to)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	unknown, err := repo.FindBlackoutByID("unknown")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := repo.DeleteBlackout(blackout.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	if len(found) != 1 || found[0].ID != blackout.ID || found[0].Reason != "点検" {
+		t.Errorf("Expected blackout %v, got %v", blackout, found)
+	}
+	if unknown != nil {
+		t.Errorf("Expected nil for unknown blackout, got %v", unknown)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
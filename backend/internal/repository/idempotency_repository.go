@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+type IdempotencyRepository interface {
+	// Reserve は record.Key の有効な記録がない場合に record を保存して nil を返す。
+	// 有効な記録がある場合は保存せずにその記録を返す。有効期限は record.CreatedAt の時点で判定する。
+	Reserve(record *model.IdempotencyRecord) (*model.IdempotencyRecord, error)
+	// Complete は Reserve で保存した記録をレスポンスと有効期限で更新する
+	Complete(record *model.IdempotencyRecord) error
+	// Delete はキーの記録を削除する。記録がない場合は何もしない。
+	Delete(key string) error
+	// DeleteExpired は now の時点で有効期限が切れている記録を削除し、削除した件数を返す
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// InMemoryIdempotencyRepository - In-memory implementation for testing
+type InMemoryIdempotencyRepository struct {
+	records map[string]*model.IdempotencyRecord
+	mutex   sync.RWMutex
+}
+
+func NewInMemoryIdempotencyRepository() *InMemoryIdempotencyRepository {
+	return &InMemoryIdempotencyRepository{
+		records: make(map[string]*model.IdempotencyRecord),
+	}
+}
+
+func (r *InMemoryIdempotencyRepository) Reserve(record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, ok := r.records[record.Key]; ok && !existing.Expired(record.CreatedAt) {
+		stored := *existing
+		return &stored, nil
+	}
+	stored := *record
+	r.records[record.Key] = &stored
+	return nil, nil
+}
+
+func (r *InMemoryIdempotencyRepository) Complete(record *model.IdempotencyRecord) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *record
+	r.records[record.Key] = &stored
+	return nil
+}
+
+func (r *InMemoryIdempotencyRepository) Delete(key string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.records, key)
+	return nil
+}
+
+func (r *InMemoryIdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var deleted int64
+	for key, record := range r.records {
+		if record.Expired(now) {
+			delete(r.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// MySQLIdempotencyRepository - MySQL implementation
+type MySQLIdempotencyRepository struct {
+	db *sql.DB
+}
+
+const idempotencyColumns = "idempotency_key, fingerprint, status_code, header, body, created_at, expires_at"
+
+// NewMySQLIdempotencyRepository creates a new MySQL repository
+func NewMySQLIdempotencyRepository(db *sql.DB) *MySQLIdempotencyRepository {
+	return &MySQLIdempotencyRepository{
+		db: db,
+	}
+}
+
+// scanIdempotencyRecord は idempotencyColumns の順に並んだ1行を記録として読み込む
+func scanIdempotencyRecord(row rowScanner) (*model.IdempotencyRecord, error) {
+	var record model.IdempotencyRecord
+	var header string
+	if err := row.Scan(
+		&record.Key,
+		&record.Fingerprint,
+		&record.StatusCode,
+		&header,
+		&record.Body,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(header), &record.Header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	return &record, nil
+}
+
+// Reserve inserts the record unless an unexpired record with the same key exists
+func (r *MySQLIdempotencyRepository) Reserve(record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode header: %w", err)
+	}
+
+	if _, err := r.db.Exec(
+		"DELETE FROM idempotency_keys WHERE idempotency_key = ? AND expires_at <= ?",
+		record.Key,
+		record.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("failed to delete expired idempotency key: %w", err)
+	}
+
+	// 主キーが重複した場合は既存の行を変更せず、影響を受けた行数が 0 になる
+	result, err := r.db.Exec(
+		"INSERT INTO idempotency_keys ("+idempotencyColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)"+
+			" ON DUPLICATE KEY UPDATE idempotency_key = idempotency_key",
+		record.Key,
+		record.Fingerprint,
+		record.StatusCode,
+		string(header),
+		record.Body,
+		record.CreatedAt,
+		record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if inserted == 1 {
+		return nil, nil
+	}
+
+	existing, err := scanIdempotencyRecord(r.db.QueryRow(
+		"SELECT "+idempotencyColumns+" FROM idempotency_keys WHERE idempotency_key = ?",
+		record.Key,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find idempotency key: %w", err)
+	}
+	return existing, nil
+}
+
+// Complete stores the response of a reserved record
+func (r *MySQLIdempotencyRepository) Complete(record *model.IdempotencyRecord) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode header: %w", err)
+	}
+
+	_, err = r.db.Exec(
+		"UPDATE idempotency_keys SET status_code = ?, header = ?, body = ?, expires_at = ? WHERE idempotency_key = ?",
+		record.StatusCode,
+		string(header),
+		record.Body,
+		record.ExpiresAt,
+		record.Key,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the record with the given key
+func (r *MySQLIdempotencyRepository) Delete(key string) error {
+	if _, err := r.db.Exec("DELETE FROM idempotency_keys WHERE idempotency_key = ?", key); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes all records that have expired at now
+func (r *MySQLIdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM idempotency_keys WHERE expires_at <= ?", now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+func TestInMemoryIdempotencyRepository(t *testing.T) {
+	// 準備
+	repo := NewInMemoryIdempotencyRepository()
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	record := &model.IdempotencyRecord{Key: "user-1:key-1", Fingerprint: "a", CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+
+	// 未使用のキーは保存する
+	existing, err := repo.Reserve(record)
+	if err != nil || existing != nil {
+		t.Fatalf("Expected the key to be reserved, got %v, %v", existing, err)
+	}
+
+	// 処理中のキーは既存の記録を返す
+	retry := &model.IdempotencyRecord{Key: record.Key, Fingerprint: "b", CreatedAt: now.Add(time.Second), ExpiresAt: now.Add(time.Minute)}
+	existing, _ = repo.Reserve(retry)
+	if existing == nil || existing.Fingerprint != "a" || existing.Completed() {
+		t.Fatalf("Expected the in-progress record, got %v", existing)
+	}
+
+	// レスポンスを記録する
+	record.StatusCode = 201
+	record.Body = []byte(`{"id":"r-1"}`)
+	record.ExpiresAt = now.Add(time.Hour)
+	if err := repo.Complete(record); err != nil {
+		t.Fatalf("Failed to complete record: %v", err)
+	}
+	existing, _ = repo.Reserve(retry)
+	if existing == nil || existing.StatusCode != 201 || string(existing.Body) != `{"id":"r-1"}` {
+		t.Fatalf("Expected the completed record, got %v", existing)
+	}
+
+	// 期限切れのキーは再利用できる
+	expired := &model.IdempotencyRecord{Key: record.Key, Fingerprint: "c", CreatedAt: now.Add(time.Hour), ExpiresAt: now.Add(2 * time.Hour)}
+	if existing, _ = repo.Reserve(expired); existing != nil {
+		t.Fatalf("Expected the expired key to be reserved again, got %v", existing)
+	}
+
+	// 期限切れの記録をまとめて削除する
+	_, _ = repo.Reserve(&model.IdempotencyRecord{Key: "user-1:key-2", CreatedAt: now, ExpiresAt: now.Add(time.Minute)})
+	deleted, err := repo.DeleteExpired(now.Add(time.Hour))
+	if err != nil || deleted != 1 {
+		t.Errorf("Expected 1 expired record to be deleted, got %d, %v", deleted, err)
+	}
+
+	// 削除したキーは再び保存できる
+	if err := repo.Delete(record.Key); err != nil {
+		t.Fatalf("Failed to delete record: %v", err)
+	}
+	if existing, _ = repo.Reserve(record); existing != nil {
+		t.Errorf("Expected the deleted key to be reserved again, got %v", existing)
+	}
+}
+
+func TestMySQLIdempotencyRepository_Reserve(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLIdempotencyRepository(db)
+
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	record := &model.IdempotencyRecord{Key: "user-1:key-1", Fingerprint: "a", Body: []byte{}, CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+
+	// 未使用のキーは挿入される
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE idempotency_key = \\? AND expires_at <= \\?").
+		WithArgs(record.Key, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO idempotency_keys (.+) ON DUPLICATE KEY UPDATE").
+		WithArgs(record.Key, "a", 0, "null", record.Body, now, record.ExpiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	existing, err := repo.Reserve(record)
+	if err != nil || existing != nil {
+		t.Errorf("Expected the key to be reserved, got %v, %v", existing, err)
+	}
+
+	// 使用済みのキーは既存の記録を返す
+	columns := []string{"idempotency_key", "fingerprint", "status_code", "header", "body", "created_at", "expires_at"}
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE idempotency_key = \\? AND expires_at <= \\?").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO idempotency_keys").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT (.+) FROM idempotency_keys WHERE idempotency_key = \\?").
+		WithArgs(record.Key).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(record.Key, "a", 201, `{"Content-Type":"application/json"}`, []byte(`{"id":"r-1"}`), now, now.Add(time.Hour)))
+
+	existing, err = repo.Reserve(record)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if existing == nil || existing.StatusCode != 201 || existing.Header["Content-Type"] != "application/json" || string(existing.Body) != `{"id":"r-1"}` {
+		t.Errorf("Expected the completed record, got %v", existing)
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -1,19 +1,56 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
 )
 
+// ErrVersionConflict は更新しようとした予約の版が保存されている版と異なる（読み込んだ後に他の操作で変更された）ことを表す
+var ErrVersionConflict = errors.New("reservation version conflict")
+
+// ErrDuplicateICalUID は保存しようとした予約の ICalUID が既に他の予約に使われていることを表す
+var ErrDuplicateICalUID = errors.New("reservation iCalendar UID already exists")
+
+// mysqlDuplicateEntry は一意の索引に重複した値を保存しようとしたときの MySQL のエラー番号
+const mysqlDuplicateEntry = 1062
+
 type ReservationRepository interface {
+	// Create は予約を保存する。ICalUID が他の予約と同じ場合は保存せずに ErrDuplicateICalUID を返す。
 	Create(reservation *model.Reservation) error
+	// CreateIfNoOverlap は同じリソースの既存の予約と時間帯が重ならない場合のみ予約を保存する。
+	// 重なる予約があった場合は保存せずに、それらの予約を返す。
+	// 重複チェックと保存はアトミックに行われる。ICalUID は Create と同じく一意であることを確認する。
+	CreateIfNoOverlap(reservation *model.Reservation) ([]*model.Reservation, error)
+	// Update は reservation.Version が保存されている版と一致する場合のみ予約を更新し、reservation.Version を 1 増やす。
+	// 一致しない場合は更新せずに ErrVersionConflict を返す。
+	Update(reservation *model.Reservation) error
+	// UpdateIfNoOverlap は更新後の時間帯が同じリソースの他の予約と重ならない場合のみ予約を更新する。
+	// 重なる予約があった場合は更新せずに、それらの予約を返す。版は Update と同じように確認する。
+	UpdateIfNoOverlap(reservation *model.Reservation) ([]*model.Reservation, error)
 	FindAll() ([]*model.Reservation, error)
+	FindByResourceID(resourceID string) ([]*model.Reservation, error)
+	// FindInRange は [from, to) の区間と重なる予約を開始時刻の昇順で返す。
+	// resourceID が空の場合は全リソースの予約を対象にする。
+	FindInRange(resourceID string, from, to time.Time) ([]*model.Reservation, error)
+	// List は query の条件に合う予約を query の順に返す
+	List(query ReservationQuery) ([]*model.Reservation, error)
+	// Each は query の条件に合う予約を query の順に1件ずつ fn に渡す。予約を全てメモリに読み込まずに処理するために使う。
+	// fn がエラーを返した場合はそこで止めてそのエラーを返す。
+	Each(query ReservationQuery, fn func(reservation *model.Reservation) error) error
+	// FindBySeriesID は繰り返し予約の各回を開始時刻の昇順で返す
+	FindBySeriesID(seriesID string) ([]*model.Reservation, error)
 	FindByID(id string) (*model.Reservation, error)
+	// FindByICalUID は iCalendar から取り込んだ予約を取り込み元の UID で探す。見つからない場合は nil を返す。
+	FindByICalUID(uid string) (*model.Reservation, error)
 	Delete(id string) error
 }
 
@@ -33,10 +70,99 @@ func (r *InMemoryReservationRepository) Create(reservation *model.Reservation) e
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	if r.icalUIDTaken(reservation) {
+		return ErrDuplicateICalUID
+	}
+	r.reservations[reservation.ID] = reservation
+	return nil
+}
+
+func (r *InMemoryReservationRepository) CreateIfNoOverlap(reservation *model.Reservation) ([]*model.Reservation, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.icalUIDTaken(reservation) {
+		return nil, ErrDuplicateICalUID
+	}
+	if conflicts := r.findConflicts(reservation); len(conflicts) > 0 {
+		return conflicts, nil
+	}
+
+	r.reservations[reservation.ID] = reservation
+	return nil, nil
+}
+
+func (r *InMemoryReservationRepository) Update(reservation *model.Reservation) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkVersion(reservation); err != nil {
+		return err
+	}
+	reservation.Version++
 	r.reservations[reservation.ID] = reservation
 	return nil
 }
 
+func (r *InMemoryReservationRepository) UpdateIfNoOverlap(reservation *model.Reservation) ([]*model.Reservation, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.checkVersion(reservation); err != nil {
+		return nil, err
+	}
+	if conflicts := r.findConflicts(reservation); len(conflicts) > 0 {
+		return conflicts, nil
+	}
+
+	reservation.Version++
+	r.reservations[reservation.ID] = reservation
+	return nil, nil
+}
+
+// checkVersion は reservation が保存されていて、その版が reservation.Version と一致するかを確認する。
+// 呼び出し側でロックを取得しておくこと。
+func (r *InMemoryReservationRepository) checkVersion(reservation *model.Reservation) error {
+	stored, ok := r.reservations[reservation.ID]
+	if !ok {
+		return fmt.Errorf("reservation %s not found", reservation.ID)
+	}
+	if stored.Version != reservation.Version {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// icalUIDTaken は reservation の ICalUID が他の予約に使われているかを返す。MySQL の一意の索引と同じく空の UID は重複しない。
+// 呼び出し側でロックを取得しておくこと。
+func (r *InMemoryReservationRepository) icalUIDTaken(reservation *model.Reservation) bool {
+	if reservation.ICalUID == "" {
+		return false
+	}
+	for _, existing := range r.reservations {
+		if existing.ID != reservation.ID && existing.ICalUID == reservation.ICalUID {
+			return true
+		}
+	}
+	return false
+}
+
+// findConflicts は reservation 自身を除いて、同じリソースで時間帯が重なる取り消されていない予約を返す。
+// 呼び出し側でロックを取得しておくこと。
+func (r *InMemoryReservationRepository) findConflicts(reservation *model.Reservation) []*model.Reservation {
+	var conflicts []*model.Reservation
+	for _, existing := range r.reservations {
+		if existing.ID != reservation.ID &&
+			existing.ResourceID == reservation.ResourceID &&
+			existing.Active() &&
+			existing.Overlaps(reservation.StartTime, reservation.EndTime) {
+			conflicts = append(conflicts, existing)
+		}
+	}
+	sortByStartTime(conflicts)
+	return conflicts
+}
+
 func (r *InMemoryReservationRepository) FindAll() ([]*model.Reservation, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -49,6 +175,80 @@ func (r *InMemoryReservationRepository) FindAll() ([]*model.Reservation, error)
 	return reservations, nil
 }
 
+func (r *InMemoryReservationRepository) FindByResourceID(resourceID string) ([]*model.Reservation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	reservations := make([]*model.Reservation, 0)
+	for _, reservation := range r.reservations {
+		if reservation.ResourceID == resourceID {
+			reservations = append(reservations, reservation)
+		}
+	}
+	sortByStartTime(reservations)
+
+	return reservations, nil
+}
+
+func (r *InMemoryReservationRepository) FindBySeriesID(seriesID string) ([]*model.Reservation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	reservations := make([]*model.Reservation, 0)
+	for _, reservation := range r.reservations {
+		if reservation.SeriesID == seriesID {
+			reservations = append(reservations, reservation)
+		}
+	}
+	sortByStartTime(reservations)
+
+	return reservations, nil
+}
+
+func (r *InMemoryReservationRepository) FindInRange(resourceID string, from, to time.Time) ([]*model.Reservation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	reservations := make([]*model.Reservation, 0)
+	for _, reservation := range r.reservations {
+		if resourceID != "" && reservation.ResourceID != resourceID {
+			continue
+		}
+		if reservation.Overlaps(from, to) {
+			reservations = append(reservations, reservation)
+		}
+	}
+	sortByStartTime(reservations)
+
+	return reservations, nil
+}
+
+func (r *InMemoryReservationRepository) List(query ReservationQuery) ([]*model.Reservation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	reservations := make([]*model.Reservation, 0, len(r.reservations))
+	for _, reservation := range r.reservations {
+		reservations = append(reservations, reservation)
+	}
+
+	return query.apply(reservations), nil
+}
+
+func (r *InMemoryReservationRepository) Each(query ReservationQuery, fn func(reservation *model.Reservation) error) error {
+	// fn の中から予約を保存できるよう、ロックを解放してから fn を呼ぶ
+	reservations, err := r.List(query)
+	if err != nil {
+		return err
+	}
+	for _, reservation := range reservations {
+		if err := fn(reservation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *InMemoryReservationRepository) FindByID(id string) (*model.Reservation, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -61,6 +261,19 @@ func (r *InMemoryReservationRepository) FindByID(id string) (*model.Reservation,
 	return reservation, nil
 }
 
+func (r *InMemoryReservationRepository) FindByICalUID(uid string) (*model.Reservation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, reservation := range r.reservations {
+		if reservation.ICalUID == uid {
+			return reservation, nil
+		}
+	}
+
+	return nil, nil
+}
+
 func (r *InMemoryReservationRepository) Delete(id string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -69,101 +282,428 @@ func (r *InMemoryReservationRepository) Delete(id string) error {
 	return nil
 }
 
+// sortByStartTime は予約を開始時刻の昇順に並べ替える
+func sortByStartTime(reservations []*model.Reservation) {
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].StartTime.Before(reservations[j].StartTime)
+	})
+}
+
 // MySQLReservationRepository - MySQL implementation
 type MySQLReservationRepository struct {
 	db *sql.DB
 }
 
+const (
+	reservationColumns = "id, resource_id, series_id, user_id, title, description, attendees, start_time, end_time, status, cancelled_at, cancellation_reason, created_at, updated_at, version, ical_uid"
+
+	// reservationLockPrefix はリソースごとに予約の重複チェックを直列化するための
+	// MySQL の名前付きロックの接頭辞。同じ MySQL を共有する全サーバーインスタンス間で有効になる。
+	reservationLockPrefix = "yoyaku.reservations."
+	// namedLockTimeout は名前付きロックの取得を待つ秒数
+	namedLockTimeout = 10
+)
+
 // NewMySQLReservationRepository creates a new MySQL repository
-func NewMySQLReservationRepository(db *sql.DB) (*MySQLReservationRepository, error) {
-	// Create reservations table if it doesn't exist
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS reservations (
-			id VARCHAR(36) PRIMARY KEY,
-			start_time DATETIME NOT NULL,
-			end_time DATETIME NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		)
-	`)
+func NewMySQLReservationRepository(db *sql.DB) *MySQLReservationRepository {
+	return &MySQLReservationRepository{
+		db: db,
+	}
+}
+
+// reservationLockName はリソースごとの名前付きロックの名前を返す
+func reservationLockName(resourceID string) string {
+	return reservationLockPrefix + resourceID
+}
+
+// nullString は空文字列を NULL として保存するための値を返す
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// attendeesJSON は参加者を attendees カラムに保存する JSON に変換する。参加者がいない場合は空の配列にする。
+func attendeesJSON(attendees []string) (string, error) {
+	if attendees == nil {
+		attendees = []string{}
+	}
+	data, err := json.Marshal(attendees)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create reservations table: %w", err)
+		return "", fmt.Errorf("failed to encode attendees: %w", err)
 	}
+	return string(data), nil
+}
 
-	return &MySQLReservationRepository{
-		db: db,
-	}, nil
+// rowScanner は *sql.Row と *sql.Rows の共通インターフェース
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanReservation は reservationColumns の順に並んだ1行を予約として読み込む
+func scanReservation(row rowScanner) (*model.Reservation, error) {
+	var reservation model.Reservation
+	var seriesID, userID, icalUID sql.NullString
+	var attendees []byte
+	var cancelledAt sql.NullTime
+	var startTime, endTime, createdAt, updatedAt time.Time
+	if err := row.Scan(
+		&reservation.ID,
+		&reservation.ResourceID,
+		&seriesID,
+		&userID,
+		&reservation.Title,
+		&reservation.Description,
+		&attendees,
+		&startTime,
+		&endTime,
+		&reservation.Status,
+		&cancelledAt,
+		&reservation.CancellationReason,
+		&createdAt,
+		&updatedAt,
+		&reservation.Version,
+		&icalUID,
+	); err != nil {
+		return nil, err
+	}
+	reservation.SeriesID = seriesID.String
+	reservation.UserID = userID.String
+	reservation.ICalUID = icalUID.String
+	reservation.Attendees = []string{}
+	if len(attendees) > 0 {
+		if err := json.Unmarshal(attendees, &reservation.Attendees); err != nil {
+			return nil, fmt.Errorf("invalid attendees: %w", err)
+		}
+	}
+	if cancelledAt.Valid {
+		reservation.CancelledAt = &cancelledAt.Time
+	}
+	reservation.StartTime = startTime
+	reservation.EndTime = endTime
+	reservation.CreatedAt = createdAt
+	reservation.UpdatedAt = updatedAt
+	return &reservation, nil
+}
+
+// scanReservations は reservationColumns を選択したクエリ結果を全て読み込む
+func scanReservations(rows *sql.Rows) ([]*model.Reservation, error) {
+	defer rows.Close()
+
+	var reservations []*model.Reservation
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reservation: %w", err)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return reservations, nil
+}
+
+// withNamedLock は名前付きロックを取得した専用コネクション上のトランザクションで fn を実行する。
+// ロックはコミットまたはロールバックの後に解放される。
+func withNamedLock(db *sql.DB, name string, fn func(tx *sql.Tx) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, namedLockTimeout).Scan(&acquired); err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("failed to acquire lock %q: timed out", name)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name)
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
 // Create inserts a new reservation into the database
 func (r *MySQLReservationRepository) Create(reservation *model.Reservation) error {
-	_, err := r.db.Exec(
-		"INSERT INTO reservations (id, start_time, end_time, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+	return insertReservation(r.db, reservation)
+}
+
+// execer は *sql.DB と *sql.Tx の共通インターフェース
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertReservation(db execer, reservation *model.Reservation) error {
+	attendees, err := attendeesJSON(reservation.Attendees)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(
+		"INSERT INTO reservations ("+reservationColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 		reservation.ID,
+		reservation.ResourceID,
+		nullString(reservation.SeriesID),
+		nullString(reservation.UserID),
+		reservation.Title,
+		reservation.Description,
+		attendees,
 		reservation.StartTime,
 		reservation.EndTime,
+		reservation.Status,
+		reservation.CancelledAt,
+		reservation.CancellationReason,
 		reservation.CreatedAt,
 		reservation.UpdatedAt,
+		reservation.Version,
+		nullString(reservation.ICalUID),
 	)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry && reservation.ICalUID != "" {
+		// id は UUID のため、重複するのは ical_uid の一意の索引だけ
+		return ErrDuplicateICalUID
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create reservation: %w", err)
 	}
 	return nil
 }
 
+// CreateIfNoOverlap inserts a reservation unless it overlaps an existing one on the same resource.
+// The overlap check and insert run while holding a per-resource named lock, so concurrent
+// requests from several server instances cannot double-book the same time range.
+func (r *MySQLReservationRepository) CreateIfNoOverlap(reservation *model.Reservation) ([]*model.Reservation, error) {
+	var conflicts []*model.Reservation
+	err := withNamedLock(r.db, reservationLockName(reservation.ResourceID), func(tx *sql.Tx) error {
+		var err error
+		conflicts, err = findConflicts(tx, reservation)
+		if err != nil || len(conflicts) > 0 {
+			return err
+		}
+		return insertReservation(tx, reservation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
+
+// findConflicts は reservation 自身を除いて、同じリソースで時間帯が重なる取り消されていない予約を返す
+func findConflicts(tx *sql.Tx, reservation *model.Reservation) ([]*model.Reservation, error) {
+	rows, err := tx.Query(
+		"SELECT "+reservationColumns+" FROM reservations WHERE resource_id = ? AND start_time < ? AND end_time > ? AND id <> ? AND status <> ? ORDER BY start_time",
+		reservation.ResourceID,
+		reservation.EndTime,
+		reservation.StartTime,
+		reservation.ID,
+		model.StatusCancelled,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find overlapping reservations: %w", err)
+	}
+	return scanReservations(rows)
+}
+
+// Update overwrites the mutable fields of a reservation if its version still matches the stored one.
+func (r *MySQLReservationRepository) Update(reservation *model.Reservation) error {
+	return updateReservation(r.db, reservation)
+}
+
+// updateReservation は版を条件にした UPDATE で予約を更新する。読み込みと書き込みの間に他の更新が入った場合は
+// 条件に合う行がなくなるため、ErrVersionConflict を返す。
+func updateReservation(db execer, reservation *model.Reservation) error {
+	attendees, err := attendeesJSON(reservation.Attendees)
+	if err != nil {
+		return err
+	}
+	result, err := db.Exec(
+		"UPDATE reservations SET resource_id = ?, title = ?, description = ?, attendees = ?, start_time = ?, end_time = ?, status = ?, cancelled_at = ?, cancellation_reason = ?, updated_at = ?, version = version + 1 WHERE id = ? AND version = ?",
+		reservation.ResourceID,
+		reservation.Title,
+		reservation.Description,
+		attendees,
+		reservation.StartTime,
+		reservation.EndTime,
+		reservation.Status,
+		reservation.CancelledAt,
+		reservation.CancellationReason,
+		reservation.UpdatedAt,
+		reservation.ID,
+		reservation.Version,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update reservation: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update reservation: %w", err)
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+	reservation.Version++
+	return nil
+}
+
+// UpdateIfNoOverlap updates a reservation unless its new time range overlaps another
+// reservation on the same resource, holding the same per-resource lock as CreateIfNoOverlap.
+func (r *MySQLReservationRepository) UpdateIfNoOverlap(reservation *model.Reservation) ([]*model.Reservation, error) {
+	var conflicts []*model.Reservation
+	err := withNamedLock(r.db, reservationLockName(reservation.ResourceID), func(tx *sql.Tx) error {
+		var err error
+		conflicts, err = findConflicts(tx, reservation)
+		if err != nil || len(conflicts) > 0 {
+			return err
+		}
+		return updateReservation(tx, reservation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
+
 // FindAll returns all reservations
 func (r *MySQLReservationRepository) FindAll() ([]*model.Reservation, error) {
-	rows, err := r.db.Query("SELECT id, start_time, end_time, created_at, updated_at FROM reservations")
+	rows, err := r.db.Query("SELECT " + reservationColumns + " FROM reservations")
 	if err != nil {
 		return nil, fmt.Errorf("failed to find reservations: %w", err)
 	}
+	return scanReservations(rows)
+}
+
+// FindByResourceID returns all reservations of a resource ordered by start time
+func (r *MySQLReservationRepository) FindByResourceID(resourceID string) ([]*model.Reservation, error) {
+	rows, err := r.db.Query(
+		"SELECT "+reservationColumns+" FROM reservations WHERE resource_id = ? ORDER BY start_time",
+		resourceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reservations: %w", err)
+	}
+	return scanReservations(rows)
+}
+
+// FindBySeriesID returns all occurrences of a series ordered by start time
+func (r *MySQLReservationRepository) FindBySeriesID(seriesID string) ([]*model.Reservation, error) {
+	rows, err := r.db.Query(
+		"SELECT "+reservationColumns+" FROM reservations WHERE series_id = ? ORDER BY start_time",
+		seriesID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reservations: %w", err)
+	}
+	return scanReservations(rows)
+}
+
+// FindInRange returns reservations intersecting [from, to) ordered by start time.
+// The start_time bound lets MySQL answer it with a range scan on the time indexes.
+func (r *MySQLReservationRepository) FindInRange(resourceID string, from, to time.Time) ([]*model.Reservation, error) {
+	conditions := "start_time < ? AND end_time > ?"
+	args := []interface{}{to, from}
+	if resourceID != "" {
+		conditions = "resource_id = ? AND " + conditions
+		args = append([]interface{}{resourceID}, args...)
+	}
+
+	rows, err := r.db.Query("SELECT "+reservationColumns+" FROM reservations WHERE "+conditions+" ORDER BY start_time", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reservations: %w", err)
+	}
+	return scanReservations(rows)
+}
+
+// List returns reservations matching query in its sort order.
+// Ties on the sort column are broken by id so that cursors resume at a stable position.
+func (r *MySQLReservationRepository) List(query ReservationQuery) ([]*model.Reservation, error) {
+	statement, args := query.sql()
+	rows, err := r.db.Query(statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reservations: %w", err)
+	}
+	reservations, err := scanReservations(rows)
+	if err != nil {
+		return nil, err
+	}
+	if reservations == nil {
+		reservations = []*model.Reservation{}
+	}
+	return reservations, nil
+}
+
+// Each scans reservations matching the query one row at a time without buffering the result set
+func (r *MySQLReservationRepository) Each(query ReservationQuery, fn func(reservation *model.Reservation) error) error {
+	statement, args := query.sql()
+	rows, err := r.db.Query(statement, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list reservations: %w", err)
+	}
 	defer rows.Close()
 
-	var reservations []*model.Reservation
 	for rows.Next() {
-		var reservation model.Reservation
-		var startTime, endTime, createdAt, updatedAt time.Time
-		if err := rows.Scan(&reservation.ID, &startTime, &endTime, &createdAt, &updatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan reservation: %w", err)
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan reservation: %w", err)
+		}
+		if err := fn(reservation); err != nil {
+			return err
 		}
-		reservation.StartTime = startTime
-		reservation.EndTime = endTime
-		reservation.CreatedAt = createdAt
-		reservation.UpdatedAt = updatedAt
-		reservations = append(reservations, &reservation)
 	}
-
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
+		return fmt.Errorf("rows error: %w", err)
 	}
-
-	return reservations, nil
+	return nil
 }
 
 // FindByID returns a reservation by ID
 func (r *MySQLReservationRepository) FindByID(id string) (*model.Reservation, error) {
-	var reservation model.Reservation
-	var startTime, endTime, createdAt, updatedAt time.Time
-	
-	err := r.db.QueryRow(
-		"SELECT id, start_time, end_time, created_at, updated_at FROM reservations WHERE id = ?", 
+	reservation, err := scanReservation(r.db.QueryRow(
+		"SELECT "+reservationColumns+" FROM reservations WHERE id = ?",
 		id,
-	).Scan(&reservation.ID, &startTime, &endTime, &createdAt, &updatedAt)
-	
+	))
+
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to find reservation: %w", err)
 	}
-	
-	reservation.StartTime = startTime
-	reservation.EndTime = endTime
-	reservation.CreatedAt = createdAt
-	reservation.UpdatedAt = updatedAt
-	
-	return &reservation, nil
+
+	return reservation, nil
+}
+
+// FindByICalUID returns a reservation imported from iCalendar by its source UID
+func (r *MySQLReservationRepository) FindByICalUID(uid string) (*model.Reservation, error) {
+	reservation, err := scanReservation(r.db.QueryRow(
+		"SELECT "+reservationColumns+" FROM reservations WHERE ical_uid = ? LIMIT 1",
+		uid,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reservation: %w", err)
+	}
+
+	return reservation, nil
 }
 
 // Delete removes a reservation by ID
@@ -2,20 +2,29 @@ package repository
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
 )
 
+// reservationColumnNames は sqlmock の行定義に使うカラム名
+var reservationColumnNames = strings.Split(reservationColumns, ", ")
+
 func TestInMemoryReservationRepository_Create(t *testing.T) {
 	// 準備
 	repo := NewInMemoryReservationRepository()
 	now := time.Now()
-	reservation := model.NewReservation(now, now.Add(1*time.Hour))
+	reservation := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
 
 	// 実行
 	err := repo.Create(reservation)
@@ -39,8 +48,8 @@ func TestInMemoryReservationRepository_FindAll(t *testing.T) {
 	// 準備
 	repo := NewInMemoryReservationRepository()
 	now := time.Now()
-	reservation1 := model.NewReservation(now, now.Add(1*time.Hour))
-	reservation2 := model.NewReservation(now.Add(2*time.Hour), now.Add(3*time.Hour))
+	reservation1 := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
+	reservation2 := model.NewReservation("resource-1", now.Add(2*time.Hour), now.Add(3*time.Hour))
 
 	// 実行：予約を追加
 	err := repo.Create(reservation1)
@@ -68,7 +77,7 @@ func TestInMemoryReservationRepository_FindByID(t *testing.T) {
 	// 準備
 	repo := NewInMemoryReservationRepository()
 	now := time.Now()
-	reservation := model.NewReservation(now, now.Add(1*time.Hour))
+	reservation := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
 	err := repo.Create(reservation)
 	if err != nil {
 		t.Fatalf("Failed to create reservation: %v", err)
@@ -115,7 +124,7 @@ func TestInMemoryReservationRepository_Delete(t *testing.T) {
 	// 準備
 	repo := NewInMemoryReservationRepository()
 	now := time.Now()
-	reservation := model.NewReservation(now, now.Add(1*time.Hour))
+	reservation := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
 	err := repo.Create(reservation)
 	if err != nil {
 		t.Fatalf("Failed to create reservation: %v", err)
@@ -144,37 +153,422 @@ func TestInMemoryReservationRepository_Delete(t *testing.T) {
 	}
 }
 
-// MySQLレポジトリのテスト
+func TestInMemoryReservationRepository_CreateIfNoOverlap(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	base := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	existing := model.NewReservation("resource-1", base, base.Add(1*time.Hour))
+	if _, err := repo.CreateIfNoOverlap(existing); err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
 
-func TestMySQLReservationRepository_New(t *testing.T) {
-	// SQLMockのセットアップ
-	db, mock, err := sqlmock.New()
+	// テストケース
+	tests := []struct {
+		name          string
+		start         time.Time
+		end           time.Time
+		wantConflicts int
+	}{
+		{"重なる予約", base.Add(30 * time.Minute), base.Add(90 * time.Minute), 1},
+		{"同じ時間帯の予約", base, base.Add(1 * time.Hour), 1},
+		{"直後に連続する予約", base.Add(1 * time.Hour), base.Add(2 * time.Hour), 0},
+		{"直前に連続する予約", base.Add(-1 * time.Hour), base, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservation := model.NewReservation("resource-1", tt.start, tt.end)
+
+			// 実行
+			conflicts, err := repo.CreateIfNoOverlap(reservation)
+
+			// 検証
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(conflicts) != tt.wantConflicts {
+				t.Fatalf("Expected %d conflicts, got %d", tt.wantConflicts, len(conflicts))
+			}
+			stored, _ := repo.FindByID(reservation.ID)
+			if tt.wantConflicts > 0 && stored != nil {
+				t.Error("Expected conflicting reservation not to be stored")
+			}
+			if tt.wantConflicts == 0 && stored == nil {
+				t.Error("Expected reservation to be stored")
+			}
+			if tt.wantConflicts > 0 && conflicts[0].ID != existing.ID {
+				t.Errorf("Expected conflict with %s, got %s", existing.ID, conflicts[0].ID)
+			}
+		})
+	}
+}
+
+func TestInMemoryReservationRepository_CreateIfNoOverlap_DifferentResource(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	base := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := repo.CreateIfNoOverlap(model.NewReservation("room-a", base, base.Add(1*time.Hour))); err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+
+	// 実行：別のリソースの同じ時間帯を予約
+	conflicts, err := repo.CreateIfNoOverlap(model.NewReservation("room-b", base, base.Add(1*time.Hour)))
+
+	// 検証
 	if err != nil {
-		t.Fatalf("Failed to create mock: %v", err)
+		t.Errorf("Expected no error, got %v", err)
 	}
-	defer db.Close()
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts across resources, got %d", len(conflicts))
+	}
+}
 
-	// テーブル作成クエリの期待値を設定
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
+func TestInMemoryReservationRepository_CreateIfNoOverlap_IgnoresCancelled(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	now := time.Now()
+	cancelled := model.NewReservation("room-a", now, now.Add(time.Hour))
+	cancelled.Status = model.StatusCancelled
+	if err := repo.Create(cancelled); err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
 
-	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
+	// 実行
+	conflicts, err := repo.CreateIfNoOverlap(model.NewReservation("room-a", now, now.Add(time.Hour)))
 
 	// 検証
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if repo == nil {
-		t.Error("Expected repository to be created, got nil")
+	if len(conflicts) != 0 {
+		t.Errorf("Expected cancelled reservation not to conflict, got %v", conflicts)
 	}
+}
 
-	// モックの期待通りに実行されたか確認
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("Unfulfilled expectations: %v", err)
+func TestInMemoryReservationRepository_CreateIfNoOverlap_DuplicateICalUID(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	now := time.Now()
+	imported := model.NewReservation("room-a", now, now.Add(time.Hour))
+	imported.ICalUID = "event-1@example.com"
+	if _, err := repo.CreateIfNoOverlap(imported); err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+
+	// 実行 - 同じ UID の予約を他のリソースに保存する
+	duplicate := model.NewReservation("room-b", now, now.Add(time.Hour))
+	duplicate.ICalUID = imported.ICalUID
+	_, err := repo.CreateIfNoOverlap(duplicate)
+
+	// 検証
+	if !errors.Is(err, ErrDuplicateICalUID) {
+		t.Errorf("Expected ErrDuplicateICalUID, got %v", err)
+	}
+	if found, _ := repo.FindByID(duplicate.ID); found != nil {
+		t.Error("Expected the duplicate not to be saved")
+	}
+
+	// UID のない予約は重複しない
+	for _, resourceID := range []string{"room-b", "room-c"} {
+		if _, err := repo.CreateIfNoOverlap(model.NewReservation(resourceID, now, now.Add(time.Hour))); err != nil {
+			t.Errorf("Expected reservations without a UID to be saved, got %v", err)
+		}
+	}
+}
+
+func TestInMemoryReservationRepository_UpdateIfNoOverlap(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	base := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	first := model.NewReservation("resource-1", base, base.Add(1*time.Hour))
+	second := model.NewReservation("resource-1", base.Add(2*time.Hour), base.Add(3*time.Hour))
+	for _, r := range []*model.Reservation{first, second} {
+		if err := repo.Create(r); err != nil {
+			t.Fatalf("Failed to create reservation: %v", err)
+		}
+	}
+
+	// 実行：自分自身の時間帯と重なるように延長するのは問題ない
+	extended := *first
+	extended.EndTime = base.Add(90 * time.Minute)
+	conflicts, err := repo.UpdateIfNoOverlap(&extended)
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %d", len(conflicts))
+	}
+	stored, _ := repo.FindByID(first.ID)
+	if !stored.EndTime.Equal(extended.EndTime) {
+		t.Errorf("Expected end time %v, got %v", extended.EndTime, stored.EndTime)
+	}
+
+	// 実行：他の予約と重なる変更は拒否される
+	moved := *stored
+	moved.StartTime = base.Add(150 * time.Minute)
+	moved.EndTime = base.Add(210 * time.Minute)
+	conflicts, err = repo.UpdateIfNoOverlap(&moved)
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].ID != second.ID {
+		t.Errorf("Expected conflict with %s, got %v", second.ID, conflicts)
+	}
+	stored, _ = repo.FindByID(first.ID)
+	if !stored.StartTime.Equal(base) {
+		t.Error("Expected conflicting update not to be stored")
+	}
+
+	// 実行：存在しない予約の更新はエラー
+	if _, err := repo.UpdateIfNoOverlap(model.NewReservation("resource-1", base, base.Add(time.Hour))); err == nil {
+		t.Error("Expected error when updating non-existent reservation, got nil")
+	}
+}
+
+func TestInMemoryReservationRepository_Update_Version(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	base := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	reservation := model.NewReservation("resource-1", base, base.Add(time.Hour))
+	if err := repo.Create(reservation); err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+
+	// 実行：読み込んだ版のまま更新すると版が進む
+	first := *reservation
+	first.Title = "1回目"
+	if err := repo.Update(&first); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Expected version 2, got %d", first.Version)
+	}
+
+	// 実行：古い版からの更新は拒否される
+	stale := *reservation
+	stale.Title = "古い版"
+	if err := repo.Update(&stale); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+	if _, err := repo.UpdateIfNoOverlap(&stale); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+
+	// 検証
+	stored, _ := repo.FindByID(reservation.ID)
+	if stored.Title != "1回目" || stored.Version != 2 {
+		t.Errorf("Expected first update to be kept, got %q (version %d)", stored.Title, stored.Version)
+	}
+}
+
+func TestInMemoryReservationRepository_FindByResourceID(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	now := time.Now()
+	later := model.NewReservation("room-a", now.Add(2*time.Hour), now.Add(3*time.Hour))
+	earlier := model.NewReservation("room-a", now, now.Add(1*time.Hour))
+	other := model.NewReservation("room-b", now, now.Add(1*time.Hour))
+	for _, r := range []*model.Reservation{later, earlier, other} {
+		if err := repo.Create(r); err != nil {
+			t.Fatalf("Failed to create reservation: %v", err)
+		}
+	}
+
+	// 実行
+	reservations, err := repo.FindByResourceID("room-a")
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(reservations) != 2 {
+		t.Fatalf("Expected 2 reservations, got %d", len(reservations))
+	}
+	if reservations[0].ID != earlier.ID || reservations[1].ID != later.ID {
+		t.Error("Expected reservations to be ordered by start time")
+	}
+}
+
+func TestInMemoryReservationRepository_FindBySeriesID(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	now := time.Now()
+	series := model.NewReservationSeries("room-a", "FREQ=DAILY;COUNT=2", now, now.Add(time.Hour))
+	second := series.NewOccurrence(now.AddDate(0, 0, 1))
+	first := series.NewOccurrence(now)
+	single := model.NewReservation("room-a", now.Add(2*time.Hour), now.Add(3*time.Hour))
+	for _, r := range []*model.Reservation{second, single, first} {
+		if err := repo.Create(r); err != nil {
+			t.Fatalf("Failed to create reservation: %v", err)
+		}
+	}
+
+	// 実行
+	reservations, err := repo.FindBySeriesID(series.ID)
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(reservations) != 2 {
+		t.Fatalf("Expected 2 reservations, got %d", len(reservations))
+	}
+	if reservations[0].ID != first.ID || reservations[1].ID != second.ID {
+		t.Error("Expected occurrences to be ordered by start time")
+	}
+}
+
+func TestInMemoryReservationRepository_FindInRange(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	base := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	before := model.NewReservation("room-a", base.Add(-2*time.Hour), base)
+	straddling := model.NewReservation("room-a", base.Add(-1*time.Hour), base.Add(1*time.Hour))
+	inside := model.NewReservation("room-a", base.Add(24*time.Hour), base.Add(25*time.Hour))
+	otherResource := model.NewReservation("room-b", base.Add(2*time.Hour), base.Add(3*time.Hour))
+	after := model.NewReservation("room-a", base.Add(7*24*time.Hour), base.Add(7*24*time.Hour+time.Hour))
+	for _, r := range []*model.Reservation{after, inside, before, straddling, otherResource} {
+		if err := repo.Create(r); err != nil {
+			t.Fatalf("Failed to create reservation: %v", err)
+		}
+	}
+
+	// テストケース
+	tests := []struct {
+		name       string
+		resourceID string
+		wantIDs    []string
+	}{
+		{"全リソース", "", []string{straddling.ID, otherResource.ID, inside.ID}},
+		{"リソースで絞り込み", "room-a", []string{straddling.ID, inside.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 実行：[base, base+7日) の1週間
+			reservations, err := repo.FindInRange(tt.resourceID, base, base.Add(7*24*time.Hour))
+
+			// 検証
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(reservations) != len(tt.wantIDs) {
+				t.Fatalf("Expected %d reservations, got %d", len(tt.wantIDs), len(reservations))
+			}
+			for i, id := range tt.wantIDs {
+				if reservations[i].ID != id {
+					t.Errorf("Expected reservation %d to be %s, got %s", i, id, reservations[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestInMemoryReservationRepository_List(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	base := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	newReservation := func(id, resourceID string, start time.Time, duration time.Duration, createdAt time.Time) *model.Reservation {
+		r := model.NewReservation(resourceID, start, start.Add(duration))
+		r.ID = id
+		r.CreatedAt = createdAt
+		return r
+	}
+	a := newReservation("a", "room-a", base, time.Hour, base.AddDate(0, 0, -3))
+	b := newReservation("b", "room-a", base.Add(2*time.Hour), 30*time.Minute, base.AddDate(0, 0, -1))
+	c := newReservation("c", "room-b", base.Add(2*time.Hour), 3*time.Hour, base.AddDate(0, 0, -2))
+	d := newReservation("d", "room-a", base.AddDate(0, 0, 7), time.Hour, base)
+	cancelled := newReservation("e", "room-a", base.Add(time.Hour), time.Hour, base)
+	cancelled.Status = model.StatusCancelled
+	for _, r := range []*model.Reservation{d, cancelled, c, b, a} {
+		if err := repo.Create(r); err != nil {
+			t.Fatalf("Failed to create reservation: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		query   ReservationQuery
+		wantIDs []string
+	}{
+		{name: "開始時刻の昇順で同じ時刻は ID 順", wantIDs: []string{"a", "b", "c", "d"}},
+		{name: "開始時刻の降順", query: ReservationQuery{Descending: true}, wantIDs: []string{"d", "c", "b", "a"}},
+		{name: "作成日時の昇順", query: ReservationQuery{SortBy: SortByCreatedAt}, wantIDs: []string{"a", "c", "b", "d"}},
+		{name: "リソースで絞り込み", query: ReservationQuery{ResourceID: "room-b"}, wantIDs: []string{"c"}},
+		{name: "期間で絞り込み", query: ReservationQuery{From: base.Add(30 * time.Minute), To: base.AddDate(0, 0, 1)}, wantIDs: []string{"a", "b", "c"}},
+		{name: "作成日時で絞り込み", query: ReservationQuery{CreatedAfter: base.AddDate(0, 0, -2)}, wantIDs: []string{"b", "d"}},
+		{name: "長さで絞り込み", query: ReservationQuery{MinDuration: time.Hour, MaxDuration: 2 * time.Hour}, wantIDs: []string{"a", "d"}},
+		{name: "状態で絞り込み", query: ReservationQuery{Statuses: []model.ReservationStatus{model.StatusCancelled}}, wantIDs: []string{"e"}},
+		{name: "件数", query: ReservationQuery{Limit: 2}, wantIDs: []string{"a", "b"}},
+		{name: "カーソルの後から", query: ReservationQuery{After: &ReservationCursor{Value: b.StartTime, ID: "b"}}, wantIDs: []string{"c", "d"}},
+		{name: "降順のカーソルの後から", query: ReservationQuery{Descending: true, After: &ReservationCursor{Value: c.StartTime, ID: "c"}}, wantIDs: []string{"b", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 実行
+			reservations, err := repo.List(tt.query)
+
+			// 検証
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			ids := make([]string, len(reservations))
+			for i, r := range reservations {
+				ids[i] = r.ID
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("Expected %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestInMemoryReservationRepository_CreateIfNoOverlap_Concurrent(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationRepository()
+	base := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	const workers = 50
+
+	// 実行：同じ時間帯の予約を並行して作成
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conflicts, err := repo.CreateIfNoOverlap(model.NewReservation("resource-1", base, base.Add(1*time.Hour)))
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+				return
+			}
+			if len(conflicts) == 0 {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 検証
+	if created != 1 {
+		t.Errorf("Expected exactly 1 reservation to be created, got %d", created)
+	}
+	reservations, _ := repo.FindAll()
+	if len(reservations) != 1 {
+		t.Errorf("Expected 1 stored reservation, got %d", len(reservations))
 	}
 }
 
-func TestMySQLReservationRepository_New_Error(t *testing.T) {
+// MySQLレポジトリのテスト
+
+func TestMySQLReservationRepository_New(t *testing.T) {
 	// SQLMockのセットアップ
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -182,18 +576,12 @@ func TestMySQLReservationRepository_New_Error(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリでエラーを返すように設定
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnError(errors.New("database error"))
-
-	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
+	// レポジトリの作成（テーブルはマイグレーションで作成するため、クエリは実行しない）
+	repo := NewMySQLReservationRepository(db)
 
 	// 検証
-	if err == nil {
-		t.Error("Expected error, got nil")
-	}
-	if repo != nil {
-		t.Errorf("Expected nil repository, got %v", repo)
+	if repo == nil {
+		t.Error("Expected repository to be created, got nil")
 	}
 
 	// モックの期待通りに実行されたか確認
@@ -210,26 +598,34 @@ func TestMySQLReservationRepository_Create(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定（NewMySQLReservationRepositoryでの呼び出し）
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
-
 	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
-	if err != nil {
-		t.Fatalf("Failed to create repository: %v", err)
-	}
+	repo := NewMySQLReservationRepository(db)
 
 	// テスト対象の予約データ
 	now := time.Now()
-	reservation := model.NewReservation(now, now.Add(1*time.Hour))
+	reservation := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
+	reservation.UserID = "user-1"
+	reservation.Title = "定例会議"
+	reservation.Attendees = []string{"a@example.com"}
 
 	// INSERTクエリの期待値を設定
 	mock.ExpectExec("INSERT INTO reservations").WithArgs(
 		reservation.ID,
+		reservation.ResourceID,
+		nil,
+		"user-1",
+		"定例会議",
+		"",
+		`["a@example.com"]`,
 		reservation.StartTime,
 		reservation.EndTime,
+		reservation.Status,
+		nil,
+		"",
 		reservation.CreatedAt,
 		reservation.UpdatedAt,
+		reservation.Version,
+		nil,
 	).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// 実行
@@ -254,26 +650,31 @@ func TestMySQLReservationRepository_Create_Error(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定（NewMySQLReservationRepositoryでの呼び出し）
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
-
 	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
-	if err != nil {
-		t.Fatalf("Failed to create repository: %v", err)
-	}
+	repo := NewMySQLReservationRepository(db)
 
 	// テスト対象の予約データ
 	now := time.Now()
-	reservation := model.NewReservation(now, now.Add(1*time.Hour))
+	reservation := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
 
 	// INSERTクエリでエラーを返すように設定
 	mock.ExpectExec("INSERT INTO reservations").WithArgs(
 		reservation.ID,
+		reservation.ResourceID,
+		nil,
+		nil,
+		"",
+		"",
+		"[]",
 		reservation.StartTime,
 		reservation.EndTime,
+		reservation.Status,
+		nil,
+		"",
 		reservation.CreatedAt,
 		reservation.UpdatedAt,
+		reservation.Version,
+		nil,
 	).WillReturnError(errors.New("database error"))
 
 	// 実行
@@ -298,14 +699,8 @@ func TestMySQLReservationRepository_FindAll(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定（NewMySQLReservationRepositoryでの呼び出し）
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
-
 	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
-	if err != nil {
-		t.Fatalf("Failed to create repository: %v", err)
-	}
+	repo := NewMySQLReservationRepository(db)
 
 	// テストデータ
 	now := time.Now()
@@ -319,12 +714,12 @@ func TestMySQLReservationRepository_FindAll(t *testing.T) {
 	updatedAt := now
 
 	// SELECTクエリの結果を設定
-	rows := sqlmock.NewRows([]string{"id", "start_time", "end_time", "created_at", "updated_at"}).
-		AddRow(id1, startTime1, endTime1, createdAt, updatedAt).
-		AddRow(id2, startTime2, endTime2, createdAt, updatedAt)
+	rows := sqlmock.NewRows(reservationColumnNames).
+		AddRow(id1, "resource-1", nil, nil, "", "", nil, startTime1, endTime1, "confirmed", nil, "", createdAt, updatedAt, 1, nil).
+		AddRow(id2, "resource-1", nil, nil, "", "", nil, startTime2, endTime2, "confirmed", nil, "", createdAt, updatedAt, 1, nil)
 
 	// SELECTクエリの期待値を設定
-	mock.ExpectQuery("SELECT id, start_time, end_time, created_at, updated_at FROM reservations").
+	mock.ExpectQuery("SELECT id, resource_id, series_id, user_id, title, description, attendees, start_time, end_time, status, cancelled_at, cancellation_reason, created_at, updated_at, version, ical_uid FROM reservations").
 		WillReturnRows(rows)
 
 	// 実行
@@ -358,17 +753,11 @@ func TestMySQLReservationRepository_FindAll_QueryError(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定（NewMySQLReservationRepositoryでの呼び出し）
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
-
 	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
-	if err != nil {
-		t.Fatalf("Failed to create repository: %v", err)
-	}
+	repo := NewMySQLReservationRepository(db)
 
 	// SELECTクエリでエラーを返すように設定
-	mock.ExpectQuery("SELECT id, start_time, end_time, created_at, updated_at FROM reservations").
+	mock.ExpectQuery("SELECT id, resource_id, series_id, user_id, title, description, attendees, start_time, end_time, status, cancelled_at, cancellation_reason, created_at, updated_at, version, ical_uid FROM reservations").
 		WillReturnError(errors.New("database error"))
 
 	// 実行
@@ -396,21 +785,15 @@ func TestMySQLReservationRepository_FindAll_ScanError(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定（NewMySQLReservationRepositoryでの呼び出し）
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
-
 	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
-	if err != nil {
-		t.Fatalf("Failed to create repository: %v", err)
-	}
+	repo := NewMySQLReservationRepository(db)
 
 	// 型不一致によるスキャンエラーを発生させるために不正な列タイプを設定
-	rows := sqlmock.NewRows([]string{"id", "start_time", "end_time", "created_at", "updated_at"}).
-		AddRow("id1", "not-a-time", "not-a-time", "not-a-time", "not-a-time")
+	rows := sqlmock.NewRows(reservationColumnNames).
+		AddRow("id1", "resource-1", nil, nil, "", "", nil, "not-a-time", "not-a-time", "confirmed", nil, "", "not-a-time", "not-a-time", 1, nil)
 
 	// SELECTクエリの期待値を設定
-	mock.ExpectQuery("SELECT id, start_time, end_time, created_at, updated_at FROM reservations").
+	mock.ExpectQuery("SELECT id, resource_id, series_id, user_id, title, description, attendees, start_time, end_time, status, cancelled_at, cancellation_reason, created_at, updated_at, version, ical_uid FROM reservations").
 		WillReturnRows(rows)
 
 	// 実行
@@ -430,7 +813,7 @@ func TestMySQLReservationRepository_FindAll_ScanError(t *testing.T) {
 	}
 }
 
-func TestMySQLReservationRepository_FindByID(t *testing.T) {
+func TestMySQLReservationRepository_FindByResourceID(t *testing.T) {
 	// SQLMockのセットアップ
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -438,45 +821,29 @@ func TestMySQLReservationRepository_FindByID(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定（NewMySQLReservationRepositoryでの呼び出し）
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
-
 	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
-	if err != nil {
-		t.Fatalf("Failed to create repository: %v", err)
-	}
+	repo := NewMySQLReservationRepository(db)
 
 	// テストデータ
 	now := time.Now()
 	id := uuid.New().String()
-	startTime := now
-	endTime := now.Add(1 * time.Hour)
-	createdAt := now
-	updatedAt := now
-
-	// SELECTクエリの結果を設定
-	rows := sqlmock.NewRows([]string{"id", "start_time", "end_time", "created_at", "updated_at"}).
-		AddRow(id, startTime, endTime, createdAt, updatedAt)
+	rows := sqlmock.NewRows(reservationColumnNames).
+		AddRow(id, "room-a", nil, nil, "", "", nil, now, now.Add(1*time.Hour), "confirmed", nil, "", now, now, 1, nil)
 
 	// SELECTクエリの期待値を設定
-	mock.ExpectQuery("SELECT id, start_time, end_time, created_at, updated_at FROM reservations WHERE id = ?").
-		WithArgs(id).
+	mock.ExpectQuery("SELECT (.+) FROM reservations WHERE resource_id = \\? ORDER BY start_time").
+		WithArgs("room-a").
 		WillReturnRows(rows)
 
 	// 実行
-	reservation, err := repo.FindByID(id)
+	reservations, err := repo.FindByResourceID("room-a")
 
 	// 検証
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if reservation == nil {
-		t.Error("Expected reservation, got nil")
-		return
-	}
-	if reservation.ID != id {
-		t.Errorf("Expected reservation ID %s, got %s", id, reservation.ID)
+	if len(reservations) != 1 || reservations[0].ResourceID != "room-a" {
+		t.Errorf("Expected 1 reservation of room-a, got %v", reservations)
 	}
 
 	// モックの期待通りに実行されたか確認
@@ -485,28 +852,325 @@ func TestMySQLReservationRepository_FindByID(t *testing.T) {
 	}
 }
 
-func TestMySQLReservationRepository_FindByID_NotFound(t *testing.T) {
-	// SQLMockのセットアップ
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("Failed to create mock: %v", err)
+func TestMySQLReservationRepository_FindInRange(t *testing.T) {
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		resourceID string
+		query      string
+		args       []driver.Value
+	}{
+		{
+			name:  "全リソース",
+			query: "SELECT (.+) FROM reservations WHERE start_time < \\? AND end_time > \\? ORDER BY start_time",
+			args:  []driver.Value{to, from},
+		},
+		{
+			name:       "リソースで絞り込み",
+			resourceID: "room-a",
+			query:      "SELECT (.+) FROM reservations WHERE resource_id = \\? AND start_time < \\? AND end_time > \\? ORDER BY start_time",
+			args:       []driver.Value{"room-a", to, from},
+		},
 	}
-	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定（NewMySQLReservationRepositoryでの呼び出し）
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// SQLMockのセットアップ
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock: %v", err)
+			}
+			defer db.Close()
 
-	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
-	if err != nil {
-		t.Fatalf("Failed to create repository: %v", err)
-	}
+			// レポジトリの作成
+			repo := NewMySQLReservationRepository(db)
 
-	// テストデータ
-	id := uuid.New().String()
+			// SELECTクエリの期待値を設定
+			rows := sqlmock.NewRows(reservationColumnNames).
+				AddRow(uuid.New().String(), "room-a", nil, nil, "", "", nil, from.Add(time.Hour), from.Add(2*time.Hour), "confirmed", nil, "", from, from, 1, nil)
+			mock.ExpectQuery(tt.query).WithArgs(tt.args...).WillReturnRows(rows)
 
-	// SELECTクエリで行が見つからないことを設定
-	mock.ExpectQuery("SELECT id, start_time, end_time, created_at, updated_at FROM reservations WHERE id = ?").
+			// 実行
+			reservations, err := repo.FindInRange(tt.resourceID, from, to)
+
+			// 検証
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if len(reservations) != 1 {
+				t.Errorf("Expected 1 reservation, got %d", len(reservations))
+			}
+
+			// モックの期待通りに実行されたか確認
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMySQLReservationRepository_List(t *testing.T) {
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+
+	tests := []struct {
+		name  string
+		query ReservationQuery
+		sql   string
+		args  []driver.Value
+	}{
+		{
+			name:  "条件なし",
+			query: ReservationQuery{},
+			sql:   "SELECT " + reservationColumns + " FROM reservations WHERE status <> ? ORDER BY start_time ASC, id ASC",
+			args:  []driver.Value{"cancelled"},
+		},
+		{
+			name: "すべての絞り込み",
+			query: ReservationQuery{
+				ResourceID: "room-a", From: from, To: to, CreatedAfter: from.AddDate(0, -1, 0),
+				MinDuration: 30 * time.Minute, MaxDuration: 2 * time.Hour,
+				Statuses: []model.ReservationStatus{model.StatusConfirmed, model.StatusPending}, Limit: 11,
+			},
+			sql: "SELECT " + reservationColumns + " FROM reservations WHERE resource_id = ? AND start_time < ? AND end_time > ? AND created_at > ?" +
+				" AND TIMESTAMPDIFF(SECOND, start_time, end_time) >= ? AND TIMESTAMPDIFF(SECOND, start_time, end_time) <= ? AND status IN (?, ?)" +
+				" ORDER BY start_time ASC, id ASC LIMIT ?",
+			args: []driver.Value{"room-a", to, from, from.AddDate(0, -1, 0), int64(1800), int64(7200), "confirmed", "pending", 11},
+		},
+		{
+			name:  "作成日時の降順でカーソルの後から",
+			query: ReservationQuery{SortBy: SortByCreatedAt, Descending: true, After: &ReservationCursor{Value: from, ID: "r-1"}, Limit: 3},
+			sql:   "SELECT " + reservationColumns + " FROM reservations WHERE status <> ? AND (created_at < ? OR (created_at = ? AND id < ?)) ORDER BY created_at DESC, id DESC LIMIT ?",
+			args:  []driver.Value{"cancelled", from, from, "r-1", 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// SQLMockのセットアップ
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock: %v", err)
+			}
+			defer db.Close()
+
+			// レポジトリの作成
+			repo := NewMySQLReservationRepository(db)
+
+			// SELECTクエリの期待値を設定
+			rows := sqlmock.NewRows(reservationColumnNames).
+				AddRow(uuid.New().String(), "room-a", nil, nil, "", "", nil, from.Add(time.Hour), from.Add(2*time.Hour), "confirmed", nil, "", from, from, 1, nil)
+			mock.ExpectQuery(regexp.QuoteMeta(tt.sql)).WithArgs(tt.args...).WillReturnRows(rows)
+
+			// 実行
+			reservations, err := repo.List(tt.query)
+
+			// 検証
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if len(reservations) != 1 {
+				t.Errorf("Expected 1 reservation, got %d", len(reservations))
+			}
+
+			// モックの期待通りに実行されたか確認
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMySQLReservationRepository_Each(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLReservationRepository(db)
+
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	query := ReservationQuery{ResourceID: "room-a"}
+	statement, _ := query.sql()
+	rows := sqlmock.NewRows(reservationColumnNames).
+		AddRow("r-1", "room-a", nil, nil, "", "", nil, from, from.Add(time.Hour), "confirmed", nil, "", from, from, 1, nil).
+		AddRow("r-2", "room-a", nil, nil, "", "", nil, from.Add(time.Hour), from.Add(2*time.Hour), "confirmed", nil, "", from, from, 1, nil).
+		AddRow("r-3", "room-a", nil, nil, "", "", nil, from.Add(2*time.Hour), from.Add(3*time.Hour), "confirmed", nil, "", from, from, 1, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(statement)).WithArgs("room-a", "cancelled").WillReturnRows(rows)
+
+	// 実行: 2件目で止める
+	stop := errors.New("stop")
+	var ids []string
+	err = repo.Each(query, func(reservation *model.Reservation) error {
+		ids = append(ids, reservation.ID)
+		if len(ids) == 2 {
+			return stop
+		}
+		return nil
+	})
+
+	// 検証
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected the error from fn, got %v", err)
+	}
+	if !slices.Equal(ids, []string{"r-1", "r-2"}) {
+		t.Errorf("Expected r-1 and r-2, got %v", ids)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLReservationRepository_FindByID(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLReservationRepository(db)
+
+	// テストデータ
+	now := time.Now()
+	id := uuid.New().String()
+	startTime := now
+	endTime := now.Add(1 * time.Hour)
+	createdAt := now
+	updatedAt := now
+
+	// SELECTクエリの結果を設定
+	rows := sqlmock.NewRows(reservationColumnNames).
+		AddRow(id, "resource-1", nil, nil, "", "", nil, startTime, endTime, "confirmed", nil, "", createdAt, updatedAt, 1, nil)
+
+	// SELECTクエリの期待値を設定
+	mock.ExpectQuery("SELECT id, resource_id, series_id, user_id, title, description, attendees, start_time, end_time, status, cancelled_at, cancellation_reason, created_at, updated_at, version, ical_uid FROM reservations WHERE id = ?").
+		WithArgs(id).
+		WillReturnRows(rows)
+
+	// 実行
+	reservation, err := repo.FindByID(id)
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if reservation == nil {
+		t.Error("Expected reservation, got nil")
+		return
+	}
+	if reservation.ID != id {
+		t.Errorf("Expected reservation ID %s, got %s", id, reservation.ID)
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLReservationRepository_FindByID_Cancelled(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMySQLReservationRepository(db)
+
+	// 取り消された予約の行
+	now := time.Now()
+	cancelledAt := now.Add(-time.Minute)
+	rows := sqlmock.NewRows(reservationColumnNames).
+		AddRow("id-1", "resource-1", "series-1", "user-1", "定例会議", "週次の進捗確認", `["a@example.com","b@example.com"]`, now, now.Add(time.Hour), "cancelled", cancelledAt, "体調不良", now, now, 1, nil)
+	mock.ExpectQuery("SELECT (.+) FROM reservations WHERE id = \\?").
+		WithArgs("id-1").
+		WillReturnRows(rows)
+
+	// 実行
+	reservation, err := repo.FindByID("id-1")
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reservation.Status != model.StatusCancelled || reservation.SeriesID != "series-1" || reservation.UserID != "user-1" {
+		t.Errorf("Unexpected reservation: %+v", reservation)
+	}
+	if reservation.CancelledAt == nil || !reservation.CancelledAt.Equal(cancelledAt) || reservation.CancellationReason != "体調不良" {
+		t.Errorf("Expected cancellation to be scanned, got %v %q", reservation.CancelledAt, reservation.CancellationReason)
+	}
+	if reservation.Title != "定例会議" || reservation.Description != "週次の進捗確認" || !slices.Equal(reservation.Attendees, []string{"a@example.com", "b@example.com"}) {
+		t.Errorf("Expected details to be scanned, got %q %q %v", reservation.Title, reservation.Description, reservation.Attendees)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLReservationRepository_FindByICalUID(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLReservationRepository(db)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM reservations WHERE ical_uid = \\? LIMIT 1").
+		WithArgs("event-1@example.com").
+		WillReturnRows(sqlmock.NewRows(reservationColumnNames).
+			AddRow("id-1", "resource-1", nil, nil, "", "", nil, now, now.Add(time.Hour), "confirmed", nil, "", now, now, 1, "event-1@example.com"))
+	mock.ExpectQuery("SELECT (.+) FROM reservations WHERE ical_uid = \\? LIMIT 1").
+		WithArgs("missing@example.com").
+		WillReturnRows(sqlmock.NewRows(reservationColumnNames))
+
+	// 実行
+	found, err := repo.FindByICalUID("event-1@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	missing, err := repo.FindByICalUID("missing@example.com")
+
+	// 検証
+	if found == nil || found.ID != "id-1" || found.ICalUID != "event-1@example.com" {
+		t.Errorf("Expected the imported reservation, got %+v", found)
+	}
+	if err != nil || missing != nil {
+		t.Errorf("Expected nil for unknown UID, got %v, %v", missing, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLReservationRepository_FindByID_NotFound(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLReservationRepository(db)
+
+	// テストデータ
+	id := uuid.New().String()
+
+	// SELECTクエリで行が見つからないことを設定
+	mock.ExpectQuery("SELECT id, resource_id, series_id, user_id, title, description, attendees, start_time, end_time, status, cancelled_at, cancellation_reason, created_at, updated_at, version, ical_uid FROM reservations WHERE id = ?").
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
@@ -535,20 +1199,14 @@ func TestMySQLReservationRepository_FindByID_Error(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定（NewMySQLReservationRepositoryでの呼び出し）
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
-
 	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
-	if err != nil {
-		t.Fatalf("Failed to create repository: %v", err)
-	}
+	repo := NewMySQLReservationRepository(db)
 
 	// テストデータ
 	id := uuid.New().String()
 
 	// SELECTクエリでエラーを返すように設定
-	mock.ExpectQuery("SELECT id, start_time, end_time, created_at, updated_at FROM reservations WHERE id = ?").
+	mock.ExpectQuery("SELECT id, resource_id, series_id, user_id, title, description, attendees, start_time, end_time, status, cancelled_at, cancellation_reason, created_at, updated_at, version, ical_uid FROM reservations WHERE id = ?").
 		WithArgs(id).
 		WillReturnError(errors.New("database error"))
 
@@ -577,14 +1235,8 @@ func TestMySQLReservationRepository_Delete(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定（NewMySQLReservationRepositoryでの呼び出し）
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
-
 	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
-	if err != nil {
-		t.Fatalf("Failed to create repository: %v", err)
-	}
+	repo := NewMySQLReservationRepository(db)
 
 	// テストデータ
 	id := uuid.New().String()
@@ -616,14 +1268,8 @@ func TestMySQLReservationRepository_Delete_Error(t *testing.T) {
 	}
 	defer db.Close()
 
-	// テーブル作成クエリの期待値を設定（NewMySQLReservationRepositoryでの呼び出し）
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS reservations").WillReturnResult(sqlmock.NewResult(0, 0))
-
 	// レポジトリの作成
-	repo, err := NewMySQLReservationRepository(db)
-	if err != nil {
-		t.Fatalf("Failed to create repository: %v", err)
-	}
+	repo := NewMySQLReservationRepository(db)
 
 	// テストデータ
 	id := uuid.New().String()
@@ -645,4 +1291,263 @@ func TestMySQLReservationRepository_Delete_Error(t *testing.T) {
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+func TestMySQLReservationRepository_CreateIfNoOverlap(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLReservationRepository(db)
+
+	// テスト対象の予約データ
+	now := time.Now()
+	reservation := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
+
+	// ロック取得、重複チェック、INSERT、コミット、ロック解放の順に実行されることを期待
+	mock.ExpectQuery("SELECT GET_LOCK").WithArgs(reservationLockName("resource-1"), namedLockTimeout).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM reservations WHERE resource_id = \\? AND start_time < \\? AND end_time > \\? AND id <> \\? AND status <> \\?").
+		WithArgs(reservation.ResourceID, reservation.EndTime, reservation.StartTime, reservation.ID, model.StatusCancelled).
+		WillReturnRows(sqlmock.NewRows(reservationColumnNames))
+	mock.ExpectExec("INSERT INTO reservations").WithArgs(
+		reservation.ID,
+		reservation.ResourceID,
+		nil,
+		nil,
+		"",
+		"",
+		"[]",
+		reservation.StartTime,
+		reservation.EndTime,
+		reservation.Status,
+		nil,
+		"",
+		reservation.CreatedAt,
+		reservation.UpdatedAt,
+		reservation.Version,
+		nil,
+	).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT RELEASE_LOCK").WithArgs(reservationLockName("resource-1")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// 実行
+	conflicts, err := repo.CreateIfNoOverlap(reservation)
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %d", len(conflicts))
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLReservationRepository_CreateIfNoOverlap_Conflict(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLReservationRepository(db)
+
+	// テストデータ
+	now := time.Now()
+	reservation := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
+	existingID := uuid.New().String()
+
+	// 重複する予約が見つかった場合はINSERTせずにコミットすることを期待
+	mock.ExpectQuery("SELECT GET_LOCK").WithArgs(reservationLockName("resource-1"), namedLockTimeout).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM reservations WHERE resource_id = \\? AND start_time < \\? AND end_time > \\? AND id <> \\? AND status <> \\?").
+		WithArgs(reservation.ResourceID, reservation.EndTime, reservation.StartTime, reservation.ID, model.StatusCancelled).
+		WillReturnRows(sqlmock.NewRows(reservationColumnNames).
+			AddRow(existingID, "resource-1", nil, nil, "", "", nil, now.Add(-30*time.Minute), now.Add(30*time.Minute), "confirmed", nil, "", now, now, 1, nil))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT RELEASE_LOCK").WithArgs(reservationLockName("resource-1")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// 実行
+	conflicts, err := repo.CreateIfNoOverlap(reservation)
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].ID != existingID {
+		t.Errorf("Expected conflict with %s, got %v", existingID, conflicts)
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLReservationRepository_CreateIfNoOverlap_DuplicateICalUID(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLReservationRepository(db)
+
+	// テストデータ
+	now := time.Now()
+	reservation := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
+	reservation.ICalUID = "event-1@example.com"
+
+	// 同時に取り込まれた同じ UID の予約があり、一意の索引で INSERT が失敗することを期待
+	mock.ExpectQuery("SELECT GET_LOCK").WithArgs(reservationLockName("resource-1"), namedLockTimeout).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM reservations WHERE resource_id = \\? AND start_time < \\? AND end_time > \\? AND id <> \\? AND status <> \\?").
+		WillReturnRows(sqlmock.NewRows(reservationColumnNames))
+	mock.ExpectExec("INSERT INTO reservations").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'event-1@example.com' for key 'idx_reservations_ical_uid'"})
+	mock.ExpectRollback()
+	mock.ExpectExec("SELECT RELEASE_LOCK").WithArgs(reservationLockName("resource-1")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// 実行
+	_, err = repo.CreateIfNoOverlap(reservation)
+
+	// 検証
+	if !errors.Is(err, ErrDuplicateICalUID) {
+		t.Errorf("Expected ErrDuplicateICalUID, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLReservationRepository_CreateIfNoOverlap_LockTimeout(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLReservationRepository(db)
+
+	// GET_LOCKがタイムアウト（0）を返すように設定
+	mock.ExpectQuery("SELECT GET_LOCK").WithArgs(reservationLockName("resource-1"), namedLockTimeout).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+
+	// 実行
+	now := time.Now()
+	_, err = repo.CreateIfNoOverlap(model.NewReservation("resource-1", now, now.Add(1*time.Hour)))
+
+	// 検証
+	if err == nil {
+		t.Error("Expected error, got nil")
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLReservationRepository_UpdateIfNoOverlap(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLReservationRepository(db)
+
+	// テスト対象の予約データ
+	now := time.Now()
+	reservation := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
+
+	// ロックを取得した上で、自分自身を除いた重複チェックとUPDATEを行うことを期待
+	mock.ExpectQuery("SELECT GET_LOCK").WithArgs(reservationLockName("resource-1"), namedLockTimeout).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM reservations WHERE resource_id = \\? AND start_time < \\? AND end_time > \\? AND id <> \\? AND status <> \\?").
+		WithArgs(reservation.ResourceID, reservation.EndTime, reservation.StartTime, reservation.ID, model.StatusCancelled).
+		WillReturnRows(sqlmock.NewRows(reservationColumnNames))
+	mock.ExpectExec("UPDATE reservations SET resource_id = \\?, title = \\?, description = \\?, attendees = \\?, start_time = \\?, end_time = \\?, status = \\?, cancelled_at = \\?, cancellation_reason = \\?, updated_at = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?").
+		WithArgs(reservation.ResourceID, "", "", "[]", reservation.StartTime, reservation.EndTime, reservation.Status, nil, "", reservation.UpdatedAt, reservation.ID, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT RELEASE_LOCK").WithArgs(reservationLockName("resource-1")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// 実行
+	conflicts, err := repo.UpdateIfNoOverlap(reservation)
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %d", len(conflicts))
+	}
+	if reservation.Version != 2 {
+		t.Errorf("Expected version to be incremented to 2, got %d", reservation.Version)
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLReservationRepository_Update_VersionConflict(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLReservationRepository(db)
+
+	// テスト対象の予約データ - 読み込んだ後に他の更新で版が進んでいる
+	now := time.Now()
+	reservation := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
+	reservation.Version = 3
+
+	// 版を条件にした UPDATE に一致する行がないことを期待
+	mock.ExpectExec("UPDATE reservations SET (.+) WHERE id = \\? AND version = \\?").
+		WithArgs(reservation.ResourceID, "", "", "[]", reservation.StartTime, reservation.EndTime, reservation.Status, nil, "", reservation.UpdatedAt, reservation.ID, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// 実行
+	err = repo.Update(reservation)
+
+	// 検証
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+	if reservation.Version != 3 {
+		t.Errorf("Expected version to stay 3, got %d", reservation.Version)
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
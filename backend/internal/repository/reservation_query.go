@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+// ReservationSortKey は予約一覧を並べ替える項目
+type ReservationSortKey string
+
+const (
+	// SortByStartTime は開始時刻で並べ替える
+	SortByStartTime ReservationSortKey = "startTime"
+	// SortByCreatedAt は作成日時で並べ替える
+	SortByCreatedAt ReservationSortKey = "createdAt"
+)
+
+// Valid は定義済みの項目かを返す
+func (k ReservationSortKey) Valid() bool {
+	return k == SortByStartTime || k == SortByCreatedAt
+}
+
+// ReservationCursor は並べ替えた予約一覧での位置。Value は並べ替えの項目の値で、同じ値の予約は ID の順に並べる。
+type ReservationCursor struct {
+	Value time.Time
+	ID    string
+}
+
+// ReservationQuery は予約一覧の検索条件。ゼロ値のフィールドでは絞り込まない。
+type ReservationQuery struct {
+	// ResourceID を指定するとそのリソースの予約だけを返す
+	ResourceID string
+	// From と To を指定すると [From, To) と重なる予約だけを返す
+	From time.Time
+	To   time.Time
+	// CreatedAfter を指定するとその日時より後に作成された予約だけを返す
+	CreatedAfter time.Time
+	// MinDuration と MaxDuration を指定すると長さがその範囲に含まれる予約だけを返す
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	// Statuses を指定するとその状態の予約だけを返す。空の場合は取り消された予約を除いて返す。
+	Statuses []model.ReservationStatus
+	// SortBy は並べ替えの項目。空の場合は SortByStartTime。
+	SortBy     ReservationSortKey
+	Descending bool
+	// After を指定すると、並べ替えた一覧でその位置より後の予約だけを返す
+	After *ReservationCursor
+	// Limit を指定すると先頭から最大 Limit 件を返す
+	Limit int
+}
+
+// sortKey は並べ替えの項目を返す
+func (q ReservationQuery) sortKey() ReservationSortKey {
+	if q.SortBy == "" {
+		return SortByStartTime
+	}
+	return q.SortBy
+}
+
+// SortValue は reservation の並べ替えの項目の値を返す
+func (q ReservationQuery) SortValue(reservation *model.Reservation) time.Time {
+	if q.sortKey() == SortByCreatedAt {
+		return reservation.CreatedAt
+	}
+	return reservation.StartTime
+}
+
+// compare は並べ替えた一覧で (value, id) が cursor より前なら負、後なら正の値を返す
+func (q ReservationQuery) compare(value time.Time, id string, cursor ReservationCursor) int {
+	c := value.Compare(cursor.Value)
+	if c == 0 {
+		c = cmp.Compare(id, cursor.ID)
+	}
+	if q.Descending {
+		return -c
+	}
+	return c
+}
+
+// matches は reservation が並べ替えと件数以外の条件に合うかを返す
+func (q ReservationQuery) matches(reservation *model.Reservation) bool {
+	if q.ResourceID != "" && reservation.ResourceID != q.ResourceID {
+		return false
+	}
+	if !q.From.IsZero() && !reservation.Overlaps(q.From, q.To) {
+		return false
+	}
+	if !q.CreatedAfter.IsZero() && !reservation.CreatedAt.After(q.CreatedAfter) {
+		return false
+	}
+	duration := reservation.EndTime.Sub(reservation.StartTime)
+	if q.MinDuration > 0 && duration < q.MinDuration {
+		return false
+	}
+	if q.MaxDuration > 0 && duration > q.MaxDuration {
+		return false
+	}
+	if len(q.Statuses) == 0 {
+		if !reservation.Active() {
+			return false
+		}
+	} else if !slices.Contains(q.Statuses, reservation.Status) {
+		return false
+	}
+	return q.After == nil || q.compare(q.SortValue(reservation), reservation.ID, *q.After) > 0
+}
+
+// apply は reservations のうち条件に合う予約を並べ替えて最大 Limit 件返す
+func (q ReservationQuery) apply(reservations []*model.Reservation) []*model.Reservation {
+	matched := make([]*model.Reservation, 0)
+	for _, reservation := range reservations {
+		if q.matches(reservation) {
+			matched = append(matched, reservation)
+		}
+	}
+	slices.SortFunc(matched, func(a, b *model.Reservation) int {
+		return q.compare(q.SortValue(a), a.ID, ReservationCursor{Value: q.SortValue(b), ID: b.ID})
+	})
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched
+}
+
+// sql は条件に合う予約を選択する MySQL のクエリと引数を返す
+func (q ReservationQuery) sql() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if q.ResourceID != "" {
+		conditions = append(conditions, "resource_id = ?")
+		args = append(args, q.ResourceID)
+	}
+	if !q.From.IsZero() {
+		conditions = append(conditions, "start_time < ? AND end_time > ?")
+		args = append(args, q.To, q.From)
+	}
+	if !q.CreatedAfter.IsZero() {
+		conditions = append(conditions, "created_at > ?")
+		args = append(args, q.CreatedAfter)
+	}
+	if q.MinDuration > 0 {
+		conditions = append(conditions, "TIMESTAMPDIFF(SECOND, start_time, end_time) >= ?")
+		args = append(args, int64(q.MinDuration/time.Second))
+	}
+	if q.MaxDuration > 0 {
+		conditions = append(conditions, "TIMESTAMPDIFF(SECOND, start_time, end_time) <= ?")
+		args = append(args, int64(q.MaxDuration/time.Second))
+	}
+	if len(q.Statuses) == 0 {
+		conditions = append(conditions, "status <> ?")
+		args = append(args, model.StatusCancelled)
+	} else {
+		conditions = append(conditions, "status IN (?"+strings.Repeat(", ?", len(q.Statuses)-1)+")")
+		for _, status := range q.Statuses {
+			args = append(args, status)
+		}
+	}
+
+	column, direction, operator := "start_time", "ASC", ">"
+	if q.sortKey() == SortByCreatedAt {
+		column = "created_at"
+	}
+	if q.Descending {
+		direction, operator = "DESC", "<"
+	}
+	if q.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, operator))
+		args = append(args, q.After.Value, q.After.Value, q.After.ID)
+	}
+
+	query := "SELECT " + reservationColumns + " FROM reservations"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %[1]s %[2]s, id %[2]s", column, direction)
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+	return query, args
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+type ResourceRepository interface {
+	Create(resource *model.Resource) error
+	FindAll() ([]*model.Resource, error)
+	FindByID(id string) (*model.Resource, error)
+	Update(resource *model.Resource) error
+	Delete(id string) error
+}
+
+// InMemoryResourceRepository - In-memory implementation for testing
+type InMemoryResourceRepository struct {
+	resources map[string]*model.Resource
+	mutex     sync.RWMutex
+}
+
+func NewInMemoryResourceRepository() *InMemoryResourceRepository {
+	return &InMemoryResourceRepository{
+		resources: make(map[string]*model.Resource),
+	}
+}
+
+func (r *InMemoryResourceRepository) Create(resource *model.Resource) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.resources[resource.ID] = resource
+	return nil
+}
+
+func (r *InMemoryResourceRepository) FindAll() ([]*model.Resource, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	resources := make([]*model.Resource, 0, len(r.resources))
+	for _, resource := range r.resources {
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].CreatedAt.Before(resources[j].CreatedAt)
+	})
+
+	return resources, nil
+}
+
+func (r *InMemoryResourceRepository) FindByID(id string) (*model.Resource, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	resource, ok := r.resources[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return resource, nil
+}
+
+func (r *InMemoryResourceRepository) Update(resource *model.Resource) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.resources[resource.ID]; !ok {
+		return fmt.Errorf("resource %s not found", resource.ID)
+	}
+	r.resources[resource.ID] = resource
+	return nil
+}
+
+func (r *InMemoryResourceRepository) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.resources, id)
+	return nil
+}
+
+// MySQLResourceRepository - MySQL implementation
+type MySQLResourceRepository struct {
+	db *sql.DB
+}
+
+const resourceColumns = "id, name, description, capacity, active, created_at, updated_at"
+
+// NewMySQLResourceRepository creates a new MySQL repository
+func NewMySQLResourceRepository(db *sql.DB) *MySQLResourceRepository {
+	return &MySQLResourceRepository{
+		db: db,
+	}
+}
+
+// scanResource は resourceColumns の順に並んだ1行をリソースとして読み込む
+func scanResource(row rowScanner) (*model.Resource, error) {
+	var resource model.Resource
+	if err := row.Scan(
+		&resource.ID,
+		&resource.Name,
+		&resource.Description,
+		&resource.Capacity,
+		&resource.Active,
+		&resource.CreatedAt,
+		&resource.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// Create inserts a new resource into the database
+func (r *MySQLResourceRepository) Create(resource *model.Resource) error {
+	_, err := r.db.Exec(
+		"INSERT INTO resources ("+resourceColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		resource.ID,
+		resource.Name,
+		resource.Description,
+		resource.Capacity,
+		resource.Active,
+		resource.CreatedAt,
+		resource.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create resource: %w", err)
+	}
+	return nil
+}
+
+// FindAll returns all resources ordered by creation time
+func (r *MySQLResourceRepository) FindAll() ([]*model.Resource, error) {
+	rows, err := r.db.Query("SELECT " + resourceColumns + " FROM resources ORDER BY created_at")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find resources: %w", err)
+	}
+	defer rows.Close()
+
+	var resources []*model.Resource
+	for rows.Next() {
+		resource, err := scanResource(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan resource: %w", err)
+		}
+		resources = append(resources, resource)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return resources, nil
+}
+
+// FindByID returns a resource by ID
+func (r *MySQLResourceRepository) FindByID(id string) (*model.Resource, error) {
+	resource, err := scanResource(r.db.QueryRow(
+		"SELECT "+resourceColumns+" FROM resources WHERE id = ?",
+		id,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find resource: %w", err)
+	}
+
+	return resource, nil
+}
+
+// Update overwrites the mutable fields of a resource
+func (r *MySQLResourceRepository) Update(resource *model.Resource) error {
+	_, err := r.db.Exec(
+		"UPDATE resources SET name = ?, description = ?, capacity = ?, active = ?, updated_at = ? WHERE id = ?",
+		resource.Name,
+		resource.Description,
+		resource.Capacity,
+		resource.Active,
+		resource.UpdatedAt,
+		resource.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update resource: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a resource by ID
+func (r *MySQLResourceRepository) Delete(id string) error {
+	_, err := r.db.Exec("DELETE FROM resources WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete resource: %w", err)
+	}
+	return nil
+}
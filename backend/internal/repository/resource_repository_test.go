@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+func TestInMemoryResourceRepository_CRUD(t *testing.T) {
+	// 準備
+	repo := NewInMemoryResourceRepository()
+	roomA := model.NewResource("会議室A", "", 6, true)
+	roomB := model.NewResource("会議室B", "", 10, true)
+	roomB.CreatedAt = roomA.CreatedAt.Add(time.Second)
+
+	// 作成
+	for _, r := range []*model.Resource{roomB, roomA} {
+		if err := repo.Create(r); err != nil {
+			t.Fatalf("Failed to create resource: %v", err)
+		}
+	}
+
+	// 全件取得（作成日時順）
+	resources, err := repo.FindAll()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resources) != 2 || resources[0].ID != roomA.ID {
+		t.Errorf("Expected resources ordered by creation time, got %v", resources)
+	}
+
+	// 更新
+	updated := *roomA
+	updated.Active = false
+	if err := repo.Update(&updated); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	found, _ := repo.FindByID(roomA.ID)
+	if found == nil || found.Active {
+		t.Error("Expected resource to be deactivated")
+	}
+
+	// 存在しないリソースの更新はエラー
+	if err := repo.Update(model.NewResource("存在しない", "", 0, true)); err == nil {
+		t.Error("Expected error when updating non-existent resource, got nil")
+	}
+
+	// 削除
+	if err := repo.Delete(roomA.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	found, _ = repo.FindByID(roomA.ID)
+	if found != nil {
+		t.Error("Expected resource to be deleted, but it still exists")
+	}
+}
+
+func TestMySQLResourceRepository_Create(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLResourceRepository(db)
+
+	// INSERTクエリの期待値を設定
+	resource := model.NewResource("会議室A", "プロジェクター付き", 8, true)
+	mock.ExpectExec("INSERT INTO resources").WithArgs(
+		resource.ID,
+		resource.Name,
+		resource.Description,
+		resource.Capacity,
+		resource.Active,
+		resource.CreatedAt,
+		resource.UpdatedAt,
+	).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// 実行
+	err = repo.Create(resource)
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLResourceRepository_FindAll(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLResourceRepository(db)
+
+	// SELECTクエリの結果を設定
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "capacity", "active", "created_at", "updated_at"}).
+		AddRow("room-a", "会議室A", "", 6, true, now, now).
+		AddRow("room-b", "会議室B", "", 10, false, now, now)
+	mock.ExpectQuery("SELECT (.+) FROM resources ORDER BY created_at").WillReturnRows(rows)
+
+	// 実行
+	resources, err := repo.FindAll()
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(resources))
+	}
+	if resources[1].Active {
+		t.Error("Expected second resource to be inactive")
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLResourceRepository_FindByID_NotFound(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLResourceRepository(db)
+
+	// 行が見つからない場合
+	mock.ExpectQuery("SELECT (.+) FROM resources WHERE id = \\?").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "capacity", "active", "created_at", "updated_at"}))
+
+	// 実行
+	resource, err := repo.FindByID("missing")
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if resource != nil {
+		t.Errorf("Expected nil resource, got %v", resource)
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLResourceRepository_Update(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLResourceRepository(db)
+
+	// UPDATEクエリの期待値を設定
+	resource := model.NewResource("会議室A", "", 6, false)
+	mock.ExpectExec("UPDATE resources SET").WithArgs(
+		resource.Name,
+		resource.Description,
+		resource.Capacity,
+		resource.Active,
+		resource.UpdatedAt,
+		resource.ID,
+	).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// 実行
+	err = repo.Update(resource)
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLResourceRepository_Delete_Error(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLResourceRepository(db)
+
+	// DELETEクエリでエラーを返すように設定
+	mock.ExpectExec("DELETE FROM resources WHERE id = \\?").
+		WithArgs("room-a").
+		WillReturnError(errors.New("database error"))
+
+	// 実行
+	err = repo.Delete("room-a")
+
+	// 検証
+	if err == nil {
+		t.Error("Expected error, got nil")
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
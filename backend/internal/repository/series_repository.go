@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+type ReservationSeriesRepository interface {
+	Create(series *model.ReservationSeries) error
+	FindByID(id string) (*model.ReservationSeries, error)
+	// SaveException は例外を保存する。同じ回（シリーズと本来の開始時刻）の例外があれば置き換える。
+	SaveException(exception *model.SeriesException) error
+	// FindExceptions はシリーズの例外を本来の開始時刻の昇順で返す
+	FindExceptions(seriesID string) ([]*model.SeriesException, error)
+}
+
+// InMemoryReservationSeriesRepository - In-memory implementation for testing
+type InMemoryReservationSeriesRepository struct {
+	series     map[string]*model.ReservationSeries
+	exceptions map[string]map[time.Time]*model.SeriesException
+	mutex      sync.RWMutex
+}
+
+func NewInMemoryReservationSeriesRepository() *InMemoryReservationSeriesRepository {
+	return &InMemoryReservationSeriesRepository{
+		series:     make(map[string]*model.ReservationSeries),
+		exceptions: make(map[string]map[time.Time]*model.SeriesException),
+	}
+}
+
+func (r *InMemoryReservationSeriesRepository) Create(series *model.ReservationSeries) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.series[series.ID] = series
+	return nil
+}
+
+func (r *InMemoryReservationSeriesRepository) FindByID(id string) (*model.ReservationSeries, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	series, ok := r.series[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return series, nil
+}
+
+func (r *InMemoryReservationSeriesRepository) SaveException(exception *model.SeriesException) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	byStart, ok := r.exceptions[exception.SeriesID]
+	if !ok {
+		byStart = make(map[time.Time]*model.SeriesException)
+		r.exceptions[exception.SeriesID] = byStart
+	}
+	key := exception.OriginalStartTime.UTC()
+	if existing, ok := byStart[key]; ok {
+		saved := *exception
+		saved.CreatedAt = existing.CreatedAt
+		exception = &saved
+	}
+	byStart[key] = exception
+	return nil
+}
+
+func (r *InMemoryReservationSeriesRepository) FindExceptions(seriesID string) ([]*model.SeriesException, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	exceptions := make([]*model.SeriesException, 0, len(r.exceptions[seriesID]))
+	for _, exception := range r.exceptions[seriesID] {
+		exceptions = append(exceptions, exception)
+	}
+	sort.Slice(exceptions, func(i, j int) bool {
+		return exceptions[i].OriginalStartTime.Before(exceptions[j].OriginalStartTime)
+	})
+
+	return exceptions, nil
+}
+
+// MySQLReservationSeriesRepository - MySQL implementation
+type MySQLReservationSeriesRepository struct {
+	db *sql.DB
+}
+
+const (
+	seriesColumns    = "id, resource_id, recurrence, start_time, end_time, created_at, updated_at"
+	exceptionColumns = "series_id, original_start_time, type, reservation_id, created_at, updated_at"
+)
+
+// NewMySQLReservationSeriesRepository creates a new MySQL repository
+func NewMySQLReservationSeriesRepository(db *sql.DB) *MySQLReservationSeriesRepository {
+	return &MySQLReservationSeriesRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new series into the database
+func (r *MySQLReservationSeriesRepository) Create(series *model.ReservationSeries) error {
+	_, err := r.db.Exec(
+		"INSERT INTO reservation_series ("+seriesColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		series.ID,
+		series.ResourceID,
+		series.Recurrence,
+		series.StartTime,
+		series.EndTime,
+		series.CreatedAt,
+		series.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create reservation series: %w", err)
+	}
+	return nil
+}
+
+// FindByID returns a series by ID
+func (r *MySQLReservationSeriesRepository) FindByID(id string) (*model.ReservationSeries, error) {
+	var series model.ReservationSeries
+	err := r.db.QueryRow(
+		"SELECT "+seriesColumns+" FROM reservation_series WHERE id = ?",
+		id,
+	).Scan(
+		&series.ID,
+		&series.ResourceID,
+		&series.Recurrence,
+		&series.StartTime,
+		&series.EndTime,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reservation series: %w", err)
+	}
+
+	return &series, nil
+}
+
+// SaveException inserts an exception or replaces the one recorded for the same occurrence.
+// created_at is kept so the history shows when the occurrence first diverged from the rule.
+func (r *MySQLReservationSeriesRepository) SaveException(exception *model.SeriesException) error {
+	_, err := r.db.Exec(
+		"INSERT INTO reservation_series_exceptions ("+exceptionColumns+") VALUES (?, ?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE type = VALUES(type), reservation_id = VALUES(reservation_id), updated_at = VALUES(updated_at)",
+		exception.SeriesID,
+		exception.OriginalStartTime,
+		exception.Type,
+		exception.ReservationID,
+		exception.CreatedAt,
+		exception.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save reservation series exception: %w", err)
+	}
+	return nil
+}
+
+// FindExceptions returns the exceptions of a series ordered by original start time
+func (r *MySQLReservationSeriesRepository) FindExceptions(seriesID string) ([]*model.SeriesException, error) {
+	rows, err := r.db.Query(
+		"SELECT "+exceptionColumns+" FROM reservation_series_exceptions WHERE series_id = ? ORDER BY original_start_time",
+		seriesID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reservation series exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	exceptions := make([]*model.SeriesException, 0)
+	for rows.Next() {
+		var exception model.SeriesException
+		if err := rows.Scan(
+			&exception.SeriesID,
+			&exception.OriginalStartTime,
+			&exception.Type,
+			&exception.ReservationID,
+			&exception.CreatedAt,
+			&exception.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan reservation series exception: %w", err)
+		}
+		exceptions = append(exceptions, &exception)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return exceptions, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+func TestInMemoryReservationSeriesRepository(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationSeriesRepository()
+	now := time.Now()
+	series := model.NewReservationSeries("room-a", "FREQ=WEEKLY;COUNT=4", now, now.Add(time.Hour))
+
+	// 実行
+	if err := repo.Create(series); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	found, err := repo.FindByID(series.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if found == nil || found.Recurrence != series.Recurrence {
+		t.Errorf("Expected series %v, got %v", series, found)
+	}
+
+	found, err = repo.FindByID("unknown")
+	if err != nil || found != nil {
+		t.Errorf("Expected nil, nil for unknown series, got %v, %v", found, err)
+	}
+}
+
+func TestMySQLReservationSeriesRepository_CreateAndFind(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMySQLReservationSeriesRepository(db)
+
+	now := time.Now()
+	series := model.NewReservationSeries("room-a", "FREQ=WEEKLY;COUNT=4", now, now.Add(time.Hour))
+
+	mock.ExpectExec("INSERT INTO reservation_series").WithArgs(
+		series.ID,
+		series.ResourceID,
+		series.Recurrence,
+		series.StartTime,
+		series.EndTime,
+		series.CreatedAt,
+		series.UpdatedAt,
+	).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	rows := sqlmock.NewRows([]string{"id", "resource_id", "recurrence", "start_time", "end_time", "created_at", "updated_at"}).
+		AddRow(series.ID, series.ResourceID, series.Recurrence, series.StartTime, series.EndTime, series.CreatedAt, series.UpdatedAt)
+	mock.ExpectQuery("SELECT (.+) FROM reservation_series WHERE id = \\?").
+		WithArgs(series.ID).
+		WillReturnRows(rows)
+
+	// 実行
+	if err := repo.Create(series); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	found, err := repo.FindByID(series.ID)
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if found == nil || found.ID != series.ID || found.Recurrence != series.Recurrence {
+		t.Errorf("Expected series %v, got %v", series, found)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestInMemoryReservationSeriesRepository_Exceptions(t *testing.T) {
+	// 準備
+	repo := NewInMemoryReservationSeriesRepository()
+	start := time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+	later := model.NewSeriesException("series-1", start.AddDate(0, 0, 7), model.ExceptionCancelled, "reservation-2")
+	modified := model.NewSeriesException("series-1", start, model.ExceptionModified, "reservation-1")
+
+	// 実行
+	for _, exception := range []*model.SeriesException{later, modified} {
+		if err := repo.SaveException(exception); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	// 同じ回の例外は置き換えられる（タイムゾーンが違っても同じ時刻なら同じ回）
+	cancelled := model.NewSeriesException("series-1", start.In(time.FixedZone("JST", 9*60*60)), model.ExceptionCancelled, "reservation-1")
+	if err := repo.SaveException(cancelled); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	exceptions, err := repo.FindExceptions("series-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(exceptions) != 2 {
+		t.Fatalf("Expected 2 exceptions, got %d", len(exceptions))
+	}
+	if exceptions[0].ReservationID != "reservation-1" || exceptions[0].Type != model.ExceptionCancelled {
+		t.Errorf("Expected first occurrence to be cancelled, got %+v", exceptions[0])
+	}
+	if !exceptions[0].CreatedAt.Equal(modified.CreatedAt) {
+		t.Errorf("Expected CreatedAt of the first exception to be kept")
+	}
+	if exceptions[1].ReservationID != "reservation-2" {
+		t.Errorf("Expected exceptions ordered by original start time, got %+v", exceptions)
+	}
+
+	exceptions, _ = repo.FindExceptions("series-2")
+	if len(exceptions) != 0 {
+		t.Errorf("Expected no exceptions for another series, got %d", len(exceptions))
+	}
+}
+
+func TestMySQLReservationSeriesRepository_Exceptions(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMySQLReservationSeriesRepository(db)
+
+	start := time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+	exception := model.NewSeriesException("series-1", start, model.ExceptionModified, "reservation-1")
+
+	mock.ExpectExec("INSERT INTO reservation_series_exceptions (.+) ON DUPLICATE KEY UPDATE").WithArgs(
+		exception.SeriesID,
+		exception.OriginalStartTime,
+		exception.Type,
+		exception.ReservationID,
+		exception.CreatedAt,
+		exception.UpdatedAt,
+	).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	rows := sqlmock.NewRows([]string{"series_id", "original_start_time", "type", "reservation_id", "created_at", "updated_at"}).
+		AddRow("series-1", start, "modified", "reservation-1", exception.CreatedAt, exception.UpdatedAt)
+	mock.ExpectQuery("SELECT (.+) FROM reservation_series_exceptions WHERE series_id = \\? ORDER BY original_start_time").
+		WithArgs("series-1").
+		WillReturnRows(rows)
+
+	// 実行
+	if err := repo.SaveException(exception); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	exceptions, err := repo.FindExceptions("series-1")
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(exceptions) != 1 || exceptions[0].Type != model.ExceptionModified || !exceptions[0].OriginalStartTime.Equal(start) {
+		t.Errorf("Unexpected exceptions: %+v", exceptions)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+type UserRepository interface {
+	Create(user *model.User) error
+	// CreateFirst はユーザーが1人もいない場合だけ user を作成し、作成したかを返す
+	CreateFirst(user *model.User) (bool, error)
+	FindAll() ([]*model.User, error)
+	FindByID(id string) (*model.User, error)
+	// FindByEmail はメールアドレスが一致するユーザーを返す。存在しない場合は nil を返す。
+	FindByEmail(email string) (*model.User, error)
+}
+
+// InMemoryUserRepository - In-memory implementation for testing
+type InMemoryUserRepository struct {
+	users map[string]*model.User
+	mutex sync.RWMutex
+}
+
+func NewInMemoryUserRepository() *InMemoryUserRepository {
+	return &InMemoryUserRepository{
+		users: make(map[string]*model.User),
+	}
+}
+
+func (r *InMemoryUserRepository) Create(user *model.User) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *InMemoryUserRepository) CreateFirst(user *model.User) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.users) > 0 {
+		return false, nil
+	}
+	r.users[user.ID] = user
+	return true, nil
+}
+
+func (r *InMemoryUserRepository) FindAll() ([]*model.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	users := make([]*model.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].CreatedAt.Before(users[j].CreatedAt)
+	})
+
+	return users, nil
+}
+
+func (r *InMemoryUserRepository) FindByID(id string) (*model.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+func (r *InMemoryUserRepository) FindByEmail(email string) (*model.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// MySQLUserRepository - MySQL implementation
+type MySQLUserRepository struct {
+	db *sql.DB
+}
+
+const (
+	userColumns = "id, display_name, email, role, created_at, updated_at"
+
+	// firstUserLockName は最初のユーザーの作成を直列化する名前付きロックの名前
+	firstUserLockName = "yoyaku.users.first"
+)
+
+// NewMySQLUserRepository creates a new MySQL repository
+func NewMySQLUserRepository(db *sql.DB) *MySQLUserRepository {
+	return &MySQLUserRepository{
+		db: db,
+	}
+}
+
+// scanUser は userColumns の順に並んだ1行をユーザーとして読み込む
+func scanUser(row rowScanner) (*model.User, error) {
+	var user model.User
+	if err := row.Scan(
+		&user.ID,
+		&user.DisplayName,
+		&user.Email,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Create inserts a new user into the database
+func (r *MySQLUserRepository) Create(user *model.User) error {
+	return insertUser(r.db, user)
+}
+
+func insertUser(db execer, user *model.User) error {
+	_, err := db.Exec(
+		"INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		user.ID,
+		user.DisplayName,
+		user.Email,
+		user.Role,
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	return nil
+}
+
+// CreateFirst inserts a user only if the users table is empty.
+// The count and insert run while holding a named lock, so concurrent requests
+// from several server instances cannot both create the first user.
+func (r *MySQLUserRepository) CreateFirst(user *model.User) (bool, error) {
+	created := false
+	err := withNamedLock(r.db, firstUserLockName, func(tx *sql.Tx) error {
+		var count int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil {
+			return fmt.Errorf("failed to count users: %w", err)
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := insertUser(tx, user); err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// FindAll returns all users ordered by creation time
+func (r *MySQLUserRepository) FindAll() ([]*model.User, error) {
+	rows, err := r.db.Query("SELECT " + userColumns + " FROM users ORDER BY created_at")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*model.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return users, nil
+}
+
+// FindByID returns a user by ID
+func (r *MySQLUserRepository) FindByID(id string) (*model.User, error) {
+	return r.findOne("id", id)
+}
+
+// FindByEmail returns a user by email address
+func (r *MySQLUserRepository) FindByEmail(email string) (*model.User, error) {
+	return r.findOne("email", email)
+}
+
+// findOne は column の値が value に一致するユーザーを返す。column は呼び出し側で固定の列名を渡すこと。
+func (r *MySQLUserRepository) findOne(column, value string) (*model.User, error) {
+	user, err := scanUser(r.db.QueryRow(
+		"SELECT "+userColumns+" FROM users WHERE "+column+" = ?",
+		value,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return user, nil
+}
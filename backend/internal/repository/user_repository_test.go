@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+func TestInMemoryUserRepository(t *testing.T) {
+	// 準備
+	repo := NewInMemoryUserRepository()
+	alice := model.NewUser("Alice", "alice@example.com", model.RoleAdmin)
+	bob := model.NewUser("Bob", "bob@example.com", model.RoleMember)
+	bob.CreatedAt = alice.CreatedAt.Add(time.Second)
+
+	// 作成
+	for _, u := range []*model.User{bob, alice} {
+		if err := repo.Create(u); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	// 全件取得（作成日時順）
+	users, err := repo.FindAll()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(users) != 2 || users[0].ID != alice.ID {
+		t.Errorf("Expected users ordered by creation time, got %v", users)
+	}
+
+	// メールアドレスで検索
+	found, err := repo.FindByEmail("bob@example.com")
+	if err != nil || found == nil || found.ID != bob.ID {
+		t.Errorf("Expected to find bob by email, got %v, %v", found, err)
+	}
+	found, _ = repo.FindByEmail("carol@example.com")
+	if found != nil {
+		t.Errorf("Expected nil for unknown email, got %v", found)
+	}
+
+	// 存在しないID
+	found, _ = repo.FindByID("missing")
+	if found != nil {
+		t.Errorf("Expected nil for unknown ID, got %v", found)
+	}
+
+	// ユーザーがいる場合は最初のユーザーとして作成しない
+	carol := model.NewUser("Carol", "carol@example.com", model.RoleAdmin)
+	created, err := repo.CreateFirst(carol)
+	if err != nil || created {
+		t.Errorf("Expected not to create the first user, got %v, %v", created, err)
+	}
+	if found, _ := repo.FindByID(carol.ID); found != nil {
+		t.Errorf("Expected carol not to be created, got %v", found)
+	}
+}
+
+func TestInMemoryUserRepository_CreateFirst_Concurrent(t *testing.T) {
+	// 準備
+	repo := NewInMemoryUserRepository()
+	var wg sync.WaitGroup
+	var createdCount atomic.Int32
+
+	// 実行
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			created, err := repo.CreateFirst(model.NewUser("管理者", fmt.Sprintf("admin%d@example.com", i), model.RoleAdmin))
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if created {
+				createdCount.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 検証
+	users, _ := repo.FindAll()
+	if createdCount.Load() != 1 || len(users) != 1 {
+		t.Errorf("Expected exactly one first user, got %d created and %d stored", createdCount.Load(), len(users))
+	}
+}
+
+func TestMySQLUserRepository_CreateFirst(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int
+		wantCreated bool
+	}{
+		{name: "ユーザーがいなければ作成する", count: 0, wantCreated: true},
+		{name: "ユーザーがいれば作成しない", count: 1, wantCreated: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock: %v", err)
+			}
+			defer db.Close()
+			repo := NewMySQLUserRepository(db)
+			user := model.NewUser("管理者", "admin@example.com", model.RoleAdmin)
+
+			// ロック取得、件数の確認、INSERT、コミット、ロック解放の順に実行されることを期待
+			mock.ExpectQuery("SELECT GET_LOCK").WithArgs(firstUserLockName, namedLockTimeout).
+				WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.count))
+			if tt.wantCreated {
+				mock.ExpectExec("INSERT INTO users").WithArgs(
+					user.ID,
+					user.DisplayName,
+					user.Email,
+					user.Role,
+					user.CreatedAt,
+					user.UpdatedAt,
+				).WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+			mock.ExpectCommit()
+			mock.ExpectExec("SELECT RELEASE_LOCK").WithArgs(firstUserLockName).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			// 実行
+			created, err := repo.CreateFirst(user)
+
+			// 検証
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if created != tt.wantCreated {
+				t.Errorf("Expected created %v, got %v", tt.wantCreated, created)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMySQLUserRepository_Create(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLUserRepository(db)
+
+	// INSERTクエリの期待値を設定
+	user := model.NewUser("山田太郎", "taro@example.com", model.RoleMember)
+	mock.ExpectExec("INSERT INTO users").WithArgs(
+		user.ID,
+		user.DisplayName,
+		user.Email,
+		user.Role,
+		user.CreatedAt,
+		user.UpdatedAt,
+	).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// 実行
+	err = repo.Create(user)
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLUserRepository_FindByEmail(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLUserRepository(db)
+
+	// SELECTクエリの結果を設定
+	now := time.Now()
+	columns := []string{"id", "display_name", "email", "role", "created_at", "updated_at"}
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE email = \\?").
+		WithArgs("taro@example.com").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("user-1", "山田太郎", "taro@example.com", "admin", now, now))
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE email = \\?").
+		WithArgs("missing@example.com").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	// 実行
+	user, err := repo.FindByEmail("taro@example.com")
+	missing, missingErr := repo.FindByEmail("missing@example.com")
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if user == nil || user.ID != "user-1" || user.Role != model.RoleAdmin {
+		t.Errorf("Expected admin user-1, got %v", user)
+	}
+	if missingErr != nil || missing != nil {
+		t.Errorf("Expected nil user and no error, got %v, %v", missing, missingErr)
+	}
+
+	// モックの期待通りに実行されたか確認
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
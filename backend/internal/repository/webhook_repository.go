@@ -0,0 +1,454 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(subscription *model.WebhookSubscription) error
+	// FindSubscriptions は全ての登録を登録順に返す
+	FindSubscriptions() ([]*model.WebhookSubscription, error)
+	// FindSubscriptionByID と FindDeliveryByID は存在しない場合に nil を返す
+	FindSubscriptionByID(id string) (*model.WebhookSubscription, error)
+	// DeleteSubscription は登録とその配送の記録を削除する
+	DeleteSubscription(id string) error
+	CreateDelivery(delivery *model.WebhookDelivery) error
+	UpdateDelivery(delivery *model.WebhookDelivery) error
+	FindDeliveryByID(id string) (*model.WebhookDelivery, error)
+	// FindDeliveries は登録の配送を新しい順に最大 limit 件返す
+	FindDeliveries(subscriptionID string, limit int) ([]*model.WebhookDelivery, error)
+	// FindDueDeliveries は now までに送信する pending の配送を送信予定の早い順に最大 limit 件返す
+	FindDueDeliveries(now time.Time, limit int) ([]*model.WebhookDelivery, error)
+	// ClaimDelivery は pending のまま送信予定が delivery.NextAttemptAt から変わっていない配送の送信予定を until に変更し、変更できたかを返す。
+	// 複数のサーバーが同じ配送を送らないよう、送信の前に呼んで配送を確保する。delivery は変更しない。
+	ClaimDelivery(delivery *model.WebhookDelivery, until time.Time) (bool, error)
+}
+
+// InMemoryWebhookRepository - In-memory implementation for testing
+//
+// 配送は送信のたびに更新されるため、呼び出し側との共有を避けてコピーを保存して返す。
+type InMemoryWebhookRepository struct {
+	subscriptions map[string]*model.WebhookSubscription
+	deliveries    map[string]model.WebhookDelivery
+	mutex         sync.RWMutex
+}
+
+func NewInMemoryWebhookRepository() *InMemoryWebhookRepository {
+	return &InMemoryWebhookRepository{
+		subscriptions: make(map[string]*model.WebhookSubscription),
+		deliveries:    make(map[string]model.WebhookDelivery),
+	}
+}
+
+func (r *InMemoryWebhookRepository) CreateSubscription(subscription *model.WebhookSubscription) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) FindSubscriptions() ([]*model.WebhookSubscription, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	subscriptions := make([]*model.WebhookSubscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+
+	return subscriptions, nil
+}
+
+func (r *InMemoryWebhookRepository) FindSubscriptionByID(id string) (*model.WebhookSubscription, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return subscription, nil
+}
+
+func (r *InMemoryWebhookRepository) DeleteSubscription(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.subscriptions, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.SubscriptionID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) CreateDelivery(delivery *model.WebhookDelivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.deliveries[delivery.ID]; !ok {
+		return fmt.Errorf("webhook delivery not found: %s", delivery.ID)
+	}
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) FindDeliveryByID(id string) (*model.WebhookDelivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &delivery, nil
+}
+
+func (r *InMemoryWebhookRepository) FindDeliveries(subscriptionID string, limit int) ([]*model.WebhookDelivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	deliveries := make([]*model.WebhookDelivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, &delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (r *InMemoryWebhookRepository) FindDueDeliveries(now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	deliveries := make([]*model.WebhookDelivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.Status == model.DeliveryPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, &delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptAt.Before(*deliveries[j].NextAttemptAt)
+	})
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (r *InMemoryWebhookRepository) ClaimDelivery(delivery *model.WebhookDelivery, until time.Time) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, ok := r.deliveries[delivery.ID]
+	if !ok || stored.Status != model.DeliveryPending || stored.NextAttemptAt == nil || delivery.NextAttemptAt == nil || !stored.NextAttemptAt.Equal(*delivery.NextAttemptAt) {
+		return false, nil
+	}
+	stored.NextAttemptAt = &until
+	r.deliveries[delivery.ID] = stored
+	return true, nil
+}
+
+// MySQLWebhookRepository - MySQL implementation
+type MySQLWebhookRepository struct {
+	db *sql.DB
+}
+
+const (
+	webhookSubscriptionColumns = "id, url, secret, events, created_by, created_at"
+	webhookDeliveryColumns     = "id, subscription_id, event_id, event, payload, status, attempts, response_status, last_error, next_attempt_at, redelivery_of, created_at, updated_at"
+)
+
+// NewMySQLWebhookRepository creates a new MySQL repository
+func NewMySQLWebhookRepository(db *sql.DB) *MySQLWebhookRepository {
+	return &MySQLWebhookRepository{
+		db: db,
+	}
+}
+
+// scanWebhookSubscription は webhookSubscriptionColumns の順に並んだ1行を登録として読み込む
+func scanWebhookSubscription(row rowScanner) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	var events []byte
+	var createdBy sql.NullString
+	if err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&subscription.Secret,
+		&events,
+		&createdBy,
+		&subscription.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(events, &subscription.Events); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook events: %w", err)
+	}
+	subscription.CreatedBy = createdBy.String
+	return &subscription, nil
+}
+
+// scanWebhookDelivery は webhookDeliveryColumns の順に並んだ1行を配送として読み込む
+func scanWebhookDelivery(row rowScanner) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	var payload []byte
+	var nextAttemptAt sql.NullTime
+	var redeliveryOf sql.NullString
+	if err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.Event,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.ResponseStatus,
+		&delivery.LastError,
+		&nextAttemptAt,
+		&redeliveryOf,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	delivery.RedeliveryOf = redeliveryOf.String
+	return &delivery, nil
+}
+
+// CreateSubscription inserts a new webhook subscription into the database
+func (r *MySQLWebhookRepository) CreateSubscription(subscription *model.WebhookSubscription) error {
+	events, err := json.Marshal(subscription.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+	_, err = r.db.Exec(
+		"INSERT INTO webhook_subscriptions ("+webhookSubscriptionColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		subscription.ID,
+		subscription.URL,
+		subscription.Secret,
+		string(events),
+		sql.NullString{String: subscription.CreatedBy, Valid: subscription.CreatedBy != ""},
+		subscription.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// FindSubscriptions returns all webhook subscriptions ordered by creation time
+func (r *MySQLWebhookRepository) FindSubscriptions() ([]*model.WebhookSubscription, error) {
+	rows, err := r.db.Query("SELECT " + webhookSubscriptionColumns + " FROM webhook_subscriptions ORDER BY created_at")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := make([]*model.WebhookSubscription, 0)
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// FindSubscriptionByID returns a webhook subscription by ID
+func (r *MySQLWebhookRepository) FindSubscriptionByID(id string) (*model.WebhookSubscription, error) {
+	subscription, err := scanWebhookSubscription(r.db.QueryRow(
+		"SELECT "+webhookSubscriptionColumns+" FROM webhook_subscriptions WHERE id = ?",
+		id,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription removes a webhook subscription and its deliveries in a single transaction
+func (r *MySQLWebhookRepository) DeleteSubscription(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM webhook_deliveries WHERE subscription_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM webhook_subscriptions WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CreateDelivery inserts a new webhook delivery into the database
+func (r *MySQLWebhookRepository) CreateDelivery(delivery *model.WebhookDelivery) error {
+	_, err := r.db.Exec(
+		"INSERT INTO webhook_deliveries ("+webhookDeliveryColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		delivery.ID,
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.Event,
+		string(delivery.Payload),
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		sql.NullString{String: delivery.RedeliveryOf, Valid: delivery.RedeliveryOf != ""},
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// UpdateDelivery records the result of a delivery attempt
+func (r *MySQLWebhookRepository) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	_, err := r.db.Exec(
+		"UPDATE webhook_deliveries SET status = ?, attempts = ?, response_status = ?, last_error = ?, next_attempt_at = ?, updated_at = ? WHERE id = ?",
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.UpdatedAt,
+		delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// FindDeliveryByID returns a webhook delivery by ID
+func (r *MySQLWebhookRepository) FindDeliveryByID(id string) (*model.WebhookDelivery, error) {
+	delivery, err := scanWebhookDelivery(r.db.QueryRow(
+		"SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE id = ?",
+		id,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// FindDeliveries returns the latest deliveries of a subscription
+func (r *MySQLWebhookRepository) FindDeliveries(subscriptionID string, limit int) ([]*model.WebhookDelivery, error) {
+	return r.findDeliveries(
+		"SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE subscription_id = ? ORDER BY created_at DESC LIMIT ?",
+		subscriptionID, limit,
+	)
+}
+
+// FindDueDeliveries returns the pending deliveries whose next attempt is due
+func (r *MySQLWebhookRepository) FindDueDeliveries(now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	return r.findDeliveries(
+		"SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?",
+		model.DeliveryPending, now, limit,
+	)
+}
+
+// ClaimDelivery moves the next attempt of a pending delivery to until, unless another
+// server has already claimed or updated it since it was read. The conditional UPDATE is
+// atomic, so only one of several servers polling the same row gets RowsAffected == 1.
+func (r *MySQLWebhookRepository) ClaimDelivery(delivery *model.WebhookDelivery, until time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ? AND status = ? AND next_attempt_at = ?",
+		until,
+		delivery.ID,
+		model.DeliveryPending,
+		delivery.NextAttemptAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (r *MySQLWebhookRepository) findDeliveries(query string, args ...interface{}) ([]*model.WebhookDelivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]*model.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+func TestInMemoryWebhookRepository(t *testing.T) {
+	// 準備
+	repo := NewInMemoryWebhookRepository()
+	subscription := model.NewWebhookSubscription("https://example.com/hook", "secret", []model.WebhookEventType{model.EventReservationCreated})
+	if err := repo.CreateSubscription(subscription); err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	first := model.NewWebhookDelivery(subscription.ID, "event-1", model.EventReservationCreated, json.RawMessage(`{}`), now)
+	second := model.NewWebhookDelivery(subscription.ID, "event-2", model.EventReservationCreated, json.RawMessage(`{}`), now.Add(time.Minute))
+	for _, d := range []*model.WebhookDelivery{second, first} {
+		if err := repo.CreateDelivery(d); err != nil {
+			t.Fatalf("Failed to create delivery: %v", err)
+		}
+	}
+
+	// 配送を新しい順に取得
+	deliveries, err := repo.FindDeliveries(subscription.ID, 10)
+	if err != nil || len(deliveries) != 2 || deliveries[0].ID != second.ID {
+		t.Errorf("Expected deliveries newest first, got %v, %v", deliveries, err)
+	}
+
+	// 送信予定を過ぎた pending の配送だけを取得
+	due, err := repo.FindDueDeliveries(now, 10)
+	if err != nil || len(due) != 1 || due[0].ID != first.ID {
+		t.Errorf("Expected only the first delivery to be due, got %v, %v", due, err)
+	}
+
+	// 確保できるのは読み込んだ後に誰も確保していない配送だけ
+	lease := now.Add(time.Minute)
+	if claimed, err := repo.ClaimDelivery(due[0], lease); err != nil || !claimed {
+		t.Errorf("Expected to claim the delivery, got %v, %v", claimed, err)
+	}
+	if claimed, _ := repo.ClaimDelivery(due[0], lease); claimed {
+		t.Error("Expected a delivery to be claimed only once")
+	}
+	if again, _ := repo.FindDueDeliveries(now, 10); len(again) != 0 {
+		t.Errorf("Expected a claimed delivery not to be due until the lease expires, got %v", again)
+	}
+
+	// 取得した配送を変更しても保存した配送は変わらない
+	due[0].Status = model.DeliverySucceeded
+	if found, _ := repo.FindDeliveryByID(first.ID); found.Status != model.DeliveryPending {
+		t.Errorf("Expected stored delivery to be unchanged, got %v", found.Status)
+	}
+
+	// 更新した配送は送信の対象から外れる
+	if err := repo.UpdateDelivery(due[0]); err != nil {
+		t.Fatalf("Failed to update delivery: %v", err)
+	}
+	if due, _ = repo.FindDueDeliveries(now.Add(time.Hour), 10); len(due) != 1 || due[0].ID != second.ID {
+		t.Errorf("Expected only the second delivery to be due, got %v", due)
+	}
+
+	// 登録を削除すると配送の記録も削除する
+	if err := repo.DeleteSubscription(subscription.ID); err != nil {
+		t.Fatalf("Failed to delete subscription: %v", err)
+	}
+	if found, _ := repo.FindSubscriptionByID(subscription.ID); found != nil {
+		t.Errorf("Expected nil for deleted subscription, got %v", found)
+	}
+	if found, _ := repo.FindDeliveryByID(first.ID); found != nil {
+		t.Errorf("Expected nil for delivery of deleted subscription, got %v", found)
+	}
+}
+
+func TestMySQLWebhookRepository_CreateSubscription(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLWebhookRepository(db)
+
+	// イベントの種類は JSON の配列で保存する
+	subscription := model.NewWebhookSubscription("https://example.com/hook", "secret", []model.WebhookEventType{model.EventReservationCreated, model.EventReservationDeleted})
+	mock.ExpectExec("INSERT INTO webhook_subscriptions").
+		WithArgs(subscription.ID, subscription.URL, "secret", `["reservation.created","reservation.deleted"]`, nil, subscription.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// 実行
+	err = repo.CreateSubscription(subscription)
+
+	// 検証
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMySQLWebhookRepository_ClaimDelivery(t *testing.T) {
+	tests := []struct {
+		name        string
+		affected    int64
+		wantClaimed bool
+	}{
+		{name: "確保できる", affected: 1, wantClaimed: true},
+		{name: "他のサーバーが先に確保した", affected: 0, wantClaimed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock: %v", err)
+			}
+			defer db.Close()
+			repo := NewMySQLWebhookRepository(db)
+			now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+			delivery := model.NewWebhookDelivery("sub-1", "event-1", model.EventReservationCreated, json.RawMessage(`{}`), now)
+			lease := now.Add(time.Minute)
+
+			// 読み込んだときの送信予定のままの場合だけ更新することを期待
+			mock.ExpectExec("UPDATE webhook_deliveries SET next_attempt_at = \\? WHERE id = \\? AND status = \\? AND next_attempt_at = \\?").
+				WithArgs(lease, delivery.ID, model.DeliveryPending, delivery.NextAttemptAt).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			// 実行
+			claimed, err := repo.ClaimDelivery(delivery, lease)
+
+			// 検証
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if claimed != tt.wantClaimed {
+				t.Errorf("Expected claimed %v, got %v", tt.wantClaimed, claimed)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMySQLWebhookRepository_FindDueDeliveries(t *testing.T) {
+	// SQLMockのセットアップ
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// レポジトリの作成
+	repo := NewMySQLWebhookRepository(db)
+
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	columns := []string{"id", "subscription_id", "event_id", "event", "payload", "status", "attempts", "response_status", "last_error", "next_attempt_at", "redelivery_of", "created_at", "updated_at"}
+	mock.ExpectQuery("SELECT (.+) FROM webhook_deliveries WHERE status = \\? AND next_attempt_at <= \\? ORDER BY next_attempt_at LIMIT \\?").
+		WithArgs(model.DeliveryPending, now, 50).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("delivery-1", "sub-1", "event-1", "reservation.created", []byte(`{"id":"event-1"}`), "pending", 2, 500, "unexpected status", now, nil, now, now))
+
+	// 実行
+	deliveries, err := repo.FindDueDeliveries(now, 50)
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("Expected 1 delivery, got %d", len(deliveries))
+	}
+	d := deliveries[0]
+	if d.Event != model.EventReservationCreated || d.Attempts != 2 || d.ResponseStatus != 500 || d.NextAttemptAt == nil || string(d.Payload) != `{"id":"event-1"}` {
+		t.Errorf("Unexpected delivery: %+v", d)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
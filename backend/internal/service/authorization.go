@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+// Action は予約に対する操作
+type Action string
+
+const (
+	// ActionViewSchedule は予約の埋まっている時間帯（空き状況）を参照する
+	ActionViewSchedule Action = "view_schedule"
+	// ActionViewDetails は予約の所有者や取り消し理由などの詳細を参照する
+	ActionViewDetails Action = "view_details"
+	// ActionCreate は予約を作成する
+	ActionCreate Action = "create"
+	// ActionUpdate は予約の時間帯やリソースを変更する
+	ActionUpdate Action = "update"
+	// ActionCancel は予約を取り消す
+	ActionCancel Action = "cancel"
+	// ActionApprove は仮予約（pending）を確定（confirmed）する
+	ActionApprove Action = "approve"
+	// ActionRecordOutcome は利用結果（completed / no_show）を記録する
+	ActionRecordOutcome Action = "record_outcome"
+	// ActionConfigure はリソースや営業カレンダーなどの設定を変更する
+	ActionConfigure Action = "configure"
+)
+
+// Actions は全ての操作
+var Actions = []Action{
+	ActionViewSchedule,
+	ActionViewDetails,
+	ActionCreate,
+	ActionUpdate,
+	ActionCancel,
+	ActionApprove,
+	ActionRecordOutcome,
+	ActionConfigure,
+}
+
+// Grant は操作を許可する範囲
+type Grant int
+
+const (
+	// GrantNone は操作を許可しない
+	GrantNone Grant = iota
+	// GrantOwn は自分の予約に対してだけ許可する。所有者のいない予約は GrantAll の権限だけが操作できる
+	GrantOwn
+	// GrantAll は全員の予約に対して許可する
+	GrantAll
+)
+
+// policyTable は権限ごとに許可する操作とその範囲。表にない操作は許可しない。
+var policyTable = map[model.UserRole]map[Action]Grant{
+	model.RoleViewer: {
+		ActionViewSchedule: GrantAll,
+	},
+	model.RoleMember: {
+		ActionViewSchedule: GrantAll,
+		ActionViewDetails:  GrantAll,
+		ActionCreate:       GrantOwn,
+		ActionUpdate:       GrantOwn,
+		ActionCancel:       GrantOwn,
+	},
+	model.RoleApprover: {
+		ActionViewSchedule:  GrantAll,
+		ActionViewDetails:   GrantAll,
+		ActionCreate:        GrantOwn,
+		ActionUpdate:        GrantAll,
+		ActionCancel:        GrantAll,
+		ActionApprove:       GrantAll,
+		ActionRecordOutcome: GrantAll,
+	},
+	model.RoleAdmin: {
+		ActionViewSchedule:  GrantAll,
+		ActionViewDetails:   GrantAll,
+		ActionCreate:        GrantAll,
+		ActionUpdate:        GrantAll,
+		ActionCancel:        GrantAll,
+		ActionApprove:       GrantAll,
+		ActionRecordOutcome: GrantAll,
+		ActionConfigure:     GrantAll,
+	},
+}
+
+// GrantFor は role に action を許可する範囲を返す
+func GrantFor(role model.UserRole, action Action) Grant {
+	return policyTable[role][action]
+}
+
+// Allowed は p が reservation に対して action を行えるかを返す。
+// reservation が nil の場合は対象の予約を問わない操作（作成や一覧）として、範囲が GrantNone でなければ許可する。
+func Allowed(p auth.Principal, action Action, reservation *model.Reservation) bool {
+	switch GrantFor(p.Role, action) {
+	case GrantAll:
+		return true
+	case GrantOwn:
+		return reservation == nil || (p.UserID != "" && reservation.UserID == p.UserID)
+	default:
+		return false
+	}
+}
+
+// authorize は ctx のプリンシパルが reservation に対して action を行えるかを確認し、行えない場合は ErrForbidden を返す。
+// プリンシパルのないリクエストにはどの操作も許可しない。
+func authorize(ctx context.Context, action Action, reservation *model.Reservation) error {
+	p, ok := auth.PrincipalFrom(ctx)
+	if !ok || !Allowed(p, action, reservation) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// statusAction は予約の状態を status に変更する操作を返す
+func statusAction(status model.ReservationStatus) Action {
+	switch status {
+	case model.StatusConfirmed:
+		return ActionApprove
+	case model.StatusCancelled:
+		return ActionCancel
+	default:
+		return ActionRecordOutcome
+	}
+}
+
+// redact は予約から埋まっている時間帯以外の情報を除いた写しを返す（予約を識別する ID と UID は残す）。詳細を参照できない利用者への一覧や取得に使う。
+func redact(reservation *model.Reservation) *model.Reservation {
+	return &model.Reservation{
+		ID:         reservation.ID,
+		ResourceID: reservation.ResourceID,
+		StartTime:  reservation.StartTime,
+		EndTime:    reservation.EndTime,
+		Attendees:  []string{},
+		Status:     reservation.Status,
+		CreatedAt:  reservation.CreatedAt,
+		UpdatedAt:  reservation.UpdatedAt,
+		Version:    reservation.Version,
+		ICalUID:    reservation.ICalUID,
+	}
+}
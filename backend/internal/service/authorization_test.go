@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+func TestGrantFor(t *testing.T) {
+	const (
+		none = GrantNone
+		own  = GrantOwn
+		all  = GrantAll
+	)
+	tests := []struct {
+		action Action
+		want   map[model.UserRole]Grant
+	}{
+		{action: ActionViewSchedule, want: map[model.UserRole]Grant{model.RoleViewer: all, model.RoleMember: all, model.RoleApprover: all, model.RoleAdmin: all}},
+		{action: ActionViewDetails, want: map[model.UserRole]Grant{model.RoleViewer: none, model.RoleMember: all, model.RoleApprover: all, model.RoleAdmin: all}},
+		{action: ActionCreate, want: map[model.UserRole]Grant{model.RoleViewer: none, model.RoleMember: own, model.RoleApprover: own, model.RoleAdmin: all}},
+		{action: ActionUpdate, want: map[model.UserRole]Grant{model.RoleViewer: none, model.RoleMember: own, model.RoleApprover: all, model.RoleAdmin: all}},
+		{action: ActionCancel, want: map[model.UserRole]Grant{model.RoleViewer: none, model.RoleMember: own, model.RoleApprover: all, model.RoleAdmin: all}},
+		{action: ActionApprove, want: map[model.UserRole]Grant{model.RoleViewer: none, model.RoleMember: none, model.RoleApprover: all, model.RoleAdmin: all}},
+		{action: ActionRecordOutcome, want: map[model.UserRole]Grant{model.RoleViewer: none, model.RoleMember: none, model.RoleApprover: all, model.RoleAdmin: all}},
+		{action: ActionConfigure, want: map[model.UserRole]Grant{model.RoleViewer: none, model.RoleMember: none, model.RoleApprover: none, model.RoleAdmin: all}},
+	}
+
+	if len(tests) != len(Actions) {
+		t.Fatalf("Expected a case for each of %d actions, got %d", len(Actions), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.action), func(t *testing.T) {
+			for _, role := range model.UserRoles {
+				if got := GrantFor(role, tt.action); got != tt.want[role] {
+					t.Errorf("%s: expected grant %d, got %d", role, tt.want[role], got)
+				}
+			}
+			// 未定義の権限には何も許可しない
+			if got := GrantFor("guest", tt.action); got != GrantNone {
+				t.Errorf("guest: expected no grant, got %d", got)
+			}
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	member := auth.Principal{UserID: "user-1", Role: model.RoleMember}
+	tests := []struct {
+		name        string
+		principal   auth.Principal
+		action      Action
+		reservation *model.Reservation
+		want        bool
+	}{
+		{name: "自分の予約は変更できる", principal: member, action: ActionUpdate, reservation: &model.Reservation{UserID: "user-1"}, want: true},
+		{name: "他人の予約は変更できない", principal: member, action: ActionUpdate, reservation: &model.Reservation{UserID: "user-2"}},
+		{name: "所有者のいない予約は変更できない", principal: member, action: ActionUpdate, reservation: &model.Reservation{}},
+		{name: "所有者のいない予約は取り消せない", principal: member, action: ActionCancel, reservation: &model.Reservation{}},
+		{name: "ユーザーIDのないプリンシパルは所有者のいない予約を自分の予約として扱わない", principal: auth.Principal{Role: model.RoleMember}, action: ActionUpdate, reservation: &model.Reservation{}},
+		{name: "管理者は所有者のいない予約を変更できる", principal: auth.Principal{UserID: "user-3", Role: model.RoleAdmin}, action: ActionUpdate, reservation: &model.Reservation{}, want: true},
+		{name: "予約を問わない操作は範囲があれば許可する", principal: member, action: ActionCreate, want: true},
+		{name: "範囲がなければ許可しない", principal: member, action: ActionApprove, reservation: &model.Reservation{UserID: "user-1"}},
+		{name: "全員の予約に許可された操作", principal: auth.Principal{UserID: "user-3", Role: model.RoleApprover}, action: ActionCancel, reservation: &model.Reservation{UserID: "user-1"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allowed(tt.principal, tt.action, tt.reservation); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAuthorize_Anonymous(t *testing.T) {
+	for _, action := range Actions {
+		if err := authorize(context.Background(), action, nil); !errors.Is(err, ErrForbidden) {
+			t.Errorf("%s: expected ErrForbidden, got %v", action, err)
+		}
+	}
+}
+
+func TestStatusAction(t *testing.T) {
+	tests := map[model.ReservationStatus]Action{
+		model.StatusConfirmed: ActionApprove,
+		model.StatusCancelled: ActionCancel,
+		model.StatusCompleted: ActionRecordOutcome,
+		model.StatusNoShow:    ActionRecordOutcome,
+	}
+	for status, want := range tests {
+		if got := statusAction(status); got != want {
+			t.Errorf("%s: expected %s, got %s", status, want, got)
+		}
+	}
+}
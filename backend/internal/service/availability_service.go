@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+type AvailabilityService struct {
+	repo         repository.ReservationRepository
+	resourceRepo repository.ResourceRepository
+	calendar     *CalendarService
+}
+
+func NewAvailabilityService(repo repository.ReservationRepository, resourceRepo repository.ResourceRepository) *AvailabilityService {
+	return &AvailabilityService{repo: repo, resourceRepo: resourceRepo}
+}
+
+// SetCalendar は空き時間から除く営業時間外と休業期間を設定する。設定しない場合は常に営業中として扱う。
+func (s *AvailabilityService) SetCalendar(calendar *CalendarService) {
+	s.calendar = calendar
+}
+
+// AvailabilityParams は空き時間の検索条件
+type AvailabilityParams struct {
+	ResourceID string
+	From       time.Time
+	To         time.Time
+	// Duration は必要な空き時間の長さ
+	Duration time.Duration
+	// Granularity を指定すると、空き時間の開始と終了をその刻み（例: 15分、30分）にそろえる。
+	// 刻みはその日の0時（From のタイムゾーン）を基準にする。ゼロ値の場合はそろえない。
+	Granularity time.Duration
+}
+
+// FindFreeSlots はリソースの [From, To) のうち、予約が入っておらず Duration 以上続く時間帯を開始時刻の昇順で返す。
+// 営業カレンダーが設定されている場合は営業時間外と休業期間も空きに含めない。
+// 期間の指定が不正な場合は ErrInvalidWindow、MaxListWindow より長い場合は ErrWindowTooLarge、
+// Duration が正でないか期間より長い場合は ErrInvalidDuration、刻みが1日を割り切れない場合は ErrInvalidGranularity を返す。
+// 空き時間から予約の入っている時間帯が分かるため、予約の一覧を参照できない場合は ErrForbidden を返す。
+func (s *AvailabilityService) FindFreeSlots(ctx context.Context, params AvailabilityParams) ([]model.TimeRange, error) {
+	if err := authorize(ctx, ActionViewSchedule, nil); err != nil {
+		return nil, err
+	}
+	if params.From.IsZero() || params.To.IsZero() || !params.To.After(params.From) {
+		return nil, ErrInvalidWindow
+	}
+	if params.To.Sub(params.From) > MaxListWindow {
+		return nil, ErrWindowTooLarge
+	}
+	if params.Duration <= 0 || params.Duration > params.To.Sub(params.From) {
+		return nil, ErrInvalidDuration
+	}
+	if params.Granularity < 0 || (params.Granularity > 0 && (params.Granularity < time.Minute || (24*time.Hour)%params.Granularity != 0)) {
+		return nil, ErrInvalidGranularity
+	}
+	if err := checkBookable(s.resourceRepo, params.ResourceID); err != nil {
+		return nil, err
+	}
+
+	reservations, err := s.repo.FindInRange(params.ResourceID, params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.calendar != nil {
+		closed, err := s.calendar.closedPeriods(params.ResourceID, params.From, params.To)
+		if err != nil {
+			return nil, err
+		}
+		reservations = withClosedPeriods(reservations, closed)
+	}
+
+	return FreeSlots(reservations, params.From, params.To, params.Duration, params.Granularity), nil
+}
+
+// withClosedPeriods は休業時間を予約済みの時間帯として busy に加え、開始時刻の昇順に並べ直す
+func withClosedPeriods(busy []*model.Reservation, closed []model.ClosedPeriod) []*model.Reservation {
+	merged := append([]*model.Reservation{}, busy...)
+	for _, c := range closed {
+		merged = append(merged, &model.Reservation{StartTime: c.StartTime, EndTime: c.EndTime, Status: model.StatusConfirmed})
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].StartTime.Before(merged[j].StartTime)
+	})
+	return merged
+}
+
+// FreeSlots は [from, to) のうち busy のどの予約とも重ならず duration 以上続く時間帯を返す。
+// busy は開始時刻の昇順で渡すこと。取り消された予約は無視する。
+// granularity が正の場合は各時間帯の開始を刻みに切り上げ、終了を刻みに切り捨てる。
+func FreeSlots(busy []*model.Reservation, from, to time.Time, duration, granularity time.Duration) []model.TimeRange {
+	slots := make([]model.TimeRange, 0)
+	addGap := func(start, end time.Time) {
+		if granularity > 0 {
+			start = alignUp(start, granularity, from.Location())
+			end = alignDown(end, granularity, from.Location())
+		}
+		if end.Sub(start) >= duration {
+			slots = append(slots, model.TimeRange{StartTime: start, EndTime: end})
+		}
+	}
+
+	cursor := from
+	for _, r := range busy {
+		if !r.Active() {
+			continue
+		}
+		if !cursor.Before(to) {
+			break
+		}
+		if r.StartTime.After(cursor) {
+			end := r.StartTime
+			if end.After(to) {
+				end = to
+			}
+			addGap(cursor, end)
+		}
+		if r.EndTime.After(cursor) {
+			cursor = r.EndTime
+		}
+	}
+	if cursor.Before(to) {
+		addGap(cursor, to)
+	}
+
+	return slots
+}
+
+// sinceMidnight は loc でのその日の0時から t までの経過時間を返す
+func sinceMidnight(t time.Time, loc *time.Location) (time.Time, time.Duration) {
+	t = t.In(loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	return t, t.Sub(midnight)
+}
+
+func alignUp(t time.Time, granularity time.Duration, loc *time.Location) time.Time {
+	t, elapsed := sinceMidnight(t, loc)
+	if r := elapsed % granularity; r != 0 {
+		return t.Add(granularity - r)
+	}
+	return t
+}
+
+func alignDown(t time.Time, granularity time.Duration, loc *time.Location) time.Time {
+	t, elapsed := sinceMidnight(t, loc)
+	return t.Add(-(elapsed % granularity))
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+func TestFreeSlots(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	at := func(hour, min int) time.Time {
+		return time.Date(2024, 4, 1, hour, min, 0, 0, jst)
+	}
+	booked := func(startHour, startMin, endHour, endMin int) *model.Reservation {
+		return model.NewReservation("room-a", at(startHour, startMin), at(endHour, endMin))
+	}
+	slot := func(startHour, startMin, endHour, endMin int) model.TimeRange {
+		return model.TimeRange{StartTime: at(startHour, startMin), EndTime: at(endHour, endMin)}
+	}
+	cancelled := booked(12, 0, 13, 0)
+	cancelled.Status = model.StatusCancelled
+
+	tests := []struct {
+		name        string
+		busy        []*model.Reservation
+		duration    time.Duration
+		granularity time.Duration
+		want        []model.TimeRange
+	}{
+		{
+			name:     "予約なし",
+			duration: time.Hour,
+			want:     []model.TimeRange{slot(9, 0, 18, 0)},
+		},
+		{
+			name:     "予約の前後",
+			busy:     []*model.Reservation{booked(10, 0, 11, 0), booked(14, 0, 15, 0)},
+			duration: time.Hour,
+			want:     []model.TimeRange{slot(9, 0, 10, 0), slot(11, 0, 14, 0), slot(15, 0, 18, 0)},
+		},
+		{
+			name:     "短すぎる隙間は除く",
+			busy:     []*model.Reservation{booked(10, 0, 11, 0), booked(11, 30, 12, 0)},
+			duration: time.Hour,
+			want:     []model.TimeRange{slot(9, 0, 10, 0), slot(12, 0, 18, 0)},
+		},
+		{
+			name:     "重なった予約と期間外にはみ出した予約",
+			busy:     []*model.Reservation{booked(8, 0, 9, 30), booked(10, 0, 12, 0), booked(11, 0, 11, 30), booked(17, 0, 19, 0)},
+			duration: 30 * time.Minute,
+			want:     []model.TimeRange{slot(9, 30, 10, 0), slot(12, 0, 17, 0)},
+		},
+		{
+			name:     "取り消された予約は無視する",
+			busy:     []*model.Reservation{cancelled},
+			duration: time.Hour,
+			want:     []model.TimeRange{slot(9, 0, 18, 0)},
+		},
+		{
+			name:        "刻みにそろえる",
+			busy:        []*model.Reservation{booked(9, 0, 10, 10), booked(11, 50, 13, 5)},
+			duration:    time.Hour,
+			granularity: 15 * time.Minute,
+			want:        []model.TimeRange{slot(10, 15, 11, 45), slot(13, 15, 18, 0)},
+		},
+		{
+			name:        "そろえると短くなる隙間は除く",
+			busy:        []*model.Reservation{booked(9, 0, 10, 10), booked(11, 5, 18, 0)},
+			duration:    time.Hour,
+			granularity: 30 * time.Minute,
+			want:        []model.TimeRange{},
+		},
+		{
+			name:     "空きなし",
+			busy:     []*model.Reservation{booked(9, 0, 18, 0)},
+			duration: time.Minute,
+			want:     []model.TimeRange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FreeSlots(tt.busy, at(9, 0), at(18, 0), tt.duration, tt.granularity)
+			if !slices.EqualFunc(got, tt.want, func(a, b model.TimeRange) bool {
+				return a.StartTime.Equal(b.StartTime) && a.EndTime.Equal(b.EndTime)
+			}) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// viewerCtx は予約の一覧だけを参照できるユーザー
+var viewerCtx = auth.WithPrincipal(context.Background(), auth.Principal{UserID: "viewer-1", Role: model.RoleViewer})
+
+func TestAvailabilityService_FindFreeSlots(t *testing.T) {
+	// 準備
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	mockRepo := newMockReservationRepository()
+	var queried []time.Time
+	mockRepo.findInRangeFunc = func(resourceID string, f, t time.Time) ([]*model.Reservation, error) {
+		queried = []time.Time{f, t}
+		return []*model.Reservation{model.NewReservation(resourceID, from.Add(time.Hour), to.Add(-time.Hour))}, nil
+	}
+	service := NewAvailabilityService(mockRepo, newMockResourceRepository(activeResource))
+
+	// 実行
+	slots, err := service.FindFreeSlots(viewerCtx, AvailabilityParams{
+		ResourceID: activeResource.ID,
+		From:       from,
+		To:         to,
+		Duration:   time.Hour,
+	})
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(queried) != 2 || !queried[0].Equal(from) || !queried[1].Equal(to) {
+		t.Errorf("Expected repository to be queried for the window, got %v", queried)
+	}
+	if len(slots) != 2 {
+		t.Errorf("Expected 2 free slots, got %v", slots)
+	}
+}
+
+func TestAvailabilityService_FindFreeSlots_InvalidParams(t *testing.T) {
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		params  AvailabilityParams
+		wantErr error
+	}{
+		{"期間の指定なし", AvailabilityParams{ResourceID: activeResource.ID, Duration: time.Hour}, ErrInvalidWindow},
+		{"期間が逆", AvailabilityParams{ResourceID: activeResource.ID, From: from, To: from.Add(-time.Hour), Duration: time.Hour}, ErrInvalidWindow},
+		{"期間が長すぎる", AvailabilityParams{ResourceID: activeResource.ID, From: from, To: from.AddDate(2, 0, 0), Duration: time.Hour}, ErrWindowTooLarge},
+		{"長さの指定なし", AvailabilityParams{ResourceID: activeResource.ID, From: from, To: from.Add(time.Hour)}, ErrInvalidDuration},
+		{"期間より長い", AvailabilityParams{ResourceID: activeResource.ID, From: from, To: from.Add(time.Hour), Duration: 2 * time.Hour}, ErrInvalidDuration},
+		{"1日を割り切れない刻み", AvailabilityParams{ResourceID: activeResource.ID, From: from, To: from.Add(time.Hour), Duration: time.Hour, Granularity: 7 * time.Minute}, ErrInvalidGranularity},
+		{"存在しないリソース", AvailabilityParams{ResourceID: "missing", From: from, To: from.Add(time.Hour), Duration: time.Hour}, ErrResourceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewAvailabilityService(newMockReservationRepository(), newMockResourceRepository(activeResource))
+
+			_, err := service.FindFreeSlots(viewerCtx, tt.params)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAvailabilityService_FindFreeSlots_Forbidden(t *testing.T) {
+	// 準備
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	service := NewAvailabilityService(newMockReservationRepository(), newMockResourceRepository(activeResource))
+
+	// 実行 - 匿名のリクエスト
+	_, err := service.FindFreeSlots(context.Background(), AvailabilityParams{ResourceID: activeResource.ID, From: from, To: from.Add(time.Hour), Duration: time.Hour})
+
+	// 検証
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected %v, got %v", ErrForbidden, err)
+	}
+}
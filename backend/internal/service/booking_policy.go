@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/holiday"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+// PolicyRule は予約ポリシーの規則の名前
+type PolicyRule string
+
+const (
+	RuleMinDuration   PolicyRule = "min_duration"
+	RuleMaxDuration   PolicyRule = "max_duration"
+	RuleMaxAdvance    PolicyRule = "max_advance"
+	RuleMinNotice     PolicyRule = "min_notice"
+	RuleNoPast        PolicyRule = "no_past"
+	RuleSlotAlignment PolicyRule = "slot_alignment"
+	RuleHoliday       PolicyRule = "holiday"
+	// RuleBusinessHours と RuleBlackout は CalendarService の営業時間と休業期間の規則
+	RuleBusinessHours PolicyRule = "business_hours"
+	RuleBlackout      PolicyRule = "blackout"
+)
+
+// BookingPolicy は予約の作成・変更時に適用する規則。ゼロ値のフィールドはその規則を適用しない。
+type BookingPolicy struct {
+	// MinDuration と MaxDuration は予約の長さの下限と上限
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	// MaxAdvance は現在から予約の開始までの期間の上限（何日先まで予約できるか）
+	MaxAdvance time.Duration
+	// MinNotice は現在から予約の開始までに必要な猶予
+	MinNotice time.Duration
+	// RejectPast が true の場合、開始時刻が過去の予約を受け付けない
+	RejectPast bool
+	// SlotGranularity を指定すると、開始・終了時刻がこの刻み（開始時刻のタイムゾーンの0時基準）にそろっている必要がある
+	SlotGranularity time.Duration
+	// RejectHolidays が true の場合、日本の祝日・休日（JST の日付）に掛かる予約を受け付けない
+	RejectHolidays bool
+}
+
+// Validate はポリシーの設定が矛盾していないかを確認する
+func (p BookingPolicy) Validate() error {
+	if p.MinDuration < 0 || p.MaxDuration < 0 || p.MaxAdvance < 0 || p.MinNotice < 0 || p.SlotGranularity < 0 {
+		return fmt.Errorf("booking policy durations must not be negative")
+	}
+	if p.MaxDuration > 0 && p.MinDuration > p.MaxDuration {
+		return fmt.Errorf("min duration %s exceeds max duration %s", p.MinDuration, p.MaxDuration)
+	}
+	if p.MaxAdvance > 0 && p.MinNotice > p.MaxAdvance {
+		return fmt.Errorf("min notice %s exceeds max advance %s", p.MinNotice, p.MaxAdvance)
+	}
+	if p.SlotGranularity > 0 && (p.SlotGranularity < time.Minute || (24*time.Hour)%p.SlotGranularity != 0) {
+		return fmt.Errorf("slot granularity %s must be at least 1m and divide 24h", p.SlotGranularity)
+	}
+	return nil
+}
+
+// PolicyViolation は違反した規則とその内容
+type PolicyViolation struct {
+	Rule    PolicyRule
+	Message string
+}
+
+// Check は now の時点で startTime〜endTime の予約がポリシーを満たすかを確認し、違反した規則を全て返す
+func (p BookingPolicy) Check(startTime, endTime, now time.Time) []PolicyViolation {
+	var violations []PolicyViolation
+	add := func(rule PolicyRule, format string, args ...any) {
+		violations = append(violations, PolicyViolation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	duration := endTime.Sub(startTime)
+	if p.MinDuration > 0 && duration < p.MinDuration {
+		add(RuleMinDuration, "reservation must be at least %s long", p.MinDuration)
+	}
+	if p.MaxDuration > 0 && duration > p.MaxDuration {
+		add(RuleMaxDuration, "reservation must be at most %s long", p.MaxDuration)
+	}
+
+	lead := startTime.Sub(now)
+	if p.RejectPast && lead < 0 {
+		add(RuleNoPast, "reservation must not start in the past")
+	} else if p.MinNotice > 0 && lead < p.MinNotice {
+		add(RuleMinNotice, "reservation must start at least %s from now", p.MinNotice)
+	}
+	if p.MaxAdvance > 0 && lead > p.MaxAdvance {
+		add(RuleMaxAdvance, "reservation must start within %s from now", p.MaxAdvance)
+	}
+
+	if p.SlotGranularity > 0 {
+		loc := startTime.Location()
+		if !alignDown(startTime, p.SlotGranularity, loc).Equal(startTime) || !alignDown(endTime, p.SlotGranularity, loc).Equal(endTime) {
+			add(RuleSlotAlignment, "start and end times must be aligned to %s slots", p.SlotGranularity)
+		}
+	}
+
+	if p.RejectHolidays {
+		if h, ok := firstHoliday(startTime, endTime); ok {
+			add(RuleHoliday, "reservation must not fall on a holiday (%s %s)", h.Date.Format("2006-01-02"), h.Name)
+		}
+	}
+
+	return violations
+}
+
+// firstHoliday は [startTime, endTime) に掛かる日（JST）のうち最初の祝日・休日を返す
+func firstHoliday(startTime, endTime time.Time) (holiday.Holiday, bool) {
+	start := startTime.In(holiday.JST)
+	last := endTime.Add(-time.Nanosecond).In(holiday.JST)
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, holiday.JST); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if h, ok := holiday.Lookup(day); ok {
+			return h, true
+		}
+	}
+	return holiday.Holiday{}, false
+}
+
+// checkPolicy は予約がポリシーを満たさないか休業時間と重なる場合に *PolicyViolationError を返す。
+// closed は予約の時間帯を含む期間について closedPeriods で取得した休業時間。
+func (s *ReservationService) checkPolicy(startTime, endTime, now time.Time, closed []model.ClosedPeriod) error {
+	violations := s.policy.Check(startTime, endTime, now)
+	violations = append(violations, closedViolations(closed, startTime, endTime)...)
+	if len(violations) > 0 {
+		return &PolicyViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// checkUpdatePolicy は予約の変更で、変更前が original の回 u がポリシーを満たすかを確認する。
+// 時間帯を変えない回は長さや現在時刻に対する規則を確認し直さず、リソースを変える場合だけ休業時間を確認する。
+// まとめてずらす回のうち既に始まった回は、target（変更を指定した回）でなければ現在時刻に対する規則を確認しない。
+func (s *ReservationService) checkUpdatePolicy(u, original *model.Reservation, target bool, now time.Time, closed []model.ClosedPeriod) error {
+	moved := !u.StartTime.Equal(original.StartTime) || !u.EndTime.Equal(original.EndTime)
+	if !moved && u.ResourceID == original.ResourceID {
+		return nil
+	}
+	var violations []PolicyViolation
+	if moved {
+		violations = s.policy.Check(u.StartTime, u.EndTime, now)
+		if !target && original.StartTime.Before(now) {
+			violations = slices.DeleteFunc(violations, func(v PolicyViolation) bool { return timeRule(v.Rule) })
+		}
+	}
+	violations = append(violations, closedViolations(closed, u.StartTime, u.EndTime)...)
+	if len(violations) > 0 {
+		return &PolicyViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// timeRule は規則が現在時刻に対する規則（過去・猶予・何日先まで）かを返す
+func timeRule(rule PolicyRule) bool {
+	return rule == RuleNoPast || rule == RuleMinNotice || rule == RuleMaxAdvance
+}
+
+// closedPeriods はリソースの [from, to) の休業時間を返す。営業カレンダーが設定されていない場合は空を返す。
+func (s *ReservationService) closedPeriods(resourceID string, from, to time.Time) ([]model.ClosedPeriod, error) {
+	if s.calendar == nil {
+		return nil, nil
+	}
+	return s.calendar.closedPeriods(resourceID, from, to)
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+func TestBookingPolicy_Check(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, jst)
+	policy := BookingPolicy{
+		MinDuration:     30 * time.Minute,
+		MaxDuration:     4 * time.Hour,
+		MaxAdvance:      30 * 24 * time.Hour,
+		MinNotice:       time.Hour,
+		RejectPast:      true,
+		SlotGranularity: 15 * time.Minute,
+	}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  []PolicyRule
+	}{
+		{
+			name:  "全ての規則を満たす",
+			start: now.Add(2 * time.Hour),
+			end:   now.Add(3 * time.Hour),
+		},
+		{
+			name:  "短すぎる",
+			start: now.Add(2 * time.Hour),
+			end:   now.Add(2*time.Hour + 15*time.Minute),
+			want:  []PolicyRule{RuleMinDuration},
+		},
+		{
+			name:  "長すぎる",
+			start: now.Add(2 * time.Hour),
+			end:   now.Add(7 * time.Hour),
+			want:  []PolicyRule{RuleMaxDuration},
+		},
+		{
+			name:  "先すぎる",
+			start: now.AddDate(0, 0, 31),
+			end:   now.AddDate(0, 0, 31).Add(time.Hour),
+			want:  []PolicyRule{RuleMaxAdvance},
+		},
+		{
+			name:  "猶予が足りない",
+			start: now.Add(30 * time.Minute),
+			end:   now.Add(90 * time.Minute),
+			want:  []PolicyRule{RuleMinNotice},
+		},
+		{
+			name:  "過去の予約は猶予ではなく過去として報告する",
+			start: now.Add(-time.Hour),
+			end:   now,
+			want:  []PolicyRule{RuleNoPast},
+		},
+		{
+			name:  "刻みにそろっていない",
+			start: now.Add(2*time.Hour + 10*time.Minute),
+			end:   now.Add(3*time.Hour + 10*time.Minute),
+			want:  []PolicyRule{RuleSlotAlignment},
+		},
+		{
+			name:  "複数の違反を全て返す",
+			start: now.Add(-time.Hour + 5*time.Minute),
+			end:   now.Add(-time.Hour + 10*time.Minute),
+			want:  []PolicyRule{RuleMinDuration, RuleNoPast, RuleSlotAlignment},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 実行
+			violations := policy.Check(tt.start, tt.end, now)
+
+			// 検証
+			var got []PolicyRule
+			for _, v := range violations {
+				got = append(got, v.Rule)
+				if v.Message == "" {
+					t.Errorf("Expected message for rule %s", v.Rule)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected violations %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBookingPolicy_CheckHolidays(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, jst)
+	policy := BookingPolicy{RejectHolidays: true}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  bool
+	}{
+		{name: "平日", start: time.Date(2024, 5, 7, 10, 0, 0, 0, jst), end: time.Date(2024, 5, 7, 11, 0, 0, 0, jst)},
+		{name: "祝日", start: time.Date(2024, 5, 3, 10, 0, 0, 0, jst), end: time.Date(2024, 5, 3, 11, 0, 0, 0, jst), want: true},
+		{name: "振替休日", start: time.Date(2024, 5, 6, 10, 0, 0, 0, jst), end: time.Date(2024, 5, 6, 11, 0, 0, 0, jst), want: true},
+		{name: "UTCで指定しても日本の日付で判定する", start: time.Date(2024, 5, 2, 16, 0, 0, 0, time.UTC), end: time.Date(2024, 5, 2, 17, 0, 0, 0, time.UTC), want: true},
+		{name: "前日から祝日に掛かる", start: time.Date(2024, 5, 2, 23, 0, 0, 0, jst), end: time.Date(2024, 5, 3, 1, 0, 0, 0, jst), want: true},
+		{name: "祝日の0時ちょうどに終わる", start: time.Date(2024, 5, 2, 23, 0, 0, 0, jst), end: time.Date(2024, 5, 3, 0, 0, 0, 0, jst)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := policy.Check(tt.start, tt.end, now)
+			got := len(violations) == 1 && violations[0].Rule == RuleHoliday
+			if got != tt.want {
+				t.Errorf("Expected holiday violation %v, got %v", tt.want, violations)
+			}
+		})
+	}
+}
+
+func TestBookingPolicy_CheckZeroValue(t *testing.T) {
+	// ゼロ値のポリシーはどの規則も適用しない
+	now := time.Now()
+	if violations := (BookingPolicy{}).Check(now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0), now); len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+}
+
+func TestBookingPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  BookingPolicy
+		wantErr bool
+	}{
+		{name: "ゼロ値", policy: BookingPolicy{}},
+		{name: "正しい設定", policy: BookingPolicy{MinDuration: time.Hour, MaxDuration: 2 * time.Hour, SlotGranularity: 30 * time.Minute}},
+		{name: "負の値", policy: BookingPolicy{MinNotice: -time.Hour}, wantErr: true},
+		{name: "下限が上限より長い", policy: BookingPolicy{MinDuration: 3 * time.Hour, MaxDuration: 2 * time.Hour}, wantErr: true},
+		{name: "猶予が予約可能期間より長い", policy: BookingPolicy{MinNotice: 48 * time.Hour, MaxAdvance: 24 * time.Hour}, wantErr: true},
+		{name: "刻みが1日を割り切れない", policy: BookingPolicy{SlotGranularity: 7 * time.Minute}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReservationService_CreateReservation_PolicyViolation(t *testing.T) {
+	// 準備
+	repo := repository.NewInMemoryReservationRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	service.SetPolicy(BookingPolicy{MaxDuration: time.Hour})
+
+	start := time.Now().Add(24 * time.Hour)
+
+	// 実行
+	_, err := service.CreateReservation(adminCtx, CreateReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  start,
+		EndTime:    start.Add(2 * time.Hour),
+	})
+
+	// 検証
+	var policyErr *PolicyViolationError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Expected PolicyViolationError, got %v", err)
+	}
+	if len(policyErr.Violations) != 1 || policyErr.Violations[0].Rule != RuleMaxDuration {
+		t.Errorf("Expected max_duration violation, got %v", policyErr.Violations)
+	}
+	if all, _ := repo.FindAll(); len(all) != 0 {
+		t.Errorf("Expected no reservation to be created, got %d", len(all))
+	}
+}
+
+func TestReservationService_CreateRecurringReservation_PolicyViolation(t *testing.T) {
+	// 準備
+	repo := repository.NewInMemoryReservationRepository()
+	seriesRepo := repository.NewInMemoryReservationSeriesRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), seriesRepo)
+	service.SetPolicy(BookingPolicy{MaxAdvance: 10 * 24 * time.Hour})
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+
+	// 実行 - 3回目が10日より先になる
+	_, err := service.CreateRecurringReservation(adminCtx, CreateRecurringReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  start,
+		EndTime:    start.Add(time.Hour),
+		Recurrence: "FREQ=WEEKLY;COUNT=3",
+	})
+
+	// 検証 - 1回も予約しない
+	var policyErr *PolicyViolationError
+	if !errors.As(err, &policyErr) || policyErr.Violations[0].Rule != RuleMaxAdvance {
+		t.Fatalf("Expected max_advance violation, got %v", err)
+	}
+	if all, _ := repo.FindAll(); len(all) != 0 {
+		t.Errorf("Expected no reservation to be created, got %d", len(all))
+	}
+}
+
+func TestReservationService_UpdateReservation_PolicyViolation(t *testing.T) {
+	// 準備
+	repo := repository.NewInMemoryReservationRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	service.SetPolicy(BookingPolicy{RejectPast: true})
+
+	start := time.Now().Add(24 * time.Hour)
+	reservation, err := service.CreateReservation(adminCtx, CreateReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  start,
+		EndTime:    start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+
+	// 実行 - 過去に移動する
+	past := time.Now().Add(-2 * time.Hour)
+	end := past.Add(time.Hour)
+	_, err = service.UpdateReservation(adminCtx, reservation.ID, UpdateReservationParams{StartTime: &past, EndTime: &end})
+
+	// 検証
+	var policyErr *PolicyViolationError
+	if !errors.As(err, &policyErr) || policyErr.Violations[0].Rule != RuleNoPast {
+		t.Fatalf("Expected no_past violation, got %v", err)
+	}
+	stored, _ := repo.FindByID(reservation.ID)
+	if !stored.StartTime.Equal(reservation.StartTime) {
+		t.Errorf("Expected reservation to be unchanged, got start %v", stored.StartTime)
+	}
+}
+
+func TestReservationService_UpdateReservation_StartedReservation(t *testing.T) {
+	policy := BookingPolicy{RejectPast: true, MinNotice: time.Hour, MaxAdvance: 30 * 24 * time.Hour}
+	title := "設計レビュー"
+	shift := 30 * time.Minute
+
+	tests := []struct {
+		name      string
+		scope     Scope
+		shift     time.Duration
+		target    int
+		wantShift bool
+		wantRule  PolicyRule
+	}{
+		{name: "進行中の予約の目的だけを変更する", scope: ScopeThis, target: 2},
+		{name: "開始済みのシリーズを全てずらす", scope: ScopeAll, shift: shift, target: 3, wantShift: true},
+		{name: "開始済みの回を指定してずらすとポリシーを確認する", scope: ScopeAll, shift: shift, target: 2, wantRule: RuleNoPast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備 - 2日前から毎日の予約で、3回目が進行中
+			repo := repository.NewInMemoryReservationRepository()
+			service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+			start := time.Now().Add(-30*time.Minute).AddDate(0, 0, -2)
+			result, err := service.CreateRecurringReservation(adminCtx, CreateRecurringReservationParams{
+				ResourceID: activeResource.ID,
+				StartTime:  start,
+				EndTime:    start.Add(time.Hour),
+				Recurrence: "FREQ=DAILY;COUNT=5",
+			})
+			if err != nil {
+				t.Fatalf("Failed to create series: %v", err)
+			}
+			service.SetPolicy(policy)
+			target := result.Reservations[tt.target]
+			params := UpdateReservationParams{Title: &title, Scope: tt.scope}
+			if tt.shift != 0 {
+				newStart, newEnd := target.StartTime.Add(tt.shift), target.EndTime.Add(tt.shift)
+				params.StartTime, params.EndTime = &newStart, &newEnd
+			}
+
+			// 実行
+			_, err = service.UpdateReservation(adminCtx, target.ID, params)
+
+			// 検証
+			if tt.wantRule != "" {
+				var policyErr *PolicyViolationError
+				if !errors.As(err, &policyErr) || policyErr.Violations[0].Rule != tt.wantRule {
+					t.Fatalf("Expected %s violation, got %v", tt.wantRule, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for i, original := range result.Reservations {
+				stored, _ := repo.FindByID(original.ID)
+				want := original.StartTime
+				if tt.wantShift {
+					want = want.Add(tt.shift)
+				}
+				if !stored.StartTime.Equal(want) {
+					t.Errorf("occurrence %d: expected start %v, got %v", i+1, want, stored.StartTime)
+				}
+			}
+		})
+	}
+}
+
+func TestReservationService_CreateReservation_Holiday(t *testing.T) {
+	// 準備
+	repo := repository.NewInMemoryReservationRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	service.SetPolicy(BookingPolicy{RejectHolidays: true})
+	jst := time.FixedZone("JST", 9*60*60)
+	newYearsDay := time.Date(2025, 1, 1, 10, 0, 0, 0, jst)
+
+	// 実行
+	_, err := service.CreateReservation(adminCtx, CreateReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  newYearsDay,
+		EndTime:    newYearsDay.Add(time.Hour),
+	})
+
+	// 検証
+	var policyErr *PolicyViolationError
+	if !errors.As(err, &policyErr) || policyErr.Violations[0].Rule != RuleHoliday {
+		t.Fatalf("Expected holiday violation, got %v", err)
+	}
+
+	// 翌日は予約できる
+	if _, err := service.CreateReservation(adminCtx, CreateReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  newYearsDay.AddDate(0, 0, 1),
+		EndTime:    newYearsDay.AddDate(0, 0, 1).Add(time.Hour),
+	}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+// feedTokenBytes はフィードのトークンの乱数のバイト数
+const feedTokenBytes = 32
+
+// CalendarFeedService はカレンダーアプリが購読するフィードのトークンを管理する
+type CalendarFeedService struct {
+	repo         repository.CalendarFeedRepository
+	userRepo     repository.UserRepository
+	resourceRepo repository.ResourceRepository
+}
+
+func NewCalendarFeedService(repo repository.CalendarFeedRepository, userRepo repository.UserRepository, resourceRepo repository.ResourceRepository) *CalendarFeedService {
+	return &CalendarFeedService{repo: repo, userRepo: userRepo, resourceRepo: resourceRepo}
+}
+
+// CreateFeedParams は発行するフィード
+type CreateFeedParams struct {
+	// ResourceID を指定するとそのリソースの予約だけを配信する
+	ResourceID string
+	// CalDAV を指定するとトークンで CalDAV も使えるようにする
+	CalDAV bool
+}
+
+// CreateFeed は ctx のユーザーのフィードを発行し、フィードと URL に含めるトークンを返す。トークンはこの戻り値でしか得られない。
+// ユーザーIDのないプリンシパルや予約を参照できない権限には ErrForbidden、リソースが存在しない場合は ErrResourceNotFound を返す。
+func (s *CalendarFeedService) CreateFeed(ctx context.Context, params CreateFeedParams) (*model.CalendarFeed, string, error) {
+	p, ok := auth.PrincipalFrom(ctx)
+	if !ok || p.UserID == "" || !Allowed(p, ActionViewSchedule, nil) {
+		return nil, "", ErrForbidden
+	}
+	if params.ResourceID != "" {
+		resource, err := s.resourceRepo.FindByID(params.ResourceID)
+		if err != nil {
+			return nil, "", err
+		}
+		if resource == nil {
+			return nil, "", ErrResourceNotFound
+		}
+	}
+
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, "", err
+	}
+	feed := model.NewCalendarFeed(p.UserID, p.Role, params.ResourceID, hashFeedToken(token))
+	feed.CalDAV = params.CalDAV
+	if err := s.repo.Create(feed); err != nil {
+		return nil, "", err
+	}
+	return feed, token, nil
+}
+
+// ListFeeds は ctx のユーザーが発行したフィードを発行順に返す。ユーザーIDのないプリンシパルには ErrForbidden を返す。
+func (s *CalendarFeedService) ListFeeds(ctx context.Context) ([]*model.CalendarFeed, error) {
+	p, ok := auth.PrincipalFrom(ctx)
+	if !ok || p.UserID == "" {
+		return nil, ErrForbidden
+	}
+	return s.repo.FindByUserID(p.UserID)
+}
+
+// DeleteFeed はフィードを削除し、そのトークンでの購読を止める。発行したユーザーと管理者だけが削除できる。
+// 他のユーザーのフィードは存在を明かさないよう、存在しない場合と同じく ErrCalendarFeedNotFound を返す。
+func (s *CalendarFeedService) DeleteFeed(ctx context.Context, id string) error {
+	p, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return ErrForbidden
+	}
+	feed, err := s.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if feed == nil || (feed.UserID != p.UserID && !p.IsAdmin()) {
+		return ErrCalendarFeedNotFound
+	}
+	return s.repo.Delete(id)
+}
+
+// Authenticate はトークンのフィードを探し、フィードを発行したユーザーをプリンシパルとして格納した ctx の子コンテキストを返す。
+// 権限はユーザーの現在の権限で、ユーザーが登録されていない場合は発行時の権限を使う。
+// トークンが無効な場合は ErrCalendarFeedNotFound を返す。
+func (s *CalendarFeedService) Authenticate(ctx context.Context, token string) (context.Context, *model.CalendarFeed, error) {
+	feed, err := s.repo.FindByTokenHash(hashFeedToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if feed == nil {
+		return nil, nil, ErrCalendarFeedNotFound
+	}
+
+	role := feed.Role
+	user, err := s.userRepo.FindByID(feed.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user != nil {
+		role = user.Role
+	}
+	return auth.WithPrincipal(ctx, auth.Principal{UserID: feed.UserID, Role: role}), feed, nil
+}
+
+// newFeedToken は URL に含められるランダムなトークンを返す
+func newFeedToken() (string, error) {
+	b := make([]byte, feedTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashFeedToken は保存・検索に使うトークンの SHA-256 ハッシュを返す
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
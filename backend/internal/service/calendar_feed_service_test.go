@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+func TestCalendarFeedService(t *testing.T) {
+	// 準備
+	users := repository.NewInMemoryUserRepository()
+	member := model.NewUser("山田", "yamada@example.com", model.RoleMember)
+	_ = users.Create(member)
+	feeds := repository.NewInMemoryCalendarFeedRepository()
+	service := NewCalendarFeedService(feeds, users, newMockResourceRepository(activeResource))
+	memberCtx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: member.ID, Role: model.RoleMember})
+
+	// 発行したトークンで発行したユーザーとして認証できる
+	feed, token, err := service.CreateFeed(memberCtx, CreateFeedParams{ResourceID: activeResource.ID, CalDAV: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if token == "" || feed.TokenHash == token || feed.ResourceID != activeResource.ID || !feed.CalDAV {
+		t.Fatalf("Expected a hashed token for %s, got %+v", activeResource.ID, feed)
+	}
+	ctx, found, err := service.Authenticate(context.Background(), token)
+	if err != nil || found.ID != feed.ID {
+		t.Fatalf("Expected to authenticate feed %s, got %v, %v", feed.ID, found, err)
+	}
+	if p, ok := auth.PrincipalFrom(ctx); !ok || p != (auth.Principal{UserID: member.ID, Role: model.RoleMember}) {
+		t.Errorf("Expected principal of %s, got %+v", member.ID, p)
+	}
+
+	// ユーザーの現在の権限で配信する
+	member.Role = model.RoleViewer
+	ctx, _, _ = service.Authenticate(context.Background(), token)
+	if p, _ := auth.PrincipalFrom(ctx); p.Role != model.RoleViewer {
+		t.Errorf("Expected the current role %s, got %s", model.RoleViewer, p.Role)
+	}
+
+	// 他のユーザーは一覧に含まれず、削除もできない
+	otherCtx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: "other", Role: model.RoleMember})
+	if list, _ := service.ListFeeds(otherCtx); len(list) != 0 {
+		t.Errorf("Expected no feeds for other user, got %v", list)
+	}
+	if err := service.DeleteFeed(otherCtx, feed.ID); !errors.Is(err, ErrCalendarFeedNotFound) {
+		t.Errorf("Expected ErrCalendarFeedNotFound, got %v", err)
+	}
+
+	// 削除したフィードのトークンは無効になる
+	if err := service.DeleteFeed(memberCtx, feed.ID); err != nil {
+		t.Fatalf("Failed to delete feed: %v", err)
+	}
+	if _, _, err := service.Authenticate(context.Background(), token); !errors.Is(err, ErrCalendarFeedNotFound) {
+		t.Errorf("Expected ErrCalendarFeedNotFound, got %v", err)
+	}
+}
+
+func TestCalendarFeedService_CreateFeed_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		resourceID string
+		wantErr    error
+	}{
+		{name: "匿名では発行できない", wantErr: ErrForbidden},
+		{name: "ユーザーIDのないプリンシパル", principal: &auth.Principal{Role: model.RoleAdmin}, wantErr: ErrForbidden},
+		{name: "存在しないリソース", principal: &auth.Principal{UserID: "user-1", Role: model.RoleMember}, resourceID: "unknown", wantErr: ErrResourceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			service := NewCalendarFeedService(repository.NewInMemoryCalendarFeedRepository(), repository.NewInMemoryUserRepository(), newMockResourceRepository(activeResource))
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, *tt.principal)
+			}
+
+			// 実行
+			_, _, err := service.CreateFeed(ctx, CreateFeedParams{ResourceID: tt.resourceID})
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
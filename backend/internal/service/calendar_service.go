@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	// 実行環境に tzdata がなくても営業時間のタイムゾーンを解釈できるようにする
+	_ "time/tzdata"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+// DefaultTimeZone は営業時間のタイムゾーンを省略した場合に使うタイムゾーン
+const DefaultTimeZone = "Asia/Tokyo"
+
+type CalendarService struct {
+	repo         repository.CalendarRepository
+	resourceRepo repository.ResourceRepository
+}
+
+func NewCalendarService(repo repository.CalendarRepository, resourceRepo repository.ResourceRepository) *CalendarService {
+	return &CalendarService{repo: repo, resourceRepo: resourceRepo}
+}
+
+// GetWeeklySchedule は営業時間を返す
+func (s *CalendarService) GetWeeklySchedule() (*model.WeeklySchedule, error) {
+	return s.repo.GetWeeklySchedule()
+}
+
+// SetWeeklySchedule は営業時間を置き換える。Hours を空にすると終日営業に戻る。
+// 曜日や時刻の形式が不正な場合、開始が終了より後の場合、同じ曜日の時間帯が重なる場合、
+// タイムゾーンが不明な場合は ErrInvalidBusinessHours、設定を変更できない権限には ErrForbidden を返す。
+func (s *CalendarService) SetWeeklySchedule(ctx context.Context, schedule model.WeeklySchedule) (*model.WeeklySchedule, error) {
+	if err := authorize(ctx, ActionConfigure, nil); err != nil {
+		return nil, err
+	}
+	if schedule.TimeZone == "" {
+		schedule.TimeZone = DefaultTimeZone
+	}
+	if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidBusinessHours, schedule.TimeZone)
+	}
+
+	hours := append([]model.BusinessHours{}, schedule.Hours...)
+	for _, h := range hours {
+		if h.Weekday < time.Sunday || h.Weekday > time.Saturday {
+			return nil, fmt.Errorf("%w: weekday must be 0 (Sunday) to 6 (Saturday)", ErrInvalidBusinessHours)
+		}
+		open, err := model.ParseClock(h.Open)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBusinessHours, err)
+		}
+		closing, err := model.ParseClock(h.Close)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBusinessHours, err)
+		}
+		if open >= closing {
+			return nil, fmt.Errorf("%w: %s %s-%s closes before it opens", ErrInvalidBusinessHours, h.Weekday, h.Open, h.Close)
+		}
+	}
+	// "HH:MM" 形式は文字列の順序と時刻の順序が一致する
+	sort.Slice(hours, func(i, j int) bool {
+		if hours[i].Weekday != hours[j].Weekday {
+			return hours[i].Weekday < hours[j].Weekday
+		}
+		return hours[i].Open < hours[j].Open
+	})
+	for i := 1; i < len(hours); i++ {
+		if hours[i].Weekday == hours[i-1].Weekday && hours[i].Open < hours[i-1].Close {
+			return nil, fmt.Errorf("%w: %s hours overlap", ErrInvalidBusinessHours, hours[i].Weekday)
+		}
+	}
+
+	schedule.Hours = hours
+	if err := s.repo.SaveWeeklySchedule(&schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// CreateBlackoutParams は休業期間の作成内容
+type CreateBlackoutParams struct {
+	// ResourceID が空の場合は全てのリソースに適用する
+	ResourceID string
+	StartTime  time.Time
+	EndTime    time.Time
+	Reason     string
+}
+
+// CreateBlackout は休業期間を作成する。
+// 設定を変更できない権限には ErrForbidden、終了時刻が開始時刻より後でない場合は ErrInvalidTimeRange、
+// リソースが存在しない場合は ErrResourceNotFound を返す。既にある予約は取り消さない。
+func (s *CalendarService) CreateBlackout(ctx context.Context, params CreateBlackoutParams) (*model.Blackout, error) {
+	if err := authorize(ctx, ActionConfigure, nil); err != nil {
+		return nil, err
+	}
+	if !params.EndTime.After(params.StartTime) {
+		return nil, ErrInvalidTimeRange
+	}
+	if params.ResourceID != "" {
+		resource, err := s.resourceRepo.FindByID(params.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		if resource == nil {
+			return nil, ErrResourceNotFound
+		}
+	}
+
+	blackout := model.NewBlackout(params.ResourceID, params.StartTime, params.EndTime, params.Reason)
+	if err := s.repo.CreateBlackout(blackout); err != nil {
+		return nil, err
+	}
+	return blackout, nil
+}
+
+// ListBlackoutsParams は休業期間の一覧の絞り込み条件
+type ListBlackoutsParams struct {
+	// ResourceID を指定すると、そのリソースと全てのリソースに適用される休業期間だけを返す
+	ResourceID string
+	// From と To を指定すると [From, To) と重なる休業期間だけを返す
+	From time.Time
+	To   time.Time
+}
+
+// ListBlackouts は休業期間を開始時刻の昇順で返す。期間の指定が不正な場合は ErrInvalidWindow を返す。
+func (s *CalendarService) ListBlackouts(params ListBlackoutsParams) ([]*model.Blackout, error) {
+	if (params.From.IsZero() != params.To.IsZero()) || (!params.From.IsZero() && !params.To.After(params.From)) {
+		return nil, ErrInvalidWindow
+	}
+
+	blackouts, err := s.repo.FindBlackouts(params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+	if params.ResourceID == "" {
+		return blackouts, nil
+	}
+
+	filtered := make([]*model.Blackout, 0, len(blackouts))
+	for _, b := range blackouts {
+		if b.AppliesTo(params.ResourceID) {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered, nil
+}
+
+// DeleteBlackout は休業期間を削除する。設定を変更できない権限には ErrForbidden、存在しない場合は ErrBlackoutNotFound を返す。
+func (s *CalendarService) DeleteBlackout(ctx context.Context, id string) error {
+	if err := authorize(ctx, ActionConfigure, nil); err != nil {
+		return err
+	}
+	blackout, err := s.repo.FindBlackoutByID(id)
+	if err != nil {
+		return err
+	}
+	if blackout == nil {
+		return ErrBlackoutNotFound
+	}
+	return s.repo.DeleteBlackout(id)
+}
+
+// ClosedPeriodsParams は休業時間の取得条件
+type ClosedPeriodsParams struct {
+	// ResourceID を指定するとそのリソースの休業期間も含める。空の場合は全てのリソースに適用される休業期間だけを含める。
+	ResourceID string
+	From       time.Time
+	To         time.Time
+}
+
+// GetClosedPeriods は [From, To) のうち営業時間外と休業期間を開始時刻の昇順で返す。
+// 営業時間外の連続した時間帯（閉店から翌日の開店までなど）は1つにまとめる。
+// 期間の指定が不正な場合は ErrInvalidWindow、MaxListWindow より長い場合は ErrWindowTooLarge を返す。
+func (s *CalendarService) GetClosedPeriods(params ClosedPeriodsParams) ([]model.ClosedPeriod, error) {
+	if params.From.IsZero() || params.To.IsZero() || !params.To.After(params.From) {
+		return nil, ErrInvalidWindow
+	}
+	if params.To.Sub(params.From) > MaxListWindow {
+		return nil, ErrWindowTooLarge
+	}
+	return s.closedPeriods(params.ResourceID, params.From, params.To)
+}
+
+func (s *CalendarService) closedPeriods(resourceID string, from, to time.Time) ([]model.ClosedPeriod, error) {
+	schedule, err := s.repo.GetWeeklySchedule()
+	if err != nil {
+		return nil, err
+	}
+	closed, err := outsideBusinessHours(schedule, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	blackouts, err := s.repo.FindBlackouts(from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blackouts {
+		if !b.AppliesTo(resourceID) {
+			continue
+		}
+		closed = append(closed, model.ClosedPeriod{
+			StartTime:  maxTime(b.StartTime, from),
+			EndTime:    minTime(b.EndTime, to),
+			Reason:     model.ClosedBlackout,
+			BlackoutID: b.ID,
+			Note:       b.Reason,
+		})
+	}
+
+	sort.SliceStable(closed, func(i, j int) bool {
+		return closed[i].StartTime.Before(closed[j].StartTime)
+	})
+	return closed, nil
+}
+
+// outsideBusinessHours は [from, to) のうち営業時間外の時間帯を返す。営業時間が設定されていない場合は空を返す。
+func outsideBusinessHours(schedule *model.WeeklySchedule, from, to time.Time) ([]model.ClosedPeriod, error) {
+	if len(schedule.Hours) == 0 {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load business hours time zone: %w", err)
+	}
+
+	// 営業時間帯を from の前日から日ごとに並べ、その隙間を営業時間外とする
+	var closed []model.ClosedPeriod
+	cursor := from
+	addClosed := func(end time.Time) {
+		if end.After(cursor) {
+			closed = append(closed, model.ClosedPeriod{StartTime: cursor, EndTime: end, Reason: model.ClosedOutsideBusinessHours})
+		}
+	}
+	local := from.In(loc)
+	for day := time.Date(local.Year(), local.Month(), local.Day()-1, 0, 0, 0, 0, loc); day.Before(to) && cursor.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, h := range schedule.Hours {
+			if h.Weekday != day.Weekday() {
+				continue
+			}
+			// 保存時に検証済みの形式なので、ここでは解析できるものとして扱う
+			open, _ := model.ParseClock(h.Open)
+			closing, _ := model.ParseClock(h.Close)
+			openAt := time.Date(day.Year(), day.Month(), day.Day(), 0, int(open.Minutes()), 0, 0, loc)
+			closeAt := time.Date(day.Year(), day.Month(), day.Day(), 0, int(closing.Minutes()), 0, 0, loc)
+			if !closeAt.After(cursor) {
+				continue
+			}
+			addClosed(minTime(openAt, to))
+			cursor = maxTime(cursor, closeAt)
+		}
+	}
+	addClosed(to)
+
+	return closed, nil
+}
+
+// closedViolations は startTime〜endTime の予約と重なる休業時間をポリシー違反として返す。
+// 営業時間外は1件にまとめ、休業期間はそれぞれ報告する。
+func closedViolations(closed []model.ClosedPeriod, startTime, endTime time.Time) []PolicyViolation {
+	var violations []PolicyViolation
+	outsideHours := false
+	for _, c := range closed {
+		if !c.StartTime.Before(endTime) || !c.EndTime.After(startTime) {
+			continue
+		}
+		switch c.Reason {
+		case model.ClosedOutsideBusinessHours:
+			if !outsideHours {
+				outsideHours = true
+				violations = append(violations, PolicyViolation{Rule: RuleBusinessHours, Message: "reservation must be within business hours"})
+			}
+		case model.ClosedBlackout:
+			message := fmt.Sprintf("reservation overlaps a blackout period from %s to %s",
+				c.StartTime.Format(time.RFC3339), c.EndTime.Format(time.RFC3339))
+			if c.Note != "" {
+				message += " (" + c.Note + ")"
+			}
+			violations = append(violations, PolicyViolation{Rule: RuleBlackout, Message: message})
+		}
+	}
+	return violations
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+// weekdayHours は月〜金の open〜close を営業時間にする
+func weekdayHours(open, close string) []model.BusinessHours {
+	var hours []model.BusinessHours
+	for d := time.Monday; d <= time.Friday; d++ {
+		hours = append(hours, model.BusinessHours{Weekday: d, Open: open, Close: close})
+	}
+	return hours
+}
+
+func newTestCalendar(t *testing.T, hours []model.BusinessHours) (*CalendarService, *repository.InMemoryCalendarRepository) {
+	t.Helper()
+	repo := repository.NewInMemoryCalendarRepository()
+	calendar := NewCalendarService(repo, newMockResourceRepository(activeResource))
+	if _, err := calendar.SetWeeklySchedule(adminCtx, model.WeeklySchedule{TimeZone: "Asia/Tokyo", Hours: hours}); err != nil {
+		t.Fatalf("Failed to set business hours: %v", err)
+	}
+	return calendar, repo
+}
+
+func TestCalendarService_SetWeeklySchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule model.WeeklySchedule
+		wantErr  bool
+	}{
+		{name: "平日9時〜18時", schedule: model.WeeklySchedule{TimeZone: "Asia/Tokyo", Hours: weekdayHours("09:00", "18:00")}},
+		{name: "終日営業（空）", schedule: model.WeeklySchedule{}},
+		{name: "24:00まで", schedule: model.WeeklySchedule{Hours: []model.BusinessHours{{Weekday: time.Saturday, Open: "00:00", Close: "24:00"}}}},
+		{name: "昼休みで分ける", schedule: model.WeeklySchedule{Hours: []model.BusinessHours{
+			{Weekday: time.Monday, Open: "13:00", Close: "18:00"},
+			{Weekday: time.Monday, Open: "09:00", Close: "12:00"},
+		}}},
+		{name: "不明なタイムゾーン", schedule: model.WeeklySchedule{TimeZone: "Mars/Olympus", Hours: weekdayHours("09:00", "18:00")}, wantErr: true},
+		{name: "曜日が範囲外", schedule: model.WeeklySchedule{Hours: []model.BusinessHours{{Weekday: 7, Open: "09:00", Close: "18:00"}}}, wantErr: true},
+		{name: "時刻の形式が不正", schedule: model.WeeklySchedule{Hours: []model.BusinessHours{{Weekday: time.Monday, Open: "9:00", Close: "18:00"}}}, wantErr: true},
+		{name: "開店が閉店より後", schedule: model.WeeklySchedule{Hours: []model.BusinessHours{{Weekday: time.Monday, Open: "18:00", Close: "09:00"}}}, wantErr: true},
+		{name: "同じ曜日の時間帯が重なる", schedule: model.WeeklySchedule{Hours: []model.BusinessHours{
+			{Weekday: time.Monday, Open: "09:00", Close: "13:00"},
+			{Weekday: time.Monday, Open: "12:00", Close: "18:00"},
+		}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			service := NewCalendarService(repository.NewInMemoryCalendarRepository(), newMockResourceRepository(activeResource))
+
+			// 実行
+			saved, err := service.SetWeeklySchedule(adminCtx, tt.schedule)
+
+			// 検証
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidBusinessHours) {
+					t.Errorf("Expected ErrInvalidBusinessHours, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if saved.TimeZone == "" {
+				t.Error("Expected time zone to default")
+			}
+			for i := 1; i < len(saved.Hours); i++ {
+				if saved.Hours[i].Weekday == saved.Hours[i-1].Weekday && saved.Hours[i].Open < saved.Hours[i-1].Open {
+					t.Errorf("Expected hours to be sorted, got %v", saved.Hours)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendarService_GetClosedPeriods(t *testing.T) {
+	// 準備 - 平日9時〜18時（日本時間）と月曜日の昼の点検
+	service, _ := newTestCalendar(t, weekdayHours("09:00", "18:00"))
+	jst := time.FixedZone("JST", 9*60*60)
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, 4, day, hour, 0, 0, 0, jst)
+	}
+	blackout, err := service.CreateBlackout(adminCtx, CreateBlackoutParams{StartTime: at(8, 12), EndTime: at(8, 13), Reason: "点検"})
+	if err != nil {
+		t.Fatalf("Failed to create blackout: %v", err)
+	}
+	// 別のリソースの休業期間は含めない
+	otherResource := model.NewResource("会議室B", "", 4, true)
+	service.resourceRepo = newMockResourceRepository(activeResource, otherResource)
+	if _, err := service.CreateBlackout(adminCtx, CreateBlackoutParams{ResourceID: otherResource.ID, StartTime: at(8, 14), EndTime: at(8, 15)}); err != nil {
+		t.Fatalf("Failed to create blackout: %v", err)
+	}
+
+	// 実行 - 2024-04-05（金）〜 2024-04-09（火）0時
+	closed, err := service.GetClosedPeriods(ClosedPeriodsParams{ResourceID: activeResource.ID, From: at(5, 0), To: at(9, 0)})
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []model.ClosedPeriod{
+		{StartTime: at(5, 0), EndTime: at(5, 9), Reason: model.ClosedOutsideBusinessHours},
+		{StartTime: at(5, 18), EndTime: at(8, 9), Reason: model.ClosedOutsideBusinessHours},
+		{StartTime: at(8, 12), EndTime: at(8, 13), Reason: model.ClosedBlackout, BlackoutID: blackout.ID, Note: "点検"},
+		{StartTime: at(8, 18), EndTime: at(9, 0), Reason: model.ClosedOutsideBusinessHours},
+	}
+	if !slices.EqualFunc(closed, want, func(a, b model.ClosedPeriod) bool {
+		return a.StartTime.Equal(b.StartTime) && a.EndTime.Equal(b.EndTime) && a.Reason == b.Reason && a.BlackoutID == b.BlackoutID && a.Note == b.Note
+	}) {
+		t.Errorf("Expected %v, got %v", want, closed)
+	}
+}
+
+func TestCalendarService_GetClosedPeriods_NoHours(t *testing.T) {
+	// 準備 - 営業時間を設定しない
+	service := NewCalendarService(repository.NewInMemoryCalendarRepository(), newMockResourceRepository(activeResource))
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	// 実行
+	closed, err := service.GetClosedPeriods(ClosedPeriodsParams{From: from, To: from.AddDate(0, 0, 7)})
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(closed) != 0 {
+		t.Errorf("Expected no closed periods, got %v", closed)
+	}
+
+	if _, err := service.GetClosedPeriods(ClosedPeriodsParams{From: from}); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("Expected ErrInvalidWindow, got %v", err)
+	}
+	if _, err := service.GetClosedPeriods(ClosedPeriodsParams{From: from, To: from.Add(MaxListWindow + time.Hour)}); !errors.Is(err, ErrWindowTooLarge) {
+		t.Errorf("Expected ErrWindowTooLarge, got %v", err)
+	}
+}
+
+func TestCalendarService_Blackouts(t *testing.T) {
+	// 準備
+	service, _ := newTestCalendar(t, nil)
+	start := time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+
+	// 実行・検証
+	if _, err := service.CreateBlackout(adminCtx, CreateBlackoutParams{StartTime: start, EndTime: start}); !errors.Is(err, ErrInvalidTimeRange) {
+		t.Errorf("Expected ErrInvalidTimeRange, got %v", err)
+	}
+	if _, err := service.CreateBlackout(adminCtx, CreateBlackoutParams{ResourceID: "unknown", StartTime: start, EndTime: start.Add(time.Hour)}); !errors.Is(err, ErrResourceNotFound) {
+		t.Errorf("Expected ErrResourceNotFound, got %v", err)
+	}
+
+	blackout, err := service.CreateBlackout(adminCtx, CreateBlackoutParams{ResourceID: activeResource.ID, StartTime: start, EndTime: start.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	listed, err := service.ListBlackouts(ListBlackoutsParams{ResourceID: "other"})
+	if err != nil || len(listed) != 0 {
+		t.Errorf("Expected no blackouts for other resource, got %v, %v", listed, err)
+	}
+	listed, err = service.ListBlackouts(ListBlackoutsParams{ResourceID: activeResource.ID})
+	if err != nil || len(listed) != 1 {
+		t.Errorf("Expected 1 blackout, got %v, %v", listed, err)
+	}
+
+	if err := service.DeleteBlackout(adminCtx, blackout.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := service.DeleteBlackout(adminCtx, blackout.ID); !errors.Is(err, ErrBlackoutNotFound) {
+		t.Errorf("Expected ErrBlackoutNotFound, got %v", err)
+	}
+}
+
+func TestReservationService_CreateReservation_Calendar(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, 4, day, hour, min, 0, 0, jst)
+	}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  []PolicyRule
+	}{
+		{name: "営業時間内", start: at(8, 9, 0), end: at(8, 10, 0)},
+		{name: "閉店時刻ちょうどに終わる", start: at(8, 17, 0), end: at(8, 18, 0)},
+		{name: "開店前から", start: at(8, 8, 30), end: at(8, 9, 30), want: []PolicyRule{RuleBusinessHours}},
+		{name: "休業日（土曜日）", start: at(6, 10, 0), end: at(6, 11, 0), want: []PolicyRule{RuleBusinessHours}},
+		{name: "休業期間と重なる", start: at(9, 11, 30), end: at(9, 12, 30), want: []PolicyRule{RuleBlackout}},
+		{name: "閉店後から休業期間まで", start: at(9, 17, 0), end: at(10, 10, 0), want: []PolicyRule{RuleBlackout, RuleBusinessHours}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備 - 平日9時〜18時と、4/9 12時〜4/10 12時の休業期間
+			calendar, _ := newTestCalendar(t, weekdayHours("09:00", "18:00"))
+			if _, err := calendar.CreateBlackout(adminCtx, CreateBlackoutParams{StartTime: at(9, 12, 0), EndTime: at(10, 12, 0), Reason: "停電"}); err != nil {
+				t.Fatalf("Failed to create blackout: %v", err)
+			}
+			repo := repository.NewInMemoryReservationRepository()
+			service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+			service.SetCalendar(calendar)
+
+			// 実行
+			_, err := service.CreateReservation(adminCtx, CreateReservationParams{ResourceID: activeResource.ID, StartTime: tt.start, EndTime: tt.end})
+
+			// 検証
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			var policyErr *PolicyViolationError
+			if !errors.As(err, &policyErr) {
+				t.Fatalf("Expected PolicyViolationError, got %v", err)
+			}
+			var got []PolicyRule
+			for _, v := range policyErr.Violations {
+				got = append(got, v.Rule)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected violations %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReservationService_UpdateReservation_Calendar(t *testing.T) {
+	// 準備
+	jst := time.FixedZone("JST", 9*60*60)
+	start := time.Date(2024, 4, 8, 10, 0, 0, 0, jst) // 月曜日
+	calendar, _ := newTestCalendar(t, weekdayHours("09:00", "18:00"))
+	repo := repository.NewInMemoryReservationRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	service.SetCalendar(calendar)
+	reservation, err := service.CreateReservation(adminCtx, CreateReservationParams{ResourceID: activeResource.ID, StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+
+	// 実行 - 日曜日に移動する
+	sunday := start.AddDate(0, 0, -1)
+	end := sunday.Add(time.Hour)
+	_, err = service.UpdateReservation(adminCtx, reservation.ID, UpdateReservationParams{StartTime: &sunday, EndTime: &end})
+
+	// 検証
+	var policyErr *PolicyViolationError
+	if !errors.As(err, &policyErr) || policyErr.Violations[0].Rule != RuleBusinessHours {
+		t.Fatalf("Expected business_hours violation, got %v", err)
+	}
+}
+
+func TestAvailabilityService_FindFreeSlots_Calendar(t *testing.T) {
+	// 準備 - 平日9時〜18時、昼休み12時〜13時は休業期間
+	jst := time.FixedZone("JST", 9*60*60)
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, 4, day, hour, 0, 0, 0, jst)
+	}
+	calendar, _ := newTestCalendar(t, weekdayHours("09:00", "18:00"))
+	if _, err := calendar.CreateBlackout(adminCtx, CreateBlackoutParams{StartTime: at(8, 12), EndTime: at(8, 13)}); err != nil {
+		t.Fatalf("Failed to create blackout: %v", err)
+	}
+	repo := repository.NewInMemoryReservationRepository()
+	if err := repo.Create(model.NewReservation(activeResource.ID, at(8, 15), at(8, 16))); err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+	service := NewAvailabilityService(repo, newMockResourceRepository(activeResource))
+	service.SetCalendar(calendar)
+
+	// 実行 - 日曜日から月曜日まで
+	slots, err := service.FindFreeSlots(viewerCtx, AvailabilityParams{ResourceID: activeResource.ID, From: at(7, 0), To: at(9, 0), Duration: time.Hour})
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []model.TimeRange{
+		{StartTime: at(8, 9), EndTime: at(8, 12)},
+		{StartTime: at(8, 13), EndTime: at(8, 15)},
+		{StartTime: at(8, 16), EndTime: at(8, 18)},
+	}
+	if !slices.EqualFunc(slots, want, func(a, b model.TimeRange) bool {
+		return a.StartTime.Equal(b.StartTime) && a.EndTime.Equal(b.EndTime)
+	}) {
+		t.Errorf("Expected %v, got %v", want, slots)
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+var (
+	// ErrReservationNotFound は指定された予約が存在しないことを表す
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrInvalidTimeRange は終了時刻が開始時刻より後になっていないことを表す
+	ErrInvalidTimeRange = errors.New("end time must be after start time")
+	// ErrInvalidWindow は一覧取得の期間指定が不正（片方だけの指定や from >= to）であることを表す
+	ErrInvalidWindow = errors.New("invalid time window")
+	// ErrWindowTooLarge は一覧取得の期間が MaxListWindow を超えていることを表す
+	ErrWindowTooLarge = errors.New("time window is too large")
+	// ErrInvalidCursor は予約一覧のカーソルが不正か、並べ替えの条件と合わないことを表す
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidLimit は予約一覧の件数の指定が不正であることを表す
+	ErrInvalidLimit = errors.New("invalid limit")
+	// ErrInvalidSort は予約一覧の並べ替えの項目が未定義であることを表す
+	ErrInvalidSort = errors.New("invalid sort key")
+	// ErrInvalidDuration は空き時間の検索や予約一覧で指定した長さが不正であることを表す
+	ErrInvalidDuration = errors.New("invalid duration")
+	// ErrInvalidGranularity は空き時間の検索で指定した刻みが不正であることを表す
+	ErrInvalidGranularity = errors.New("invalid granularity")
+	// ErrResourceNotFound は指定されたリソースが存在しないことを表す
+	ErrResourceNotFound = errors.New("resource not found")
+	// ErrResourceInactive は指定されたリソースが予約を受け付けていないことを表す
+	ErrResourceInactive = errors.New("resource is not active")
+	// ErrInvalidRecurrence は繰り返し予約の RRULE が不正であることを表す
+	ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+	// ErrSeriesTooLong は繰り返し予約の期間が MaxSeriesSpan を超えていることを表す
+	ErrSeriesTooLong = errors.New("recurrence spans too long a period")
+	// ErrSeriesNotFound は指定された繰り返し予約が存在しないことを表す
+	ErrSeriesNotFound = errors.New("reservation series not found")
+	// ErrInvalidScope は繰り返し予約の変更・削除の範囲の指定が不正であることを表す
+	ErrInvalidScope = errors.New("invalid scope")
+	// ErrInvalidStatus は未定義の予約の状態が指定されたことを表す
+	ErrInvalidStatus = errors.New("invalid reservation status")
+	// ErrInvalidStatusTransition は予約の現在の状態から指定された状態に変更できないことを表す
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+	// ErrVersionConflict は予約が指定された版から変更されているか、保存までの間に他の操作で変更されたことを表す
+	ErrVersionConflict = repository.ErrVersionConflict
+	// ErrVersionRequired は既存の予約を版を指定せずに変更しようとしたことを表す
+	ErrVersionRequired = errors.New("version is required to change an existing reservation")
+	// ErrReservationNotEditable は取り消しや完了などにより予約を変更できないことを表す
+	ErrReservationNotEditable = errors.New("reservation can no longer be changed")
+	// ErrInvalidBusinessHours は営業時間の設定が不正であることを表す
+	ErrInvalidBusinessHours = errors.New("invalid business hours")
+	// ErrBlackoutNotFound は指定された休業期間が存在しないことを表す
+	ErrBlackoutNotFound = errors.New("blackout period not found")
+	// ErrForbidden は呼び出し元のユーザーに操作の権限がないことを表す
+	ErrForbidden = errors.New("forbidden")
+	// ErrUserNotFound は指定されたユーザーが存在しないことを表す
+	ErrUserNotFound = errors.New("user not found")
+	// ErrEmailTaken はメールアドレスが既に他のユーザーに使われていることを表す
+	ErrEmailTaken = errors.New("email is already registered")
+	// ErrInvalidRole は未定義のユーザーの権限が指定されたことを表す
+	ErrInvalidRole = errors.New("invalid user role")
+	// ErrResourceInUse は予約が残っているためリソースを削除できないことを表す
+	ErrResourceInUse = errors.New("resource has reservations")
+	// ErrExportTooLarge は書き出す予約が MaxExportReservations 件を超えていることを表す
+	ErrExportTooLarge = errors.New("too many reservations to export")
+	// ErrImportTooLarge は取り込むイベントが MaxImportEvents 件を超えていることを表す
+	ErrImportTooLarge = errors.New("too many events to import")
+	// ErrUnsupportedEvent はカレンダーのイベントが予約として保存できない種類（繰り返しや取り消されたイベントなど）であることを表す
+	ErrUnsupportedEvent = errors.New("unsupported event")
+	// ErrUIDConflict はイベントの UID が他のカレンダーの予約か取り消された予約に使われていることを表す
+	ErrUIDConflict = errors.New("UID is used by another reservation")
+	// ErrCalendarFeedNotFound は指定されたカレンダーのフィードが存在しないか、トークンが無効であることを表す
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+	// ErrWebhookNotFound は指定された Webhook の登録が存在しないことを表す
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookDeliveryNotFound は指定された Webhook の配送が存在しないことを表す
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrInvalidWebhookURL は Webhook の URL が http か https の絶対 URL でないことを表す
+	ErrInvalidWebhookURL = errors.New("webhook URL must be an absolute http or https URL")
+	// ErrInvalidWebhookEvents は Webhook のイベントの種類が指定されていないか、未定義の種類を含むことを表す
+	ErrInvalidWebhookEvents = errors.New("invalid webhook events")
+)
+
+// ConflictError は既存の予約と時間帯が重なるために予約できなかったことを表す
+type ConflictError struct {
+	Conflicts []*model.Reservation
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("reservation overlaps with %d existing reservation(s)", len(e.Conflicts))
+}
+
+// InvalidDetailsError は予約の目的か参加者が長すぎるか形式が不正であることを表す
+type InvalidDetailsError struct {
+	// Field は不正な項目（title / description / attendees）
+	Field  string
+	Reason string
+}
+
+func (e *InvalidDetailsError) Error() string {
+	return e.Field + " " + e.Reason
+}
+
+// PolicyViolationError は予約がポリシーに違反していることを表す
+type PolicyViolationError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PolicyViolationError) Error() string {
+	rules := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		rules[i] = string(v.Rule)
+	}
+	return "reservation violates booking policy: " + strings.Join(rules, ", ")
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+// reservationCursor は予約一覧の続きを取得するカーソルの中身。
+// 並べ替えの条件も含め、別の条件で取得した一覧のカーソルを受け付けないようにする。
+type reservationCursor struct {
+	SortBy     repository.ReservationSortKey `json:"sort"`
+	Descending bool                          `json:"desc,omitempty"`
+	Value      time.Time                     `json:"value"`
+	ID         string                        `json:"id"`
+}
+
+// encodeCursor は query で並べ替えた一覧の reservation の次から取得するカーソルを返す
+func encodeCursor(query repository.ReservationQuery, reservation *model.Reservation) string {
+	data, _ := json.Marshal(reservationCursor{
+		SortBy:     query.SortBy,
+		Descending: query.Descending,
+		Value:      query.SortValue(reservation),
+		ID:         reservation.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor は cursor を query の一覧での位置に変換する。
+// 形式が不正な場合や query と並べ替えの条件が異なる場合は ErrInvalidCursor を返す。
+func decodeCursor(cursor string, query repository.ReservationQuery) (*repository.ReservationCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c reservationCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" || c.Value.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	if c.SortBy != query.SortBy || c.Descending != query.Descending {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.ReservationCursor{Value: c.Value, ID: c.ID}, nil
+}
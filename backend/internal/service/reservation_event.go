@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/ical"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+// イベントの操作は、リソースごとのカレンダーの iCalendar のイベントとして予約を扱う（CalDAV）。
+// イベントは UID で識別し、取り消された予約はカレンダーにないものとして扱う。
+
+// PutEventParams はカレンダーに保存するイベント
+type PutEventParams struct {
+	// ResourceID はイベントを保存するカレンダーのリソース
+	ResourceID string
+	Event      ical.Event
+	// Version を指定すると、UID の予約が存在してその版と一致する場合だけ保存する。0 の場合は版を確認しない。
+	Version int64
+	// CreateOnly の場合は UID の予約が存在しない場合だけ作成する
+	CreateOnly bool
+	// RequireVersion の場合は UID の予約が存在すると ErrVersionRequired を返す（版を確認しない上書きを防ぐ）
+	RequireVersion bool
+}
+
+// GetEvent はリソースのカレンダーにある UID の予約を返す。予約の詳細を参照できない権限には埋まっている時間帯だけを返す。
+// 空き状況も参照できない場合は ErrForbidden、カレンダーに UID の予約がない場合は ErrReservationNotFound を返す。
+func (s *ReservationService) GetEvent(ctx context.Context, resourceID, uid string) (*model.Reservation, error) {
+	if err := authorize(ctx, ActionViewSchedule, nil); err != nil {
+		return nil, err
+	}
+	reservation, err := s.eventReservation(resourceID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if authorize(ctx, ActionViewDetails, reservation) != nil {
+		return redact(reservation), nil
+	}
+	return reservation, nil
+}
+
+// PutEvent はイベントをリソースのカレンダーに保存し、保存した予約と新しく作成したかを返す。
+// UID の予約がない場合は CreateReservation と同じ検証で作成し、取り込みと同じく UID を記録して TENTATIVE のイベントを仮予約にする。
+// UID の予約がある場合は UpdateReservation と同じ検証で時間帯と目的・参加者を変更する（状態は変更しない）。
+// 件名が空の予約を書き出すとリソースの名前が件名になるため、その件名のまま保存された場合は件名を空のままにする。
+// 繰り返しのイベントと取り消されたイベントには ErrUnsupportedEvent、UID が他のカレンダーの予約か取り消された予約のものである場合は ErrUIDConflict、
+// Version や CreateOnly の条件に合わない場合と、同じ UID のイベントが同時に作成された場合は ErrVersionConflict を返す。その他のエラーは CreateReservation と UpdateReservation と同じ。
+func (s *ReservationService) PutEvent(ctx context.Context, params PutEventParams) (*model.Reservation, bool, error) {
+	event := params.Event
+	if event.RRule != "" {
+		return nil, false, fmt.Errorf("%w: recurring events are not supported", ErrUnsupportedEvent)
+	}
+	if event.Status == ical.StatusCancelled {
+		return nil, false, fmt.Errorf("%w: cancelled events cannot be saved; delete the event instead", ErrUnsupportedEvent)
+	}
+	existing, err := s.findByUID(event.UID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing == nil {
+		if params.Version != 0 {
+			return nil, false, ErrVersionConflict
+		}
+		if err := authorize(ctx, ActionCreate, nil); err != nil {
+			return nil, false, err
+		}
+		reservation, err := s.newReservation(ctx, CreateReservationParams{
+			ResourceID: params.ResourceID,
+			StartTime:  event.Start,
+			EndTime:    event.End,
+			Details: ReservationDetails{
+				Title:       event.Summary,
+				Description: event.Description,
+				Attendees:   event.Attendees,
+			},
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		reservation.ICalUID = event.UID
+		if event.Status == ical.StatusTentative {
+			reservation.Status = model.StatusPending
+		}
+		conflicts, err := s.repo.CreateIfNoOverlap(reservation)
+		if errors.Is(err, repository.ErrDuplicateICalUID) {
+			// 確認した後に同じ UID の予約が同時に作成された
+			return nil, false, ErrVersionConflict
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if len(conflicts) > 0 {
+			return nil, false, &ConflictError{Conflicts: conflicts}
+		}
+		s.publish(model.EventReservationCreated, reservation)
+		return reservation, true, nil
+	}
+
+	if existing.ResourceID != params.ResourceID || existing.Status == model.StatusCancelled {
+		return nil, false, ErrUIDConflict
+	}
+	if params.CreateOnly {
+		return nil, false, ErrVersionConflict
+	}
+	if params.RequireVersion {
+		return nil, false, ErrVersionRequired
+	}
+	title := event.Summary
+	if existing.Title == "" {
+		resource, err := s.resourceRepo.FindByID(existing.ResourceID)
+		if err != nil {
+			return nil, false, err
+		}
+		if resource != nil && strings.TrimSpace(title) == resource.Name {
+			title = ""
+		}
+	}
+	updated, err := s.UpdateReservation(ctx, existing.ID, UpdateReservationParams{
+		StartTime:   &event.Start,
+		EndTime:     &event.End,
+		Title:       &title,
+		Description: &event.Description,
+		Attendees:   &event.Attendees,
+		Version:     params.Version,
+	})
+	return updated, false, err
+}
+
+// DeleteEvent はリソースのカレンダーにある UID の予約を DeleteReservation と同じく取り消す。
+// カレンダーに UID の予約がない場合は ErrReservationNotFound を返す。その他のエラーは DeleteReservation と同じ。
+func (s *ReservationService) DeleteEvent(ctx context.Context, resourceID, uid string, version int64) error {
+	if err := authorize(ctx, ActionCancel, nil); err != nil {
+		return err
+	}
+	reservation, err := s.eventReservation(resourceID, uid)
+	if err != nil {
+		return err
+	}
+	return s.DeleteReservation(ctx, reservation.ID, DeleteReservationParams{Version: version})
+}
+
+// eventReservation はリソースのカレンダーにある UID の予約を返す。取り消された予約や他のリソースの予約の場合は ErrReservationNotFound を返す。
+func (s *ReservationService) eventReservation(resourceID, uid string) (*model.Reservation, error) {
+	reservation, err := s.findByUID(uid)
+	if err != nil {
+		return nil, err
+	}
+	if reservation == nil || reservation.ResourceID != resourceID || reservation.Status == model.StatusCancelled {
+		return nil, ErrReservationNotFound
+	}
+	return reservation, nil
+}
+
+// findByUID は iCalendar の UID が uid の予約を返す。取り込んだ予約は取り込み元の UID で、それ以外は予約IDから決めた UID で探す。
+// 見つからない場合は nil を返す。
+func (s *ReservationService) findByUID(uid string) (*model.Reservation, error) {
+	reservation, err := s.repo.FindByICalUID(uid)
+	if err != nil || reservation != nil {
+		return reservation, err
+	}
+	id, ok := strings.CutSuffix(uid, "@"+model.ICalUIDDomain)
+	if !ok {
+		return nil, nil
+	}
+	reservation, err = s.repo.FindByID(id)
+	if err != nil || reservation == nil || reservation.UID() != uid {
+		return nil, err
+	}
+	return reservation, nil
+}
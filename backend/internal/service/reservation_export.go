@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+const (
+	// DefaultExportPast と DefaultExportFuture は期間を指定しない書き出しに含める、現在より前と後の期間
+	DefaultExportPast   = 30 * 24 * time.Hour
+	DefaultExportFuture = 180 * 24 * time.Hour
+	// MaxExportReservations は一度に書き出せる予約の件数の上限
+	MaxExportReservations = 5000
+)
+
+// ExportReservationsParams は書き出す予約の条件
+type ExportReservationsParams struct {
+	// ResourceID が空の場合は全リソースの予約を書き出す
+	ResourceID string
+	// From と To を指定すると [From, To) と重なる予約を書き出す。
+	// 両方ゼロ値の場合は現在の DefaultExportPast 前から DefaultExportFuture 後まで。
+	From time.Time
+	To   time.Time
+	// Statuses を指定するとその状態の予約だけを書き出す。空の場合は取り消された予約を除く。
+	Statuses []model.ReservationStatus
+}
+
+// ReservationExport は書き出す予約と、予約のリソースの名前
+type ReservationExport struct {
+	Reservations []*model.Reservation
+	// ResourceNames はリソースIDごとの名前
+	ResourceNames map[string]string
+}
+
+// ExportReservations は条件に合う予約を開始時刻の順に返す。予約の詳細を参照できない権限には埋まっている時間帯だけを返す。
+// 期間の指定が不正な場合は ErrInvalidWindow または ErrWindowTooLarge、
+// 予約が MaxExportReservations 件を超える場合は ErrExportTooLarge を返す。
+func (s *ReservationService) ExportReservations(ctx context.Context, params ExportReservationsParams) (*ReservationExport, error) {
+	query, err := exportQuery(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	// 上限を超えたことを判定するため 1 件多く取得する
+	query.Limit = MaxExportReservations + 1
+	reservations, err := s.repo.List(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(reservations) > MaxExportReservations {
+		return nil, ErrExportTooLarge
+	}
+
+	if authorize(ctx, ActionViewDetails, nil) != nil {
+		for i, r := range reservations {
+			reservations[i] = redact(r)
+		}
+	}
+
+	names, err := s.resourceNames()
+	if err != nil {
+		return nil, err
+	}
+	return &ReservationExport{Reservations: reservations, ResourceNames: names}, nil
+}
+
+// StreamReservations は ExportReservations と同じ条件の予約を、件数の上限なしに開始時刻の順に1件ずつ予約のリソースの名前とともに fn に渡す。
+// 予約を全て読み込まずに書き出すために使う。権限と期間は fn を呼ぶ前に確認し、ExportReservations と同じエラーを返す（ErrExportTooLarge は返さない）。
+// fn がエラーを返した場合はそこで止めてそのエラーを返す。
+func (s *ReservationService) StreamReservations(ctx context.Context, params ExportReservationsParams, fn func(reservation *model.Reservation, resourceName string) error) error {
+	query, err := exportQuery(ctx, params)
+	if err != nil {
+		return err
+	}
+	query.Limit = 0
+	names, err := s.resourceNames()
+	if err != nil {
+		return err
+	}
+
+	details := authorize(ctx, ActionViewDetails, nil) == nil
+	return s.repo.Each(query, func(reservation *model.Reservation) error {
+		if !details {
+			reservation = redact(reservation)
+		}
+		return fn(reservation, names[reservation.ResourceID])
+	})
+}
+
+// exportQuery は空き状況を参照できるかを確認し、書き出す予約の検索条件を返す。件数は呼び出し側で設定する。
+func exportQuery(ctx context.Context, params ExportReservationsParams) (repository.ReservationQuery, error) {
+	if err := authorize(ctx, ActionViewSchedule, nil); err != nil {
+		return repository.ReservationQuery{}, err
+	}
+	if params.From.IsZero() && params.To.IsZero() {
+		now := time.Now()
+		params.From, params.To = now.Add(-DefaultExportPast), now.Add(DefaultExportFuture)
+	}
+	return listQuery(ListReservationsParams{
+		ResourceID: params.ResourceID,
+		From:       params.From,
+		To:         params.To,
+		Statuses:   params.Statuses,
+	})
+}
+
+// resourceNames はリソースIDごとのリソースの名前を返す
+func (s *ReservationService) resourceNames() (map[string]string, error) {
+	resources, err := s.resourceRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(resources))
+	for _, resource := range resources {
+		names[resource.ID] = resource.Name
+	}
+	return names, nil
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/ical"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+// MaxImportEvents は一度に取り込めるイベントの件数の上限
+const MaxImportEvents = 1000
+
+// ImportOutcome は取り込んだイベントの結果
+type ImportOutcome string
+
+const (
+	// ImportCreated は予約を作成したこと（ドライランでは作成できること）を表す
+	ImportCreated ImportOutcome = "created"
+	// ImportSkipped は UID が既存の予約か同じファイルの前のイベントと重複するため取り込まなかったことを表す
+	ImportSkipped ImportOutcome = "skipped"
+	// ImportRejected はイベントを予約にできなかったことを表す
+	ImportRejected ImportOutcome = "rejected"
+)
+
+// ImportReservationsParams は iCalendar から取り込む予約
+type ImportReservationsParams struct {
+	// ResourceID を指定すると全てのイベントをそのリソースの予約にする。
+	// 空の場合はイベントの LOCATION とリソースの名前（大文字と小文字を区別しない）か ID が一致するリソースの予約にする。
+	ResourceID string
+	// Events は ical.Decode で読み込んだイベント
+	Events []ical.DecodedEvent
+	// DryRun の場合は予約を保存せずに、保存した場合と同じ結果を返す
+	DryRun bool
+}
+
+// ImportResult はイベントごとの取り込みの結果
+type ImportResult struct {
+	UID     string
+	Summary string
+	Outcome ImportOutcome
+	// Reason はスキップまたは拒否した理由
+	Reason string
+	// ReservationID は作成した予約、またはスキップした場合に UID が重複した既存の予約のID。ドライランで作成できる場合は空。
+	ReservationID string
+	// ResourceID、StartTime、EndTime は予約にした（する）リソースと時間帯。イベントを解釈できなかった場合は空。
+	ResourceID string
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// ImportReport は取り込みの結果。Results はイベントと同じ順に並ぶ。
+type ImportReport struct {
+	DryRun  bool
+	Results []ImportResult
+}
+
+// Count は outcome の結果になったイベントの件数を返す
+func (r *ImportReport) Count(outcome ImportOutcome) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Outcome == outcome {
+			n++
+		}
+	}
+	return n
+}
+
+// ImportReservations は iCalendar のイベントを ctx のプリンシパルを所有者とする予約として取り込む。
+// 各イベントは CreateReservation と同じ検証と重複の確認を行い、作成できないイベントは拒否して残りのイベントの取り込みを続ける。
+// 取り込んだ予約には取り込み元の UID を記録し、同じ UID のイベント（このサービスが書き出した予約の UID を含む）は重複としてスキップする。
+// そのため途中で失敗した取り込みは同じファイルでやり直せる。TENTATIVE のイベントは仮予約（pending）として取り込み、繰り返しのイベントと取り消されたイベントは拒否する。
+// 予約を作成できない権限には ErrForbidden、イベントが MaxImportEvents 件を超える場合は ErrImportTooLarge、
+// ResourceID のリソースが存在しない場合は ErrResourceNotFound を返す。
+func (s *ReservationService) ImportReservations(ctx context.Context, params ImportReservationsParams) (*ImportReport, error) {
+	if err := authorize(ctx, ActionCreate, nil); err != nil {
+		return nil, err
+	}
+	if len(params.Events) > MaxImportEvents {
+		return nil, ErrImportTooLarge
+	}
+	im, err := s.beginImport(ctx, params.ResourceID, params.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: params.DryRun, Results: make([]ImportResult, 0, len(params.Events))}
+	for _, event := range params.Events {
+		result, err := im.add(eventEntry(event))
+		if err != nil {
+			return nil, err
+		}
+		result.Summary = event.Summary
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// eventEntry はイベントを取り込む予約にする。予約にできないイベントは Err を設定する。
+func eventEntry(event ical.DecodedEvent) ImportEntry {
+	entry := ImportEntry{
+		UID:       event.UID,
+		Location:  event.Location,
+		StartTime: event.Start,
+		EndTime:   event.End,
+		Details: ReservationDetails{
+			Title:       event.Summary,
+			Description: event.Description,
+			Attendees:   event.Attendees,
+		},
+	}
+	switch {
+	case event.UID == "":
+		entry.Err = errors.New("missing UID")
+	case event.Err != nil:
+		entry.Err = event.Err
+	case event.RRule != "":
+		entry.Err = errors.New("recurring events are not supported")
+	case event.Status == ical.StatusCancelled:
+		entry.Err = errors.New("cancelled events are not imported")
+	case event.Status == ical.StatusTentative:
+		entry.Status = model.StatusPending
+	}
+	return entry
+}
+
+// ImportEntry は取り込む1件の予約
+type ImportEntry struct {
+	// UID は取り込み元での予約の識別子。既存の予約か前に取り込んだ予約と一致する場合はスキップする。空の場合は重複を確認しない。
+	UID string
+	// ResourceID は予約のリソース。空の場合は Location とリソースの名前（大文字と小文字を区別しない）か ID が一致するリソースにする。
+	// 取り込みでリソースを指定した場合はどちらも使わない。
+	ResourceID string
+	Location   string
+	StartTime  time.Time
+	EndTime    time.Time
+	Details    ReservationDetails
+	// Status は作成する予約の状態。空の場合は確定（confirmed）、仮予約（pending）以外は拒否する。
+	Status model.ReservationStatus
+	// Err は取り込む前に見つかった問題。UID の重複を確認した後に拒否の理由にする。
+	Err error
+}
+
+// ImportEntriesParams は1件ずつ取り込む予約の条件
+type ImportEntriesParams struct {
+	// ResourceID を指定すると全ての予約をそのリソースの予約にする
+	ResourceID string
+	// DryRun の場合は予約を保存せずに、保存した場合と同じ結果を返す
+	DryRun bool
+}
+
+// ImportEntries は next が返す予約を、next が io.EOF を返すまで ctx のプリンシパルを所有者として1件ずつ取り込み、
+// 予約ごとの結果を fn に渡す。CSV のように予約を全て読み込まずに取り込むために使う。
+// 検証、重複の確認、UID の扱いは ImportReservations と同じで、取り込めない予約は拒否して続ける。
+// 予約を作成できない権限には ErrForbidden、ResourceID のリソースが存在しない場合は ErrResourceNotFound を返し、
+// next が io.EOF 以外のエラーを返した場合はそこで止めてそのエラーを返す。
+func (s *ReservationService) ImportEntries(ctx context.Context, params ImportEntriesParams, next func() (ImportEntry, error), fn func(result ImportResult)) error {
+	im, err := s.beginImport(ctx, params.ResourceID, params.DryRun)
+	if err != nil {
+		return err
+	}
+	for {
+		entry, err := next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result, err := im.add(entry)
+		if err != nil {
+			return err
+		}
+		fn(result)
+	}
+}
+
+// importer は1回の取り込みの状態を保持する
+type importer struct {
+	service    *ReservationService
+	ctx        context.Context
+	resourceID string
+	dryRun     bool
+	resources  []*model.Resource
+	// seen はこれまでに現れた UID
+	seen map[string]bool
+	// planned はドライランで作成できると判定した予約。同じ取り込みの予約どうしの重複の確認に使う。
+	planned []*model.Reservation
+}
+
+// beginImport は予約を作成できる権限と resourceID のリソースを確認し、取り込みを始める
+func (s *ReservationService) beginImport(ctx context.Context, resourceID string, dryRun bool) (*importer, error) {
+	if err := authorize(ctx, ActionCreate, nil); err != nil {
+		return nil, err
+	}
+	resources, err := s.resourceRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	if resourceID != "" && findResource(resources, resourceID) == nil {
+		return nil, ErrResourceNotFound
+	}
+	return &importer{
+		service:    s,
+		ctx:        ctx,
+		resourceID: resourceID,
+		dryRun:     dryRun,
+		resources:  resources,
+		seen:       make(map[string]bool),
+	}, nil
+}
+
+// add は予約を1件取り込む。CreateReservation と同じ検証と重複の確認を行い、取り込めない理由は結果で返す。
+// リポジトリのエラーなど取り込みを続けられない場合だけエラーを返す。
+func (im *importer) add(entry ImportEntry) (ImportResult, error) {
+	result := ImportResult{UID: entry.UID, Summary: entry.Details.Title}
+	reject := func(format string, args ...any) (ImportResult, error) {
+		result.Outcome = ImportRejected
+		result.Reason = fmt.Sprintf(format, args...)
+		return result, nil
+	}
+
+	if entry.UID != "" {
+		if im.seen[entry.UID] {
+			result.Outcome = ImportSkipped
+			result.Reason = "duplicate UID in the import"
+			return result, nil
+		}
+		im.seen[entry.UID] = true
+
+		existing, err := im.service.findByUID(entry.UID)
+		if err != nil {
+			return result, err
+		}
+		if existing != nil {
+			result.Outcome = ImportSkipped
+			result.Reason = "reservation with this UID already exists"
+			result.ReservationID = existing.ID
+			result.ResourceID = existing.ResourceID
+			result.StartTime, result.EndTime = existing.StartTime, existing.EndTime
+			return result, nil
+		}
+	}
+
+	if entry.Err != nil {
+		return reject("%v", entry.Err)
+	}
+	result.StartTime, result.EndTime = entry.StartTime, entry.EndTime
+	status := entry.Status
+	if status == "" {
+		status = model.StatusConfirmed
+	}
+	if status != model.StatusConfirmed && status != model.StatusPending {
+		return reject("status %q cannot be imported", status)
+	}
+	resource := im.resource(entry)
+	if resource == nil {
+		if entry.ResourceID != "" {
+			return reject("no resource with ID %q", entry.ResourceID)
+		}
+		return reject("no resource matches location %q", entry.Location)
+	}
+	result.ResourceID = resource.ID
+
+	reservation, err := im.service.newReservation(im.ctx, CreateReservationParams{
+		ResourceID: resource.ID,
+		StartTime:  entry.StartTime,
+		EndTime:    entry.EndTime,
+		Details:    entry.Details,
+	})
+	if err == nil {
+		reservation.ICalUID = entry.UID
+		reservation.Status = status
+		err = im.save(reservation)
+	}
+	if errors.Is(err, repository.ErrDuplicateICalUID) {
+		// 確認した後に同時に取り込まれた
+		result.Outcome = ImportSkipped
+		result.Reason = "reservation with this UID already exists"
+		return result, nil
+	}
+	if rejectable(err) {
+		return reject("%v", err)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	result.Outcome = ImportCreated
+	if !im.dryRun {
+		result.ReservationID = reservation.ID
+	}
+	return result, nil
+}
+
+// resource は予約にするリソースを返す。一致するリソースがない場合は nil を返す。
+func (im *importer) resource(entry ImportEntry) *model.Resource {
+	if im.resourceID != "" {
+		return findResource(im.resources, im.resourceID)
+	}
+	if entry.ResourceID != "" {
+		return findResource(im.resources, entry.ResourceID)
+	}
+	location := strings.TrimSpace(entry.Location)
+	if location == "" {
+		return nil
+	}
+	for _, r := range im.resources {
+		if strings.EqualFold(r.Name, location) {
+			return r
+		}
+	}
+	return findResource(im.resources, location)
+}
+
+// save は予約を保存する。ドライランでは保存せずに、既存の予約と前に取り込んだ予約との重複だけを確認する。
+func (im *importer) save(reservation *model.Reservation) error {
+	if !im.dryRun {
+		conflicts, err := im.service.repo.CreateIfNoOverlap(reservation)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return &ConflictError{Conflicts: conflicts}
+		}
+		im.service.publish(model.EventReservationCreated, reservation)
+		return nil
+	}
+
+	existing, err := im.service.repo.FindInRange(reservation.ResourceID, reservation.StartTime, reservation.EndTime)
+	if err != nil {
+		return err
+	}
+	var conflicts []*model.Reservation
+	for _, r := range append(existing, im.planned...) {
+		if r.ResourceID == reservation.ResourceID && r.Active() && r.Overlaps(reservation.StartTime, reservation.EndTime) {
+			conflicts = append(conflicts, r)
+		}
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+	im.planned = append(im.planned, reservation)
+	return nil
+}
+
+// rejectable は予約を作成できなかった原因が取り込む予約の内容にあり、その予約を拒否して取り込みを続けられるエラーかを返す
+func rejectable(err error) bool {
+	var conflictErr *ConflictError
+	var policyErr *PolicyViolationError
+	var detailsErr *InvalidDetailsError
+	return errors.As(err, &conflictErr) || errors.As(err, &policyErr) || errors.As(err, &detailsErr) ||
+		errors.Is(err, ErrInvalidTimeRange) || errors.Is(err, ErrResourceNotFound) || errors.Is(err, ErrResourceInactive)
+}
+
+// findResource は ID が id のリソースを返す。見つからない場合は nil を返す。
+func findResource(resources []*model.Resource, id string) *model.Resource {
+	for _, r := range resources {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
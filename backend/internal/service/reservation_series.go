@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+// Scope は繰り返し予約の1回を変更・削除するときに対象とする回の範囲
+type Scope string
+
+const (
+	// ScopeThis は指定した回だけを対象にする
+	ScopeThis Scope = "this"
+	// ScopeFollowing は指定した回と、それ以降の回を対象にする
+	ScopeFollowing Scope = "following"
+	// ScopeAll はシリーズの全ての回を対象にする
+	ScopeAll Scope = "all"
+)
+
+// ParseScope はクエリパラメータの値を Scope に変換する。空文字列は ScopeThis とみなす。
+func ParseScope(s string) (Scope, error) {
+	switch scope := Scope(s); scope {
+	case "":
+		return ScopeThis, nil
+	case ScopeThis, ScopeFollowing, ScopeAll:
+		return scope, nil
+	default:
+		return "", ErrInvalidScope
+	}
+}
+
+// SeriesDetail は繰り返し予約とその取り消されていない回、例外の一覧
+type SeriesDetail struct {
+	Series       *model.ReservationSeries
+	Reservations []*model.Reservation
+	Exceptions   []*model.SeriesException
+}
+
+// GetSeries は繰り返し予約の詳細を返す。存在しない場合は ErrSeriesNotFound、
+// 予約の詳細を参照できない場合は ErrForbidden を返す。
+func (s *ReservationService) GetSeries(ctx context.Context, id string) (*SeriesDetail, error) {
+	if err := authorize(ctx, ActionViewDetails, nil); err != nil {
+		return nil, err
+	}
+	series, err := s.seriesRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if series == nil {
+		return nil, ErrSeriesNotFound
+	}
+
+	reservations, err := s.repo.FindBySeriesID(id)
+	if err != nil {
+		return nil, err
+	}
+	reservations = filterByStatus(reservations, nil)
+	exceptions, err := s.seriesRepo.FindExceptions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SeriesDetail{Series: series, Reservations: reservations, Exceptions: exceptions}, nil
+}
+
+// scopedOccurrences は target を起点に scope の範囲に含まれる回を本来の開始時刻の昇順で返す。
+// あわせて各回の予約IDから本来の開始時刻を引けるマップを返す。
+// 繰り返し予約でない場合は scope に関わらず target だけを返す。scope のゼロ値は ScopeThis と同じ。
+func (s *ReservationService) scopedOccurrences(target *model.Reservation, scope Scope) ([]*model.Reservation, map[string]time.Time, error) {
+	if target.SeriesID == "" {
+		return []*model.Reservation{target}, nil, nil
+	}
+
+	exceptions, err := s.seriesRepo.FindExceptions(target.SeriesID)
+	if err != nil {
+		return nil, nil, err
+	}
+	originals := make(map[string]time.Time, len(exceptions))
+	for _, exception := range exceptions {
+		originals[exception.ReservationID] = exception.OriginalStartTime
+	}
+	originalStart := func(r *model.Reservation) time.Time {
+		if t, ok := originals[r.ID]; ok {
+			return t
+		}
+		return r.StartTime
+	}
+
+	if scope == "" || scope == ScopeThis {
+		originals[target.ID] = originalStart(target)
+		return []*model.Reservation{target}, originals, nil
+	}
+
+	occurrences, err := s.repo.FindBySeriesID(target.SeriesID)
+	if err != nil {
+		return nil, nil, err
+	}
+	from := originalStart(target)
+	var scoped []*model.Reservation
+	for _, occurrence := range occurrences {
+		originals[occurrence.ID] = originalStart(occurrence)
+		if scope == ScopeFollowing && originals[occurrence.ID].Before(from) {
+			continue
+		}
+		// 取り消し済みや完了した回は対象にしない
+		if occurrence.ID != target.ID && !editable(occurrence.Status) {
+			continue
+		}
+		scoped = append(scoped, occurrence)
+	}
+	sortByOriginalStart(scoped, originals)
+
+	return scoped, originals, nil
+}
+
+// sortByOriginalStart は回を本来の開始時刻の昇順に並べ替える
+func sortByOriginalStart(occurrences []*model.Reservation, originals map[string]time.Time) {
+	sort.Slice(occurrences, func(i, j int) bool {
+		return originals[occurrences[i].ID].Before(originals[occurrences[j].ID])
+	})
+}
+
+// discardOccurrences は繰り返し予約の作成が途中で失敗したときに、作成済みの回を取り消して err を返す。
+// 予約は削除できないため、取り消した回として残す。
+func (s *ReservationService) discardOccurrences(created []*model.Reservation, err error) error {
+	now := time.Now()
+	for _, reservation := range created {
+		if rollbackErr := s.repo.Update(cancelledCopy(reservation, "", now)); rollbackErr != nil {
+			err = errors.Join(err, rollbackErr)
+		}
+	}
+	return err
+}
+
+// updateOccurrences は updates を順に保存する。途中で重複や失敗があった場合は、
+// それまでに保存した回を originals の内容に戻してからエラーを返す。
+func (s *ReservationService) updateOccurrences(updates, originals []*model.Reservation) error {
+	for i, updated := range updates {
+		conflicts, err := s.repo.UpdateIfNoOverlap(updated)
+		if err == nil && len(conflicts) > 0 {
+			err = &ConflictError{Conflicts: conflicts}
+		}
+		if err != nil {
+			for j, original := range originals[:i] {
+				// 保存によって版が進んでいるため、戻すときは保存後の版を指定する
+				restored := *original
+				restored.Version = updates[j].Version
+				if rollbackErr := s.repo.Update(&restored); rollbackErr != nil {
+					err = errors.Join(err, rollbackErr)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
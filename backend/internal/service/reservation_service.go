@@ -1,39 +1,687 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/recurrence"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
 )
 
 type ReservationService struct {
-	repo repository.ReservationRepository
+	repo         repository.ReservationRepository
+	resourceRepo repository.ResourceRepository
+	seriesRepo   repository.ReservationSeriesRepository
+	policy       BookingPolicy
+	calendar     *CalendarService
+	events       EventPublisher
 }
 
-func NewReservationService(repo repository.ReservationRepository) *ReservationService {
-	return &ReservationService{repo: repo}
+func NewReservationService(repo repository.ReservationRepository, resourceRepo repository.ResourceRepository, seriesRepo repository.ReservationSeriesRepository) *ReservationService {
+	return &ReservationService{repo: repo, resourceRepo: resourceRepo, seriesRepo: seriesRepo}
+}
+
+// SetPolicy は予約の作成・変更時に適用するポリシーを設定する。設定しない場合はどの規則も適用しない。
+func (s *ReservationService) SetPolicy(policy BookingPolicy) {
+	s.policy = policy
+}
+
+// SetCalendar は予約の作成・変更時に確認する営業時間と休業期間を設定する。設定しない場合は常に営業中として扱う。
+func (s *ReservationService) SetCalendar(calendar *CalendarService) {
+	s.calendar = calendar
+}
+
+// SetEvents は予約の作成・変更・取り消しを保存した後に通知する先を設定する。設定しない場合は通知しない。
+func (s *ReservationService) SetEvents(events EventPublisher) {
+	s.events = events
+}
+
+// publish は保存した予約の変更を通知する
+func (s *ReservationService) publish(event model.WebhookEventType, reservations ...*model.Reservation) {
+	if s.events == nil {
+		return
+	}
+	for _, reservation := range reservations {
+		s.events.Publish(event, reservation)
+	}
 }
 
 type CreateReservationParams struct {
-	StartTime time.Time `json:"startTime" validate:"required"`
-	EndTime   time.Time `json:"endTime" validate:"required,gtfield=StartTime"`
+	ResourceID string    `json:"resourceId" validate:"required"`
+	StartTime  time.Time `json:"startTime" validate:"required"`
+	EndTime    time.Time `json:"endTime" validate:"required,gtfield=StartTime"`
+	Details    ReservationDetails
+}
+
+// ReservationDetails は予約の目的と参加者。
+// 保存時に Title の前後の空白を除き、Attendees のメールアドレスを小文字にそろえて重複を除く。
+type ReservationDetails struct {
+	Title       string
+	Description string
+	Attendees   []string
+}
+
+const (
+	// MaxTitleLength は予約の目的（Title）の最大文字数
+	MaxTitleLength = 200
+	// MaxDescriptionLength は予約の説明（Description）の最大文字数
+	MaxDescriptionLength = 2000
+	// MaxAttendees は予約の参加者の最大人数
+	MaxAttendees = 50
+	// MaxEmailLength は参加者のメールアドレスの最大文字数
+	MaxEmailLength = 254
+	// MaxCancellationReasonLength は取り消し理由の最大文字数
+	MaxCancellationReasonLength = 500
+)
+
+// Validate は目的と参加者が保存できる内容かを確認し、できない場合は *InvalidDetailsError を返す。
+// API、取り込み、CalDAV のどの経路でも予約を保存する前にサービスで確認する。メールアドレスは保存時と同じくそろえてから確認する。
+func (d ReservationDetails) Validate() error {
+	if utf8.RuneCountInString(strings.TrimSpace(d.Title)) > MaxTitleLength {
+		return &InvalidDetailsError{Field: "title", Reason: fmt.Sprintf("must be at most %d characters", MaxTitleLength)}
+	}
+	if utf8.RuneCountInString(d.Description) > MaxDescriptionLength {
+		return &InvalidDetailsError{Field: "description", Reason: fmt.Sprintf("must be at most %d characters", MaxDescriptionLength)}
+	}
+	if len(d.Attendees) > MaxAttendees {
+		return &InvalidDetailsError{Field: "attendees", Reason: fmt.Sprintf("must not exceed %d people", MaxAttendees)}
+	}
+	for _, email := range normalizeAttendees(d.Attendees) {
+		if !validEmail(email) {
+			return &InvalidDetailsError{Field: "attendees", Reason: fmt.Sprintf("invalid email address %q", email)}
+		}
+	}
+	return nil
+}
+
+// validEmail は email が表示名などを含まない1つのメールアドレスかを返す
+func validEmail(email string) bool {
+	if len(email) > MaxEmailLength {
+		return false
+	}
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
+// apply は details を reservation に設定する
+func (d ReservationDetails) apply(reservation *model.Reservation) {
+	reservation.Title = strings.TrimSpace(d.Title)
+	reservation.Description = d.Description
+	reservation.Attendees = normalizeAttendees(d.Attendees)
+}
+
+// normalizeAttendees はメールアドレスの前後の空白を除いて小文字にそろえ、空のものと重複を除く
+func normalizeAttendees(attendees []string) []string {
+	normalized := make([]string, 0, len(attendees))
+	for _, a := range attendees {
+		email := strings.ToLower(strings.TrimSpace(a))
+		if email != "" && !slices.Contains(normalized, email) {
+			normalized = append(normalized, email)
+		}
+	}
+	return normalized
+}
+
+// MaxSeriesSpan は繰り返し予約の最初の回の開始から最後の回の開始までの期間の上限
+const MaxSeriesSpan = 366 * 24 * time.Hour
+
+// CreateRecurringReservationParams は繰り返し予約の作成内容。
+// StartTime と EndTime は最初の回の時間帯で、Recurrence は RFC 5545 の RRULE。
+type CreateRecurringReservationParams struct {
+	ResourceID string
+	StartTime  time.Time
+	EndTime    time.Time
+	Recurrence string
+	// Details は全ての回に設定する
+	Details ReservationDetails
+}
+
+// SkippedOccurrence は既存の予約と重なったために予約できなかった回
+type SkippedOccurrence struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Conflicts []*model.Reservation
+}
+
+// RecurringReservationResult は繰り返し予約の作成結果
+type RecurringReservationResult struct {
+	Series       *model.ReservationSeries
+	Reservations []*model.Reservation
+	Skipped      []SkippedOccurrence
+}
+
+// UpdateReservationParams は予約の変更内容。nil のフィールドは変更しない。
+type UpdateReservationParams struct {
+	ResourceID  *string
+	StartTime   *time.Time
+	EndTime     *time.Time
+	Title       *string
+	Description *string
+	Attendees   *[]string
+	// Scope は繰り返し予約の回を変更するときの対象範囲。ゼロ値は ScopeThis と同じ。
+	Scope Scope
+	// Version を指定すると、予約の現在の版と一致する場合だけ変更する。0 の場合は版を確認しない。
+	Version int64
+}
+
+// MaxListWindow は予約一覧で一度に指定できる期間の上限
+const MaxListWindow = 366 * 24 * time.Hour
+
+const (
+	// DefaultListLimit は予約一覧で件数を指定しない場合に一度に返す件数
+	DefaultListLimit = 100
+	// MaxListLimit は予約一覧で一度に返す件数の上限
+	MaxListLimit = 500
+)
+
+// ListReservationsParams は予約一覧の絞り込み条件
+type ListReservationsParams struct {
+	// ResourceID が空の場合は全リソースの予約を返す
+	ResourceID string
+	// From と To を指定すると [From, To) と重なる予約だけを返す。両方ゼロ値の場合は期間で絞り込まない。
+	From time.Time
+	To   time.Time
+	// CreatedAfter を指定するとその日時より後に作成された予約だけを返す
+	CreatedAfter time.Time
+	// MinDuration と MaxDuration を指定すると長さがその範囲に含まれる予約だけを返す
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	// Statuses を指定するとその状態の予約だけを返す。空の場合は取り消された予約を除いて返す。
+	Statuses []model.ReservationStatus
+	// SortBy は並べ替えの項目（startTime または createdAt）。空の場合は開始時刻で並べ替える。同じ値の予約は ID の順に並べる。
+	SortBy     string
+	Descending bool
+	// Cursor を指定すると、前のページの ReservationPage.NextCursor の続きから返す
+	Cursor string
+	// Limit は一度に返す件数。0 の場合は DefaultListLimit。
+	Limit int
 }
 
-func (s *ReservationService) CreateReservation(params CreateReservationParams) (*model.Reservation, error) {
-	reservation := model.NewReservation(params.StartTime, params.EndTime)
-	err := s.repo.Create(reservation)
+// ReservationPage は予約一覧の 1 ページ分
+type ReservationPage struct {
+	Reservations []*model.Reservation
+	// NextCursor は続きを取得するカーソル。最後のページでは空。
+	NextCursor string
+}
+
+// CreateReservation は ctx のプリンシパルを所有者として予約を作成する。ユーザーIDのないプリンシパルでは所有者のいない予約になる。
+// 予約を作成できない権限には ErrForbidden、終了時刻が開始時刻より後でない場合は ErrInvalidTimeRange、リソースが存在しない場合は ErrResourceNotFound、
+// 無効化されている場合は ErrResourceInactive、ポリシーに違反する場合は *PolicyViolationError、
+// 同じリソースの既存の予約と時間帯が重なる場合は *ConflictError を返す。
+func (s *ReservationService) CreateReservation(ctx context.Context, params CreateReservationParams) (*model.Reservation, error) {
+	if err := authorize(ctx, ActionCreate, nil); err != nil {
+		return nil, err
+	}
+	reservation, err := s.newReservation(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts, err := s.repo.CreateIfNoOverlap(reservation)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, &ConflictError{Conflicts: conflicts}
+	}
+
+	s.publish(model.EventReservationCreated, reservation)
+	return reservation, nil
+}
+
+// newReservation は作成する予約を検証して組み立てる。保存と時間帯の重複の確認は呼び出し側で行う。
+func (s *ReservationService) newReservation(ctx context.Context, params CreateReservationParams) (*model.Reservation, error) {
+	if err := s.validate(params.ResourceID, params.StartTime, params.EndTime); err != nil {
+		return nil, err
+	}
+	if err := params.Details.Validate(); err != nil {
+		return nil, err
+	}
+	closed, err := s.closedPeriods(params.ResourceID, params.StartTime, params.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPolicy(params.StartTime, params.EndTime, time.Now(), closed); err != nil {
+		return nil, err
+	}
+
+	reservation := model.NewReservation(params.ResourceID, params.StartTime, params.EndTime)
+	reservation.UserID = ownerID(ctx)
+	params.Details.apply(reservation)
+	return reservation, nil
+}
+
+// CreateRecurringReservation は RRULE に従って繰り返し予約を作成する。権限の確認と各回の所有者は CreateReservation と同じく ctx から決める。
+// 各回は通常の予約と同じく重複を確認し、重なった回は予約せずに Skipped で報告する。
+// RRULE が不正な場合や COUNT / UNTIL で終わらない場合は ErrInvalidRecurrence、
+// 繰り返しが MaxSeriesSpan を超える場合は ErrSeriesTooLong、いずれかの回がポリシーに違反する場合は *PolicyViolationError、
+// 全ての回が重なった場合は *ConflictError を返す。保存の途中で失敗した場合は作成済みの回を取り消してからエラーを返す。
+func (s *ReservationService) CreateRecurringReservation(ctx context.Context, params CreateRecurringReservationParams) (*RecurringReservationResult, error) {
+	if err := authorize(ctx, ActionCreate, nil); err != nil {
+		return nil, err
+	}
+	if err := s.validate(params.ResourceID, params.StartTime, params.EndTime); err != nil {
+		return nil, err
+	}
+	if err := params.Details.Validate(); err != nil {
+		return nil, err
+	}
+
+	rule, err := recurrence.Parse(params.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+	if rule.Count == 0 && rule.Until.IsZero() {
+		return nil, fmt.Errorf("%w: COUNT or UNTIL is required", ErrInvalidRecurrence)
+	}
+
+	limit := params.StartTime.Add(MaxSeriesSpan)
+	if !rule.Until.IsZero() && rule.Until.After(limit) {
+		return nil, ErrSeriesTooLong
+	}
+	occurrences := rule.Between(params.StartTime, params.StartTime, limit)
+	if rule.Count > 0 && len(occurrences) < rule.Count {
+		// 残りの回は上限より後にある
+		return nil, ErrSeriesTooLong
+	}
+	if len(occurrences) == 0 {
+		return nil, fmt.Errorf("%w: rule produces no occurrences", ErrInvalidRecurrence)
+	}
+	// ポリシーに違反する回や休業時間と重なる回がある場合は一つも予約しない
+	now := time.Now()
+	duration := params.EndTime.Sub(params.StartTime)
+	closed, err := s.closedPeriods(params.ResourceID, occurrences[0], occurrences[len(occurrences)-1].Add(duration))
+	if err != nil {
+		return nil, err
+	}
+	for _, startTime := range occurrences {
+		if err := s.checkPolicy(startTime, startTime.Add(duration), now, closed); err != nil {
+			return nil, err
+		}
+	}
+
+	series := model.NewReservationSeries(params.ResourceID, rule.String(), params.StartTime, params.EndTime)
+	result := &RecurringReservationResult{Series: series}
+	owner := ownerID(ctx)
+	var allConflicts []*model.Reservation
+	for _, startTime := range occurrences {
+		reservation := series.NewOccurrence(startTime)
+		reservation.UserID = owner
+		params.Details.apply(reservation)
+		conflicts, err := s.repo.CreateIfNoOverlap(reservation)
+		if err != nil {
+			return nil, s.discardOccurrences(result.Reservations, err)
+		}
+		if len(conflicts) > 0 {
+			result.Skipped = append(result.Skipped, SkippedOccurrence{
+				StartTime: reservation.StartTime,
+				EndTime:   reservation.EndTime,
+				Conflicts: conflicts,
+			})
+			allConflicts = append(allConflicts, conflicts...)
+			continue
+		}
+		result.Reservations = append(result.Reservations, reservation)
+	}
+
+	if len(result.Reservations) == 0 {
+		return nil, &ConflictError{Conflicts: allConflicts}
+	}
+	if err := s.seriesRepo.Create(series); err != nil {
+		return nil, s.discardOccurrences(result.Reservations, err)
+	}
+
+	s.publish(model.EventReservationCreated, result.Reservations...)
+	return result, nil
+}
+
+// UpdateReservation は予約の時間帯やリソース、目的と参加者を変更する。
+// 存在しない予約には ErrReservationNotFound、ctx のプリンシパルがこの予約を変更できない場合は ErrForbidden、
+// 版が params.Version と異なるか保存までの間に他の操作で変更された場合は ErrVersionConflict を返し、
+// 変更後の内容には作成時と同じ検証を行い、時間帯やリソースを変える回だけポリシーを確認する（checkUpdatePolicy）。
+// 繰り返し予約の回では params.Scope の範囲の各回を同じだけずらし、同じ長さとリソース、目的と参加者にそろえる。
+// いずれかの回が重なった場合は全ての回を元に戻して *ConflictError を返す。
+func (s *ReservationService) UpdateReservation(ctx context.Context, id string, params UpdateReservationParams) (*model.Reservation, error) {
+	// 変更の権限が全くない場合は予約を探す前に拒否し、予約が存在するかを知らせない
+	if err := authorize(ctx, ActionUpdate, nil); err != nil {
+		return nil, err
+	}
+	current, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrReservationNotFound
+	}
+	if err := authorize(ctx, ActionUpdate, current); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(current, params.Version); err != nil {
+		return nil, err
+	}
+
+	if !editable(current.Status) {
+		return nil, ErrReservationNotEditable
+	}
+
+	updated := *current
+	if params.ResourceID != nil {
+		updated.ResourceID = *params.ResourceID
+	}
+	if params.StartTime != nil {
+		updated.StartTime = *params.StartTime
+	}
+	if params.EndTime != nil {
+		updated.EndTime = *params.EndTime
+	}
+	if err := s.validate(updated.ResourceID, updated.StartTime, updated.EndTime); err != nil {
+		return nil, err
+	}
+	if err := params.details().Validate(); err != nil {
+		return nil, err
+	}
+
+	scoped, originalStarts, err := s.scopedOccurrences(current, params.Scope)
 	if err != nil {
 		return nil, err
 	}
 
+	shift := updated.StartTime.Sub(current.StartTime)
+	duration := updated.EndTime.Sub(updated.StartTime)
+	now := time.Now()
+	updates := make([]*model.Reservation, 0, len(scoped))
+	originals := make([]*model.Reservation, 0, len(scoped))
+	var result *model.Reservation
+	for _, occurrence := range scoped {
+		u := *occurrence
+		u.ResourceID = updated.ResourceID
+		u.StartTime = occurrence.StartTime.Add(shift)
+		u.EndTime = u.StartTime.Add(duration)
+		params.applyDetails(&u)
+		u.UpdatedAt = now
+		if occurrence.ID == current.ID {
+			result = &u
+		}
+		updates = append(updates, &u)
+		originals = append(originals, occurrence)
+	}
+
+	// 休業時間は全ての回を含む範囲でまとめて取得する
+	from, to := updates[0].StartTime, updates[0].EndTime
+	for _, u := range updates {
+		from, to = minTime(from, u.StartTime), maxTime(to, u.EndTime)
+	}
+	closed, err := s.closedPeriods(updated.ResourceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i, u := range updates {
+		if err := s.checkUpdatePolicy(u, originals[i], u.ID == current.ID, now, closed); err != nil {
+			return nil, err
+		}
+	}
+	if shift > 0 {
+		// 後ろにずらすときは後の回から動かし、移動先が同じシリーズの回と重ならないようにする
+		slices.Reverse(updates)
+		slices.Reverse(originals)
+	}
+
+	if err := s.updateOccurrences(updates, originals); err != nil {
+		return nil, err
+	}
+
+	if current.SeriesID != "" {
+		for _, u := range updates {
+			exception := model.NewSeriesException(current.SeriesID, originalStarts[u.ID], model.ExceptionModified, u.ID)
+			if err := s.seriesRepo.SaveException(exception); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s.publish(model.EventReservationUpdated, updates...)
+	return result, nil
+}
+
+// checkVersion は version を指定した場合に、予約の現在の版と一致するかを確認する
+func checkVersion(reservation *model.Reservation, version int64) error {
+	if version != 0 && reservation.Version != version {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// details は params で指定された目的と参加者を返す。指定のない項目は空にする。
+func (params UpdateReservationParams) details() ReservationDetails {
+	var details ReservationDetails
+	if params.Title != nil {
+		details.Title = *params.Title
+	}
+	if params.Description != nil {
+		details.Description = *params.Description
+	}
+	if params.Attendees != nil {
+		details.Attendees = *params.Attendees
+	}
+	return details
+}
+
+// applyDetails は params で指定された目的と参加者を reservation に設定する
+func (params UpdateReservationParams) applyDetails(reservation *model.Reservation) {
+	if params.Title != nil {
+		reservation.Title = strings.TrimSpace(*params.Title)
+	}
+	if params.Description != nil {
+		reservation.Description = *params.Description
+	}
+	if params.Attendees != nil {
+		reservation.Attendees = normalizeAttendees(*params.Attendees)
+	}
+}
+
+// validate は予約の作成と変更で共通の検証を行う
+func (s *ReservationService) validate(resourceID string, startTime, endTime time.Time) error {
+	if !endTime.After(startTime) {
+		return ErrInvalidTimeRange
+	}
+
+	return checkBookable(s.resourceRepo, resourceID)
+}
+
+// checkBookable はリソースが存在し、予約を受け付けているかを確認する
+func checkBookable(resourceRepo repository.ResourceRepository, resourceID string) error {
+	resource, err := resourceRepo.FindByID(resourceID)
+	if err != nil {
+		return err
+	}
+	if resource == nil {
+		return ErrResourceNotFound
+	}
+	if !resource.Active {
+		return ErrResourceInactive
+	}
+
+	return nil
+}
+
+// GetReservation は予約を返す。予約の詳細を参照できない権限には埋まっている時間帯だけを返す。
+// 取り消された予約も返す。存在しない予約には ErrReservationNotFound、空き状況も参照できない場合は ErrForbidden を返す。
+func (s *ReservationService) GetReservation(ctx context.Context, id string) (*model.Reservation, error) {
+	if err := authorize(ctx, ActionViewSchedule, nil); err != nil {
+		return nil, err
+	}
+	reservation, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if reservation == nil {
+		return nil, ErrReservationNotFound
+	}
+	if authorize(ctx, ActionViewDetails, reservation) != nil {
+		return redact(reservation), nil
+	}
 	return reservation, nil
 }
 
-func (s *ReservationService) GetAllReservations() ([]*model.Reservation, error) {
-	return s.repo.FindAll()
+// GetAllReservations は条件に合う予約を並べ替えて最大 Limit 件返す。予約の詳細を参照できない権限には埋まっている時間帯だけを返す。
+// 空き状況も参照できない場合は ErrForbidden、期間の指定が不正な場合は ErrInvalidWindow、
+// MaxListWindow より長い場合は ErrWindowTooLarge、長さの範囲が不正な場合は ErrInvalidDuration、
+// 並べ替えの項目が未定義の場合は ErrInvalidSort、件数が範囲外の場合は ErrInvalidLimit、
+// カーソルが不正な場合は ErrInvalidCursor を返す。
+func (s *ReservationService) GetAllReservations(ctx context.Context, params ListReservationsParams) (*ReservationPage, error) {
+	if err := authorize(ctx, ActionViewSchedule, nil); err != nil {
+		return nil, err
+	}
+	query, err := listQuery(params)
+	if err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	// 続きの有無を判定するため 1 件多く取得する
+	query.Limit++
+	reservations, err := s.repo.List(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ReservationPage{Reservations: reservations}
+	if len(reservations) > limit {
+		page.Reservations = reservations[:limit]
+		page.NextCursor = encodeCursor(query, page.Reservations[limit-1])
+	}
+	if authorize(ctx, ActionViewDetails, nil) != nil {
+		for i, r := range page.Reservations {
+			page.Reservations[i] = redact(r)
+		}
+	}
+	return page, nil
 }
 
-func (s *ReservationService) DeleteReservation(id string) error {
-	return s.repo.Delete(id)
+// listQuery は予約一覧の条件を検証してリポジトリの検索条件に変換する
+func listQuery(params ListReservationsParams) (repository.ReservationQuery, error) {
+	query := repository.ReservationQuery{
+		ResourceID:   params.ResourceID,
+		CreatedAfter: params.CreatedAfter,
+		MinDuration:  params.MinDuration,
+		MaxDuration:  params.MaxDuration,
+		Statuses:     params.Statuses,
+		SortBy:       repository.ReservationSortKey(params.SortBy),
+		Descending:   params.Descending,
+		Limit:        params.Limit,
+	}
+	if !params.From.IsZero() || !params.To.IsZero() {
+		if params.From.IsZero() || params.To.IsZero() || !params.To.After(params.From) {
+			return query, ErrInvalidWindow
+		}
+		if params.To.Sub(params.From) > MaxListWindow {
+			return query, ErrWindowTooLarge
+		}
+		query.From, query.To = params.From, params.To
+	}
+	if params.MinDuration < 0 || params.MaxDuration < 0 ||
+		(params.MaxDuration > 0 && params.MinDuration > params.MaxDuration) {
+		return query, ErrInvalidDuration
+	}
+	if query.SortBy == "" {
+		query.SortBy = repository.SortByStartTime
+	}
+	if !query.SortBy.Valid() {
+		return query, ErrInvalidSort
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultListLimit
+	}
+	if query.Limit < 0 || query.Limit > MaxListLimit {
+		return query, ErrInvalidLimit
+	}
+	if params.Cursor != "" {
+		after, err := decodeCursor(params.Cursor, query)
+		if err != nil {
+			return query, err
+		}
+		query.After = after
+	}
+	return query, nil
+}
+
+// filterByStatus は statuses に含まれる状態の予約だけを返す。statuses が空の場合は取り消された予約を除く。
+func filterByStatus(reservations []*model.Reservation, statuses []model.ReservationStatus) []*model.Reservation {
+	filtered := make([]*model.Reservation, 0, len(reservations))
+	for _, r := range reservations {
+		if (len(statuses) == 0 && r.Active()) || slices.Contains(statuses, r.Status) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// DeleteReservationParams は予約の取り消し内容
+type DeleteReservationParams struct {
+	// Scope は繰り返し予約の回を取り消すときの対象範囲。ゼロ値は ScopeThis と同じ。
+	Scope Scope
+	// Reason は取り消しの理由（任意）
+	Reason string
+	// Version を指定すると、予約の現在の版と一致する場合だけ取り消す。0 の場合は版を確認しない。
+	Version int64
+}
+
+// DeleteReservation は予約を取り消す。予約は削除せず、状態を cancelled にして取り消し日時と理由を記録する。
+// 既に取り消されている予約には版に関わらず何もしない。存在しない予約には ErrReservationNotFound、
+// 理由が MaxCancellationReasonLength 文字を超える場合は *InvalidDetailsError、完了・無断キャンセルの予約には ErrInvalidStatusTransition、
+// ctx のプリンシパルがこの予約を取り消せない場合は ErrForbidden、版が params.Version と異なる場合は ErrVersionConflict を返す。
+// 繰り返し予約の回では scope の範囲の回を取り消し、それぞれを取り消しの例外として記録する。途中で失敗した場合は全ての回を元に戻す。
+func (s *ReservationService) DeleteReservation(ctx context.Context, id string, params DeleteReservationParams) error {
+	if err := authorize(ctx, ActionCancel, nil); err != nil {
+		return err
+	}
+	if err := validateCancellationReason(params.Reason); err != nil {
+		return err
+	}
+	current, err := s.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrReservationNotFound
+	}
+	if err := authorize(ctx, ActionCancel, current); err != nil {
+		return err
+	}
+	// 範囲が指定した回だけの場合は、既に取り消されていれば何もしない。
+	// following / all では指定した回が取り消し済みでも、範囲に残っている回を取り消す。
+	alreadyCancelled := current.Status == model.StatusCancelled
+	if alreadyCancelled && (current.SeriesID == "" || params.Scope == "" || params.Scope == ScopeThis) {
+		return nil
+	}
+	if err := checkVersion(current, params.Version); err != nil {
+		return err
+	}
+	if !alreadyCancelled && !CanTransition(current.Status, model.StatusCancelled) {
+		return invalidTransition(current.Status, model.StatusCancelled)
+	}
+
+	scoped, originalStarts, err := s.scopedOccurrences(current, params.Scope)
+	if err != nil {
+		return err
+	}
+	scoped = slices.DeleteFunc(scoped, func(occurrence *model.Reservation) bool {
+		return occurrence.Status == model.StatusCancelled
+	})
+	_, err = s.cancelOccurrences(scoped, params.Reason, originalStarts, time.Now())
+	return err
+}
+
+// ownerID は ctx のプリンシパルのユーザーIDを返す。匿名の場合は空を返す。
+func ownerID(ctx context.Context) string {
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		return p.UserID
+	}
+	return ""
 }
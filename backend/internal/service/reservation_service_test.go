@@ -1,20 +1,42 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/ical"
 	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
 )
 
+// adminCtx は全ての操作を許可された管理者のプリンシパルを格納したコンテキスト。
+// ユーザーIDを持たないため、作成した予約は所有者のいない予約になる。
+var adminCtx = auth.WithPrincipal(context.Background(), auth.Principal{Role: model.RoleAdmin})
+
 // モックリポジトリの実装
 type mockReservationRepository struct {
-	reservations map[string]*model.Reservation
-	createFunc   func(reservation *model.Reservation) error
-	findAllFunc  func() ([]*model.Reservation, error)
-	findByIDFunc func(id string) (*model.Reservation, error)
-	deleteFunc   func(id string) error
+	reservations          map[string]*model.Reservation
+	createFunc            func(reservation *model.Reservation) error
+	createIfNoOverlapFunc func(reservation *model.Reservation) ([]*model.Reservation, error)
+	updateFunc            func(reservation *model.Reservation) error
+	updateIfNoOverlapFunc func(reservation *model.Reservation) ([]*model.Reservation, error)
+	findAllFunc           func() ([]*model.Reservation, error)
+	listFunc              func(query repository.ReservationQuery) ([]*model.Reservation, error)
+	eachFunc              func(query repository.ReservationQuery, fn func(reservation *model.Reservation) error) error
+	findByResourceIDFunc  func(resourceID string) ([]*model.Reservation, error)
+	findInRangeFunc       func(resourceID string, from, to time.Time) ([]*model.Reservation, error)
+	findBySeriesIDFunc    func(seriesID string) ([]*model.Reservation, error)
+	findByIDFunc          func(id string) (*model.Reservation, error)
+	findByICalUIDFunc     func(uid string) (*model.Reservation, error)
+	deleteFunc            func(id string) error
 }
 
 func newMockReservationRepository() *mockReservationRepository {
@@ -23,12 +45,39 @@ func newMockReservationRepository() *mockReservationRepository {
 		createFunc: func(reservation *model.Reservation) error {
 			return nil
 		},
+		createIfNoOverlapFunc: func(reservation *model.Reservation) ([]*model.Reservation, error) {
+			return nil, nil
+		},
+		updateFunc: func(reservation *model.Reservation) error {
+			return nil
+		},
+		updateIfNoOverlapFunc: func(reservation *model.Reservation) ([]*model.Reservation, error) {
+			return nil, nil
+		},
 		findAllFunc: func() ([]*model.Reservation, error) {
 			return []*model.Reservation{}, nil
 		},
+		listFunc: func(query repository.ReservationQuery) ([]*model.Reservation, error) {
+			return []*model.Reservation{}, nil
+		},
+		eachFunc: func(query repository.ReservationQuery, fn func(reservation *model.Reservation) error) error {
+			return nil
+		},
+		findByResourceIDFunc: func(resourceID string) ([]*model.Reservation, error) {
+			return []*model.Reservation{}, nil
+		},
+		findInRangeFunc: func(resourceID string, from, to time.Time) ([]*model.Reservation, error) {
+			return []*model.Reservation{}, nil
+		},
+		findBySeriesIDFunc: func(seriesID string) ([]*model.Reservation, error) {
+			return []*model.Reservation{}, nil
+		},
 		findByIDFunc: func(id string) (*model.Reservation, error) {
 			return nil, nil
 		},
+		findByICalUIDFunc: func(uid string) (*model.Reservation, error) {
+			return nil, nil
+		},
 		deleteFunc: func(id string) error {
 			return nil
 		},
@@ -39,14 +88,50 @@ func (m *mockReservationRepository) Create(reservation *model.Reservation) error
 	return m.createFunc(reservation)
 }
 
+func (m *mockReservationRepository) CreateIfNoOverlap(reservation *model.Reservation) ([]*model.Reservation, error) {
+	return m.createIfNoOverlapFunc(reservation)
+}
+
+func (m *mockReservationRepository) Update(reservation *model.Reservation) error {
+	return m.updateFunc(reservation)
+}
+
+func (m *mockReservationRepository) UpdateIfNoOverlap(reservation *model.Reservation) ([]*model.Reservation, error) {
+	return m.updateIfNoOverlapFunc(reservation)
+}
+
 func (m *mockReservationRepository) FindAll() ([]*model.Reservation, error) {
 	return m.findAllFunc()
 }
 
+func (m *mockReservationRepository) List(query repository.ReservationQuery) ([]*model.Reservation, error) {
+	return m.listFunc(query)
+}
+
+func (m *mockReservationRepository) Each(query repository.ReservationQuery, fn func(reservation *model.Reservation) error) error {
+	return m.eachFunc(query, fn)
+}
+
+func (m *mockReservationRepository) FindByResourceID(resourceID string) ([]*model.Reservation, error) {
+	return m.findByResourceIDFunc(resourceID)
+}
+
+func (m *mockReservationRepository) FindInRange(resourceID string, from, to time.Time) ([]*model.Reservation, error) {
+	return m.findInRangeFunc(resourceID, from, to)
+}
+
+func (m *mockReservationRepository) FindBySeriesID(seriesID string) ([]*model.Reservation, error) {
+	return m.findBySeriesIDFunc(seriesID)
+}
+
 func (m *mockReservationRepository) FindByID(id string) (*model.Reservation, error) {
 	return m.findByIDFunc(id)
 }
 
+func (m *mockReservationRepository) FindByICalUID(uid string) (*model.Reservation, error) {
+	return m.findByICalUIDFunc(uid)
+}
+
 func (m *mockReservationRepository) Delete(id string) error {
 	return m.deleteFunc(id)
 }
@@ -56,20 +141,21 @@ func TestReservationService_CreateReservation(t *testing.T) {
 	mockRepo := newMockReservationRepository()
 	var savedReservation *model.Reservation
 
-	mockRepo.createFunc = func(reservation *model.Reservation) error {
+	mockRepo.createIfNoOverlapFunc = func(reservation *model.Reservation) ([]*model.Reservation, error) {
 		savedReservation = reservation
-		return nil
+		return nil, nil
 	}
 
-	service := NewReservationService(mockRepo)
+	service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
 	now := time.Now()
 	params := CreateReservationParams{
-		StartTime: now,
-		EndTime:   now.Add(1 * time.Hour),
+		ResourceID: activeResource.ID,
+		StartTime:  now,
+		EndTime:    now.Add(1 * time.Hour),
 	}
 
 	// 実行
-	createdReservation, err := service.CreateReservation(params)
+	createdReservation, err := service.CreateReservation(adminCtx, params)
 
 	// 検証
 	if err != nil {
@@ -92,80 +178,1979 @@ func TestReservationService_CreateReservation(t *testing.T) {
 	}
 }
 
-func TestReservationService_GetAllReservations(t *testing.T) {
+func TestReservationService_CreateReservation_Conflict(t *testing.T) {
+	// 準備
+	mockRepo := newMockReservationRepository()
+	now := time.Now()
+	existing := model.NewReservation("resource-1", now, now.Add(1*time.Hour))
+
+	mockRepo.createIfNoOverlapFunc = func(reservation *model.Reservation) ([]*model.Reservation, error) {
+		return []*model.Reservation{existing}, nil
+	}
+
+	service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	params := CreateReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  now.Add(30 * time.Minute),
+		EndTime:    now.Add(90 * time.Minute),
+	}
+
+	// 実行
+	createdReservation, err := service.CreateReservation(adminCtx, params)
+
+	// 検証
+	if createdReservation != nil {
+		t.Errorf("Expected no reservation, got %v", createdReservation)
+	}
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected ConflictError, got %v", err)
+	}
+	if len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].ID != existing.ID {
+		t.Errorf("Expected conflict with %s, got %v", existing.ID, conflictErr.Conflicts)
+	}
+}
+
+func TestReservationService_CreateReservation_RepositoryError(t *testing.T) {
 	// 準備
 	mockRepo := newMockReservationRepository()
+	expectedErr := errors.New("create error")
+
+	mockRepo.createIfNoOverlapFunc = func(reservation *model.Reservation) ([]*model.Reservation, error) {
+		return nil, expectedErr
+	}
+
+	service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	now := time.Now()
+	params := CreateReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  now,
+		EndTime:    now.Add(1 * time.Hour),
+	}
+
+	// 実行
+	_, err := service.CreateReservation(adminCtx, params)
+
+	// 検証
+	if err != expectedErr {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+}
+
+func TestReservationService_CreateReservation_ResourceErrors(t *testing.T) {
 	now := time.Now()
-	expectedReservations := []*model.Reservation{
-		model.NewReservation(now, now.Add(1*time.Hour)),
-		model.NewReservation(now.Add(2*time.Hour), now.Add(3*time.Hour)),
+	inactiveResource := model.NewResource("閉鎖中の会議室", "", 4, false)
+
+	tests := []struct {
+		name       string
+		resourceID string
+		wantErr    error
+	}{
+		{"存在しないリソース", "missing", ErrResourceNotFound},
+		{"無効化されたリソース", inactiveResource.ID, ErrResourceInactive},
 	}
 
-	mockRepo.findAllFunc = func() ([]*model.Reservation, error) {
-		return expectedReservations, nil
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			mockRepo := newMockReservationRepository()
+			mockRepo.createIfNoOverlapFunc = func(reservation *model.Reservation) ([]*model.Reservation, error) {
+				t.Error("Expected reservation not to be saved")
+				return nil, nil
+			}
+			service := NewReservationService(mockRepo, newMockResourceRepository(inactiveResource), repository.NewInMemoryReservationSeriesRepository())
+
+			// 実行
+			_, err := service.CreateReservation(adminCtx, CreateReservationParams{
+				ResourceID: tt.resourceID,
+				StartTime:  now,
+				EndTime:    now.Add(1 * time.Hour),
+			})
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
 	}
+}
 
-	service := NewReservationService(mockRepo)
+func TestReservationService_CreateReservation_InvalidTimeRange(t *testing.T) {
+	// 準備
+	mockRepo := newMockReservationRepository()
+	service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	now := time.Now()
 
 	// 実行
-	reservations, err := service.GetAllReservations()
+	_, err := service.CreateReservation(adminCtx, CreateReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  now,
+		EndTime:    now,
+	})
+
+	// 検証
+	if !errors.Is(err, ErrInvalidTimeRange) {
+		t.Errorf("Expected ErrInvalidTimeRange, got %v", err)
+	}
+}
+
+func TestReservationService_UpdateReservation(t *testing.T) {
+	// 準備
+	mockRepo := newMockReservationRepository()
+	now := time.Now()
+	existing := model.NewReservation(activeResource.ID, now, now.Add(1*time.Hour))
+	existing.UpdatedAt = existing.UpdatedAt.Add(-time.Hour)
+	mockRepo.findByIDFunc = func(id string) (*model.Reservation, error) {
+		if id == existing.ID {
+			return existing, nil
+		}
+		return nil, nil
+	}
+	var saved *model.Reservation
+	mockRepo.updateIfNoOverlapFunc = func(reservation *model.Reservation) ([]*model.Reservation, error) {
+		saved = reservation
+		return nil, nil
+	}
+
+	service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	newEnd := now.Add(2 * time.Hour)
+
+	// 実行：終了時刻だけを変更
+	updated, err := service.UpdateReservation(adminCtx, existing.ID, UpdateReservationParams{EndTime: &newEnd})
 
 	// 検証
 	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if saved == nil {
+		t.Fatal("Expected reservation to be saved in repository")
+	}
+	if updated.ID != existing.ID || !updated.CreatedAt.Equal(existing.CreatedAt) {
+		t.Error("Expected ID and CreatedAt to be preserved")
+	}
+	if !updated.StartTime.Equal(existing.StartTime) {
+		t.Errorf("Expected start time %v to be unchanged, got %v", existing.StartTime, updated.StartTime)
+	}
+	if !updated.EndTime.Equal(newEnd) {
+		t.Errorf("Expected end time %v, got %v", newEnd, updated.EndTime)
+	}
+	if !updated.UpdatedAt.After(existing.UpdatedAt) {
+		t.Error("Expected UpdatedAt to be bumped")
 	}
-	if len(reservations) != len(expectedReservations) {
-		t.Errorf("Expected %d reservations, got %d", len(expectedReservations), len(reservations))
+	if !existing.EndTime.Equal(now.Add(1 * time.Hour)) {
+		t.Error("Expected the stored reservation not to be mutated in place")
+	}
+}
+
+func TestReservationService_UpdateReservation_Errors(t *testing.T) {
+	now := time.Now()
+	existing := model.NewReservation(activeResource.ID, now, now.Add(1*time.Hour))
+	other := model.NewReservation(activeResource.ID, now.Add(2*time.Hour), now.Add(3*time.Hour))
+	beforeStart := now.Add(-1 * time.Hour)
+	overlappingEnd := now.Add(150 * time.Minute)
+	missingResource := "missing"
+
+	tests := []struct {
+		name      string
+		id        string
+		params    UpdateReservationParams
+		conflicts []*model.Reservation
+		wantErr   error
+	}{
+		{"存在しない予約", "missing", UpdateReservationParams{}, nil, ErrReservationNotFound},
+		{"終了時刻が開始時刻より前", existing.ID, UpdateReservationParams{EndTime: &beforeStart}, nil, ErrInvalidTimeRange},
+		{"存在しないリソース", existing.ID, UpdateReservationParams{ResourceID: &missingResource}, nil, ErrResourceNotFound},
+		{"版が一致しない", existing.ID, UpdateReservationParams{Version: existing.Version + 1}, nil, ErrVersionConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			mockRepo := newMockReservationRepository()
+			mockRepo.findByIDFunc = func(id string) (*model.Reservation, error) {
+				if id == existing.ID {
+					return existing, nil
+				}
+				return nil, nil
+			}
+			mockRepo.updateIfNoOverlapFunc = func(reservation *model.Reservation) ([]*model.Reservation, error) {
+				t.Error("Expected reservation not to be saved")
+				return nil, nil
+			}
+			service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+			// 実行
+			_, err := service.UpdateReservation(adminCtx, tt.id, tt.params)
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("他の予約と重なる", func(t *testing.T) {
+		// 準備
+		mockRepo := newMockReservationRepository()
+		mockRepo.findByIDFunc = func(id string) (*model.Reservation, error) {
+			return existing, nil
+		}
+		mockRepo.updateIfNoOverlapFunc = func(reservation *model.Reservation) ([]*model.Reservation, error) {
+			return []*model.Reservation{other}, nil
+		}
+		service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+		// 実行
+		_, err := service.UpdateReservation(adminCtx, existing.ID, UpdateReservationParams{EndTime: &overlappingEnd})
+
+		// 検証
+		var conflictErr *ConflictError
+		if !errors.As(err, &conflictErr) {
+			t.Fatalf("Expected ConflictError, got %v", err)
+		}
+		if conflictErr.Conflicts[0].ID != other.ID {
+			t.Errorf("Expected conflict with %s, got %s", other.ID, conflictErr.Conflicts[0].ID)
+		}
+	})
+}
+
+func TestReservationService_GetAllReservations_Query(t *testing.T) {
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		params  ListReservationsParams
+		want    repository.ReservationQuery
+		wantErr error
+	}{
+		{name: "省略時", want: repository.ReservationQuery{SortBy: repository.SortByStartTime, Limit: DefaultListLimit + 1}},
+		{
+			name: "すべて指定",
+			params: ListReservationsParams{
+				ResourceID: "room-a", From: from, To: from.AddDate(0, 0, 7), CreatedAfter: from.AddDate(0, -1, 0),
+				MinDuration: 30 * time.Minute, MaxDuration: 2 * time.Hour, Statuses: []model.ReservationStatus{model.StatusCancelled},
+				SortBy: "createdAt", Descending: true, Limit: 10,
+			},
+			want: repository.ReservationQuery{
+				ResourceID: "room-a", From: from, To: from.AddDate(0, 0, 7), CreatedAfter: from.AddDate(0, -1, 0),
+				MinDuration: 30 * time.Minute, MaxDuration: 2 * time.Hour, Statuses: []model.ReservationStatus{model.StatusCancelled},
+				SortBy: repository.SortByCreatedAt, Descending: true, Limit: 11,
+			},
+		},
+		{name: "期間の上限ちょうど", params: ListReservationsParams{From: from, To: from.Add(MaxListWindow)}, want: repository.ReservationQuery{From: from, To: from.Add(MaxListWindow), SortBy: repository.SortByStartTime, Limit: DefaultListLimit + 1}},
+		{name: "件数の上限ちょうど", params: ListReservationsParams{Limit: MaxListLimit}, want: repository.ReservationQuery{SortBy: repository.SortByStartTime, Limit: MaxListLimit + 1}},
+		{name: "fromだけ指定", params: ListReservationsParams{From: from}, wantErr: ErrInvalidWindow},
+		{name: "toだけ指定", params: ListReservationsParams{To: from}, wantErr: ErrInvalidWindow},
+		{name: "fromとtoが逆", params: ListReservationsParams{From: from, To: from.Add(-time.Hour)}, wantErr: ErrInvalidWindow},
+		{name: "fromとtoが同じ", params: ListReservationsParams{From: from, To: from}, wantErr: ErrInvalidWindow},
+		{name: "期間が長すぎる", params: ListReservationsParams{From: from, To: from.Add(MaxListWindow + time.Second)}, wantErr: ErrWindowTooLarge},
+		{name: "最短が最長より長い", params: ListReservationsParams{MinDuration: 2 * time.Hour, MaxDuration: time.Hour}, wantErr: ErrInvalidDuration},
+		{name: "負の長さ", params: ListReservationsParams{MinDuration: -time.Hour}, wantErr: ErrInvalidDuration},
+		{name: "未定義の並べ替え", params: ListReservationsParams{SortBy: "title"}, wantErr: ErrInvalidSort},
+		{name: "件数が多すぎる", params: ListReservationsParams{Limit: MaxListLimit + 1}, wantErr: ErrInvalidLimit},
+		{name: "負の件数", params: ListReservationsParams{Limit: -1}, wantErr: ErrInvalidLimit},
+		{name: "不正なカーソル", params: ListReservationsParams{Cursor: "not-a-cursor"}, wantErr: ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			mockRepo := newMockReservationRepository()
+			var received *repository.ReservationQuery
+			mockRepo.listFunc = func(query repository.ReservationQuery) ([]*model.Reservation, error) {
+				received = &query
+				return []*model.Reservation{}, nil
+			}
+			service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+			// 実行
+			page, err := service.GetAllReservations(adminCtx, tt.params)
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				if received != nil {
+					t.Error("Expected List not to be called")
+				}
+				return
+			}
+			if received == nil {
+				t.Fatal("Expected List to be called")
+			}
+			if !reflect.DeepEqual(*received, tt.want) {
+				t.Errorf("Expected query %+v, got %+v", tt.want, *received)
+			}
+			if page.NextCursor != "" {
+				t.Errorf("Expected no next cursor, got %q", page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestReservationService_GetAllReservations_Paging(t *testing.T) {
+	// 準備 - 開始時刻が同じ予約を含めて 5 件登録する
+	repo := repository.NewInMemoryReservationRepository()
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	var want []string
+	for i, offset := range []int{0, 1, 1, 2, 3} {
+		r := model.NewReservation(activeResource.ID, start.Add(time.Duration(offset)*time.Hour), start.Add(time.Duration(offset+1)*time.Hour))
+		r.ID = fmt.Sprintf("reservation-%d", i)
+		if err := repo.Create(r); err != nil {
+			t.Fatalf("Failed to create reservation: %v", err)
+		}
+		want = append(want, r.ID)
+	}
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+	for _, descending := range []bool{false, true} {
+		t.Run(fmt.Sprintf("descending=%v", descending), func(t *testing.T) {
+			// 実行 - 2 件ずつカーソルをたどる
+			var got []string
+			params := ListReservationsParams{Descending: descending, Limit: 2}
+			for pages := 0; ; pages++ {
+				if pages > len(want) {
+					t.Fatal("Expected paging to finish")
+				}
+				page, err := service.GetAllReservations(adminCtx, params)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				for _, r := range page.Reservations {
+					got = append(got, r.ID)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				params.Cursor = page.NextCursor
+			}
+
+			// 検証
+			expected := slices.Clone(want)
+			if descending {
+				slices.Reverse(expected)
+			}
+			if !slices.Equal(got, expected) {
+				t.Errorf("Expected %v, got %v", expected, got)
+			}
+		})
 	}
-	for i, r := range reservations {
-		if r.ID != expectedReservations[i].ID {
-			t.Errorf("Expected reservation ID %s, got %s", expectedReservations[i].ID, r.ID)
+
+	t.Run("並べ替えの条件が異なるカーソル", func(t *testing.T) {
+		page, err := service.GetAllReservations(adminCtx, ListReservationsParams{Limit: 2})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		_, err = service.GetAllReservations(adminCtx, ListReservationsParams{SortBy: "createdAt", Limit: 2, Cursor: page.NextCursor})
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor, got %v", err)
 		}
+	})
+}
+
+func TestReservationService_Versions(t *testing.T) {
+	// 準備
+	repo := repository.NewInMemoryReservationRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	created, err := service.CreateReservation(adminCtx, CreateReservationParams{ResourceID: activeResource.ID, StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+	if created.Version != 1 {
+		t.Fatalf("Expected version 1, got %d", created.Version)
+	}
+
+	// 実行：読み込んだ版を指定した変更は成功し、版が進む
+	title := "定例会議"
+	updated, err := service.UpdateReservation(adminCtx, created.ID, UpdateReservationParams{Title: &title, Version: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("Expected version 2, got %d", updated.Version)
+	}
+
+	// 実行：古い版を指定した変更・状態の変更・取り消しは拒否される
+	if _, err := service.UpdateReservation(adminCtx, created.ID, UpdateReservationParams{Title: &title, Version: 1}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("UpdateReservation: expected ErrVersionConflict, got %v", err)
+	}
+	if _, err := service.ChangeStatus(adminCtx, created.ID, ChangeStatusParams{Status: model.StatusCompleted, Version: 1}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("ChangeStatus: expected ErrVersionConflict, got %v", err)
+	}
+	if err := service.DeleteReservation(adminCtx, created.ID, DeleteReservationParams{Version: 1}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("DeleteReservation: expected ErrVersionConflict, got %v", err)
+	}
+
+	// 実行：最新の版を指定すれば取り消せる。取り消し済みの予約には版に関わらず何もしない
+	if err := service.DeleteReservation(adminCtx, created.ID, DeleteReservationParams{Version: 2}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := service.DeleteReservation(adminCtx, created.ID, DeleteReservationParams{Version: 2}); err != nil {
+		t.Errorf("Expected repeated cancellation to succeed, got %v", err)
+	}
+
+	// 検証
+	stored, _ := repo.FindByID(created.ID)
+	if stored.Status != model.StatusCancelled || stored.Version != 3 {
+		t.Errorf("Expected cancelled reservation at version 3, got %s at version %d", stored.Status, stored.Version)
 	}
 }
 
 func TestReservationService_DeleteReservation(t *testing.T) {
 	// 準備
 	mockRepo := newMockReservationRepository()
-	testID := "test-id"
-	var deletedID string
+	existing := model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour))
+	var updated *model.Reservation
 
+	mockRepo.findByIDFunc = func(id string) (*model.Reservation, error) {
+		return existing, nil
+	}
+	mockRepo.updateFunc = func(reservation *model.Reservation) error {
+		updated = reservation
+		return nil
+	}
 	mockRepo.deleteFunc = func(id string) error {
-		deletedID = id
+		t.Error("Expected reservation not to be deleted")
 		return nil
 	}
 
-	service := NewReservationService(mockRepo)
+	service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
 
 	// 実行
-	err := service.DeleteReservation(testID)
+	err := service.DeleteReservation(adminCtx, existing.ID, DeleteReservationParams{Reason: "会議が中止になった"})
 
 	// 検証
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if deletedID != testID {
-		t.Errorf("Expected ID %s to be deleted, got %s", testID, deletedID)
+	if updated == nil || updated.ID != existing.ID {
+		t.Fatalf("Expected reservation %s to be updated, got %v", existing.ID, updated)
+	}
+	if updated.Status != model.StatusCancelled || updated.CancelledAt == nil || updated.CancellationReason != "会議が中止になった" {
+		t.Errorf("Expected reservation to be cancelled with reason, got %+v", updated)
+	}
+	if existing.Status != model.StatusConfirmed {
+		t.Error("Expected original reservation not to be mutated")
 	}
 }
 
 func TestReservationService_DeleteReservation_Error(t *testing.T) {
 	// 準備
 	mockRepo := newMockReservationRepository()
-	testID := "test-id"
-	expectedErr := errors.New("delete error")
+	existing := model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour))
+	expectedErr := errors.New("update error")
 
-	mockRepo.deleteFunc = func(id string) error {
+	mockRepo.findByIDFunc = func(id string) (*model.Reservation, error) {
+		return existing, nil
+	}
+	mockRepo.updateFunc = func(reservation *model.Reservation) error {
 		return expectedErr
 	}
 
-	service := NewReservationService(mockRepo)
+	service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
 
 	// 実行
-	err := service.DeleteReservation(testID)
+	err := service.DeleteReservation(adminCtx, existing.ID, DeleteReservationParams{})
 
 	// 検証
 	if err != expectedErr {
 		t.Errorf("Expected error %v, got %v", expectedErr, err)
 	}
-}
\ No newline at end of file
+}
+
+func TestReservationService_CancellationReasonTooLong(t *testing.T) {
+	reason := strings.Repeat("あ", MaxCancellationReasonLength+1)
+
+	tests := []struct {
+		name   string
+		cancel func(service *ReservationService, id string) error
+	}{
+		{name: "取り消し", cancel: func(service *ReservationService, id string) error {
+			return service.DeleteReservation(adminCtx, id, DeleteReservationParams{Reason: reason})
+		}},
+		{name: "状態の変更", cancel: func(service *ReservationService, id string) error {
+			_, err := service.ChangeStatus(adminCtx, id, ChangeStatusParams{Status: model.StatusCancelled, Reason: reason})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			repo := repository.NewInMemoryReservationRepository()
+			existing := model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour))
+			if err := repo.Create(existing); err != nil {
+				t.Fatalf("Failed to create reservation: %v", err)
+			}
+			service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+			// 実行
+			err := tt.cancel(service, existing.ID)
+
+			// 検証
+			var detailsErr *InvalidDetailsError
+			if !errors.As(err, &detailsErr) || detailsErr.Field != "reason" {
+				t.Fatalf("Expected an invalid reason error, got %v", err)
+			}
+			if saved, _ := repo.FindByID(existing.ID); saved.Status == model.StatusCancelled {
+				t.Error("Expected reservation not to be cancelled")
+			}
+		})
+	}
+}
+
+func TestReservationService_DeleteReservation_ByStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     model.ReservationStatus
+		wantErr    error
+		wantUpdate bool
+	}{
+		{name: "仮予約は取り消せる", status: model.StatusPending, wantUpdate: true},
+		{name: "取り消し済みは何もしない", status: model.StatusCancelled},
+		{name: "完了した予約は取り消せない", status: model.StatusCompleted, wantErr: ErrInvalidStatusTransition},
+		{name: "無断キャンセルは取り消せない", status: model.StatusNoShow, wantErr: ErrInvalidStatusTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			mockRepo := newMockReservationRepository()
+			existing := model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour))
+			existing.Status = tt.status
+			updated := false
+			mockRepo.findByIDFunc = func(id string) (*model.Reservation, error) {
+				return existing, nil
+			}
+			mockRepo.updateFunc = func(reservation *model.Reservation) error {
+				updated = true
+				return nil
+			}
+			service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+			// 実行
+			err := service.DeleteReservation(adminCtx, existing.ID, DeleteReservationParams{})
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if updated != tt.wantUpdate {
+				t.Errorf("Expected update %v, got %v", tt.wantUpdate, updated)
+			}
+		})
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	allowed := map[model.ReservationStatus][]model.ReservationStatus{
+		model.StatusPending:   {model.StatusConfirmed, model.StatusCancelled},
+		model.StatusConfirmed: {model.StatusCancelled, model.StatusCompleted, model.StatusNoShow},
+	}
+
+	for _, from := range model.ReservationStatuses {
+		for _, to := range model.ReservationStatuses {
+			want := slices.Contains(allowed[from], to)
+			if got := CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestReservationService_ChangeStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    model.ReservationStatus
+		to      model.ReservationStatus
+		wantErr error
+	}{
+		{name: "仮予約を確定", from: model.StatusPending, to: model.StatusConfirmed},
+		{name: "利用完了", from: model.StatusConfirmed, to: model.StatusCompleted},
+		{name: "無断キャンセル", from: model.StatusConfirmed, to: model.StatusNoShow},
+		{name: "完了から確定には戻せない", from: model.StatusCompleted, to: model.StatusConfirmed, wantErr: ErrInvalidStatusTransition},
+		{name: "取り消しから確定には戻せない", from: model.StatusCancelled, to: model.StatusConfirmed, wantErr: ErrInvalidStatusTransition},
+		{name: "未定義の状態", from: model.StatusConfirmed, to: "deleted", wantErr: ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			repo := repository.NewInMemoryReservationRepository()
+			existing := model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour))
+			existing.Status = tt.from
+			if err := repo.Create(existing); err != nil {
+				t.Fatalf("Failed to create reservation: %v", err)
+			}
+			service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+			// 実行
+			reservation, err := service.ChangeStatus(adminCtx, existing.ID, ChangeStatusParams{Status: tt.to})
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if reservation.Status != tt.to {
+				t.Errorf("Expected status %s, got %s", tt.to, reservation.Status)
+			}
+			saved, _ := repo.FindByID(existing.ID)
+			if saved.Status != tt.to {
+				t.Errorf("Expected saved status %s, got %s", tt.to, saved.Status)
+			}
+		})
+	}
+}
+
+func TestReservationService_UpdateReservation_NotEditable(t *testing.T) {
+	// 準備
+	mockRepo := newMockReservationRepository()
+	existing := model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour))
+	existing.Status = model.StatusCancelled
+	mockRepo.findByIDFunc = func(id string) (*model.Reservation, error) {
+		return existing, nil
+	}
+	service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+	// 実行
+	newEnd := existing.EndTime.Add(time.Hour)
+	_, err := service.UpdateReservation(adminCtx, existing.ID, UpdateReservationParams{EndTime: &newEnd})
+
+	// 検証
+	if !errors.Is(err, ErrReservationNotEditable) {
+		t.Errorf("Expected ErrReservationNotEditable, got %v", err)
+	}
+}
+
+func TestReservationService_CreateRecurringReservation(t *testing.T) {
+	// 準備
+	repo := repository.NewInMemoryReservationRepository()
+	seriesRepo := repository.NewInMemoryReservationSeriesRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), seriesRepo)
+
+	jst := time.FixedZone("JST", 9*60*60)
+	start := time.Date(2024, 4, 2, 10, 0, 0, 0, jst) // 火曜日
+
+	// 3回目と重なる予約を先に入れておく
+	existing := model.NewReservation(activeResource.ID, start.AddDate(0, 0, 14).Add(30*time.Minute), start.AddDate(0, 0, 14).Add(2*time.Hour))
+	if err := repo.Create(existing); err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+
+	// 実行
+	result, err := service.CreateRecurringReservation(adminCtx, CreateRecurringReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  start,
+		EndTime:    start.Add(time.Hour),
+		Recurrence: "FREQ=WEEKLY;BYDAY=TU;COUNT=4",
+	})
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Reservations) != 3 {
+		t.Fatalf("Expected 3 booked occurrences, got %d", len(result.Reservations))
+	}
+	for _, r := range result.Reservations {
+		if r.SeriesID != result.Series.ID {
+			t.Errorf("Expected series ID %s, got %s", result.Series.ID, r.SeriesID)
+		}
+		if r.EndTime.Sub(r.StartTime) != time.Hour {
+			t.Errorf("Expected 1 hour occurrence, got %v", r.EndTime.Sub(r.StartTime))
+		}
+	}
+	if len(result.Skipped) != 1 || !result.Skipped[0].StartTime.Equal(start.AddDate(0, 0, 14)) {
+		t.Fatalf("Expected third occurrence to be skipped, got %v", result.Skipped)
+	}
+	if len(result.Skipped[0].Conflicts) != 1 || result.Skipped[0].Conflicts[0].ID != existing.ID {
+		t.Errorf("Expected conflict with %s, got %v", existing.ID, result.Skipped[0].Conflicts)
+	}
+
+	series, _ := seriesRepo.FindByID(result.Series.ID)
+	if series == nil {
+		t.Error("Expected series to be saved")
+	}
+}
+
+func TestReservationService_CreateRecurringReservation_AllConflict(t *testing.T) {
+	// 準備
+	repo := repository.NewInMemoryReservationRepository()
+	seriesRepo := repository.NewInMemoryReservationSeriesRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), seriesRepo)
+
+	start := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	existing := model.NewReservation(activeResource.ID, start, start.AddDate(0, 0, 3))
+	if err := repo.Create(existing); err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+
+	// 実行
+	result, err := service.CreateRecurringReservation(adminCtx, CreateRecurringReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  start,
+		EndTime:    start.Add(time.Hour),
+		Recurrence: "FREQ=DAILY;COUNT=3",
+	})
+
+	// 検証
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected ConflictError, got %v", err)
+	}
+	if result != nil {
+		t.Errorf("Expected nil result, got %v", result)
+	}
+	if len(conflictErr.Conflicts) != 3 {
+		t.Errorf("Expected 3 conflicts, got %d", len(conflictErr.Conflicts))
+	}
+	reservations, _ := repo.FindAll()
+	if len(reservations) != 1 {
+		t.Errorf("Expected no occurrences to be booked, got %d reservations", len(reservations))
+	}
+}
+
+// failingReservationRepository は createFailAt 回目の CreateIfNoOverlap と updateFailAt 回目の Update を失敗させる。
+// createErr を指定しない場合、CreateIfNoOverlap は一般的なエラーを返す。
+type failingReservationRepository struct {
+	repository.ReservationRepository
+	createFailAt int
+	createErr    error
+	updateFailAt int
+	created      int
+	updated      int
+}
+
+func (r *failingReservationRepository) CreateIfNoOverlap(reservation *model.Reservation) ([]*model.Reservation, error) {
+	r.created++
+	if r.created == r.createFailAt {
+		if r.createErr != nil {
+			return nil, r.createErr
+		}
+		return nil, errors.New("insert error")
+	}
+	return r.ReservationRepository.CreateIfNoOverlap(reservation)
+}
+
+func (r *failingReservationRepository) Update(reservation *model.Reservation) error {
+	r.updated++
+	if r.updated == r.updateFailAt {
+		return ErrVersionConflict
+	}
+	return r.ReservationRepository.Update(reservation)
+}
+
+// failingSeriesRepository は繰り返し予約の保存を失敗させる
+type failingSeriesRepository struct {
+	repository.ReservationSeriesRepository
+}
+
+func (r *failingSeriesRepository) Create(series *model.ReservationSeries) error {
+	return errors.New("insert error")
+}
+
+func TestReservationService_CreateRecurringReservation_RollsBack(t *testing.T) {
+	tests := []struct {
+		name        string
+		failAt      int
+		failSeries  bool
+		wantCreated int
+	}{
+		{name: "3回目の保存に失敗する", failAt: 3, wantCreated: 2},
+		{name: "シリーズの保存に失敗する", failSeries: true, wantCreated: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			repo := &failingReservationRepository{ReservationRepository: repository.NewInMemoryReservationRepository(), createFailAt: tt.failAt}
+			var seriesRepo repository.ReservationSeriesRepository = repository.NewInMemoryReservationSeriesRepository()
+			if tt.failSeries {
+				seriesRepo = &failingSeriesRepository{ReservationSeriesRepository: seriesRepo}
+			}
+			service := NewReservationService(repo, newMockResourceRepository(activeResource), seriesRepo)
+			start := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+
+			// 実行
+			result, err := service.CreateRecurringReservation(adminCtx, CreateRecurringReservationParams{
+				ResourceID: activeResource.ID,
+				StartTime:  start,
+				EndTime:    start.Add(time.Hour),
+				Recurrence: "FREQ=DAILY;COUNT=3",
+			})
+
+			// 検証 - 作成済みの回は取り消され、有効な予約は残らない
+			if err == nil || result != nil {
+				t.Fatalf("Expected an error, got %v, %v", result, err)
+			}
+			reservations, _ := repo.FindAll()
+			if len(reservations) != tt.wantCreated {
+				t.Fatalf("Expected %d saved occurrences, got %d", tt.wantCreated, len(reservations))
+			}
+			for _, r := range reservations {
+				if r.Status != model.StatusCancelled || r.CancelledAt == nil {
+					t.Errorf("Expected occurrence %s to be cancelled, got %s", r.ID, r.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestReservationService_CreateRecurringReservation_InvalidRule(t *testing.T) {
+	start := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		recurrence string
+		wantErr    error
+	}{
+		{name: "解析できない", recurrence: "FREQ=HOURLY;COUNT=3", wantErr: ErrInvalidRecurrence},
+		{name: "終わりがない", recurrence: "FREQ=WEEKLY", wantErr: ErrInvalidRecurrence},
+		{name: "UNTILが開始より前", recurrence: "FREQ=DAILY;UNTIL=20240301T000000Z", wantErr: ErrInvalidRecurrence},
+		{name: "COUNTが期間の上限を超える", recurrence: "FREQ=MONTHLY;COUNT=24", wantErr: ErrSeriesTooLong},
+		{name: "UNTILが期間の上限を超える", recurrence: "FREQ=DAILY;UNTIL=20260101T000000Z", wantErr: ErrSeriesTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			mockRepo := newMockReservationRepository()
+			mockRepo.createIfNoOverlapFunc = func(reservation *model.Reservation) ([]*model.Reservation, error) {
+				t.Error("Expected no occurrence to be booked")
+				return nil, nil
+			}
+			service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+			// 実行
+			_, err := service.CreateRecurringReservation(adminCtx, CreateRecurringReservationParams{
+				ResourceID: activeResource.ID,
+				StartTime:  start,
+				EndTime:    start.Add(time.Hour),
+				Recurrence: tt.recurrence,
+			})
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// newWeeklySeries は8回の毎週の繰り返し予約を作成し、サービスと各回を開始時刻順に返す
+func newWeeklySeries(t *testing.T) (*ReservationService, repository.ReservationRepository, repository.ReservationSeriesRepository, *RecurringReservationResult) {
+	t.Helper()
+	repo := repository.NewInMemoryReservationRepository()
+	seriesRepo := repository.NewInMemoryReservationSeriesRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), seriesRepo)
+
+	start := time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+	result, err := service.CreateRecurringReservation(adminCtx, CreateRecurringReservationParams{
+		ResourceID: activeResource.ID,
+		StartTime:  start,
+		EndTime:    start.Add(time.Hour),
+		Recurrence: "FREQ=WEEKLY;BYDAY=TU;COUNT=8",
+	})
+	if err != nil || len(result.Reservations) != 8 {
+		t.Fatalf("Failed to create series: %v", err)
+	}
+	return service, repo, seriesRepo, result
+}
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Scope
+		wantErr bool
+	}{
+		{input: "", want: ScopeThis},
+		{input: "this", want: ScopeThis},
+		{input: "following", want: ScopeFollowing},
+		{input: "all", want: ScopeAll},
+		{input: "everything", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseScope(tt.input)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidScope) {
+				t.Errorf("ParseScope(%q): expected ErrInvalidScope, got %v", tt.input, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseScope(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestReservationService_Details(t *testing.T) {
+	// 準備
+	service, repo, _, result := newWeeklySeries(t)
+	first := result.Reservations[0]
+
+	// 実行 - 全ての回の目的と参加者を変更する
+	title := "  定例会議  "
+	attendees := []string{" A@Example.com", "a@example.com", "", "b@example.com"}
+	updated, err := service.UpdateReservation(adminCtx, first.ID, UpdateReservationParams{Title: &title, Attendees: &attendees, Scope: ScopeAll})
+
+	// 検証 - タイトルは前後の空白を除き、参加者は小文字にそろえて空のものと重複を除く
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	wantAttendees := []string{"a@example.com", "b@example.com"}
+	if updated.Title != "定例会議" || !slices.Equal(updated.Attendees, wantAttendees) {
+		t.Errorf("Unexpected details %q %v", updated.Title, updated.Attendees)
+	}
+	occurrences, _ := repo.FindBySeriesID(result.Series.ID)
+	for i, occurrence := range occurrences {
+		if occurrence.Title != "定例会議" || !slices.Equal(occurrence.Attendees, wantAttendees) {
+			t.Errorf("occurrence %d: unexpected details %q %v", i+1, occurrence.Title, occurrence.Attendees)
+		}
+		if !occurrence.StartTime.Equal(result.Reservations[i].StartTime) {
+			t.Errorf("occurrence %d: expected time to be unchanged", i+1)
+		}
+	}
+
+	// 実行 - 説明だけを変更すると他の項目は変わらない
+	description := "週次の進捗確認"
+	updated, err = service.UpdateReservation(adminCtx, first.ID, UpdateReservationParams{Description: &description})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Description != description || updated.Title != "定例会議" || !slices.Equal(updated.Attendees, wantAttendees) {
+		t.Errorf("Unexpected details %+v", updated)
+	}
+}
+
+func TestReservationDetails_Validate(t *testing.T) {
+	manyAttendees := make([]string, MaxAttendees+1)
+	for i := range manyAttendees {
+		manyAttendees[i] = fmt.Sprintf("user%d@example.com", i)
+	}
+
+	tests := []struct {
+		name      string
+		details   ReservationDetails
+		wantField string
+	}{
+		{name: "上限の長さ", details: ReservationDetails{Title: strings.Repeat("あ", MaxTitleLength), Description: strings.Repeat("a", MaxDescriptionLength), Attendees: manyAttendees[:MaxAttendees]}},
+		{name: "前後の空白は目的の長さに含めない", details: ReservationDetails{Title: " " + strings.Repeat("あ", MaxTitleLength) + " "}},
+		{name: "メールアドレスは小文字にそろえて確認する", details: ReservationDetails{Attendees: []string{" A@Example.com ", ""}}},
+		{name: "長すぎる目的", details: ReservationDetails{Title: strings.Repeat("あ", MaxTitleLength+1)}, wantField: "title"},
+		{name: "長すぎる説明", details: ReservationDetails{Description: strings.Repeat("a", MaxDescriptionLength+1)}, wantField: "description"},
+		{name: "多すぎる参加者", details: ReservationDetails{Attendees: manyAttendees}, wantField: "attendees"},
+		{name: "不正なメールアドレス", details: ReservationDetails{Attendees: []string{"not-an-email"}}, wantField: "attendees"},
+		{name: "表示名つきのメールアドレス", details: ReservationDetails{Attendees: []string{"Taro <taro@example.com>"}}, wantField: "attendees"},
+		{name: "長すぎるメールアドレス", details: ReservationDetails{Attendees: []string{strings.Repeat("a", MaxEmailLength) + "@example.com"}}, wantField: "attendees"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 実行
+			err := tt.details.Validate()
+
+			// 検証
+			var detailsErr *InvalidDetailsError
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &detailsErr) || detailsErr.Field != tt.wantField {
+				t.Errorf("Expected invalid %s, got %v", tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestReservationService_InvalidDetails(t *testing.T) {
+	// 準備
+	service, repo, _, result := newWeeklySeries(t)
+	first := result.Reservations[0]
+	longTitle := strings.Repeat("あ", MaxTitleLength+1)
+	start := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Hour)
+	var detailsErr *InvalidDetailsError
+
+	// 作成・繰り返し予約の作成・変更のいずれでも保存する前に拒否する
+	_, err := service.CreateReservation(adminCtx, CreateReservationParams{ResourceID: activeResource.ID, StartTime: start, EndTime: start.Add(time.Hour), Details: ReservationDetails{Title: longTitle}})
+	if !errors.As(err, &detailsErr) {
+		t.Errorf("Expected InvalidDetailsError on create, got %v", err)
+	}
+	_, err = service.CreateRecurringReservation(adminCtx, CreateRecurringReservationParams{ResourceID: activeResource.ID, StartTime: start, EndTime: start.Add(time.Hour), Recurrence: "FREQ=DAILY;COUNT=2", Details: ReservationDetails{Attendees: []string{"not-an-email"}}})
+	if !errors.As(err, &detailsErr) {
+		t.Errorf("Expected InvalidDetailsError on recurring create, got %v", err)
+	}
+	_, err = service.UpdateReservation(adminCtx, first.ID, UpdateReservationParams{Title: &longTitle})
+	if !errors.As(err, &detailsErr) {
+		t.Errorf("Expected InvalidDetailsError on update, got %v", err)
+	}
+	if stored, _ := repo.FindByID(first.ID); stored.Title != first.Title {
+		t.Errorf("Expected the reservation to be unchanged, got title %q", stored.Title)
+	}
+}
+
+func TestReservationService_UpdateReservation_SeriesScopes(t *testing.T) {
+	// 準備
+	service, repo, seriesRepo, result := newWeeklySeries(t)
+	fifth := result.Reservations[4]
+	originalFifthStart := fifth.StartTime
+
+	// 実行 - 5回目だけ翌日に移動
+	newStart := fifth.StartTime.AddDate(0, 0, 1)
+	newEnd := newStart.Add(time.Hour)
+	moved, err := service.UpdateReservation(adminCtx, fifth.ID, UpdateReservationParams{StartTime: &newStart, EndTime: &newEnd, Scope: ScopeThis})
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !moved.StartTime.Equal(newStart) {
+		t.Errorf("Expected start time %v, got %v", newStart, moved.StartTime)
+	}
+	sixth, _ := repo.FindByID(result.Reservations[5].ID)
+	if !sixth.StartTime.Equal(result.Reservations[5].StartTime) {
+		t.Error("Expected other occurrences to be unchanged")
+	}
+
+	// 実行 - 5回目以降を30分後ろにずらす
+	laterStart := moved.StartTime.Add(30 * time.Minute)
+	laterEnd := moved.EndTime.Add(30 * time.Minute)
+	if _, err := service.UpdateReservation(adminCtx, fifth.ID, UpdateReservationParams{StartTime: &laterStart, EndTime: &laterEnd, Scope: ScopeFollowing}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	occurrences, _ := repo.FindBySeriesID(result.Series.ID)
+	for i, occurrence := range occurrences {
+		want := result.Reservations[i].StartTime
+		if i >= 4 {
+			want = want.Add(30 * time.Minute)
+		}
+		if i == 4 {
+			want = want.AddDate(0, 0, 1)
+		}
+		if !occurrence.StartTime.Equal(want) {
+			t.Errorf("occurrence %d: expected start %v, got %v", i+1, want, occurrence.StartTime)
+		}
+		if occurrence.EndTime.Sub(occurrence.StartTime) != time.Hour {
+			t.Errorf("occurrence %d: expected duration to be kept", i+1)
+		}
+	}
+
+	exceptions, _ := seriesRepo.FindExceptions(result.Series.ID)
+	if len(exceptions) != 4 {
+		t.Fatalf("Expected 4 modified occurrences, got %d", len(exceptions))
+	}
+	if !exceptions[0].OriginalStartTime.Equal(originalFifthStart) || exceptions[0].ReservationID != fifth.ID {
+		t.Errorf("Expected exception keyed by original start time %v, got %+v", originalFifthStart, exceptions[0])
+	}
+	for _, exception := range exceptions {
+		if exception.Type != model.ExceptionModified {
+			t.Errorf("Expected modified exception, got %s", exception.Type)
+		}
+	}
+}
+
+func TestReservationService_UpdateReservation_SeriesConflictRollsBack(t *testing.T) {
+	// 準備
+	service, repo, _, result := newWeeklySeries(t)
+	blocker := model.NewReservation(activeResource.ID, result.Reservations[7].EndTime, result.Reservations[7].EndTime.Add(time.Hour))
+	if err := repo.Create(blocker); err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+
+	// 実行 - 全ての回を1時間延長すると最後の回だけが重なる
+	newEnd := result.Reservations[0].EndTime.Add(time.Hour)
+	_, err := service.UpdateReservation(adminCtx, result.Reservations[0].ID, UpdateReservationParams{EndTime: &newEnd, Scope: ScopeAll})
+
+	// 検証
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected ConflictError, got %v", err)
+	}
+	occurrences, _ := repo.FindBySeriesID(result.Series.ID)
+	for i, occurrence := range occurrences {
+		if !occurrence.EndTime.Equal(result.Reservations[i].EndTime) {
+			t.Errorf("occurrence %d: expected to be rolled back, got end time %v", i+1, occurrence.EndTime)
+		}
+	}
+}
+
+func TestReservationService_DeleteReservation_SeriesScopes(t *testing.T) {
+	// 準備
+	service, repo, seriesRepo, result := newWeeklySeries(t)
+
+	// 実行 - 2回目だけ取り消し、7回目以降を取り消す
+	if err := service.DeleteReservation(adminCtx, result.Reservations[1].ID, DeleteReservationParams{Scope: ScopeThis}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := service.DeleteReservation(adminCtx, result.Reservations[6].ID, DeleteReservationParams{Scope: ScopeFollowing}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	occurrences, _ := repo.FindBySeriesID(result.Series.ID)
+	if len(occurrences) != 8 {
+		t.Errorf("Expected cancelled occurrences to be kept, got %d occurrences", len(occurrences))
+	}
+	if remaining := filterByStatus(occurrences, nil); len(remaining) != 5 {
+		t.Errorf("Expected 5 remaining occurrences, got %d", len(remaining))
+	}
+	exceptions, _ := seriesRepo.FindExceptions(result.Series.ID)
+	if len(exceptions) != 3 {
+		t.Fatalf("Expected 3 cancelled occurrences, got %d", len(exceptions))
+	}
+	for i, index := range []int{1, 6, 7} {
+		if exceptions[i].Type != model.ExceptionCancelled || exceptions[i].ReservationID != result.Reservations[index].ID {
+			t.Errorf("Expected occurrence %d to be recorded as cancelled, got %+v", index+1, exceptions[i])
+		}
+	}
+
+	// 実行 - 残りを全て取り消す
+	if err := service.DeleteReservation(adminCtx, result.Reservations[0].ID, DeleteReservationParams{Scope: ScopeAll}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証
+	detail, err := service.GetSeries(adminCtx, result.Series.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(detail.Reservations) != 0 || len(detail.Exceptions) != 8 {
+		t.Errorf("Expected all 8 occurrences to be cancelled, got %d remaining and %d exceptions", len(detail.Reservations), len(detail.Exceptions))
+	}
+}
+
+func TestReservationService_DeleteReservation_FromCancelledOccurrence(t *testing.T) {
+	// 準備 - 2回目を取り消しておく
+	service, repo, _, result := newWeeklySeries(t)
+	second := result.Reservations[1].ID
+	if err := service.DeleteReservation(adminCtx, second, DeleteReservationParams{}); err != nil {
+		t.Fatalf("Failed to cancel occurrence: %v", err)
+	}
+
+	// 実行 - 指定した回だけの取り消しは何もしない
+	if err := service.DeleteReservation(adminCtx, second, DeleteReservationParams{Scope: ScopeThis}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	occurrences, _ := repo.FindBySeriesID(result.Series.ID)
+	if remaining := filterByStatus(occurrences, nil); len(remaining) != 7 {
+		t.Fatalf("Expected 7 remaining occurrences, got %d", len(remaining))
+	}
+
+	// 実行 - 取り消し済みの回から以降を取り消す
+	if err := service.DeleteReservation(adminCtx, second, DeleteReservationParams{Scope: ScopeFollowing}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 検証 - 1回目だけが残る
+	occurrences, _ = repo.FindBySeriesID(result.Series.ID)
+	remaining := filterByStatus(occurrences, nil)
+	if len(remaining) != 1 || remaining[0].ID != result.Reservations[0].ID {
+		t.Errorf("Expected only the first occurrence to remain, got %d", len(remaining))
+	}
+}
+
+func TestReservationService_DeleteReservation_SeriesRollsBack(t *testing.T) {
+	// 準備 - 3回目の取り消しの保存が他の操作と競合する
+	_, inner, seriesRepo, result := newWeeklySeries(t)
+	repo := &failingReservationRepository{ReservationRepository: inner, updateFailAt: 3}
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), seriesRepo)
+
+	// 実行
+	err := service.DeleteReservation(adminCtx, result.Reservations[0].ID, DeleteReservationParams{Scope: ScopeAll, Reason: "中止"})
+
+	// 検証 - 取り消した回を元に戻し、例外も記録しない
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Expected ErrVersionConflict, got %v", err)
+	}
+	occurrences, _ := repo.FindBySeriesID(result.Series.ID)
+	if remaining := filterByStatus(occurrences, nil); len(remaining) != 8 {
+		t.Errorf("Expected all 8 occurrences to remain, got %d", len(remaining))
+	}
+	for _, occurrence := range occurrences {
+		if occurrence.CancelledAt != nil || occurrence.CancellationReason != "" {
+			t.Errorf("Expected occurrence %s to be restored, got %+v", occurrence.ID, occurrence)
+		}
+	}
+	if exceptions, _ := seriesRepo.FindExceptions(result.Series.ID); len(exceptions) != 0 {
+		t.Errorf("Expected no exceptions, got %d", len(exceptions))
+	}
+}
+
+func TestReservationService_GetSeries_NotFound(t *testing.T) {
+	service := NewReservationService(newMockReservationRepository(), newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+	if _, err := service.GetSeries(adminCtx, "unknown"); !errors.Is(err, ErrSeriesNotFound) {
+		t.Errorf("Expected ErrSeriesNotFound, got %v", err)
+	}
+}
+
+func TestReservationService_GetReservation(t *testing.T) {
+	existing := model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour))
+	existing.Title = "定例会議"
+	existing.Status = model.StatusCancelled
+
+	tests := []struct {
+		name      string
+		id        string
+		principal *auth.Principal
+		wantErr   error
+		wantTitle string
+	}{
+		{name: "取り消された予約も返す", id: existing.ID, principal: &auth.Principal{Role: model.RoleMember}, wantTitle: "定例会議"},
+		{name: "閲覧者には時間帯だけを返す", id: existing.ID, principal: &auth.Principal{Role: model.RoleViewer}},
+		{name: "存在しない予約", id: "unknown", principal: &auth.Principal{Role: model.RoleAdmin}, wantErr: ErrReservationNotFound},
+		{name: "匿名では参照できない", id: existing.ID, wantErr: ErrForbidden},
+		{name: "匿名では存在しない予約も参照できない", id: "unknown", wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			mockRepo := newMockReservationRepository()
+			mockRepo.findByIDFunc = func(id string) (*model.Reservation, error) {
+				if id == existing.ID {
+					return existing, nil
+				}
+				return nil, nil
+			}
+			service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, *tt.principal)
+			}
+
+			// 実行
+			got, err := service.GetReservation(ctx, tt.id)
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if got.ID != existing.ID || got.Status != model.StatusCancelled || got.Version != existing.Version {
+				t.Errorf("Expected reservation %s, got %+v", existing.ID, got)
+			}
+			if got.Title != tt.wantTitle {
+				t.Errorf("Expected title %q, got %q", tt.wantTitle, got.Title)
+			}
+		})
+	}
+}
+
+func TestReservationService_DeleteReservation_NotFound(t *testing.T) {
+	service := NewReservationService(newMockReservationRepository(), newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+	if err := service.DeleteReservation(adminCtx, "unknown", DeleteReservationParams{}); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("Expected ErrReservationNotFound, got %v", err)
+	}
+}
+
+func TestReservationService_ForbiddenBeforeLookup(t *testing.T) {
+	existing := model.NewReservation(activeResource.ID, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+	viewer := auth.WithPrincipal(context.Background(), auth.Principal{UserID: "user-viewer", Role: model.RoleViewer})
+
+	tests := []struct {
+		name string
+		call func(service *ReservationService, id string) error
+	}{
+		{name: "変更", call: func(service *ReservationService, id string) error {
+			_, err := service.UpdateReservation(viewer, id, UpdateReservationParams{})
+			return err
+		}},
+		{name: "取り消し", call: func(service *ReservationService, id string) error {
+			return service.DeleteReservation(viewer, id, DeleteReservationParams{})
+		}},
+		{name: "状態の変更", call: func(service *ReservationService, id string) error {
+			_, err := service.ChangeStatus(viewer, id, ChangeStatusParams{Status: model.StatusConfirmed})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			repo := repository.NewInMemoryReservationRepository()
+			if err := repo.Create(existing); err != nil {
+				t.Fatalf("Failed to create reservation: %v", err)
+			}
+			service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+			// 実行・検証 - 予約が存在するかに関わらず ErrForbidden を返す
+			for _, id := range []string{existing.ID, "unknown"} {
+				if err := tt.call(service, id); !errors.Is(err, ErrForbidden) {
+					t.Errorf("%s: expected ErrForbidden, got %v", id, err)
+				}
+			}
+		})
+	}
+}
+
+func TestReservationService_Ownership(t *testing.T) {
+	owner := auth.Principal{UserID: "user-owner", Role: model.RoleMember}
+	other := auth.Principal{UserID: "user-other", Role: model.RoleMember}
+	admin := auth.Principal{UserID: "user-admin", Role: model.RoleAdmin}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		wantErr   error
+	}{
+		{name: "所有者は変更できる", principal: &owner},
+		{name: "管理者は変更できる", principal: &admin},
+		{name: "他のユーザーは変更できない", principal: &other, wantErr: ErrForbidden},
+		{name: "匿名では変更できない", principal: nil, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			repo := repository.NewInMemoryReservationRepository()
+			service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+			start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+			created, err := service.CreateReservation(auth.WithPrincipal(context.Background(), owner), CreateReservationParams{
+				ResourceID: activeResource.ID,
+				StartTime:  start,
+				EndTime:    start.Add(time.Hour),
+			})
+			if err != nil {
+				t.Fatalf("Failed to create reservation: %v", err)
+			}
+			if created.UserID != owner.UserID {
+				t.Fatalf("Expected owner %s, got %q", owner.UserID, created.UserID)
+			}
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, *tt.principal)
+			}
+
+			// 実行
+			newEnd := start.Add(2 * time.Hour)
+			_, updateErr := service.UpdateReservation(ctx, created.ID, UpdateReservationParams{EndTime: &newEnd})
+			deleteErr := service.DeleteReservation(ctx, created.ID, DeleteReservationParams{})
+
+			// 検証
+			if !errors.Is(updateErr, tt.wantErr) {
+				t.Errorf("UpdateReservation: expected %v, got %v", tt.wantErr, updateErr)
+			}
+			if !errors.Is(deleteErr, tt.wantErr) {
+				t.Errorf("DeleteReservation: expected %v, got %v", tt.wantErr, deleteErr)
+			}
+		})
+	}
+}
+
+func TestReservationService_Ownership_Unowned(t *testing.T) {
+	member := auth.Principal{UserID: "user-other", Role: model.RoleMember}
+	admin := auth.Principal{UserID: "user-admin", Role: model.RoleAdmin}
+	title := "定例会議"
+
+	tests := []struct {
+		name      string
+		principal auth.Principal
+		call      func(service *ReservationService, ctx context.Context, id string) error
+		wantErr   error
+	}{
+		{name: "一般ユーザーは変更できない", principal: member, call: func(service *ReservationService, ctx context.Context, id string) error {
+			_, err := service.UpdateReservation(ctx, id, UpdateReservationParams{Title: &title})
+			return err
+		}, wantErr: ErrForbidden},
+		{name: "一般ユーザーは取り消せない", principal: member, call: func(service *ReservationService, ctx context.Context, id string) error {
+			return service.DeleteReservation(ctx, id, DeleteReservationParams{})
+		}, wantErr: ErrForbidden},
+		{name: "管理者は変更できる", principal: admin, call: func(service *ReservationService, ctx context.Context, id string) error {
+			_, err := service.UpdateReservation(ctx, id, UpdateReservationParams{Title: &title})
+			return err
+		}},
+		{name: "管理者は取り消せる", principal: admin, call: func(service *ReservationService, ctx context.Context, id string) error {
+			return service.DeleteReservation(ctx, id, DeleteReservationParams{})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備: 所有者のいない予約（adminCtx はユーザーIDを持たない）
+			repo := repository.NewInMemoryReservationRepository()
+			service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+			start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+			created, err := service.CreateReservation(adminCtx, CreateReservationParams{
+				ResourceID: activeResource.ID,
+				StartTime:  start,
+				EndTime:    start.Add(time.Hour),
+			})
+			if err != nil {
+				t.Fatalf("Failed to create reservation: %v", err)
+			}
+			if created.UserID != "" {
+				t.Fatalf("Expected anonymous reservation to have no owner, got %q", created.UserID)
+			}
+
+			// 実行
+			err = tt.call(service, auth.WithPrincipal(context.Background(), tt.principal), created.ID)
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReservationService_ExportReservations(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	existing := model.NewReservation(activeResource.ID, start, start.Add(time.Hour))
+	existing.Title = "定例会議"
+
+	tests := []struct {
+		name      string
+		params    ExportReservationsParams
+		principal auth.Principal
+		listErr   bool
+		wantErr   error
+		wantTitle string
+	}{
+		{name: "期間を指定して書き出す", params: ExportReservationsParams{From: start, To: start.Add(24 * time.Hour)}, principal: auth.Principal{Role: model.RoleMember}, wantTitle: "定例会議"},
+		{name: "閲覧者には時間帯だけを返す", params: ExportReservationsParams{From: start, To: start.Add(24 * time.Hour)}, principal: auth.Principal{Role: model.RoleViewer}},
+		{name: "期間を省略すると既定の期間", principal: auth.Principal{Role: model.RoleMember}, wantTitle: "定例会議"},
+		{name: "片方だけの期間", params: ExportReservationsParams{From: start}, principal: auth.Principal{Role: model.RoleMember}, wantErr: ErrInvalidWindow},
+		{name: "上限を超える件数", principal: auth.Principal{Role: model.RoleMember}, listErr: true, wantErr: ErrExportTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			var gotQuery repository.ReservationQuery
+			mockRepo := newMockReservationRepository()
+			mockRepo.listFunc = func(query repository.ReservationQuery) ([]*model.Reservation, error) {
+				gotQuery = query
+				if tt.listErr {
+					return make([]*model.Reservation, query.Limit), nil
+				}
+				return []*model.Reservation{existing}, nil
+			}
+			service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+			ctx := auth.WithPrincipal(context.Background(), tt.principal)
+
+			// 実行
+			got, err := service.ExportReservations(ctx, tt.params)
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if tt.params.From.IsZero() && gotQuery.To.Sub(gotQuery.From) != DefaultExportPast+DefaultExportFuture {
+				t.Errorf("Expected the default window, got %v to %v", gotQuery.From, gotQuery.To)
+			}
+			if gotQuery.Limit != MaxExportReservations+1 {
+				t.Errorf("Expected limit %d, got %d", MaxExportReservations+1, gotQuery.Limit)
+			}
+			if len(got.Reservations) != 1 || got.Reservations[0].Title != tt.wantTitle {
+				t.Errorf("Expected reservation with title %q, got %+v", tt.wantTitle, got.Reservations)
+			}
+			if got.ResourceNames[activeResource.ID] != activeResource.Name {
+				t.Errorf("Expected resource name %q, got %v", activeResource.Name, got.ResourceNames)
+			}
+		})
+	}
+}
+
+func TestReservationService_StreamReservations(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	existing := model.NewReservation(activeResource.ID, start, start.Add(time.Hour))
+	existing.Title = "定例会議"
+
+	tests := []struct {
+		name      string
+		params    ExportReservationsParams
+		principal auth.Principal
+		wantErr   error
+		wantTitle string
+	}{
+		{name: "件数の上限なしに読み出す", params: ExportReservationsParams{From: start, To: start.Add(24 * time.Hour)}, principal: auth.Principal{Role: model.RoleMember}, wantTitle: "定例会議"},
+		{name: "閲覧者には時間帯だけを返す", principal: auth.Principal{Role: model.RoleViewer}},
+		{name: "片方だけの期間", params: ExportReservationsParams{To: start}, principal: auth.Principal{Role: model.RoleMember}, wantErr: ErrInvalidWindow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			var gotQuery repository.ReservationQuery
+			mockRepo := newMockReservationRepository()
+			mockRepo.eachFunc = func(query repository.ReservationQuery, fn func(reservation *model.Reservation) error) error {
+				gotQuery = query
+				return fn(existing)
+			}
+			service := NewReservationService(mockRepo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+			ctx := auth.WithPrincipal(context.Background(), tt.principal)
+
+			// 実行
+			var got []*model.Reservation
+			var gotNames []string
+			err := service.StreamReservations(ctx, tt.params, func(reservation *model.Reservation, resourceName string) error {
+				got = append(got, reservation)
+				gotNames = append(gotNames, resourceName)
+				return nil
+			})
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if gotQuery.Limit != 0 {
+				t.Errorf("Expected no limit, got %d", gotQuery.Limit)
+			}
+			if len(got) != 1 || got[0].Title != tt.wantTitle || gotNames[0] != activeResource.Name {
+				t.Errorf("Expected reservation with title %q in %q, got %+v %v", tt.wantTitle, activeResource.Name, got, gotNames)
+			}
+			if existing.Title != "定例会議" {
+				t.Errorf("Expected the stored reservation not to be redacted, got %q", existing.Title)
+			}
+		})
+	}
+}
+
+func TestReservationService_ImportReservations(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	event := func(uid string, offset time.Duration) ical.DecodedEvent {
+		return ical.DecodedEvent{Event: ical.Event{
+			UID:      uid,
+			Start:    start.Add(offset),
+			End:      start.Add(offset + time.Hour),
+			Summary:  "定例会議",
+			Location: "会議室a",
+		}}
+	}
+	recurring := event("recurring@example.com", 4*time.Hour)
+	recurring.RRule = "FREQ=WEEKLY;COUNT=3"
+	invalid := event("invalid@example.com", 5*time.Hour)
+	invalid.Err = errors.New("missing DTSTART")
+	unknownLocation := event("unknown@example.com", 6*time.Hour)
+	unknownLocation.Location = "会議室Z"
+	tentative := event("tentative@example.com", 7*time.Hour)
+	tentative.Status = ical.StatusTentative
+	longSummary := event("long-summary@example.com", 8*time.Hour)
+	longSummary.Summary = strings.Repeat("あ", MaxTitleLength+1)
+	badAttendee := event("bad-attendee@example.com", 9*time.Hour)
+	badAttendee.Attendees = []string{"not-an-email"}
+
+	for _, dryRun := range []bool{false, true} {
+		t.Run(fmt.Sprintf("dryRun=%v", dryRun), func(t *testing.T) {
+			// 準備: 取り込み済みの予約と、このサービスで作成した予約
+			repo := repository.NewInMemoryReservationRepository()
+			imported := model.NewReservation(activeResource.ID, start.Add(-48*time.Hour), start.Add(-47*time.Hour))
+			imported.ICalUID = "imported@example.com"
+			own := model.NewReservation(activeResource.ID, start.Add(-24*time.Hour), start.Add(-23*time.Hour))
+			for _, r := range []*model.Reservation{imported, own} {
+				if err := repo.Create(r); err != nil {
+					t.Fatalf("Failed to create reservation: %v", err)
+				}
+			}
+			service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+			ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: "user-1", Role: model.RoleMember})
+
+			// 実行
+			report, err := service.ImportReservations(ctx, ImportReservationsParams{
+				Events: []ical.DecodedEvent{
+					event("new@example.com", 0),
+					event("new@example.com", 2*time.Hour),
+					event("imported@example.com", 2*time.Hour),
+					event(own.UID(), 2*time.Hour),
+					event("overlap@example.com", 30*time.Minute),
+					event("", 2*time.Hour),
+					recurring,
+					invalid,
+					unknownLocation,
+					tentative,
+					longSummary,
+					badAttendee,
+				},
+				DryRun: dryRun,
+			})
+
+			// 検証
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			want := []ImportOutcome{
+				ImportCreated, ImportSkipped, ImportSkipped, ImportSkipped, ImportRejected,
+				ImportRejected, ImportRejected, ImportRejected, ImportRejected, ImportCreated,
+				ImportRejected, ImportRejected,
+			}
+			if len(report.Results) != len(want) {
+				t.Fatalf("Expected %d results, got %+v", len(want), report.Results)
+			}
+			for i, result := range report.Results {
+				if result.Outcome != want[i] {
+					t.Errorf("Result %d: expected %s, got %s (%s)", i, want[i], result.Outcome, result.Reason)
+				}
+			}
+			if got := report.Results[10].Reason; got != fmt.Sprintf("title must be at most %d characters", MaxTitleLength) {
+				t.Errorf("Unexpected reason for the long summary: %q", got)
+			}
+			if got := report.Results[3].ReservationID; got != own.ID {
+				t.Errorf("Expected the duplicate to refer to %s, got %s", own.ID, got)
+			}
+			if report.DryRun != dryRun || report.Count(ImportCreated) != 2 || report.Count(ImportSkipped) != 3 {
+				t.Errorf("Unexpected report %+v", report)
+			}
+
+			all, _ := repo.FindAll()
+			if dryRun {
+				if len(all) != 2 || report.Results[0].ReservationID != "" {
+					t.Errorf("Expected dry run not to create reservations, got %d reservations", len(all))
+				}
+				return
+			}
+			if len(all) != 4 {
+				t.Fatalf("Expected 2 reservations to be created, got %d reservations", len(all))
+			}
+			created, _ := repo.FindByICalUID("new@example.com")
+			if created == nil || created.ID != report.Results[0].ReservationID || created.UserID != "user-1" || created.Title != "定例会議" {
+				t.Errorf("Unexpected imported reservation %+v", created)
+			}
+			if pending, _ := repo.FindByICalUID("tentative@example.com"); pending == nil || pending.Status != model.StatusPending {
+				t.Errorf("Expected the tentative event to be imported as pending, got %+v", pending)
+			}
+		})
+	}
+}
+
+func TestReservationService_ImportReservations_Errors(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	event := ical.DecodedEvent{Event: ical.Event{UID: "event@example.com", Start: start, End: start.Add(time.Hour)}}
+
+	tests := []struct {
+		name      string
+		principal auth.Principal
+		params    ImportReservationsParams
+		wantErr   error
+	}{
+		{name: "閲覧者は取り込めない", principal: auth.Principal{Role: model.RoleViewer}, params: ImportReservationsParams{Events: []ical.DecodedEvent{event}}, wantErr: ErrForbidden},
+		{name: "イベントが多すぎる", principal: auth.Principal{Role: model.RoleMember}, params: ImportReservationsParams{Events: make([]ical.DecodedEvent, MaxImportEvents+1)}, wantErr: ErrImportTooLarge},
+		{name: "存在しないリソース", principal: auth.Principal{Role: model.RoleMember}, params: ImportReservationsParams{ResourceID: "unknown", Events: []ical.DecodedEvent{event}}, wantErr: ErrResourceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			service := NewReservationService(newMockReservationRepository(), newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+			// 実行
+			_, err := service.ImportReservations(auth.WithPrincipal(context.Background(), tt.principal), tt.params)
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReservationService_ImportEntries(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration) ImportEntry {
+		return ImportEntry{ResourceID: activeResource.ID, StartTime: start.Add(offset), EndTime: start.Add(offset + time.Hour)}
+	}
+	withUID := entry(time.Hour)
+	withUID.UID = "row@example.com"
+	byName := entry(2 * time.Hour)
+	byName.ResourceID, byName.Location = "", "会議室A"
+	pending := entry(3 * time.Hour)
+	pending.Status = model.StatusPending
+	cancelled := entry(4 * time.Hour)
+	cancelled.Status = model.StatusCancelled
+	unknown := entry(5 * time.Hour)
+	unknown.ResourceID = "unknown"
+	invalid := entry(6 * time.Hour)
+	invalid.Err = errors.New("invalid startTime")
+
+	tests := []struct {
+		name        string
+		entry       ImportEntry
+		wantOutcome ImportOutcome
+		wantStatus  model.ReservationStatus
+	}{
+		{name: "UID のない予約を取り込む", entry: entry(0), wantOutcome: ImportCreated, wantStatus: model.StatusConfirmed},
+		{name: "重なる予約は拒否する", entry: entry(30 * time.Minute), wantOutcome: ImportRejected},
+		{name: "UID のある予約を取り込む", entry: withUID, wantOutcome: ImportCreated, wantStatus: model.StatusConfirmed},
+		{name: "UID が重複する予約はスキップする", entry: withUID, wantOutcome: ImportSkipped},
+		{name: "リソースの名前で取り込む", entry: byName, wantOutcome: ImportCreated, wantStatus: model.StatusConfirmed},
+		{name: "仮予約を取り込む", entry: pending, wantOutcome: ImportCreated, wantStatus: model.StatusPending},
+		{name: "取り消された予約は拒否する", entry: cancelled, wantOutcome: ImportRejected},
+		{name: "存在しないリソース", entry: unknown, wantOutcome: ImportRejected},
+		{name: "取り込む前に見つかった問題", entry: invalid, wantOutcome: ImportRejected},
+	}
+
+	// 準備: 全ての予約を1回の取り込みで順に渡す
+	repo := repository.NewInMemoryReservationRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: "user-1", Role: model.RoleMember})
+	i := 0
+	next := func() (ImportEntry, error) {
+		if i == len(tests) {
+			return ImportEntry{}, io.EOF
+		}
+		i++
+		return tests[i-1].entry, nil
+	}
+
+	// 実行
+	var results []ImportResult
+	err := service.ImportEntries(ctx, ImportEntriesParams{}, next, func(result ImportResult) {
+		results = append(results, result)
+	})
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("Expected %d results, got %+v", len(tests), results)
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := results[i]
+			if result.Outcome != tt.wantOutcome {
+				t.Fatalf("Expected %s, got %s (%s)", tt.wantOutcome, result.Outcome, result.Reason)
+			}
+			if tt.wantOutcome != ImportCreated {
+				if result.Reason == "" {
+					t.Errorf("Expected a reason, got none")
+				}
+				return
+			}
+			created, _ := repo.FindByID(result.ReservationID)
+			if created == nil || created.Status != tt.wantStatus || created.UserID != "user-1" || created.ICalUID != tt.entry.UID {
+				t.Errorf("Unexpected imported reservation %+v", created)
+			}
+		})
+	}
+}
+
+func TestReservationService_ImportEntries_ConcurrentDuplicate(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	entry := ImportEntry{UID: "row@example.com", ResourceID: activeResource.ID, StartTime: start, EndTime: start.Add(time.Hour)}
+
+	// 準備: UID を確認した後、保存する前に同じ UID の予約が取り込まれる
+	repo := &failingReservationRepository{ReservationRepository: repository.NewInMemoryReservationRepository(), createFailAt: 1, createErr: repository.ErrDuplicateICalUID}
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: "user-1", Role: model.RoleMember})
+	done := false
+	next := func() (ImportEntry, error) {
+		if done {
+			return ImportEntry{}, io.EOF
+		}
+		done = true
+		return entry, nil
+	}
+
+	// 実行
+	var results []ImportResult
+	err := service.ImportEntries(ctx, ImportEntriesParams{}, next, func(result ImportResult) {
+		results = append(results, result)
+	})
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 1 || results[0].Outcome != ImportSkipped || results[0].Reason == "" {
+		t.Errorf("Expected the entry to be skipped, got %+v", results)
+	}
+}
+
+func TestReservationService_ImportEntries_Errors(t *testing.T) {
+	readErr := errors.New("read error")
+
+	tests := []struct {
+		name      string
+		principal auth.Principal
+		params    ImportEntriesParams
+		nextErr   error
+		wantErr   error
+	}{
+		{name: "閲覧者は取り込めない", principal: auth.Principal{Role: model.RoleViewer}, wantErr: ErrForbidden},
+		{name: "存在しないリソース", principal: auth.Principal{Role: model.RoleMember}, params: ImportEntriesParams{ResourceID: "unknown"}, wantErr: ErrResourceNotFound},
+		{name: "読み込みのエラー", principal: auth.Principal{Role: model.RoleMember}, nextErr: readErr, wantErr: readErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			service := NewReservationService(newMockReservationRepository(), newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+			next := func() (ImportEntry, error) {
+				if tt.nextErr != nil {
+					return ImportEntry{}, tt.nextErr
+				}
+				return ImportEntry{}, io.EOF
+			}
+
+			// 実行
+			err := service.ImportEntries(auth.WithPrincipal(context.Background(), tt.principal), tt.params, next, func(ImportResult) {})
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReservationService_PutEvent(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	event := ical.Event{UID: "event@example.com", Start: start, End: start.Add(time.Hour), Summary: "定例会議", Status: ical.StatusTentative}
+
+	// 準備
+	repo := repository.NewInMemoryReservationRepository()
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: "user-1", Role: model.RoleMember})
+
+	// UID の予約がない場合は作成する
+	created, isNew, err := service.PutEvent(ctx, PutEventParams{ResourceID: activeResource.ID, Event: event, CreateOnly: true})
+	if err != nil || !isNew {
+		t.Fatalf("Expected the reservation to be created, got %v (created=%v)", err, isNew)
+	}
+	if created.ICalUID != event.UID || created.Status != model.StatusPending || created.UserID != "user-1" || created.Title != "定例会議" {
+		t.Errorf("Unexpected created reservation %+v", created)
+	}
+
+	// 同じ UID のイベントは予約の変更になる
+	event.Start, event.End = start.Add(time.Hour), start.Add(2*time.Hour)
+	updated, isNew, err := service.PutEvent(ctx, PutEventParams{ResourceID: activeResource.ID, Event: event, Version: created.Version})
+	if err != nil || isNew {
+		t.Fatalf("Expected the reservation to be updated, got %v (created=%v)", err, isNew)
+	}
+	if updated.ID != created.ID || !updated.StartTime.Equal(event.Start) || updated.Version != created.Version+1 {
+		t.Errorf("Unexpected updated reservation %+v", updated)
+	}
+
+	// 件名のない予約はリソースの名前の件名で保存しても件名が空のまま
+	own := model.NewReservation(activeResource.ID, start.Add(24*time.Hour), start.Add(25*time.Hour))
+	if err := repo.Create(own); err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+	ownEvent := ical.Event{UID: own.UID(), Start: own.StartTime, End: own.EndTime, Summary: activeResource.Name}
+	if saved, _, err := service.PutEvent(adminCtx, PutEventParams{ResourceID: activeResource.ID, Event: ownEvent}); err != nil || saved.Title != "" {
+		t.Errorf("Expected the title to stay empty, got %v %+v", err, saved)
+	}
+
+	// 取得と削除
+	if got, err := service.GetEvent(ctx, activeResource.ID, event.UID); err != nil || got.ID != created.ID {
+		t.Errorf("Expected the event, got %v %+v", err, got)
+	}
+	if err := service.DeleteEvent(ctx, activeResource.ID, event.UID, created.Version); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected error %v, got %v", ErrVersionConflict, err)
+	}
+	if err := service.DeleteEvent(ctx, activeResource.ID, event.UID, 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.GetEvent(ctx, activeResource.ID, event.UID); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("Expected the cancelled reservation to leave the calendar, got %v", err)
+	}
+	if _, _, err := service.PutEvent(ctx, PutEventParams{ResourceID: activeResource.ID, Event: event}); !errors.Is(err, ErrUIDConflict) {
+		t.Errorf("Expected error %v, got %v", ErrUIDConflict, err)
+	}
+}
+
+func TestReservationService_PutEvent_ConcurrentCreate(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	event := ical.Event{UID: "event@example.com", Start: start, End: start.Add(time.Hour)}
+
+	// 準備: UID を確認した後、保存する前に同じ UID のイベントが作成される
+	repo := &failingReservationRepository{ReservationRepository: repository.NewInMemoryReservationRepository(), createFailAt: 1, createErr: repository.ErrDuplicateICalUID}
+	service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: "user-1", Role: model.RoleMember})
+
+	// 実行
+	_, _, err := service.PutEvent(ctx, PutEventParams{ResourceID: activeResource.ID, Event: event, CreateOnly: true})
+
+	// 検証
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected error %v, got %v", ErrVersionConflict, err)
+	}
+}
+
+func TestReservationService_PutEvent_Errors(t *testing.T) {
+	start := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	existing := model.NewReservation(activeResource.ID, start, start.Add(time.Hour))
+	existing.ICalUID = "existing@example.com"
+	event := func(uid string, offset time.Duration) ical.Event {
+		return ical.Event{UID: uid, Start: start.Add(offset), End: start.Add(offset + time.Hour)}
+	}
+	recurring := event("recurring@example.com", 2*time.Hour)
+	recurring.RRule = "FREQ=DAILY;COUNT=3"
+	cancelled := event("cancelled@example.com", 2*time.Hour)
+	cancelled.Status = ical.StatusCancelled
+	longSummary := event("long@example.com", 2*time.Hour)
+	longSummary.Summary = strings.Repeat("あ", MaxTitleLength+1)
+	member := auth.Principal{UserID: "user-1", Role: model.RoleMember}
+
+	tests := []struct {
+		name      string
+		principal auth.Principal
+		params    PutEventParams
+		wantErr   error
+	}{
+		{name: "繰り返しのイベント", principal: member, params: PutEventParams{ResourceID: activeResource.ID, Event: recurring}, wantErr: ErrUnsupportedEvent},
+		{name: "取り消されたイベント", principal: member, params: PutEventParams{ResourceID: activeResource.ID, Event: cancelled}, wantErr: ErrUnsupportedEvent},
+		{name: "閲覧者は作成できない", principal: auth.Principal{Role: model.RoleViewer}, params: PutEventParams{ResourceID: activeResource.ID, Event: event("new@example.com", 2*time.Hour)}, wantErr: ErrForbidden},
+		{name: "重なる予約がある", principal: member, params: PutEventParams{ResourceID: activeResource.ID, Event: event("new@example.com", 30*time.Minute)}, wantErr: &ConflictError{}},
+		{name: "予約がないのに版を指定した", principal: member, params: PutEventParams{ResourceID: activeResource.ID, Event: event("new@example.com", 2*time.Hour), Version: 1}, wantErr: ErrVersionConflict},
+		{name: "予約がある UID で作成だけを指定した", principal: auth.Principal{Role: model.RoleAdmin}, params: PutEventParams{ResourceID: activeResource.ID, Event: event(existing.ICalUID, 0), CreateOnly: true}, wantErr: ErrVersionConflict},
+		{name: "他のカレンダーの UID", principal: auth.Principal{Role: model.RoleAdmin}, params: PutEventParams{ResourceID: "other", Event: event(existing.ICalUID, 0)}, wantErr: ErrUIDConflict},
+		{name: "他人の予約は変更できない", principal: member, params: PutEventParams{ResourceID: activeResource.ID, Event: event(existing.ICalUID, 0)}, wantErr: ErrForbidden},
+		{name: "予約がある UID を版を指定せずに変更した", principal: auth.Principal{Role: model.RoleAdmin}, params: PutEventParams{ResourceID: activeResource.ID, Event: event(existing.ICalUID, 0), RequireVersion: true}, wantErr: ErrVersionRequired},
+		{name: "タイトルが長すぎる", principal: member, params: PutEventParams{ResourceID: activeResource.ID, Event: longSummary}, wantErr: &InvalidDetailsError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			repo := repository.NewInMemoryReservationRepository()
+			r := *existing
+			r.UserID = "user-2"
+			if err := repo.Create(&r); err != nil {
+				t.Fatalf("Failed to create reservation: %v", err)
+			}
+			service := NewReservationService(repo, newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+
+			// 実行
+			_, _, err := service.PutEvent(auth.WithPrincipal(context.Background(), tt.principal), tt.params)
+
+			// 検証
+			var conflictErr *ConflictError
+			if _, ok := tt.wantErr.(*ConflictError); ok {
+				if !errors.As(err, &conflictErr) {
+					t.Errorf("Expected a conflict error, got %v", err)
+				}
+				return
+			}
+			var detailsErr *InvalidDetailsError
+			if _, ok := tt.wantErr.(*InvalidDetailsError); ok {
+				if !errors.As(err, &detailsErr) || detailsErr.Field != "title" {
+					t.Errorf("Expected an invalid title error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
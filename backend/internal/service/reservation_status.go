@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+// allowedTransitions は予約の状態ごとに遷移できる状態。
+// cancelled / completed / no_show は終了状態で、そこから他の状態には遷移できない。
+var allowedTransitions = map[model.ReservationStatus][]model.ReservationStatus{
+	model.StatusPending:   {model.StatusConfirmed, model.StatusCancelled},
+	model.StatusConfirmed: {model.StatusCancelled, model.StatusCompleted, model.StatusNoShow},
+}
+
+// CanTransition は予約の状態を from から to に変更できるかを返す
+func CanTransition(from, to model.ReservationStatus) bool {
+	return slices.Contains(allowedTransitions[from], to)
+}
+
+// editable は時間帯やリソースを変更できる状態かを返す
+func editable(status model.ReservationStatus) bool {
+	return status == model.StatusPending || status == model.StatusConfirmed
+}
+
+func invalidTransition(from, to model.ReservationStatus) error {
+	return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, from, to)
+}
+
+// ChangeStatusParams は予約の状態の変更内容
+type ChangeStatusParams struct {
+	Status model.ReservationStatus
+	// Reason は cancelled に変更するときの理由（任意）
+	Reason string
+	// Version を指定すると、予約の現在の版と一致する場合だけ変更する。0 の場合は版を確認しない。
+	Version int64
+}
+
+// ChangeStatus は予約の状態を変更する。
+// 存在しない予約には ErrReservationNotFound、未定義の状態には ErrInvalidStatus、
+// 許可されていない遷移には ErrInvalidStatusTransition、ctx のプリンシパルがこの遷移の操作（承認・取り消し・利用結果の記録）を
+// 行えない場合は ErrForbidden、版が params.Version と異なる場合は ErrVersionConflict を返す。
+func (s *ReservationService) ChangeStatus(ctx context.Context, id string, params ChangeStatusParams) (*model.Reservation, error) {
+	if !params.Status.Valid() {
+		return nil, ErrInvalidStatus
+	}
+	if err := authorize(ctx, statusAction(params.Status), nil); err != nil {
+		return nil, err
+	}
+	if err := validateCancellationReason(params.Reason); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrReservationNotFound
+	}
+	if err := authorize(ctx, statusAction(params.Status), current); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(current, params.Version); err != nil {
+		return nil, err
+	}
+	if !CanTransition(current.Status, params.Status) {
+		return nil, invalidTransition(current.Status, params.Status)
+	}
+
+	if params.Status == model.StatusCancelled {
+		_, originalStarts, err := s.scopedOccurrences(current, ScopeThis)
+		if err != nil {
+			return nil, err
+		}
+		return s.cancel(current, params.Reason, originalStarts, time.Now())
+	}
+
+	updated := *current
+	updated.Status = params.Status
+	updated.UpdatedAt = time.Now()
+	if err := s.repo.Update(&updated); err != nil {
+		return nil, err
+	}
+
+	s.publish(model.EventReservationUpdated, &updated)
+	return &updated, nil
+}
+
+// validateCancellationReason は取り消し理由が MaxCancellationReasonLength 文字以内かを確認し、超える場合は *InvalidDetailsError を返す
+func validateCancellationReason(reason string) error {
+	if utf8.RuneCountInString(reason) > MaxCancellationReasonLength {
+		return &InvalidDetailsError{Field: "reason", Reason: fmt.Sprintf("must be at most %d characters", MaxCancellationReasonLength)}
+	}
+	return nil
+}
+
+// cancel は予約を取り消し済みにして保存する。繰り返し予約の回は取り消しの例外として記録する。
+func (s *ReservationService) cancel(reservation *model.Reservation, reason string, originalStarts map[string]time.Time, now time.Time) (*model.Reservation, error) {
+	cancelled, err := s.cancelOccurrences([]*model.Reservation{reservation}, reason, originalStarts, now)
+	if err != nil {
+		return nil, err
+	}
+	return cancelled[0], nil
+}
+
+// cancelOccurrences は occurrences を順に取り消し済みにして保存する。途中で失敗した場合は、
+// それまでに取り消した回を元の内容に戻してからエラーを返す。全て保存してから繰り返し予約の回を取り消しの例外として記録する。
+func (s *ReservationService) cancelOccurrences(occurrences []*model.Reservation, reason string, originalStarts map[string]time.Time, now time.Time) ([]*model.Reservation, error) {
+	cancelled := make([]*model.Reservation, 0, len(occurrences))
+	for i, occurrence := range occurrences {
+		c := cancelledCopy(occurrence, reason, now)
+		if err := s.repo.Update(c); err != nil {
+			for j, original := range occurrences[:i] {
+				// 保存によって版が進んでいるため、戻すときは保存後の版を指定する
+				restored := *original
+				restored.Version = cancelled[j].Version
+				if rollbackErr := s.repo.Update(&restored); rollbackErr != nil {
+					err = errors.Join(err, rollbackErr)
+				}
+			}
+			return nil, err
+		}
+		cancelled = append(cancelled, c)
+	}
+
+	for _, c := range cancelled {
+		if c.SeriesID != "" {
+			exception := model.NewSeriesException(c.SeriesID, originalStarts[c.ID], model.ExceptionCancelled, c.ID)
+			if err := s.seriesRepo.SaveException(exception); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s.publish(model.EventReservationDeleted, cancelled...)
+	return cancelled, nil
+}
+
+// cancelledCopy は reservation を now に reason で取り消した写しを返す
+func cancelledCopy(reservation *model.Reservation, reason string, now time.Time) *model.Reservation {
+	cancelled := *reservation
+	cancelled.Status = model.StatusCancelled
+	cancelled.CancelledAt = &now
+	cancelled.CancellationReason = reason
+	cancelled.UpdatedAt = now
+	return &cancelled
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+type ResourceService struct {
+	repo            repository.ResourceRepository
+	reservationRepo repository.ReservationRepository
+}
+
+func NewResourceService(repo repository.ResourceRepository, reservationRepo repository.ReservationRepository) *ResourceService {
+	return &ResourceService{repo: repo, reservationRepo: reservationRepo}
+}
+
+type ResourceParams struct {
+	Name        string
+	Description string
+	Capacity    int
+	Active      bool
+}
+
+// CreateResource はリソースを作成する。設定を変更できない権限には ErrForbidden を返す。
+func (s *ResourceService) CreateResource(ctx context.Context, params ResourceParams) (*model.Resource, error) {
+	if err := authorize(ctx, ActionConfigure, nil); err != nil {
+		return nil, err
+	}
+	resource := model.NewResource(params.Name, params.Description, params.Capacity, params.Active)
+	if err := s.repo.Create(resource); err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+func (s *ResourceService) GetAllResources() ([]*model.Resource, error) {
+	return s.repo.FindAll()
+}
+
+// GetResource はリソースを取得する。存在しない場合は ErrResourceNotFound を返す。
+func (s *ResourceService) GetResource(id string) (*model.Resource, error) {
+	resource, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if resource == nil {
+		return nil, ErrResourceNotFound
+	}
+
+	return resource, nil
+}
+
+// UpdateResource はリソースを変更する。設定を変更できない権限には ErrForbidden、存在しない場合は ErrResourceNotFound を返す。
+func (s *ResourceService) UpdateResource(ctx context.Context, id string, params ResourceParams) (*model.Resource, error) {
+	if err := authorize(ctx, ActionConfigure, nil); err != nil {
+		return nil, err
+	}
+	resource, err := s.GetResource(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *resource
+	updated.Name = params.Name
+	updated.Description = params.Description
+	updated.Capacity = params.Capacity
+	updated.Active = params.Active
+	updated.UpdatedAt = time.Now()
+	if err := s.repo.Update(&updated); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeleteResource はリソースを削除する。設定を変更できない権限には ErrForbidden を返す。
+// 取り消されていない予約が残っているリソースは削除せずに ErrResourceInUse を返すので、使わなくなったリソースは無効化する。
+func (s *ResourceService) DeleteResource(ctx context.Context, id string) error {
+	if err := authorize(ctx, ActionConfigure, nil); err != nil {
+		return err
+	}
+	if _, err := s.GetResource(id); err != nil {
+		return err
+	}
+
+	reservations, err := s.reservationRepo.FindByResourceID(id)
+	if err != nil {
+		return err
+	}
+	if len(filterByStatus(reservations, nil)) > 0 {
+		return ErrResourceInUse
+	}
+
+	return s.repo.Delete(id)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+)
+
+// テストで共通して使う予約可能なリソース
+var activeResource = model.NewResource("会議室A", "", 6, true)
+
+// モックリソースリポジトリの実装
+type mockResourceRepository struct {
+	resources map[string]*model.Resource
+	deleted   []string
+}
+
+func newMockResourceRepository(resources ...*model.Resource) *mockResourceRepository {
+	m := &mockResourceRepository{resources: make(map[string]*model.Resource)}
+	for _, r := range resources {
+		m.resources[r.ID] = r
+	}
+	return m
+}
+
+func (m *mockResourceRepository) Create(resource *model.Resource) error {
+	m.resources[resource.ID] = resource
+	return nil
+}
+
+func (m *mockResourceRepository) FindAll() ([]*model.Resource, error) {
+	resources := make([]*model.Resource, 0, len(m.resources))
+	for _, r := range m.resources {
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+func (m *mockResourceRepository) FindByID(id string) (*model.Resource, error) {
+	return m.resources[id], nil
+}
+
+func (m *mockResourceRepository) Update(resource *model.Resource) error {
+	m.resources[resource.ID] = resource
+	return nil
+}
+
+func (m *mockResourceRepository) Delete(id string) error {
+	m.deleted = append(m.deleted, id)
+	delete(m.resources, id)
+	return nil
+}
+
+func TestResourceService_CreateResource(t *testing.T) {
+	// 準備
+	repo := newMockResourceRepository()
+	service := NewResourceService(repo, newMockReservationRepository())
+
+	// 実行
+	resource, err := service.CreateResource(adminCtx, ResourceParams{Name: "会議室B", Capacity: 10, Active: true})
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if repo.resources[resource.ID] == nil {
+		t.Error("Expected resource to be saved in repository")
+	}
+	if resource.Name != "会議室B" || resource.Capacity != 10 || !resource.Active {
+		t.Errorf("Unexpected resource: %+v", resource)
+	}
+}
+
+func TestResourceService_GetResource_NotFound(t *testing.T) {
+	// 準備
+	service := NewResourceService(newMockResourceRepository(), newMockReservationRepository())
+
+	// 実行
+	_, err := service.GetResource("missing")
+
+	// 検証
+	if !errors.Is(err, ErrResourceNotFound) {
+		t.Errorf("Expected ErrResourceNotFound, got %v", err)
+	}
+}
+
+func TestResourceService_UpdateResource(t *testing.T) {
+	// 準備
+	original := model.NewResource("会議室A", "", 6, true)
+	original.UpdatedAt = original.UpdatedAt.Add(-time.Hour)
+	repo := newMockResourceRepository(original)
+	service := NewResourceService(repo, newMockReservationRepository())
+
+	// 実行
+	updated, err := service.UpdateResource(adminCtx, original.ID, ResourceParams{Name: "大会議室", Capacity: 20, Active: false})
+
+	// 検証
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Name != "大会議室" || updated.Capacity != 20 || updated.Active {
+		t.Errorf("Unexpected resource: %+v", updated)
+	}
+	if !updated.CreatedAt.Equal(original.CreatedAt) {
+		t.Error("Expected CreatedAt to be preserved")
+	}
+	if !updated.UpdatedAt.After(original.UpdatedAt) {
+		t.Error("Expected UpdatedAt to be bumped")
+	}
+}
+
+func TestResourceService_DeleteResource(t *testing.T) {
+	resource := model.NewResource("会議室A", "", 6, true)
+	now := time.Now()
+	cancelled := model.NewReservation(resource.ID, now, now.Add(time.Hour))
+	cancelled.Status = model.StatusCancelled
+
+	tests := []struct {
+		name         string
+		id           string
+		reservations []*model.Reservation
+		wantErr      error
+		wantDeleted  bool
+	}{
+		{"予約のないリソース", resource.ID, nil, nil, true},
+		{"予約が残っているリソース", resource.ID, []*model.Reservation{model.NewReservation(resource.ID, now, now.Add(time.Hour))}, ErrResourceInUse, false},
+		{"取り消された予約だけのリソース", resource.ID, []*model.Reservation{cancelled}, nil, true},
+		{"存在しないリソース", "missing", nil, ErrResourceNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			repo := newMockResourceRepository(resource)
+			reservationRepo := newMockReservationRepository()
+			reservationRepo.findByResourceIDFunc = func(resourceID string) ([]*model.Reservation, error) {
+				return tt.reservations, nil
+			}
+			service := NewResourceService(repo, reservationRepo)
+
+			// 実行
+			err := service.DeleteResource(adminCtx, tt.id)
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if deleted := len(repo.deleted) > 0; deleted != tt.wantDeleted {
+				t.Errorf("Expected deleted = %v, got %v", tt.wantDeleted, deleted)
+			}
+		})
+	}
+}
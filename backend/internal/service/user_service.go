@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+type UserService struct {
+	repo repository.UserRepository
+}
+
+func NewUserService(repo repository.UserRepository) *UserService {
+	return &UserService{repo: repo}
+}
+
+// CreateUserParams はユーザーの作成内容
+type CreateUserParams struct {
+	DisplayName string
+	Email       string
+	// Role を省略した場合は RoleMember として扱う
+	Role model.UserRole
+}
+
+// CreateUser はユーザーを作成する。メールアドレスは前後の空白を除いて小文字にそろえる。
+// 未定義の権限には ErrInvalidRole、同じメールアドレスのユーザーがいる場合は ErrEmailTaken を返す。
+// 管理者以外が作成できるのは、プリンシパルのない利用者が自分を一般ユーザーとして登録する場合と、最初のユーザーを管理者として登録する場合だけ。
+func (s *UserService) CreateUser(ctx context.Context, params CreateUserParams) (*model.User, error) {
+	role := params.Role
+	if role == "" {
+		role = model.RoleMember
+	}
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	firstOnly, err := s.authorizeCreation(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(params.Email))
+	existing, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+
+	user := model.NewUser(strings.TrimSpace(params.DisplayName), email, role)
+	if !firstOnly {
+		if err := s.repo.Create(user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	// 同時に届いた要求で管理者が2人作られないよう、ユーザーの有無の確認と作成をまとめて行う
+	created, err := s.repo.CreateFirst(user)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrForbidden
+	}
+	return user, nil
+}
+
+// authorizeCreation は role のユーザーを作成できるかを確認し、作成できない場合は ErrForbidden を返す。
+// firstOnly が true の場合は、最初のユーザーとしてだけ作成できる。
+func (s *UserService) authorizeCreation(ctx context.Context, role model.UserRole) (firstOnly bool, err error) {
+	if authorize(ctx, ActionConfigure, nil) == nil {
+		return false, nil
+	}
+	_, signedIn := auth.PrincipalFrom(ctx)
+	switch {
+	case role == model.RoleMember && !signedIn:
+		return false, nil
+	case role == model.RoleAdmin:
+		return true, nil
+	default:
+		return false, ErrForbidden
+	}
+}
+
+// GetAllUsers は全てのユーザーを返す。メールアドレスを含むため管理者だけが参照できる。
+func (s *UserService) GetAllUsers(ctx context.Context) ([]*model.User, error) {
+	if err := authorize(ctx, ActionConfigure, nil); err != nil {
+		return nil, err
+	}
+	return s.repo.FindAll()
+}
+
+// GetUser はユーザーを取得する。本人と管理者だけが参照でき、存在しない場合は ErrUserNotFound を返す。
+func (s *UserService) GetUser(ctx context.Context, id string) (*model.User, error) {
+	if p, ok := auth.PrincipalFrom(ctx); !ok || p.UserID != id {
+		if err := authorize(ctx, ActionConfigure, nil); err != nil {
+			return nil, err
+		}
+	}
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return user, nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+func TestUserService_CreateUser(t *testing.T) {
+	// 準備
+	service := NewUserService(repository.NewInMemoryUserRepository())
+	ctx := context.Background()
+
+	// 最初のユーザーは管理者として作成できる
+	admin, err := service.CreateUser(ctx, CreateUserParams{DisplayName: " 管理者 ", Email: " Admin@Example.com ", Role: model.RoleAdmin})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if admin.Email != "admin@example.com" || admin.DisplayName != "管理者" {
+		t.Errorf("Expected normalized name and email, got %q %q", admin.DisplayName, admin.Email)
+	}
+
+	// 権限を省略すると一般ユーザーになる
+	member, err := service.CreateUser(ctx, CreateUserParams{DisplayName: "山田", Email: "yamada@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if member.Role != model.RoleMember {
+		t.Errorf("Expected role %s, got %s", model.RoleMember, member.Role)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		params  CreateUserParams
+		wantErr error
+	}{
+		{name: "メールアドレスの重複", ctx: ctx, params: CreateUserParams{DisplayName: "別人", Email: "YAMADA@example.com"}, wantErr: ErrEmailTaken},
+		{name: "未定義の権限", ctx: ctx, params: CreateUserParams{DisplayName: "鈴木", Email: "suzuki@example.com", Role: "owner"}, wantErr: ErrInvalidRole},
+		{name: "2人目以降の管理者は匿名では作成できない", ctx: ctx, params: CreateUserParams{DisplayName: "鈴木", Email: "suzuki@example.com", Role: model.RoleAdmin}, wantErr: ErrForbidden},
+		{
+			name:    "一般ユーザーは管理者を作成できない",
+			ctx:     auth.WithPrincipal(ctx, auth.Principal{UserID: member.ID, Role: model.RoleMember}),
+			params:  CreateUserParams{DisplayName: "鈴木", Email: "suzuki@example.com", Role: model.RoleAdmin},
+			wantErr: ErrForbidden,
+		},
+		{name: "承認者は匿名では作成できない", ctx: ctx, params: CreateUserParams{DisplayName: "鈴木", Email: "suzuki@example.com", Role: model.RoleApprover}, wantErr: ErrForbidden},
+		{name: "閲覧者は匿名では作成できない", ctx: ctx, params: CreateUserParams{DisplayName: "鈴木", Email: "suzuki@example.com", Role: model.RoleViewer}, wantErr: ErrForbidden},
+		{
+			name:    "一般ユーザーは他のユーザーを作成できない",
+			ctx:     auth.WithPrincipal(ctx, auth.Principal{UserID: member.ID, Role: model.RoleMember}),
+			params:  CreateUserParams{DisplayName: "鈴木", Email: "suzuki@example.com"},
+			wantErr: ErrForbidden,
+		},
+		{
+			name:   "管理者は管理者を作成できる",
+			ctx:    auth.WithPrincipal(ctx, auth.Principal{UserID: admin.ID, Role: model.RoleAdmin}),
+			params: CreateUserParams{DisplayName: "鈴木", Email: "suzuki@example.com", Role: model.RoleAdmin},
+		},
+		{
+			name:   "管理者は承認者を作成できる",
+			ctx:    auth.WithPrincipal(ctx, auth.Principal{UserID: admin.ID, Role: model.RoleAdmin}),
+			params: CreateUserParams{DisplayName: "佐藤", Email: "sato@example.com", Role: model.RoleApprover},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 実行
+			_, err := service.CreateUser(tt.ctx, tt.params)
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUserService_GetUser_NotFound(t *testing.T) {
+	// 準備
+	service := NewUserService(repository.NewInMemoryUserRepository())
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: "admin", Role: model.RoleAdmin})
+
+	// 実行
+	_, err := service.GetUser(ctx, "missing")
+
+	// 検証
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUserService_GetUser_Authorization(t *testing.T) {
+	// 準備
+	repo := repository.NewInMemoryUserRepository()
+	service := NewUserService(repo)
+	member := model.NewUser("山田", "yamada@example.com", model.RoleMember)
+	_ = repo.Create(member)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{name: "本人は参照できる", ctx: auth.WithPrincipal(ctx, auth.Principal{UserID: member.ID, Role: model.RoleMember})},
+		{name: "管理者は参照できる", ctx: auth.WithPrincipal(ctx, auth.Principal{UserID: "admin", Role: model.RoleAdmin})},
+		{name: "他の一般ユーザーは参照できない", ctx: auth.WithPrincipal(ctx, auth.Principal{UserID: "other", Role: model.RoleMember}), wantErr: ErrForbidden},
+		{name: "匿名では参照できない", ctx: ctx, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 実行
+			_, err := service.GetUser(tt.ctx, member.ID)
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUserService_GetAllUsers_AdminOnly(t *testing.T) {
+	// 準備
+	service := NewUserService(repository.NewInMemoryUserRepository())
+	ctx := context.Background()
+
+	// 実行
+	_, memberErr := service.GetAllUsers(auth.WithPrincipal(ctx, auth.Principal{UserID: "member", Role: model.RoleMember}))
+	_, adminErr := service.GetAllUsers(auth.WithPrincipal(ctx, auth.Principal{UserID: "admin", Role: model.RoleAdmin}))
+
+	// 検証
+	if !errors.Is(memberErr, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden for a member, got %v", memberErr)
+	}
+	if adminErr != nil {
+		t.Errorf("Expected no error for an administrator, got %v", adminErr)
+	}
+}
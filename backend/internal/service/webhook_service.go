@@ -0,0 +1,419 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+// Webhook の通知のリクエストヘッダー
+const (
+	// WebhookEventHeader はイベントの種類
+	WebhookEventHeader = "X-Yoyaku-Event"
+	// WebhookDeliveryHeader は配送のID。再配送では新しいIDになる。
+	WebhookDeliveryHeader = "X-Yoyaku-Delivery"
+	// WebhookTimestampHeader は送信した日時の Unix 時間（秒）
+	WebhookTimestampHeader = "X-Yoyaku-Timestamp"
+	// WebhookSignatureHeader は SignWebhookPayload で計算した署名
+	WebhookSignatureHeader = "X-Yoyaku-Signature"
+)
+
+const (
+	// WebhookMaxAttempts は1件の配送で送信を試みる回数の上限。上限まで失敗した配送は failed にする。
+	WebhookMaxAttempts = 8
+	// WebhookRetryBase は最初の再試行までの間隔。以降は失敗するたびに倍にする。
+	WebhookRetryBase = 30 * time.Second
+	// WebhookTimeout は1回の送信で応答を待つ時間
+	WebhookTimeout = 10 * time.Second
+	// WebhookDeliveryLogLimit は配送の記録の一覧で返す件数
+	WebhookDeliveryLogLimit = 100
+	// webhookBatchSize は DeliverDue で一度に送信する配送の件数
+	webhookBatchSize = 50
+	// webhookWorkers は DeliverDue で同時に送信する配送の件数の上限
+	webhookWorkers = 8
+	// webhookClaimLease は送信する配送を他のサーバーが取り出さないよう確保しておく時間。送信と結果の記録が終わるまで続くよう WebhookTimeout より長くする。
+	webhookClaimLease = 6 * WebhookTimeout
+	// webhookSecretBytes は自動で生成する署名の鍵の乱数のバイト数
+	webhookSecretBytes = 32
+	// maxWebhookResponseBytes は読み捨てる受信側の応答の本文の最大バイト数
+	maxWebhookResponseBytes = 64 << 10
+)
+
+// EventPublisher は保存した予約の変更をイベントとして通知する
+type EventPublisher interface {
+	Publish(event model.WebhookEventType, reservation *model.Reservation)
+}
+
+// WebhookService は Webhook の登録を管理し、予約のイベントを登録した URL に配送する。
+// 配送は Publish で記録し、DeliverDue で送信する。送信に失敗した配送は間隔を倍にしながら再試行する。
+type WebhookService struct {
+	repo    repository.WebhookRepository
+	client  *http.Client
+	logf    func(format string, args ...interface{})
+	pending chan struct{}
+}
+
+// NewWebhookService は client で通知を送るサービスを返す。client が nil の場合は WebhookTimeout で打ち切るクライアントを使う。
+func NewWebhookService(repo repository.WebhookRepository, client *http.Client) *WebhookService {
+	if client == nil {
+		client = &http.Client{Timeout: WebhookTimeout}
+	}
+	return &WebhookService{
+		repo:    repo,
+		client:  client,
+		logf:    log.Printf,
+		pending: make(chan struct{}, 1),
+	}
+}
+
+// SetLogger は配送を記録できなかった場合のログの出力先を設定する。設定しない場合は log.Printf を使う。
+func (s *WebhookService) SetLogger(logf func(format string, args ...interface{})) {
+	s.logf = logf
+}
+
+// Pending は送信する配送が追加されると値を受け取れるチャネルを返す。配送の処理を待たずに始めるために使う。
+func (s *WebhookService) Pending() <-chan struct{} {
+	return s.pending
+}
+
+// CreateWebhookParams は登録する Webhook。Secret が空の場合は生成する。
+type CreateWebhookParams struct {
+	URL    string
+	Secret string
+	Events []model.WebhookEventType
+}
+
+// CreateSubscription は Webhook を登録する。署名の鍵を含む登録はこの戻り値でしか得られない。
+// 設定を変更できない権限には ErrForbidden、URL が http(s) の絶対 URL でない場合は ErrInvalidWebhookURL、
+// イベントの種類が空か未定義の場合は ErrInvalidWebhookEvents を返す。
+func (s *WebhookService) CreateSubscription(ctx context.Context, params CreateWebhookParams) (*model.WebhookSubscription, error) {
+	if err := authorize(ctx, ActionConfigure, nil); err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(params.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+	if len(params.Events) == 0 {
+		return nil, ErrInvalidWebhookEvents
+	}
+	events := make([]model.WebhookEventType, 0, len(params.Events))
+	for _, event := range params.Events {
+		if !event.Valid() {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidWebhookEvents, event)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	secret := params.Secret
+	if secret == "" {
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+	subscription := model.NewWebhookSubscription(params.URL, secret, events)
+	if p, ok := auth.PrincipalFrom(ctx); ok {
+		subscription.CreatedBy = p.UserID
+	}
+	if err := s.repo.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// ListSubscriptions は全ての登録を登録順に返す。設定を変更できない権限には ErrForbidden を返す。
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	if err := authorize(ctx, ActionConfigure, nil); err != nil {
+		return nil, err
+	}
+	return s.repo.FindSubscriptions()
+}
+
+// DeleteSubscription は登録と配送の記録を削除する。送信を待っている配送も送らない。
+// 設定を変更できない権限には ErrForbidden、存在しない場合は ErrWebhookNotFound を返す。
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	if _, err := s.findSubscription(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscription(id)
+}
+
+// ListDeliveries は登録の配送の記録を新しい順に最大 WebhookDeliveryLogLimit 件返す。
+// 設定を変更できない権限には ErrForbidden、登録が存在しない場合は ErrWebhookNotFound を返す。
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID string) ([]*model.WebhookDelivery, error) {
+	if _, err := s.findSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindDeliveries(subscriptionID, WebhookDeliveryLogLimit)
+}
+
+// Redeliver は配送と同じイベントを新しい配送としてすぐに送り直す。結果に関わらず元の配送の記録は変えない。
+// 設定を変更できない権限には ErrForbidden、登録が存在しない場合は ErrWebhookNotFound、
+// 配送が存在しないか他の登録の配送である場合は ErrWebhookDeliveryNotFound を返す。
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*model.WebhookDelivery, error) {
+	if _, err := s.findSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	original, err := s.repo.FindDeliveryByID(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil || original.SubscriptionID != subscriptionID {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	delivery := model.NewWebhookDelivery(subscriptionID, original.EventID, original.Event, original.Payload, time.Now())
+	delivery.RedeliveryOf = original.ID
+	if err := s.repo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	s.notifyPending()
+	return delivery, nil
+}
+
+// findSubscription は設定を変更できる権限かを確認してから登録を返す
+func (s *WebhookService) findSubscription(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	if err := authorize(ctx, ActionConfigure, nil); err != nil {
+		return nil, err
+	}
+	subscription, err := s.repo.FindSubscriptionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, ErrWebhookNotFound
+	}
+	return subscription, nil
+}
+
+// webhookEvent は通知の本文
+type webhookEvent struct {
+	ID        string                 `json:"id"`
+	Type      model.WebhookEventType `json:"type"`
+	CreatedAt time.Time              `json:"createdAt"`
+	Data      webhookEventData       `json:"data"`
+}
+
+type webhookEventData struct {
+	Reservation *model.Reservation `json:"reservation"`
+}
+
+// Publish は event を受け取る登録ごとに配送を記録する。予約の保存は済んでいるため、記録できなかった場合はログに残して続ける。
+func (s *WebhookService) Publish(event model.WebhookEventType, reservation *model.Reservation) {
+	if err := s.publish(event, reservation); err != nil {
+		s.logf("Failed to record webhook deliveries for %s of reservation %s: %v", event, reservation.ID, err)
+	}
+}
+
+func (s *WebhookService) publish(event model.WebhookEventType, reservation *model.Reservation) error {
+	subscriptions, err := s.repo.FindSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	eventID := uuid.New().String()
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(event) {
+			continue
+		}
+		if payload == nil {
+			// 同じイベントの配送は全て同じイベントIDと本文にする
+			if payload, err = json.Marshal(webhookEvent{
+				ID:        eventID,
+				Type:      event,
+				CreatedAt: now,
+				Data:      webhookEventData{Reservation: reservation},
+			}); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.CreateDelivery(model.NewWebhookDelivery(subscription.ID, eventID, event, payload, now)); err != nil {
+			return err
+		}
+	}
+	if payload != nil {
+		s.notifyPending()
+	}
+	return nil
+}
+
+// notifyPending は Pending のチャネルに値を送る。既に値がある場合は送らずに戻る。
+func (s *WebhookService) notifyPending() {
+	select {
+	case s.pending <- struct{}{}:
+	default:
+	}
+}
+
+// DeliverDue は now までに送信する配送を送信予定の早い順に取り出して送り、結果を記録する。送信に失敗した配送はエラーにせず再試行を予定する。
+// 一度に取り出すのは webhookBatchSize 件までで、webhookWorkers 件ずつ並行して送る。残りがある場合は Pending のチャネルに値を送る。
+// 複数のサーバーが同時に呼んでも、各配送は確保できたサーバーだけが送る。
+func (s *WebhookService) DeliverDue(ctx context.Context, now time.Time) error {
+	deliveries, err := s.repo.FindDueDeliveries(now, webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	subscriptions := make(map[string]*model.WebhookSubscription)
+	for _, delivery := range deliveries {
+		if _, ok := subscriptions[delivery.SubscriptionID]; ok {
+			continue
+		}
+		subscription, err := s.repo.FindSubscriptionByID(delivery.SubscriptionID)
+		if err != nil {
+			return err
+		}
+		subscriptions[delivery.SubscriptionID] = subscription
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mutex  sync.Mutex
+		errs   []error
+		worker = make(chan struct{}, webhookWorkers)
+	)
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+		worker <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-worker }()
+			if err := s.deliver(ctx, subscriptions[delivery.SubscriptionID], delivery, now); err != nil {
+				mutex.Lock()
+				errs = append(errs, err)
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(deliveries) == webhookBatchSize {
+		s.notifyPending()
+	}
+	return errors.Join(errs...)
+}
+
+// deliver は配送を確保してから1回送信し、結果を記録する。他のサーバーが先に確保した配送は送らない。
+func (s *WebhookService) deliver(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery, now time.Time) error {
+	claimed, err := s.repo.ClaimDelivery(delivery, now.Add(webhookClaimLease))
+	if err != nil || !claimed {
+		return err
+	}
+	s.attempt(ctx, subscription, delivery, now)
+	return s.repo.UpdateDelivery(delivery)
+}
+
+// attempt は配送を1回送信し、結果を delivery に設定する
+func (s *WebhookService) attempt(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery, now time.Time) {
+	delivery.Attempts++
+	delivery.UpdatedAt = now
+	delivery.ResponseStatus = 0
+	delivery.LastError = ""
+
+	if subscription == nil {
+		// 登録の削除と同時に取り出した配送
+		delivery.Status = model.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = "subscription deleted"
+		return
+	}
+
+	status, err := s.send(ctx, subscription, delivery, now)
+	delivery.ResponseStatus = status
+	switch {
+	case err != nil:
+		delivery.LastError = err.Error()
+	case status < 200 || status > 299:
+		delivery.LastError = fmt.Sprintf("unexpected status %d", status)
+	default:
+		delivery.Status = model.DeliverySucceeded
+		delivery.NextAttemptAt = nil
+		return
+	}
+
+	if delivery.Attempts >= WebhookMaxAttempts {
+		delivery.Status = model.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		return
+	}
+	next := now.Add(webhookRetryDelay(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+}
+
+// send は配送の本文に署名して POST し、応答のステータスコードを返す
+func (s *WebhookService) send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "yoyaku-webhook")
+	req.Header.Set(WebhookEventHeader, string(delivery.Event))
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 接続を再利用できるよう本文を読み切る
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponseBytes))
+	return resp.StatusCode, nil
+}
+
+// webhookRetryDelay は attempts 回目の送信に失敗した後、次に送信するまでの間隔を返す
+func webhookRetryDelay(attempts int) time.Duration {
+	return WebhookRetryBase << (attempts - 1)
+}
+
+// SignWebhookPayload は通知の署名を返す。署名は「タイムスタンプ.本文」の HMAC-SHA256 を16進数で表し、"sha256=" を前に付けたもの。
+// 受信側は WebhookTimestampHeader の値と受け取った本文から同じ値を計算し、WebhookSignatureHeader と比べて検証する。
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookSecret はランダムな署名の鍵を返す
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/auth"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/model"
+	"github.com/naofumi-fujii/489-yoyaku/backend/internal/repository"
+)
+
+// webhookRequest は webhookReceiver が受け取った通知
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver は通知を記録し、statuses の順にステータスを返す httptest のサーバー。statuses を使い切った後は 204 を返す。
+type webhookReceiver struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	requests []webhookRequest
+	statuses []int
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	r := &webhookReceiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.requests = append(r.requests, webhookRequest{header: req.Header.Clone(), body: body})
+		status := http.StatusNoContent
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *webhookReceiver) received() []webhookRequest {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]webhookRequest(nil), r.requests...)
+}
+
+// decodeWebhookEvent は通知の本文を読み込む
+func decodeWebhookEvent(t *testing.T, body []byte) webhookEvent {
+	t.Helper()
+	var event webhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("Failed to decode webhook payload %s: %v", body, err)
+	}
+	return event
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	// 準備
+	webhooks := NewWebhookService(repository.NewInMemoryWebhookRepository(), nil)
+	all := newWebhookReceiver(t)
+	deletedOnly := newWebhookReceiver(t)
+	subscription, err := webhooks.CreateSubscription(adminCtx, CreateWebhookParams{URL: all.URL, Secret: "secret", Events: model.WebhookEventTypes})
+	if err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	if _, err := webhooks.CreateSubscription(adminCtx, CreateWebhookParams{URL: deletedOnly.URL, Events: []model.WebhookEventType{model.EventReservationDeleted}}); err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	reservations := NewReservationService(repository.NewInMemoryReservationRepository(), newMockResourceRepository(activeResource), repository.NewInMemoryReservationSeriesRepository())
+	reservations.SetEvents(webhooks)
+
+	// 作成・変更・取り消しを保存した後に通知する
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	created, err := reservations.CreateReservation(adminCtx, CreateReservationParams{ResourceID: activeResource.ID, StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to create reservation: %v", err)
+	}
+	title := "定例"
+	if _, err := reservations.UpdateReservation(adminCtx, created.ID, UpdateReservationParams{Title: &title}); err != nil {
+		t.Fatalf("Failed to update reservation: %v", err)
+	}
+	if err := reservations.DeleteReservation(adminCtx, created.ID, DeleteReservationParams{}); err != nil {
+		t.Fatalf("Failed to delete reservation: %v", err)
+	}
+	// 重なって保存できなかった予約は通知しない
+	_, _ = reservations.CreateReservation(adminCtx, CreateReservationParams{ResourceID: activeResource.ID, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)})
+	_, err = reservations.CreateReservation(adminCtx, CreateReservationParams{ResourceID: activeResource.ID, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)})
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected ConflictError, got %v", err)
+	}
+
+	// 実行
+	if err := webhooks.DeliverDue(context.Background(), time.Now()); err != nil {
+		t.Fatalf("Failed to deliver webhooks: %v", err)
+	}
+
+	// 検証
+	requests := all.received()
+	if len(requests) != 4 {
+		t.Fatalf("Expected 4 notifications, got %d", len(requests))
+	}
+	types := make(map[model.WebhookEventType]int)
+	for _, req := range requests {
+		event := decodeWebhookEvent(t, req.body)
+		types[event.Type]++
+		if req.header.Get(WebhookEventHeader) != string(event.Type) || req.header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected headers for %s: %v", event.Type, req.header)
+		}
+		timestamp, err := strconv.ParseInt(req.header.Get(WebhookTimestampHeader), 10, 64)
+		if err != nil {
+			t.Fatalf("Invalid timestamp header: %v", err)
+		}
+		if got, want := req.header.Get(WebhookSignatureHeader), SignWebhookPayload("secret", timestamp, req.body); got != want {
+			t.Errorf("Expected signature %s, got %s", want, got)
+		}
+		if event.Type == model.EventReservationDeleted && event.Data.Reservation.Status != model.StatusCancelled {
+			t.Errorf("Expected cancelled reservation in %s, got %s", event.Type, event.Data.Reservation.Status)
+		}
+	}
+	if types[model.EventReservationCreated] != 2 || types[model.EventReservationUpdated] != 1 || types[model.EventReservationDeleted] != 1 {
+		t.Errorf("Unexpected event types: %v", types)
+	}
+	if requests := deletedOnly.received(); len(requests) != 1 || decodeWebhookEvent(t, requests[0].body).Type != model.EventReservationDeleted {
+		t.Errorf("Expected only the deleted event, got %d notifications", len(requests))
+	}
+	deliveries, err := webhooks.ListDeliveries(adminCtx, subscription.ID)
+	if err != nil || len(deliveries) != 4 {
+		t.Fatalf("Expected 4 deliveries, got %d, %v", len(deliveries), err)
+	}
+	for _, d := range deliveries {
+		if d.Status != model.DeliverySucceeded || d.Attempts != 1 || d.ResponseStatus != http.StatusNoContent || d.NextAttemptAt != nil {
+			t.Errorf("Expected succeeded delivery, got %+v", d)
+		}
+	}
+}
+
+func TestWebhookService_DeliverDue_Retry(t *testing.T) {
+	// 準備
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+	repo := repository.NewInMemoryWebhookRepository()
+	webhooks := NewWebhookService(repo, nil)
+	subscription, err := webhooks.CreateSubscription(adminCtx, CreateWebhookParams{URL: receiver.URL, Events: model.WebhookEventTypes})
+	if err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	webhooks.Publish(model.EventReservationCreated, model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour)))
+	now := time.Now()
+
+	// 失敗すると間隔を倍にしながら再試行を予定する
+	for i, wantDelay := range []time.Duration{WebhookRetryBase, 2 * WebhookRetryBase} {
+		if err := webhooks.DeliverDue(context.Background(), now); err != nil {
+			t.Fatalf("Failed to deliver webhooks: %v", err)
+		}
+		deliveries, _ := webhooks.ListDeliveries(adminCtx, subscription.ID)
+		d := deliveries[0]
+		if d.Status != model.DeliveryPending || d.Attempts != i+1 || d.LastError == "" || d.NextAttemptAt == nil || !d.NextAttemptAt.Equal(now.Add(wantDelay)) {
+			t.Fatalf("Expected retry after %v, got %+v", wantDelay, d)
+		}
+		// 予定の前には送信しない
+		if err := webhooks.DeliverDue(context.Background(), now.Add(wantDelay-time.Second)); err != nil {
+			t.Fatalf("Failed to deliver webhooks: %v", err)
+		}
+		if got := len(receiver.received()); got != i+1 {
+			t.Fatalf("Expected %d attempts before the retry is due, got %d", i+1, got)
+		}
+		now = now.Add(wantDelay)
+	}
+
+	// 成功すると配送を終える
+	if err := webhooks.DeliverDue(context.Background(), now); err != nil {
+		t.Fatalf("Failed to deliver webhooks: %v", err)
+	}
+	deliveries, _ := webhooks.ListDeliveries(adminCtx, subscription.ID)
+	if d := deliveries[0]; d.Status != model.DeliverySucceeded || d.Attempts != 3 || d.LastError != "" {
+		t.Errorf("Expected succeeded delivery after 3 attempts, got %+v", d)
+	}
+	requests := receiver.received()
+	if len(requests) != 3 || string(requests[0].body) != string(requests[2].body) {
+		t.Errorf("Expected the same payload on every attempt, got %d requests", len(requests))
+	}
+}
+
+func TestWebhookService_DeliverDue_Concurrent(t *testing.T) {
+	// 準備: 同じ配送の記録を共有する2台のサーバー
+	receiver := newWebhookReceiver(t)
+	repo := repository.NewInMemoryWebhookRepository()
+	servers := []*WebhookService{NewWebhookService(repo, nil), NewWebhookService(repo, nil)}
+	if _, err := servers[0].CreateSubscription(adminCtx, CreateWebhookParams{URL: receiver.URL, Events: model.WebhookEventTypes}); err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	const events = 20
+	for i := 0; i < events; i++ {
+		servers[0].Publish(model.EventReservationCreated, model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour)))
+	}
+
+	// 実行
+	now := time.Now()
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.DeliverDue(context.Background(), now); err != nil {
+				t.Errorf("Failed to deliver webhooks: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 検証: 各配送はどちらか1台だけが送る
+	seen := make(map[string]int)
+	for _, req := range receiver.received() {
+		seen[req.header.Get(WebhookDeliveryHeader)]++
+	}
+	if len(seen) != events {
+		t.Errorf("Expected %d deliveries, got %d", events, len(seen))
+	}
+	for id, count := range seen {
+		if count != 1 {
+			t.Errorf("Expected delivery %s to be sent once, got %d", id, count)
+		}
+	}
+}
+
+func TestWebhookService_DeliverDue_Parallel(t *testing.T) {
+	// 準備: 応答に時間のかかる受信側
+	const delay = 200 * time.Millisecond
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+	webhooks := NewWebhookService(repository.NewInMemoryWebhookRepository(), nil)
+	if _, err := webhooks.CreateSubscription(adminCtx, CreateWebhookParams{URL: receiver.URL, Events: model.WebhookEventTypes}); err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	for i := 0; i < webhookWorkers; i++ {
+		webhooks.Publish(model.EventReservationCreated, model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour)))
+	}
+
+	// 実行
+	started := time.Now()
+	if err := webhooks.DeliverDue(context.Background(), time.Now()); err != nil {
+		t.Fatalf("Failed to deliver webhooks: %v", err)
+	}
+
+	// 検証: 1件ずつ送るよりも早く終わる
+	if elapsed := time.Since(started); elapsed >= webhookWorkers*delay/2 {
+		t.Errorf("Expected deliveries to be sent in parallel, took %v", elapsed)
+	}
+}
+
+func TestWebhookService_DeliverDue_GiveUp(t *testing.T) {
+	// 準備
+	statuses := make([]int, WebhookMaxAttempts)
+	for i := range statuses {
+		statuses[i] = http.StatusServiceUnavailable
+	}
+	receiver := newWebhookReceiver(t, statuses...)
+	webhooks := NewWebhookService(repository.NewInMemoryWebhookRepository(), nil)
+	subscription, _ := webhooks.CreateSubscription(adminCtx, CreateWebhookParams{URL: receiver.URL, Events: model.WebhookEventTypes})
+	webhooks.Publish(model.EventReservationUpdated, model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour)))
+
+	// 実行
+	now := time.Now()
+	for i := 0; i < WebhookMaxAttempts+1; i++ {
+		if err := webhooks.DeliverDue(context.Background(), now); err != nil {
+			t.Fatalf("Failed to deliver webhooks: %v", err)
+		}
+		now = now.Add(webhookRetryDelay(WebhookMaxAttempts))
+	}
+
+	// 検証
+	deliveries, _ := webhooks.ListDeliveries(adminCtx, subscription.ID)
+	if d := deliveries[0]; d.Status != model.DeliveryFailed || d.Attempts != WebhookMaxAttempts || d.ResponseStatus != http.StatusServiceUnavailable || d.NextAttemptAt != nil {
+		t.Errorf("Expected failed delivery after %d attempts, got %+v", WebhookMaxAttempts, d)
+	}
+	if got := len(receiver.received()); got != WebhookMaxAttempts {
+		t.Errorf("Expected %d attempts, got %d", WebhookMaxAttempts, got)
+	}
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	// 準備
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError)
+	webhooks := NewWebhookService(repository.NewInMemoryWebhookRepository(), nil)
+	subscription, _ := webhooks.CreateSubscription(adminCtx, CreateWebhookParams{URL: receiver.URL, Events: model.WebhookEventTypes})
+	other, _ := webhooks.CreateSubscription(adminCtx, CreateWebhookParams{URL: receiver.URL, Events: model.WebhookEventTypes})
+	webhooks.Publish(model.EventReservationCreated, model.NewReservation(activeResource.ID, time.Now(), time.Now().Add(time.Hour)))
+	original, _ := webhooks.ListDeliveries(adminCtx, subscription.ID)
+	if err := webhooks.DeliverDue(context.Background(), time.Now()); err != nil {
+		t.Fatalf("Failed to deliver webhooks: %v", err)
+	}
+
+	// 実行
+	redelivery, err := webhooks.Redeliver(adminCtx, subscription.ID, original[0].ID)
+	if err != nil {
+		t.Fatalf("Failed to redeliver: %v", err)
+	}
+	select {
+	case <-webhooks.Pending():
+	default:
+		t.Error("Expected a pending notification for the redelivery")
+	}
+	if err := webhooks.DeliverDue(context.Background(), time.Now()); err != nil {
+		t.Fatalf("Failed to deliver webhooks: %v", err)
+	}
+
+	// 検証
+	if redelivery.ID == original[0].ID || redelivery.EventID != original[0].EventID || redelivery.RedeliveryOf != original[0].ID {
+		t.Errorf("Expected a new delivery of event %s, got %+v", original[0].EventID, redelivery)
+	}
+	deliveries, _ := webhooks.ListDeliveries(adminCtx, subscription.ID)
+	if len(deliveries) != 2 || deliveries[0].ID != redelivery.ID || deliveries[0].Status != model.DeliverySucceeded {
+		t.Errorf("Expected the redelivery to succeed, got %+v", deliveries)
+	}
+	// 他の登録の配送は再配送できない
+	if _, err := webhooks.Redeliver(adminCtx, other.ID, original[0].ID); !errors.Is(err, ErrWebhookDeliveryNotFound) {
+		t.Errorf("Expected ErrWebhookDeliveryNotFound, got %v", err)
+	}
+}
+
+func TestWebhookService_CreateSubscription_Errors(t *testing.T) {
+	memberCtx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: "user-1", Role: model.RoleMember})
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		params  CreateWebhookParams
+		wantErr error
+	}{
+		{name: "管理者以外は登録できない", ctx: memberCtx, params: CreateWebhookParams{URL: "https://example.com/hook", Events: model.WebhookEventTypes}, wantErr: ErrForbidden},
+		{name: "相対 URL", ctx: adminCtx, params: CreateWebhookParams{URL: "/hook", Events: model.WebhookEventTypes}, wantErr: ErrInvalidWebhookURL},
+		{name: "http(s) 以外のスキーム", ctx: adminCtx, params: CreateWebhookParams{URL: "ftp://example.com/hook", Events: model.WebhookEventTypes}, wantErr: ErrInvalidWebhookURL},
+		{name: "イベントの指定がない", ctx: adminCtx, params: CreateWebhookParams{URL: "https://example.com/hook"}, wantErr: ErrInvalidWebhookEvents},
+		{name: "未定義のイベント", ctx: adminCtx, params: CreateWebhookParams{URL: "https://example.com/hook", Events: []model.WebhookEventType{"reservation.moved"}}, wantErr: ErrInvalidWebhookEvents},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 準備
+			webhooks := NewWebhookService(repository.NewInMemoryWebhookRepository(), nil)
+
+			// 実行
+			_, err := webhooks.CreateSubscription(tt.ctx, tt.params)
+
+			// 検証
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -16,24 +16,42 @@ type Story = StoryObj<typeof ReservationList>;
 const mockReservations = [
   {
     id: '1',
+    resourceId: 'room-a',
+    title: '定例会議',
+    description: '',
+    attendees: [],
     startTime: '2024-12-01T10:00:00Z',
     endTime: '2024-12-01T11:00:00Z',
+    status: 'confirmed',
     createdAt: '2024-11-30T08:00:00Z',
     updatedAt: '2024-11-30T08:00:00Z',
+    version: 1,
   },
   {
     id: '2',
+    resourceId: 'room-a',
+    title: '定例会議',
+    description: '',
+    attendees: [],
     startTime: '2024-12-02T14:00:00Z',
     endTime: '2024-12-02T15:00:00Z',
+    status: 'confirmed',
     createdAt: '2024-11-30T09:00:00Z',
     updatedAt: '2024-11-30T09:00:00Z',
+    version: 1,
   },
   {
     id: '3',
+    resourceId: 'room-a',
+    title: '定例会議',
+    description: '',
+    attendees: [],
     startTime: '2024-12-03T16:00:00Z',
     endTime: '2024-12-03T17:00:00Z',
+    status: 'confirmed',
     createdAt: '2024-11-30T10:00:00Z',
     updatedAt: '2024-11-30T10:00:00Z',
+    version: 1,
   },
 ];
 
@@ -17,9 +17,11 @@ interface CalendarProps {
   reservations: Reservation[];
   onSelect: ({ start, end }: { start: Date; end: Date }) => void;
   onDelete?: (id: string) => void;
+  // 表示する期間が変わったとき（最初の表示を含む）に呼ばれる。end は期間の直後の時刻
+  onDatesSet?: ({ start, end }: { start: Date; end: Date }) => void;
 }
 
-export default function Calendar({ reservations, onSelect, onDelete }: CalendarProps) {
+export default function Calendar({ reservations, onSelect, onDelete, onDatesSet }: CalendarProps) {
   const calendarRef = useRef<FullCalendar | null>(null);
   const [showDeleteModal, setShowDeleteModal] = useState(false);
   const [selectedEvent, setSelectedEvent] = useState<{id: string, start: Date, end: Date} | null>(null);
@@ -81,6 +83,7 @@ export default function Calendar({ reservations, onSelect, onDelete }: CalendarP
           dayMaxEvents={true}
           events={events}
           select={onSelect}
+          datesSet={(arg) => onDatesSet?.({ start: arg.start, end: arg.end })}
           eventClick={handleEventClick}
           height="auto"
           allDaySlot={false}
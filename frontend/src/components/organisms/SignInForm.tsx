@@ -0,0 +1,96 @@
+'use client';
+
+import { useState } from 'react';
+import axios from 'axios';
+import toast from 'react-hot-toast';
+import { User } from '@/types';
+import Button from '../atoms/Button';
+import Card from '../atoms/Card';
+import Typography from '../atoms/Typography';
+
+interface SignInFormProps {
+  onSignedIn: (userId: string) => void;
+}
+
+const inputClass = 'w-full rounded border border-gray-300 px-2 py-1';
+
+// ユーザーを登録するか、登録済みのユーザーID を入力して予約を操作するユーザーを決める
+export default function SignInForm({ onSignedIn }: SignInFormProps) {
+  const [displayName, setDisplayName] = useState('');
+  const [email, setEmail] = useState('');
+  const [userId, setUserId] = useState('');
+  const [isSubmitting, setIsSubmitting] = useState(false);
+
+  const handleRegister = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setIsSubmitting(true);
+    try {
+      const response = await axios.post<User>('/api/users', { displayName, email });
+      toast.success(`${response.data.displayName} として登録しました`);
+      onSignedIn(response.data.id);
+    } catch (error) {
+      console.error('Failed to register user:', error);
+      if (axios.isAxiosError(error) && error.response?.status === 409) {
+        toast.error('このメールアドレスは登録済みです。ユーザーID を入力してください');
+      } else {
+        toast.error('ユーザーの登録に失敗しました');
+      }
+    } finally {
+      setIsSubmitting(false);
+    }
+  };
+
+  const handleSignIn = (e: React.FormEvent) => {
+    e.preventDefault();
+    if (userId.trim()) {
+      onSignedIn(userId.trim());
+    }
+  };
+
+  return (
+    <Card padding="large" className="mx-auto max-w-md">
+      <Typography variant="h2" className="mb-4">ユーザーの登録</Typography>
+      <form onSubmit={handleRegister} className="mb-8 space-y-3">
+        <div>
+          <label htmlFor="display-name" className="text-sm font-medium text-gray-700">名前</label>
+          <input
+            id="display-name"
+            className={inputClass}
+            value={displayName}
+            maxLength={100}
+            required
+            onChange={(e) => setDisplayName(e.target.value)}
+          />
+        </div>
+        <div>
+          <label htmlFor="email" className="text-sm font-medium text-gray-700">メールアドレス</label>
+          <input
+            id="email"
+            type="email"
+            className={inputClass}
+            value={email}
+            maxLength={254}
+            required
+            onChange={(e) => setEmail(e.target.value)}
+          />
+        </div>
+        <Button type="submit" isFullWidth disabled={isSubmitting}>登録する</Button>
+      </form>
+
+      <Typography variant="h3" className="mb-2">登録済みの場合</Typography>
+      <form onSubmit={handleSignIn} className="space-y-3">
+        <div>
+          <label htmlFor="user-id" className="text-sm font-medium text-gray-700">ユーザーID</label>
+          <input
+            id="user-id"
+            className={inputClass}
+            value={userId}
+            required
+            onChange={(e) => setUserId(e.target.value)}
+          />
+        </div>
+        <Button type="submit" variant="secondary" isFullWidth>このユーザーで使う</Button>
+      </form>
+    </Card>
+  );
+}
@@ -3,7 +3,7 @@
 import { useState, useEffect } from 'react';
 import axios from 'axios';
 import toast from 'react-hot-toast';
-import { Reservation } from '@/types';
+import { Reservation, Resource } from '@/types';
 import Calendar from '../organisms/Calendar';
 import ReservationModal from '../organisms/ReservationModal';
 import ReservationList from '../organisms/ReservationList';
@@ -11,20 +11,83 @@ import MainLayout from '../templates/MainLayout';
 import SplitLayout from '../templates/SplitLayout';
 import Typography from '../atoms/Typography';
 import Spinner from '../atoms/Spinner';
+import Button from '../atoms/Button';
+import SignInForm from '../organisms/SignInForm';
+import { applyIdentity, hasApiToken, loadUserId } from '@/lib/identity';
+
+// 一度のリクエストで取得する予約の件数（バックエンドの上限）
+const RESERVATION_PAGE_SIZE = 500;
+
+interface DateRange {
+  from: string;
+  to: string;
+}
+
+// カレンダーを表示するまでは今週（日曜日から）を表示中の期間とする
+function currentWeek(): DateRange {
+  const start = new Date();
+  start.setHours(0, 0, 0, 0);
+  start.setDate(start.getDate() - start.getDay());
+  const end = new Date(start);
+  end.setDate(end.getDate() + 7);
+  return { from: start.toISOString(), to: end.toISOString() };
+}
 
 export default function HomePage() {
+  // バックエンドは予約の操作ごとにユーザーを確認するため、ユーザーが決まるまで予約を取得しない
+  const [userId, setUserId] = useState<string | null>(null);
+  const [isIdentityLoaded, setIsIdentityLoaded] = useState(false);
+  const isSignedIn = hasApiToken() || userId !== null;
   const [isModalOpen, setIsModalOpen] = useState(false);
   const [selectedRange, setSelectedRange] = useState<{ start: Date; end: Date } | null>(null);
+  // 同じ時間帯の予約を再送しても重複しないよう、時間帯を選ぶたびに作り直す
+  const [idempotencyKey, setIdempotencyKey] = useState('');
   const [reservations, setReservations] = useState<Reservation[]>([]);
+  const [resources, setResources] = useState<Resource[]>([]);
+  const [selectedResourceId, setSelectedResourceId] = useState<string>('');
+  // 一覧はカレンダーに表示中の期間の予約だけを取得する
+  const [visibleRange, setVisibleRange] = useState<DateRange>(currentWeek);
   const [isLoading, setIsLoading] = useState(true);
   const [isConnected, setIsConnected] = useState(false);
   const [connectionError, setConnectionError] = useState(false);
 
-  const fetchReservations = async () => {
+  const fetchResources = async () => {
+    try {
+      const response = await axios.get<Resource[]>('/api/resources');
+      const activeResources = (response.data ?? []).filter((resource) => resource.active);
+      setResources(activeResources);
+      if (activeResources.length > 0) {
+        setSelectedResourceId((current) => current || activeResources[0].id);
+      }
+    } catch (error) {
+      console.error('Failed to fetch resources:', error);
+    }
+  };
+
+  // 期間内の予約を、続きのページ（X-Next-Cursor）もたどって全て取得する
+  const fetchAllReservations = async (resourceId: string, range: DateRange) => {
+    const all: Reservation[] = [];
+    let cursor: string | undefined;
+    do {
+      const response = await axios.get<Reservation[]>('/api/reservations', {
+        params: {
+          resourceId: resourceId || undefined,
+          from: range.from,
+          to: range.to,
+          limit: RESERVATION_PAGE_SIZE,
+          cursor,
+        },
+      });
+      all.push(...(response.data ?? []));
+      cursor = response.headers['x-next-cursor'] || undefined;
+    } while (cursor);
+    return all;
+  };
+
+  const fetchReservations = async (resourceId = selectedResourceId, range = visibleRange) => {
     try {
       setIsLoading(true);
-      const response = await axios.get('/api/reservations');
-      setReservations(response.data);
+      setReservations(await fetchAllReservations(resourceId, range));
       setIsConnected(true);
       setConnectionError(false);
     } catch (error) {
@@ -32,7 +95,7 @@ export default function HomePage() {
       if (!isConnected) {
         setConnectionError(true);
         // Retry connection after 2 seconds
-        setTimeout(fetchReservations, 2000);
+        setTimeout(() => fetchReservations(resourceId, range), 2000);
       } else {
         toast.error('予約の取得に失敗しました');
       }
@@ -41,22 +104,62 @@ export default function HomePage() {
     }
   };
 
+  const handleSignedIn = (id: string | null) => {
+    applyIdentity(id);
+    setUserId(id);
+  };
+
   useEffect(() => {
-    fetchReservations();
+    handleSignedIn(loadUserId());
+    setIsIdentityLoaded(true);
+
+    // 削除されたユーザーの ID が保存されている場合は、ユーザーを選び直してもらう
+    const interceptor = axios.interceptors.response.use(undefined, (error) => {
+      if (!hasApiToken() && axios.isAxiosError(error) && error.response?.status === 401) {
+        toast.error('ユーザーが見つかりません。もう一度登録してください');
+        handleSignedIn(null);
+      }
+      return Promise.reject(error);
+    });
+    return () => axios.interceptors.response.eject(interceptor);
   }, []);
 
+  useEffect(() => {
+    if (!isSignedIn) return;
+    fetchResources();
+  }, [isSignedIn]);
+
+  useEffect(() => {
+    if (!isSignedIn) return;
+    fetchReservations(selectedResourceId, visibleRange);
+  }, [selectedResourceId, isSignedIn, visibleRange]);
+
+  const handleDatesSet = ({ start, end }: { start: Date; end: Date }) => {
+    const from = start.toISOString();
+    const to = end.toISOString();
+    setVisibleRange((current) => (current.from === from && current.to === to ? current : { from, to }));
+  };
+
   const handleSelect = (info: { start: Date; end: Date }) => {
     setSelectedRange({ start: info.start, end: info.end });
+    setIdempotencyKey(crypto.randomUUID());
     setIsModalOpen(true);
   };
 
   const handleConfirmReservation = async () => {
     if (!selectedRange) return;
+    if (!selectedResourceId) {
+      toast.error('予約するリソースを選択してください');
+      return;
+    }
 
     try {
       await axios.post('/api/reservations', {
+        resourceId: selectedResourceId,
         startTime: selectedRange.start.toISOString(),
         endTime: selectedRange.end.toISOString(),
+      }, {
+        headers: { 'Idempotency-Key': idempotencyKey },
       });
       toast.success('予約が完了しました');
       setIsModalOpen(false);
@@ -69,15 +172,35 @@ export default function HomePage() {
 
   const handleDeleteReservation = async (id: string) => {
     try {
-      await axios.delete(`/api/reservations/${id}`);
+      const reservation = reservations.find((r) => r.id === id);
+      await axios.delete(`/api/reservations/${id}`, {
+        headers: reservation ? { 'If-Match': `"${reservation.version}"` } : undefined,
+      });
       toast.success('予約をキャンセルしました');
       fetchReservations();
     } catch (error) {
       console.error('Failed to delete reservation:', error);
+      if (axios.isAxiosError(error) && error.response?.status === 412) {
+        toast.error('予約が他の操作で変更されました。最新の内容を確認してください');
+        fetchReservations();
+        return;
+      }
       toast.error('予約のキャンセルに失敗しました');
     }
   };
 
+  if (!isIdentityLoaded) {
+    return null;
+  }
+
+  if (!isSignedIn) {
+    return (
+      <MainLayout title="予約システム">
+        <SignInForm onSignedIn={handleSignedIn} />
+      </MainLayout>
+    );
+  }
+
   if (!isConnected) {
     return (
       <div className="flex min-h-screen flex-col items-center justify-center p-6">
@@ -98,14 +221,38 @@ export default function HomePage() {
 
   return (
     <MainLayout title="予約システム">
+      <div className="mb-4 flex items-center space-x-2">
+        <label htmlFor="resource-select" className="text-sm font-medium text-gray-700">
+          リソース
+        </label>
+        <select
+          id="resource-select"
+          className="rounded border border-gray-300 px-2 py-1"
+          value={selectedResourceId}
+          onChange={(e) => setSelectedResourceId(e.target.value)}
+        >
+          {resources.length === 0 && <option value="">リソースが登録されていません</option>}
+          {resources.map((resource) => (
+            <option key={resource.id} value={resource.id}>
+              {resource.name}
+            </option>
+          ))}
+        </select>
+        {userId && !hasApiToken() && (
+          <Button variant="secondary" size="small" className="ml-auto" onClick={() => handleSignedIn(null)}>
+            ユーザーを切り替える
+          </Button>
+        )}
+      </div>
       <SplitLayout
         leftTitle="カレンダー"
-        rightTitle="予約一覧"
+        rightTitle="表示中の期間の予約"
         leftContent={
           <Calendar 
             reservations={reservations}
             onSelect={handleSelect}
             onDelete={handleDeleteReservation}
+            onDatesSet={handleDatesSet}
           />
         }
         rightContent={
@@ -0,0 +1,31 @@
+import axios from 'axios';
+
+// ヘッダーでユーザーを識別するバックエンド（AUTH_TRUST_USER_HEADER=true）に送るユーザーID の保存先
+const STORAGE_KEY = 'yoyaku.userId';
+
+// JWT で認証するバックエンド向けのトークン。設定するとユーザーID の代わりに Authorization ヘッダーで送る
+const apiToken = process.env.NEXT_PUBLIC_API_TOKEN;
+
+export function hasApiToken(): boolean {
+  return Boolean(apiToken);
+}
+
+export function loadUserId(): string | null {
+  if (typeof window === 'undefined') return null;
+  return window.localStorage.getItem(STORAGE_KEY);
+}
+
+// 以降の API リクエストで送る識別情報を設定する。userId が null の場合はユーザーID を送らない
+export function applyIdentity(userId: string | null) {
+  if (apiToken) {
+    axios.defaults.headers.common['Authorization'] = `Bearer ${apiToken}`;
+    return;
+  }
+  if (userId) {
+    window.localStorage.setItem(STORAGE_KEY, userId);
+    axios.defaults.headers.common['X-User-ID'] = userId;
+  } else {
+    window.localStorage.removeItem(STORAGE_KEY);
+    delete axios.defaults.headers.common['X-User-ID'];
+  }
+}
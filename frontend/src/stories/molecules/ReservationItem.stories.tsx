@@ -26,10 +26,16 @@ export const Default: Story = {
   args: {
     reservation: {
       id: '1',
+      resourceId: 'room-a',
+      title: '定例会議',
+      description: '',
+      attendees: [],
       startTime: '2024-03-29T10:00:00Z',
       endTime: '2024-03-29T11:00:00Z',
+      status: 'confirmed',
       createdAt: '2024-03-28T08:00:00Z',
       updatedAt: '2024-03-28T08:00:00Z',
+      version: 1,
     },
     onDelete: (id) => console.log(`Delete reservation with id: ${id}`),
   },
@@ -39,10 +45,16 @@ export const Afternoon: Story = {
   args: {
     reservation: {
       id: '2',
+      resourceId: 'room-a',
+      title: '定例会議',
+      description: '',
+      attendees: [],
       startTime: '2024-03-29T14:30:00Z',
       endTime: '2024-03-29T16:00:00Z',
+      status: 'confirmed',
       createdAt: '2024-03-28T09:00:00Z',
       updatedAt: '2024-03-28T09:00:00Z',
+      version: 1,
     },
     onDelete: (id) => console.log(`Delete reservation with id: ${id}`),
   },
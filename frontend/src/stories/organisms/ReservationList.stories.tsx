@@ -23,24 +23,42 @@ type Story = StoryObj<typeof ReservationList>;
 const mockReservations = [
   {
     id: '1',
+    resourceId: 'room-a',
+    title: '定例会議',
+    description: '',
+    attendees: [],
     startTime: '2024-03-29T10:00:00Z',
     endTime: '2024-03-29T11:00:00Z',
+    status: 'confirmed',
     createdAt: '2024-03-28T08:00:00Z',
     updatedAt: '2024-03-28T08:00:00Z',
+    version: 1,
   },
   {
     id: '2',
+    resourceId: 'room-a',
+    title: '定例会議',
+    description: '',
+    attendees: [],
     startTime: '2024-03-30T14:00:00Z',
     endTime: '2024-03-30T15:00:00Z',
+    status: 'confirmed',
     createdAt: '2024-03-28T09:00:00Z',
     updatedAt: '2024-03-28T09:00:00Z',
+    version: 1,
   },
   {
     id: '3',
+    resourceId: 'room-a',
+    title: '定例会議',
+    description: '',
+    attendees: [],
     startTime: '2024-03-31T16:00:00Z',
     endTime: '2024-03-31T17:00:00Z',
+    status: 'confirmed',
     createdAt: '2024-03-28T10:00:00Z',
     updatedAt: '2024-03-28T10:00:00Z',
+    version: 1,
   },
 ];
 
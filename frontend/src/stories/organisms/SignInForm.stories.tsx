@@ -0,0 +1,20 @@
+import type { Meta, StoryObj } from '@storybook/nextjs';
+import SignInForm from '../../components/organisms/SignInForm';
+
+const meta: Meta<typeof SignInForm> = {
+  component: SignInForm,
+  title: 'Organisms/SignInForm',
+  parameters: {
+    layout: 'centered',
+  },
+  tags: ['autodocs'],
+};
+
+export default meta;
+type Story = StoryObj<typeof SignInForm>;
+
+export const Default: Story = {
+  args: {
+    onSignedIn: (userId: string) => console.log('Signed in as', userId),
+  },
+};
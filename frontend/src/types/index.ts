@@ -1,7 +1,80 @@
+export interface Resource {
+  id: string;
+  name: string;
+  description: string;
+  capacity: number;
+  active: boolean;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export type UserRole = 'viewer' | 'member' | 'approver' | 'admin';
+
+export interface User {
+  id: string;
+  displayName: string;
+  email: string;
+  role: UserRole;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export type ReservationStatus = 'pending' | 'confirmed' | 'cancelled' | 'completed' | 'no_show';
+
 export interface Reservation {
   id: string;
+  resourceId: string;
+  seriesId?: string;
+  // 予約したユーザーのID。匿名で作成した予約にはない
+  userId?: string;
+  title: string;
+  description: string;
+  // 参加者のメールアドレス
+  attendees: string[];
   startTime: string;
   endTime: string;
+  status: ReservationStatus;
+  cancelledAt?: string;
+  cancellationReason?: string;
   createdAt: string;
   updatedAt: string;
+  // 変更のたびに増える版。変更・取り消しの If-Match ヘッダーに使う
+  version: number;
+}
+
+export interface BusinessHours {
+  // 0 (日曜日) 〜 6 (土曜日)
+  weekday: number;
+  open: string;
+  close: string;
+}
+
+export interface WeeklySchedule {
+  timeZone: string;
+  hours: BusinessHours[];
+}
+
+export interface Blackout {
+  id: string;
+  resourceId?: string;
+  startTime: string;
+  endTime: string;
+  reason?: string;
+  createdAt: string;
+}
+
+export type ClosedReason = 'outside_business_hours' | 'blackout';
+
+export interface ClosedPeriod {
+  startTime: string;
+  endTime: string;
+  reason: ClosedReason;
+  blackoutId?: string;
+  note?: string;
+}
+
+export interface Holiday {
+  // YYYY-MM-DD
+  date: string;
+  name: string;
 }